@@ -1,13 +1,29 @@
-// Redka CLI. Executes commands from a file.
+// Redka CLI. Executes commands from a file,
+// or replays an append-only command log.
+//
+// Example usage (execute commands against an in-memory database):
+//
+//	redka-cli commands.txt
+//
+// Example usage (restore a snapshot to a point in time):
+//
+//	cp dump.db restored.db
+//	redka-cli -db restored.db -aof -from 2026-10-18T12:00:00Z \
+//	    -until 2026-10-18T12:30:00Z appendonly.aof.* appendonly.aof
 package main
 
 import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/aof"
 	"github.com/flarco/redka/internal/command"
 	"github.com/flarco/redka/internal/redis"
 	_ "github.com/mattn/go-sqlite3"
@@ -15,29 +31,65 @@ import (
 
 const dbURI = "file:/data.db?vfs=memdb"
 
+// Config holds the CLI configuration.
+type Config struct {
+	Path  string // database path
+	AOF   bool   // replay append-only log files
+	From  string // replay commands logged at or after this time
+	Until string // replay commands logged at or before this time
+}
+
+var config Config
+
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage: redka-cli <filename>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: redka-cli [options] <filename>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       redka-cli -aof [options] <aof-file>...\n")
 		flag.PrintDefaults()
 	}
+	flag.StringVar(&config.Path, "db", dbURI, "database path (in-memory by default)")
+	flag.BoolVar(&config.AOF, "aof", false, "replay append-only log files (in the given order)")
+	flag.StringVar(&config.From, "from", "", "replay commands logged at or after the time (RFC 3339 or Unix seconds)")
+	flag.StringVar(&config.Until, "until", "", "replay commands logged at or before the time (RFC 3339 or Unix seconds)")
 }
 
 func main() {
 	// Parse command line arguments.
 	flag.Parse()
-	if len(flag.Args()) != 1 {
+	if len(flag.Args()) == 0 || (!config.AOF && len(flag.Args()) != 1) {
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// File with commands.
-	filename := flag.Arg(0)
-
 	// Open the database.
-	db, err := redka.Open(dbURI, nil)
+	db, err := redka.Open(config.Path, nil)
 	if err != nil {
 		fail("failed to open database: %v\n", err)
 	}
+	defer db.Close()
+
+	// Replay the command log.
+	if config.AOF {
+		from, err := parseTime(config.From)
+		if err != nil {
+			fail("invalid -from time: %v\n", err)
+		}
+		until, err := parseTime(config.Until)
+		if err != nil {
+			fail("invalid -until time: %v\n", err)
+		}
+		r := newRunner(db, io.Discard)
+		count, err := replay(r, flag.Args(), from, until)
+		fmt.Printf("replayed %d commands\n", count)
+		if err != nil {
+			db.Close()
+			fail("failed to replay commands: %v\n", err)
+		}
+		return
+	}
+
+	// File with commands.
+	filename := flag.Arg(0)
 
 	// Read commands from the file.
 	cmds, err := readCommands(filename)
@@ -46,15 +98,17 @@ func main() {
 	}
 
 	// Execute the commands.
-	r := newRunner(db)
+	r := newRunner(db, os.Stdout)
 	err = r.run(cmds)
 	if err != nil {
+		db.Close()
 		os.Exit(1)
 	}
 }
 
 // readCommands reads commands from a file.
-func readCommands(filename string) ([][]byte, error) {
+// Each line is a command with space-separated arguments.
+func readCommands(filename string) ([][][]byte, error) {
 	if filename == "" {
 		return nil, nil
 	}
@@ -65,79 +119,156 @@ func readCommands(filename string) ([][]byte, error) {
 	}
 
 	lines := bytes.Split(data, []byte{'\n'})
-	commands := make([][]byte, 0, len(lines))
+	commands := make([][][]byte, 0, len(lines))
 	for _, line := range lines {
 		line = bytes.TrimSpace(line)
 		if len(line) == 0 || bytes.HasPrefix(line, []byte{'#'}) {
 			continue
 		}
-		commands = append(commands, line)
+		commands = append(commands, bytes.Fields(line))
 	}
 
 	return commands, nil
 }
 
+// replay executes the commands from the append-only log files
+// logged between from and until (zero times mean no limit).
+// Returns the number of executed commands.
+func replay(r *runner, paths []string, from, until time.Time) (int, error) {
+	count := 0
+	for _, path := range paths {
+		n, done, err := replayFile(r, path, from, until)
+		count += n
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", path, err)
+		}
+		if done {
+			break
+		}
+	}
+	if r.inMulti {
+		// The log ends in the middle of a transaction,
+		// which therefore was not committed.
+		r.inMulti = false
+		r.clear()
+	}
+	return count, nil
+}
+
+// replayFile executes the commands from a single log file.
+// Returns the number of executed commands, and done=true
+// if a command logged after the until time is found.
+func replayFile(r *runner, path string, from, until time.Time) (int, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+
+	count := 0
+	rd := aof.NewReader(file)
+	for {
+		e, err := rd.Next()
+		if err == io.EOF {
+			return count, false, nil
+		}
+		if err != nil {
+			return count, false, err
+		}
+		if !from.IsZero() && e.Time.Before(from) {
+			// The commands after SELECT run in the selected
+			// database, even if SELECT itself is skipped.
+			if normName(e.Args) == "select" {
+				if err := r.runSingle(e.Args, redis.RedkaDB(r.db)); err != nil {
+					return count, false, err
+				}
+			}
+			continue
+		}
+		if !until.IsZero() && e.Time.After(until) {
+			return count, true, nil
+		}
+		if err := r.handle(e.Args); err != nil {
+			return count, false, err
+		}
+		count++
+	}
+}
+
+// parseTime parses a time in RFC 3339 format or in Unix seconds.
+// Returns the zero time for an empty string.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
 // multiHandlers executes commands when the runner is in a MULTI state.
-var multiHandlers = map[string]func(*runner, []byte) error{
-	"multi": func(r *runner, cmd []byte) error {
-		fmt.Println(redis.ErrNestedMulti)
+var multiHandlers = map[string]func(*runner, [][]byte) error{
+	"multi": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, redis.ErrNestedMulti)
 		return redis.ErrNestedMulti
 	},
-	"exec": func(r *runner, cmd []byte) error {
-		fmt.Println(len(r.cmds))
-		err := r.db.Update(func(tx *redka.Tx) error {
-			return r.runBatch(r.cmds, redis.RedkaTx(tx))
+	"exec": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, len(r.cmds))
+		err := r.db.Select(r.w.db).Update(func(tx *redka.Tx) error {
+			return r.runBatch(r.cmds, tx)
 		})
 		r.inMulti = false
 		r.clear()
 		return err
 	},
-	"discard": func(r *runner, cmd []byte) error {
-		fmt.Println("OK")
+	"discard": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, "OK")
 		r.inMulti = false
 		r.clear()
 		return nil
 	},
-	"_": func(r *runner, cmd []byte) error {
-		fmt.Println("QUEUED")
+	"_": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, "QUEUED")
 		r.push(cmd)
 		return nil
 	},
 }
 
 // singleHandlers executes commands when the runner is in a regular state.
-var singleHandlers = map[string]func(*runner, []byte) error{
-	"multi": func(r *runner, cmd []byte) error {
-		fmt.Println("OK")
+var singleHandlers = map[string]func(*runner, [][]byte) error{
+	"multi": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, "OK")
 		r.inMulti = true
 		return nil
 	},
-	"exec": func(r *runner, cmd []byte) error {
-		fmt.Println(redis.ErrNotInMulti)
+	"exec": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, redis.ErrNotInMulti)
 		return redis.ErrNotInMulti
 	},
-	"discard": func(r *runner, cmd []byte) error {
-		fmt.Println(redis.ErrNotInMulti)
+	"discard": func(r *runner, cmd [][]byte) error {
+		fmt.Fprintln(r.out, redis.ErrNotInMulti)
 		return redis.ErrNotInMulti
 	},
-	"_": func(r *runner, cmd []byte) error {
-		return r.runSingle(cmd, redis.RedkaDB(r.db))
+	"_": func(r *runner, cmd [][]byte) error {
+		return r.runSingle(cmd, redis.RedkaDB(r.db.Select(r.w.db)))
 	},
 }
 
 // runner executes commands.
 type runner struct {
-	db *redka.DB
-	w  writer
+	db  *redka.DB
+	out io.Writer
+	w   *writer
 	*state
 }
 
-func newRunner(db *redka.DB) *runner {
-	return &runner{db: db, w: writer{}, state: newState()}
+func newRunner(db *redka.DB, out io.Writer) *runner {
+	return &runner{db: db, out: out, w: &writer{out: out}, state: newState()}
 }
 
 // run executes commands.
-func (r *runner) run(cmds [][]byte) error {
+func (r *runner) run(cmds [][][]byte) error {
 	if len(cmds) == 1 {
 		return r.runSingle(cmds[0], redis.RedkaDB(r.db))
 	}
@@ -152,9 +283,9 @@ func (r *runner) run(cmds [][]byte) error {
 }
 
 // handle manages mode switching and command execution.
-func (r *runner) handle(cmd []byte) error {
+func (r *runner) handle(cmd [][]byte) error {
 	name := normName(cmd)
-	var handlers map[string]func(*runner, []byte) error
+	var handlers map[string]func(*runner, [][]byte) error
 	if r.inMulti {
 		handlers = multiHandlers
 	} else {
@@ -167,10 +298,11 @@ func (r *runner) handle(cmd []byte) error {
 	}
 }
 
-// runBatch executes a batch of commands.
-func (r *runner) runBatch(cmds [][]byte, red redis.Redka) error {
+// runBatch executes a batch of commands in a transaction.
+// Commands after SELECT run in the newly selected database.
+func (r *runner) runBatch(cmds [][][]byte, tx *redka.Tx) error {
 	for _, cmd := range cmds {
-		err := r.runSingle(cmd, red)
+		err := r.runSingle(cmd, redis.RedkaTx(tx.Select(r.w.db)))
 		if err != nil {
 			return err
 		}
@@ -179,75 +311,101 @@ func (r *runner) runBatch(cmds [][]byte, red redis.Redka) error {
 }
 
 // runSingle executes a single command.
-func (r *runner) runSingle(cmd []byte, red redis.Redka) error {
-	c, err := command.Parse(bytes.Fields(cmd))
+func (r *runner) runSingle(cmd [][]byte, red redis.Redka) error {
+	c, err := command.Parse(cmd)
 	if err != nil {
-		return fmt.Errorf("parse command '%s': %v", cmd, err)
+		return fmt.Errorf("parse command '%s': %v", bytes.Join(cmd, []byte{' '}), err)
 	}
 	_, err = c.Run(r.w, red)
 	if err != nil {
-		return fmt.Errorf("run command '%s': %v", cmd, err)
+		return fmt.Errorf("run command '%s': %v", bytes.Join(cmd, []byte{' '}), err)
 	}
 	return nil
 }
 
 // print prints a command and its arguments.
-func (r *runner) print(cmd []byte) {
-	fmt.Println("---")
-	fmt.Printf("> %s\n", cmd)
+func (r *runner) print(cmd [][]byte) {
+	fmt.Fprintln(r.out, "---")
+	fmt.Fprintf(r.out, "> %s\n", bytes.Join(cmd, []byte{' '}))
 }
 
 // state stores the state of the runner.
 type state struct {
 	inMulti bool
-	cmds    [][]byte
+	cmds    [][][]byte
 }
 
 func newState() *state {
-	return &state{cmds: [][]byte{}}
+	return &state{cmds: [][][]byte{}}
 }
-func (s *state) push(cmd []byte) {
+func (s *state) push(cmd [][]byte) {
 	s.cmds = append(s.cmds, cmd)
 }
 func (s *state) clear() {
-	s.cmds = [][]byte{}
+	s.cmds = [][][]byte{}
 }
 
-// writer writes command results to the standard output.
-type writer struct{}
+// writer writes command results to the output,
+// and keeps track of the selected database.
+type writer struct {
+	out io.Writer
+	db  int
+}
+
+func (w *writer) DB() int {
+	return w.db
+}
+func (w *writer) SetDB(index int) {
+	w.db = index
+}
 
 func (w writer) WriteError(msg string) {
-	fmt.Println(msg)
+	fmt.Fprintln(w.out, msg)
 }
 func (w writer) WriteString(str string) {
-	fmt.Println(str)
+	fmt.Fprintln(w.out, str)
 }
 func (w writer) WriteBulk(bulk []byte) {
-	fmt.Println(string(bulk))
+	fmt.Fprintln(w.out, string(bulk))
 }
 func (w writer) WriteBulkString(bulk string) {
-	fmt.Println(bulk)
+	fmt.Fprintln(w.out, bulk)
 }
 func (w writer) WriteInt(num int) {
-	fmt.Printf("%d\n", num)
+	fmt.Fprintf(w.out, "%d\n", num)
 }
 func (w writer) WriteInt64(num int64) {
-	fmt.Printf("%d\n", num)
+	fmt.Fprintf(w.out, "%d\n", num)
 }
 func (w writer) WriteUint64(num uint64) {
-	fmt.Printf("%d\n", num)
+	fmt.Fprintf(w.out, "%d\n", num)
 }
 func (w writer) WriteArray(count int) {
 	// do nothing
 }
+func (w writer) WriteMap(count int) {
+	// do nothing
+}
+func (w writer) WriteSet(count int) {
+	// do nothing
+}
+func (w writer) WritePush(count int) {
+	// do nothing
+}
+func (w writer) WriteDouble(num float64) {
+	fmt.Fprintln(w.out, strconv.FormatFloat(num, 'f', -1, 64))
+}
+func (w writer) WriteBool(b bool) {
+	fmt.Fprintln(w.out, b)
+}
 func (w writer) WriteNull() {
-	fmt.Println("(nil)")
+	fmt.Fprintln(w.out, "(nil)")
 }
 func (w writer) WriteRaw(data []byte) {
-	fmt.Println(string(data))
+	fmt.Fprintln(w.out, string(data))
 }
 func (w writer) WriteAny(v any) {
-	fmt.Printf("%v\n", v)
+	fmt.Fprintf(w.out, "%v\n", v)
 }
 
 // fail prints an error message and exits with status 1.
@@ -257,6 +415,6 @@ func fail(format string, a ...any) {
 }
 
 // normName returns the name of a command.
-func normName(cmd []byte) string {
-	return string(bytes.Fields(cmd)[0])
+func normName(cmd [][]byte) string {
+	return strings.ToLower(string(cmd[0]))
 }
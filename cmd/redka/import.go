@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/rdb"
+)
+
+// importJSON imports keys in JSON Lines format
+// from a file (or stdin if the file is "-").
+// Example usage:
+//
+//	./redka import keys.jsonl redka.db
+func importJSON(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: redka import <jsonl-file> <data-source>\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	file := os.Stdin
+	if fs.Arg(0) != "-" {
+		var err error
+		file, err = os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	db, err := redka.Open(fs.Arg(1), &redka.Options{DriverName: driverName})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	count, err := db.Import(file)
+	if err != nil {
+		return fmt.Errorf("import after %d keys: %w", count, err)
+	}
+	slog.Info("import", "keys", count, "took", time.Since(start))
+	return nil
+}
+
+// importRDB imports a Redis RDB file into the database.
+// Example usage:
+//
+//	./redka import-rdb -db 0 dump.rdb redka.db
+func importRDB(args []string) error {
+	fs := flag.NewFlagSet("import-rdb", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: redka import-rdb [options] <rdb-file> <data-source>\n")
+		fs.PrintDefaults()
+	}
+	var opts rdb.LoadOptions
+	fs.IntVar(&opts.DB, "db", 0, "redis database to import (-1 for all, into the same numbered databases)")
+	fs.IntVar(&opts.BatchSize, "batch", rdb.DefaultBatchSize, "keys per transaction")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	db, err := redka.Open(fs.Arg(1), &redka.Options{DriverName: driverName})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	count, err := rdb.Load(db, file, &opts)
+	if err != nil {
+		return fmt.Errorf("import after %d keys: %w", count, err)
+	}
+	slog.Info("import rdb", "keys", count, "took", time.Since(start))
+	return nil
+}
@@ -3,6 +3,38 @@
 //
 //	./redka -h localhost -p 6379 redka.db
 //
+// Example usage (import a Redis RDB file):
+//
+//	./redka import-rdb dump.rdb redka.db
+//
+// Example usage (export to a Redis RDB file):
+//
+//	./redka export-rdb redka.db dump.rdb
+//
+// Example usage (backup and restore in JSON Lines format):
+//
+//	./redka export redka.db > keys.jsonl
+//	./redka import keys.jsonl redka.db
+//
+// Example usage (leader and a read-only replica):
+//
+//	./redka -p 6379 -changelog leader.db
+//	./redka -p 6380 -replicaof localhost:6379 replica.db
+//
+// Example usage (migrate from a Redis server, which the replica
+// follows with PSYNC until promoted with REPLICAOF NO ONE):
+//
+//	./redka -p 6380 -replicaof redis.local:6379 -masterauth secret redka.db
+//
+// Example usage (write command log for point-in-time recovery):
+//
+//	./redka -appendonly -appendfsync everysec redka.db
+//	redka-cli -db dump.db -aof -from 2026-10-18T12:00:00Z appendonly.aof
+//
+// Example usage (Prometheus metrics at http://localhost:9121/metrics):
+//
+//	./redka -metrics localhost:9121 redka.db
+//
 // Example usage (client):
 //
 //	docker run --rm -it redis redis-cli -h host.docker.internal -p 6379
@@ -11,16 +43,26 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/command"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/repl"
 	"github.com/flarco/redka/internal/server"
+	"github.com/flarco/redka/internal/stats"
 	"github.com/mattn/go-sqlite3"
 )
 
@@ -40,31 +82,90 @@ pragma temp_store = memory;
 pragma mmap_size = 268435456;
 pragma foreign_keys = on;`
 
+// subcommands are the command line tools
+// available besides running the server.
+var subcommands = map[string]func(args []string) error{
+	"export":     exportJSON,
+	"export-rdb": exportRDB,
+	"import":     importJSON,
+	"import-rdb": importRDB,
+}
+
 // Config holds the server configuration.
 type Config struct {
-	Host    string
-	Port    string
-	Sock    string // unix socket
-	Path    string
-	Verbose bool
+	Host        string
+	Port        string
+	Sock        string // unix socket
+	Path        string
+	Verbose     bool
+	SaveDir     string // snapshot directory
+	SaveFile    string // snapshot file name
+	SaveFormat  string // snapshot format (sqlite or rdb)
+	ChangeLog   bool   // record changed keys for replicas
+	ReplicaOf   string // leader path or host:port
+	MasterAuth  string // leader password
+	AppendOnly  bool   // log write commands
+	AppendFile  string // command log file name
+	AppendSync  string // command log fsync policy
+	AppendSize  int64  // command log rotation size
+	Metrics     string // metrics HTTP address
+	SlowLogUs   int64  // slow log threshold in microseconds
+	SlowLogLen  int    // slow log size
+	LatencyMs   int64  // latency monitor threshold in milliseconds
+	RequirePass string // default user password
+	ACLFile     string // ACL users file
+	TLSPort     string // TLS port (disabled if empty)
+	TLSCert     string // TLS certificate file
+	TLSKey      string // TLS private key file
+	TLSCA       string // CA certificates file to verify clients
+	TLSAuth     string // TLS client auth mode
 }
 
 func (c *Config) Addr() string {
 	return net.JoinHostPort(c.Host, c.Port)
 }
 
+func (c *Config) TLSAddr() string {
+	return net.JoinHostPort(c.Host, c.TLSPort)
+}
+
 var config Config
 
 func init() {
 	// Set up command line flags.
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage: redka [options] <data-source>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       redka export [options] <data-source>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       redka import <jsonl-file> <data-source>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       redka export-rdb <data-source> <rdb-file>\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       redka import-rdb [options] <rdb-file> <data-source>\n")
 		flag.PrintDefaults()
 	}
 	flag.StringVar(&config.Host, "h", "localhost", "server host")
-	flag.StringVar(&config.Port, "p", "6379", "server port")
+	flag.StringVar(&config.Port, "p", "6379", "server port (0 to accept only TLS connections)")
 	flag.StringVar(&config.Sock, "s", "", "server socket (overrides host and port)")
 	flag.BoolVar(&config.Verbose, "v", false, "verbose logging")
+	flag.StringVar(&config.SaveDir, "dir", ".", "snapshot directory (SAVE and BGSAVE)")
+	flag.StringVar(&config.SaveFile, "dbfilename", "", "snapshot file name (default dump.db or dump.rdb)")
+	flag.StringVar(&config.SaveFormat, "save-format", persist.FormatSQLite, "snapshot format (sqlite or rdb)")
+	flag.BoolVar(&config.ChangeLog, "changelog", false, "record changed keys so that replicas can follow")
+	flag.StringVar(&config.ReplicaOf, "replicaof", "", "run as a read-only replica of the leader (path or host:port)")
+	flag.StringVar(&config.MasterAuth, "masterauth", "", "password to authenticate to the leader")
+	flag.BoolVar(&config.AppendOnly, "appendonly", false, "log write commands for point-in-time recovery")
+	flag.StringVar(&config.AppendFile, "appendfilename", "appendonly.aof", "command log file name (in the snapshot directory)")
+	flag.StringVar(&config.AppendSync, "appendfsync", aof.FsyncEverySec, "command log fsync policy (always, everysec or no)")
+	flag.Int64Var(&config.AppendSize, "appendmaxsize", 64<<20, "command log size in bytes that triggers rotation (0 to disable)")
+	flag.Int64Var(&config.SlowLogUs, "slowlog-log-slower-than", 10000, "slow log threshold in microseconds (negative to disable, 0 to log every command)")
+	flag.IntVar(&config.SlowLogLen, "slowlog-max-len", 128, "maximum number of slow log entries")
+	flag.Int64Var(&config.LatencyMs, "latency-monitor-threshold", 0, "latency monitor threshold in milliseconds (0 to disable)")
+	flag.StringVar(&config.RequirePass, "requirepass", "", "require clients to authenticate with this password (AUTH)")
+	flag.StringVar(&config.ACLFile, "aclfile", "", "load and save the ACL users in this file")
+	flag.StringVar(&config.TLSPort, "tls-port", "", "TLS server port (disabled by default)")
+	flag.StringVar(&config.TLSCert, "tls-cert-file", "", "TLS server certificate file (PEM)")
+	flag.StringVar(&config.TLSKey, "tls-key-file", "", "TLS server private key file (PEM)")
+	flag.StringVar(&config.TLSCA, "tls-ca-cert-file", "", "CA certificates file to verify TLS clients (PEM)")
+	flag.StringVar(&config.TLSAuth, "tls-auth-clients", "", "require client certificates (yes, no or optional; default yes if a CA file is set)")
+	flag.StringVar(&config.Metrics, "metrics", "", "serve Prometheus metrics at http://<host:port>/metrics (disabled by default)")
 
 	// Register an SQLite driver with custom pragmas.
 	// Ensures that the PRAGMA settings apply to
@@ -78,6 +179,17 @@ func init() {
 }
 
 func main() {
+	// Run a subcommand if requested.
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				slog.Error(os.Args[1], "error", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Parse command line arguments.
 	flag.Parse()
 	if len(flag.Args()) > 1 {
@@ -113,6 +225,7 @@ func main() {
 		DriverName: driverName,
 		Logger:     logger,
 		Pragma:     map[string]string{},
+		ChangeLog:  config.ChangeLog,
 	}
 	db, err := redka.Open(config.Path, &opts)
 	if err != nil {
@@ -121,18 +234,126 @@ func main() {
 	}
 	slog.Info("data source", "path", config.Path)
 
+	// Set up snapshots.
+	saver, err := persist.New(db, persist.Options{
+		Dir:      config.SaveDir,
+		Filename: config.SaveFile,
+		Format:   config.SaveFormat,
+	})
+	if err != nil {
+		slog.Error("snapshots", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("snapshots", "path", saver.Path(), "format", config.SaveFormat)
+
+	// Set up replication.
+	manager := repl.NewManager(db, &repl.Options{
+		DriverName: driverName,
+		Password:   config.MasterAuth,
+		Apply:      command.Apply,
+	})
+	if config.ReplicaOf != "" {
+		_ = manager.ReplicaOf(config.ReplicaOf)
+		slog.Info("replication", "role", "replica", "leader", config.ReplicaOf)
+	}
+
+	// Set up the command log.
+	var cmdLog *aof.Logger
+	if config.AppendOnly {
+		path := filepath.Join(config.SaveDir, config.AppendFile)
+		cmdLog, err = aof.Open(path, &aof.Options{
+			Fsync:   config.AppendSync,
+			MaxSize: config.AppendSize,
+		})
+		if err != nil {
+			slog.Error("command log", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("command log", "path", path, "fsync", config.AppendSync)
+	}
+
+	// Set up the ACL users.
+	acls, err := acl.NewManager(&acl.Options{
+		File:        config.ACLFile,
+		RequirePass: config.RequirePass,
+	})
+	if err != nil {
+		slog.Error("acl", "error", err)
+		os.Exit(1)
+	}
+	if config.ACLFile != "" {
+		slog.Info("acl", "file", config.ACLFile)
+	}
+
+	// Set up TLS.
+	var tlsOpts *server.TLSOptions
+	if config.TLSPort != "" {
+		auth := config.TLSAuth
+		if auth == "" {
+			auth = server.TLSAuthNo
+			if config.TLSCA != "" {
+				auth = server.TLSAuthYes
+			}
+		}
+		tlsConfig, err := server.NewTLSConfig(config.TLSCert, config.TLSKey, config.TLSCA, auth)
+		if err != nil {
+			slog.Error("tls", "error", err)
+			os.Exit(1)
+		}
+		tlsOpts = &server.TLSOptions{Addr: config.TLSAddr(), Config: tlsConfig}
+		slog.Info("tls", "addr", tlsOpts.Addr, "auth-clients", auth)
+	}
+
 	// Start the server.
 	var srv *server.Server
+	srvOpts := &server.Options{
+		Saver: saver,
+		Repl:  manager,
+		AOF:   cmdLog,
+		ACL:   acls,
+		TLS:   tlsOpts,
+		Stats: &stats.Options{
+			Version:          version,
+			SlowLogThreshold: time.Duration(config.SlowLogUs) * time.Microsecond,
+			SlowLogMaxLen:    config.SlowLogLen,
+			LatencyThreshold: time.Duration(config.LatencyMs) * time.Millisecond,
+		},
+	}
 	if config.Sock != "" {
-		srv = server.New("unix", config.Sock, db)
+		srv = server.New("unix", config.Sock, db, srvOpts)
+	} else if config.Port == "0" && tlsOpts != nil {
+		// Accept only TLS connections.
+		srv = server.New("tcp", "", db, srvOpts)
 	} else {
-		srv = server.New("tcp", config.Addr(), db)
+		srv = server.New("tcp", config.Addr(), db, srvOpts)
 	}
 	srv.Start()
 
+	// Start the metrics endpoint.
+	var metricsSrv *http.Server
+	if config.Metrics != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", srv.Stats().Handler())
+		metricsSrv = &http.Server{Addr: config.Metrics, Handler: mux}
+		go func() {
+			slog.Info("serve metrics", "addr", config.Metrics)
+			err := metricsSrv.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("serve metrics", "error", err)
+			}
+		}()
+	}
+
 	// Wait for a shutdown signal.
 	<-ctx.Done()
 
+	// Stop the metrics endpoint before the database is closed.
+	if metricsSrv != nil {
+		if err := metricsSrv.Close(); err != nil {
+			slog.Error("stop metrics", "error", err)
+		}
+	}
+
 	// Stop the server.
 	if err := srv.Stop(); err != nil {
 		slog.Error("stop server", "error", err)
@@ -0,0 +1,389 @@
+package redka
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// importBatchSize is the number of keys
+// imported in a single transaction.
+const importBatchSize = 1000
+
+// ExportOptions are options for [DB.Export].
+type ExportOptions struct {
+	// Pattern is a glob-style pattern to filter keys by name.
+	// If empty, exports all keys.
+	Pattern string
+	// Type filters keys by type.
+	// If TypeAny (zero), exports keys of all types.
+	Type TypeID
+	// DBs are the logical databases to export.
+	// If empty, exports the keys of all databases.
+	DBs []int
+}
+
+// Export writes keys to w in JSON Lines format,
+// one JSON object per key:
+//
+//	{"db":0,"key":"name","type":"string","value":"alice"}
+//	{"db":1,"key":"tags","type":"set","ttl":60000,"value":["go","sql"]}
+//
+// The db is the index of the logical database the key belongs to.
+//
+// The value shape depends on the key type:
+//   - string: a string
+//   - list, set: an array of strings
+//   - hash: an object with field-value pairs
+//   - zset: an array of {"elem":..., "score":...} objects, ordered by score
+//
+// Strings that are not valid UTF-8 are written as {"base64":"..."}
+// objects. Scores are numbers, or "inf"/"-inf" strings for infinities.
+// TTL is the remaining time to live in milliseconds,
+// omitted for keys without expiration.
+//
+// Reads the keys in a single transaction and writes them one by one,
+// so the whole dataset does not need to fit in memory.
+// The opts parameter is optional. If nil, exports all keys.
+// Returns the number of exported keys.
+func (db *DB) Export(w io.Writer, opts *ExportOptions) (int, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	pattern := opts.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	count := 0
+	err := db.View(func(tx *Tx) error {
+		dbs := opts.DBs
+		if len(dbs) == 0 {
+			counts, err := tx.Key().DBCounts()
+			if err != nil {
+				return err
+			}
+			for index := range counts {
+				dbs = append(dbs, index)
+			}
+			slices.Sort(dbs)
+		}
+		for _, index := range dbs {
+			dbtx := tx.Select(index)
+			sc := dbtx.Key().Scanner(pattern, opts.Type, 0)
+			for sc.Scan() {
+				rec, err := exportKey(dbtx, sc.Key())
+				if err != nil {
+					return err
+				}
+				rec.DB = index
+				if err := enc.Encode(rec); err != nil {
+					return err
+				}
+				count++
+			}
+			if err := sc.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// Import reads keys in JSON Lines format (as written by [DB.Export])
+// from r and stores them in the database, each key in the logical
+// database from its db field (0 if missing). Existing keys with the same
+// names are overwritten, and keys with non-positive TTL are skipped.
+// Reads and stores the keys in batches,
+// so the whole dataset does not need to fit in memory.
+// Returns the number of imported keys.
+func (db *DB) Import(r io.Reader) (int, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	count := 0
+	line := 0
+	batch := make([]exportRecord, 0, importBatchSize)
+	for {
+		line++
+		rec := exportRecord{line: line}
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.TTL != nil && *rec.TTL <= 0 {
+			continue
+		}
+		batch = append(batch, rec)
+		if len(batch) < importBatchSize {
+			continue
+		}
+		if err := db.importBatch(batch); err != nil {
+			return count, err
+		}
+		count += len(batch)
+		batch = batch[:0]
+	}
+
+	if len(batch) > 0 {
+		if err := db.importBatch(batch); err != nil {
+			return count, err
+		}
+		count += len(batch)
+	}
+	return count, nil
+}
+
+// importBatch stores a batch of records in a single transaction.
+func (db *DB) importBatch(batch []exportRecord) error {
+	return db.Update(func(tx *Tx) error {
+		for _, rec := range batch {
+			if err := importKey(tx.Select(rec.DB), rec); err != nil {
+				return fmt.Errorf("line %d: %w", rec.line, err)
+			}
+		}
+		return nil
+	})
+}
+
+// exportRecord is a key in JSON Lines format.
+type exportRecord struct {
+	DB    int             `json:"db"`
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	TTL   *int64          `json:"ttl,omitempty"`
+	Value json.RawMessage `json:"value"`
+	line  int             // line number in the input (for errors)
+}
+
+// exportItem is a sorted set element in JSON Lines format.
+type exportItem struct {
+	Elem  binValue   `json:"elem"`
+	Score scoreValue `json:"score"`
+}
+
+// exportKey reads the key value into a record.
+func exportKey(tx *Tx, k core.Key) (exportRecord, error) {
+	rec := exportRecord{Key: k.Key, Type: k.TypeName()}
+	if k.ETime != nil {
+		ttl := max(*k.ETime-time.Now().UnixMilli(), 1)
+		rec.TTL = &ttl
+	}
+
+	var val any
+	switch k.Type {
+	case core.TypeString:
+		str, err := tx.Str().Get(k.Key)
+		if err != nil {
+			return rec, err
+		}
+		val = binValue(str)
+	case core.TypeList:
+		elems, err := tx.List().Range(k.Key, 0, -1)
+		if err != nil {
+			return rec, err
+		}
+		val = toBinValues(elems)
+	case core.TypeSet:
+		elems, err := tx.Set().Items(k.Key)
+		if err != nil {
+			return rec, err
+		}
+		val = toBinValues(elems)
+	case core.TypeHash:
+		items, err := tx.Hash().Items(k.Key)
+		if err != nil {
+			return rec, err
+		}
+		hash := make(map[string]binValue, len(items))
+		for field, value := range items {
+			hash[field] = binValue(value)
+		}
+		val = hash
+	case core.TypeZSet:
+		items, err := tx.ZSet().RangeWith(k.Key).ByScore(math.Inf(-1), math.Inf(1)).Run()
+		if err != nil {
+			return rec, err
+		}
+		zset := make([]exportItem, len(items))
+		for i, item := range items {
+			zset[i] = exportItem{Elem: binValue(item.Elem), Score: scoreValue(item.Score)}
+		}
+		val = zset
+	default:
+		return rec, core.ErrKeyType
+	}
+
+	var err error
+	rec.Value, err = json.Marshal(val)
+	return rec, err
+}
+
+// importKey stores the record value, replacing the existing key if any.
+func importKey(tx *Tx, rec exportRecord) error {
+	if rec.Key == "" {
+		return errors.New("missing key")
+	}
+	if rec.DB < 0 {
+		return fmt.Errorf("invalid db %d", rec.DB)
+	}
+	if _, err := tx.Key().Delete(rec.Key); err != nil {
+		return err
+	}
+
+	var err error
+	switch rec.Type {
+	case "string":
+		var val binValue
+		if err := json.Unmarshal(rec.Value, &val); err != nil {
+			return err
+		}
+		err = tx.Str().Set(rec.Key, []byte(val))
+	case "list":
+		var elems []binValue
+		if err := json.Unmarshal(rec.Value, &elems); err != nil {
+			return err
+		}
+		for _, elem := range elems {
+			if _, err = tx.List().PushBack(rec.Key, []byte(elem)); err != nil {
+				break
+			}
+		}
+	case "set":
+		var elems []binValue
+		if err := json.Unmarshal(rec.Value, &elems); err != nil {
+			return err
+		}
+		args := make([]any, len(elems))
+		for i, elem := range elems {
+			args[i] = []byte(elem)
+		}
+		if len(args) > 0 {
+			_, err = tx.Set().Add(rec.Key, args...)
+		}
+	case "hash":
+		var hash map[string]binValue
+		if err := json.Unmarshal(rec.Value, &hash); err != nil {
+			return err
+		}
+		items := make(map[string]any, len(hash))
+		for field, value := range hash {
+			items[field] = []byte(value)
+		}
+		if len(items) > 0 {
+			_, err = tx.Hash().SetMany(rec.Key, items)
+		}
+	case "zset":
+		var zset []exportItem
+		if err := json.Unmarshal(rec.Value, &zset); err != nil {
+			return err
+		}
+		items := make(map[any]float64, len(zset))
+		for _, item := range zset {
+			items[string(item.Elem)] = float64(item.Score)
+		}
+		if len(items) > 0 {
+			_, err = tx.ZSet().AddMany(rec.Key, items)
+		}
+	default:
+		return fmt.Errorf("unknown key type %q: %w", rec.Type, core.ErrValueType)
+	}
+	if err != nil {
+		return err
+	}
+
+	if rec.TTL != nil {
+		at := time.Now().Add(time.Duration(*rec.TTL) * time.Millisecond)
+		return tx.Key().ExpireAt(rec.Key, at)
+	}
+	return nil
+}
+
+// binValue is a binary-safe JSON string. Marshals to a string
+// if the value is valid UTF-8, or to a {"base64":"..."} object otherwise.
+type binValue []byte
+
+func (v binValue) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(v) {
+		return json.Marshal(string(v))
+	}
+	return json.Marshal(struct {
+		Base64 []byte `json:"base64"`
+	}{v})
+}
+
+func (v *binValue) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*v = binValue(str)
+		return nil
+	}
+	var obj struct {
+		Base64 *[]byte `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if obj.Base64 == nil {
+		return core.ErrValueType
+	}
+	*v = *obj.Base64
+	return nil
+}
+
+// toBinValues converts a list of values to binary-safe JSON strings.
+func toBinValues(vals []core.Value) []binValue {
+	elems := make([]binValue, len(vals))
+	for i, val := range vals {
+		elems[i] = binValue(val)
+	}
+	return elems
+}
+
+// scoreValue is a sorted set score. Marshals to a number,
+// or to a "inf"/"-inf" string for infinite scores
+// (which JSON numbers cannot represent).
+type scoreValue float64
+
+func (v scoreValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case math.IsInf(float64(v), 1):
+		return []byte(`"inf"`), nil
+	case math.IsInf(float64(v), -1):
+		return []byte(`"-inf"`), nil
+	}
+	return json.Marshal(float64(v))
+}
+
+func (v *scoreValue) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `"inf"`, `"+inf"`:
+		*v = scoreValue(math.Inf(1))
+		return nil
+	case `"-inf"`:
+		*v = scoreValue(math.Inf(-1))
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*v = scoreValue(f)
+	return nil
+}
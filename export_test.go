@@ -0,0 +1,213 @@
+package redka_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func ExampleDB_Export() {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_, _ = db.Set().Add("tags", "go")
+
+	_, _ = db.Export(os.Stdout, &redka.ExportOptions{Pattern: "n*"})
+	// Output:
+	// {"db":0,"key":"name","type":"string","value":"alice"}
+}
+
+func TestDBExport(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("bin", []byte{0xff, 0x00})
+	_, _ = db.List().PushBack("list", "one")
+	_, _ = db.List().PushBack("list", "two")
+	_, _ = db.Set().Add("set", "one")
+	_, _ = db.Hash().Set("person", "name", "bob")
+	_, _ = db.ZSet().AddMany("race", map[any]float64{"alice": 11, "bob": math.Inf(1)})
+	_ = db.Str().Set("temp", "value")
+	_ = db.Key().Expire("temp", time.Minute)
+
+	t.Run("all", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := db.Export(&buf, nil)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 7)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		testx.AssertEqual(t, len(lines), 7)
+		testx.AssertEqual(t, lines[0], `{"db":0,"key":"name","type":"string","value":"alice"}`)
+		testx.AssertEqual(t, lines[1], `{"db":0,"key":"bin","type":"string","value":{"base64":"/wA="}}`)
+		testx.AssertEqual(t, lines[2], `{"db":0,"key":"list","type":"list","value":["one","two"]}`)
+		testx.AssertEqual(t, lines[3], `{"db":0,"key":"set","type":"set","value":["one"]}`)
+		testx.AssertEqual(t, lines[4], `{"db":0,"key":"person","type":"hash","value":{"name":"bob"}}`)
+		testx.AssertEqual(t, lines[5],
+			`{"db":0,"key":"race","type":"zset","value":[{"elem":"alice","score":11},{"elem":"bob","score":"inf"}]}`)
+		testx.AssertEqual(t, strings.HasPrefix(lines[6], `{"db":0,"key":"temp","type":"string","ttl":`), true)
+	})
+	t.Run("pattern", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := db.Export(&buf, &redka.ExportOptions{Pattern: "n*"})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+		testx.AssertEqual(t, buf.String(), `{"db":0,"key":"name","type":"string","value":"alice"}`+"\n")
+	})
+	t.Run("type", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := db.Export(&buf, &redka.ExportOptions{Type: redka.TypeString})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 3)
+	})
+	t.Run("databases", func(t *testing.T) {
+		_ = db.Select(2).Str().Set("name", "bob")
+		defer func() { _ = db.Select(2).Key().DeleteAll() }()
+
+		var buf bytes.Buffer
+		count, err := db.Export(&buf, &redka.ExportOptions{Pattern: "name"})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 2)
+		testx.AssertEqual(t, buf.String(),
+			`{"db":0,"key":"name","type":"string","value":"alice"}`+"\n"+
+				`{"db":2,"key":"name","type":"string","value":"bob"}`+"\n")
+
+		buf.Reset()
+		count, err = db.Export(&buf, &redka.ExportOptions{Pattern: "name", DBs: []int{2}})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+		testx.AssertEqual(t, buf.String(), `{"db":2,"key":"name","type":"string","value":"bob"}`+"\n")
+	})
+}
+
+func TestDBImport(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		src := getDB(t)
+		defer src.Close()
+		_ = src.Str().Set("bin", []byte{0xff, 0x00})
+		_, _ = src.List().PushBack("list", "one")
+		_, _ = src.List().PushBack("list", "two")
+		_, _ = src.Set().Add("set", "one", "two")
+		_, _ = src.Hash().SetMany("person", map[string]any{"name": "bob", "age": 25})
+		_, _ = src.ZSet().AddMany("race", map[any]float64{"alice": 11, "bob": math.Inf(-1)})
+		_ = src.Str().Set("temp", "value")
+		_ = src.Key().Expire("temp", time.Minute)
+
+		var buf bytes.Buffer
+		_, err := src.Export(&buf, nil)
+		testx.AssertNoErr(t, err)
+		src.Close()
+
+		dst := getDB(t)
+		defer dst.Close()
+		count, err := dst.Import(&buf)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 6)
+
+		bin, _ := dst.Str().Get("bin")
+		testx.AssertEqual(t, bin, redka.Value{0xff, 0x00})
+		list, _ := dst.List().Range("list", 0, -1)
+		testx.AssertEqual(t, list, []redka.Value{redka.Value("one"), redka.Value("two")})
+		setLen, _ := dst.Set().Len("set")
+		testx.AssertEqual(t, setLen, 2)
+		age, _ := dst.Hash().Get("person", "age")
+		testx.AssertEqual(t, age.String(), "25")
+		score, _ := dst.ZSet().GetScore("race", "bob")
+		testx.AssertEqual(t, score, math.Inf(-1))
+		key, _ := dst.Key().Get("temp")
+		testx.AssertEqual(t, *key.ETime > time.Now().UnixMilli(), true)
+	})
+	t.Run("databases", func(t *testing.T) {
+		src := getDB(t)
+		defer src.Close()
+		_ = src.Str().Set("name", "alice")
+		_ = src.Select(1).Str().Set("name", "bob")
+		_, _ = src.Select(1).List().PushBack("list", "one")
+
+		var buf bytes.Buffer
+		count, err := src.Export(&buf, nil)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 3)
+		src.Close()
+
+		dst := getDB(t)
+		defer dst.Close()
+		count, err = dst.Import(&buf)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 3)
+
+		name, _ := dst.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+		name, _ = dst.Select(1).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "bob")
+		list, _ := dst.Select(1).List().Range("list", 0, -1)
+		testx.AssertEqual(t, list, []redka.Value{redka.Value("one")})
+		exists, _ := dst.Key().Exists("list")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("no db", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		input := `{"key":"name","type":"string","value":"alice"}`
+		count, err := db.Import(strings.NewReader(input))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+		_, _ = db.List().PushBack("name", "old")
+
+		input := `{"db":0,"key":"name","type":"string","value":"alice"}`
+		count, err := db.Import(strings.NewReader(input))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("expired", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		input := `{"db":0,"key":"name","type":"string","ttl":0,"value":"alice"}`
+		count, err := db.Import(strings.NewReader(input))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 0)
+		exists, _ := db.Key().Exists("name")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("invalid type", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		input := `{"db":0,"key":"name","type":"string","value":"alice"}
+{"key":"stream","type":"stream","value":[]}`
+		_, err := db.Import(strings.NewReader(input))
+		testx.AssertEqual(t, errors.Is(err, redka.ErrValueType), true)
+		testx.AssertEqual(t, err.Error(), `line 2: unknown key type "stream": invalid value type`)
+		exists, _ := db.Key().Exists("name")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		_, err := db.Import(strings.NewReader(`{"db":0,"key":`))
+		testx.AssertEqual(t, strings.HasPrefix(err.Error(), "line 1: "), true)
+	})
+}
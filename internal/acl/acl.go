@@ -0,0 +1,318 @@
+// Package acl implements password authentication
+// and access control lists (the AUTH and ACL commands).
+package acl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultUser is the user of the clients
+// that have not authenticated explicitly.
+const DefaultUser = "default"
+
+var (
+	ErrCommand         = errors.New("acl: command not allowed")
+	ErrInvalidHash     = errors.New("acl: invalid password hash")
+	ErrKey             = errors.New("acl: key not allowed")
+	ErrDeleteDefault   = errors.New("acl: default user can't be removed")
+	ErrNoPassword      = errors.New("acl: no such password")
+	ErrNoUser          = errors.New("acl: no such user or user is disabled")
+	ErrSyntax          = errors.New("acl: syntax error")
+	ErrUnknownCategory = errors.New("acl: unknown command category")
+	ErrUnknownCommand  = errors.New("acl: unknown command")
+)
+
+// RuleError is an error in an ACL rule.
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("%s in rule '%s'", e.Err, e.Rule)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Cmd describes a command to check the permissions for.
+type Cmd struct {
+	Name       string   // lowercase command name
+	Sub        string   // lowercase first argument (subcommand), if any
+	Categories []string // command categories (without the @ prefix)
+	Keys       []string // key names
+	AllKeys    bool     // the command may access any key (like SORT with GET patterns)
+}
+
+// match reports whether the command matches the rule target:
+// a command name, a command|subcommand pair or a @category.
+func (c Cmd) match(target string) bool {
+	if target == "@all" {
+		return true
+	}
+	if cat, ok := strings.CutPrefix(target, "@"); ok {
+		return slices.Contains(c.Categories, cat)
+	}
+	if name, sub, ok := strings.Cut(target, "|"); ok {
+		return name == c.Name && sub == c.Sub
+	}
+	return target == c.Name
+}
+
+// Options configure the ACL manager.
+type Options struct {
+	// File is the ACL file path. If set, the users are
+	// loaded from the file on start (if it exists),
+	// and saved to the file on every change.
+	File string
+	// RequirePass is the default user's password.
+	// If empty, the default user has no password
+	// (unless it is defined in the ACL file).
+	RequirePass string
+}
+
+// Manager manages the ACL users. Safe for concurrent use.
+// The default user always exists and initially
+// has access to all commands and keys.
+type Manager struct {
+	file  string
+	mu    sync.RWMutex
+	users map[string]*User
+}
+
+// NewManager creates a new ACL manager and loads
+// the users from the ACL file (if any).
+// The opts parameter is optional.
+func NewManager(opts *Options) (*Manager, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	def := newUser(DefaultUser)
+	for _, rule := range []string{"on", "nopass", "~*", "&*", "+@all"} {
+		_ = def.apply(rule)
+	}
+	if opts.RequirePass != "" {
+		_ = def.apply("resetpass")
+		_ = def.apply(">" + opts.RequirePass)
+	}
+	m := &Manager{
+		file:  opts.File,
+		users: map[string]*User{DefaultUser: def},
+	}
+	if m.file != "" {
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Auth checks the user's password. Returns ErrNoUser
+// if there is no such user, the user is disabled
+// or the password is wrong.
+func (m *Manager) Auth(name, pass string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, ok := m.users[name]
+	if !ok || !user.checkPass(pass) {
+		return ErrNoUser
+	}
+	return nil
+}
+
+// NoAuth reports whether the clients can use the default
+// user without authenticating (the default user is enabled
+// and has no password).
+func (m *Manager) NoAuth() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	def := m.users[DefaultUser]
+	return def.Enabled && def.NoPass
+}
+
+// HasPass reports whether the default user has a password.
+func (m *Manager) HasPass() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.users[DefaultUser].NoPass
+}
+
+// Check reports whether the user is allowed to run the command.
+// Returns ErrNoUser if there is no such user or the user
+// is disabled, ErrCommand if the command is not allowed,
+// and ErrKey if any of the keys is not allowed (or the command
+// may access any key, and the user is not allowed all keys).
+func (m *Manager) Check(name string, cmd Cmd) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, ok := m.users[name]
+	if !ok || !user.Enabled {
+		return ErrNoUser
+	}
+	if !user.CanRun(cmd) {
+		return ErrCommand
+	}
+	if !user.CanAccess(cmd.Keys) || (cmd.AllKeys && !user.CanAccessAll()) {
+		return ErrKey
+	}
+	return nil
+}
+
+// GetUser returns a copy of the user.
+func (m *Manager) GetUser(name string) (User, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, ok := m.users[name]
+	if !ok {
+		return User{}, false
+	}
+	return *user.clone(), true
+}
+
+// SetUser creates or changes the user by applying the rules
+// in order. Returns a *RuleError if any rule is invalid,
+// in which case the user is left unchanged.
+func (m *Manager) SetUser(name string, rules ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[name]
+	if ok {
+		user = user.clone()
+	} else {
+		user = newUser(name)
+	}
+	for _, rule := range rules {
+		if err := user.apply(rule); err != nil {
+			return &RuleError{Rule: rule, Err: err}
+		}
+	}
+	m.users[name] = user
+	return m.save()
+}
+
+// DelUser deletes the users and returns
+// the number of deleted ones.
+// The default user can't be deleted.
+func (m *Manager) DelUser(names ...string) (int, error) {
+	if slices.Contains(names, DefaultUser) {
+		return 0, ErrDeleteDefault
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, name := range names {
+		if _, ok := m.users[name]; ok {
+			delete(m.users, name)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, m.save()
+}
+
+// Users returns the user names, sorted.
+func (m *Manager) Users() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.users))
+	for name := range m.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List returns the descriptions of all users,
+// sorted by name (as in ACL LIST).
+func (m *Manager) List() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.list()
+}
+
+func (m *Manager) list() []string {
+	names := make([]string, 0, len(m.users))
+	for name := range m.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = m.users[name].String()
+	}
+	return lines
+}
+
+// load loads the users from the ACL file.
+// Each line describes a user, in the same format
+// as ACL LIST: user <name> [rule ...].
+// Does nothing if the file does not exist.
+func (m *Manager) load() error {
+	file, err := os.Open(m.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "user" {
+			return fmt.Errorf("%s:%d: %w", m.file, n, ErrSyntax)
+		}
+		// Users in the file are defined from scratch.
+		user := newUser(fields[1])
+		for _, rule := range fields[2:] {
+			if err := user.apply(rule); err != nil {
+				return fmt.Errorf("%s:%d: %w", m.file, n, &RuleError{Rule: rule, Err: err})
+			}
+		}
+		m.users[user.Name] = user
+	}
+	return scanner.Err()
+}
+
+// save writes the users to the ACL file (if any).
+// Replaces the file atomically.
+func (m *Manager) save() error {
+	if m.file == "" {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.file), filepath.Base(m.file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	for _, line := range m.list() {
+		if _, err := fmt.Fprintln(tmp, line); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), m.file)
+}
@@ -0,0 +1,189 @@
+package acl_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/testx"
+)
+
+const (
+	// SHA-256 of "secret".
+	secretHash = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+)
+
+func TestDefaultUser(t *testing.T) {
+	t.Run("no password", func(t *testing.T) {
+		m, err := acl.NewManager(nil)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.NoAuth(), true)
+		testx.AssertEqual(t, m.HasPass(), false)
+		testx.AssertEqual(t, m.Auth(acl.DefaultUser, "anything"), nil)
+		testx.AssertEqual(t, m.List(), []string{"user default on nopass ~* &* +@all"})
+	})
+	t.Run("require pass", func(t *testing.T) {
+		m, err := acl.NewManager(&acl.Options{RequirePass: "secret"})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.NoAuth(), false)
+		testx.AssertEqual(t, m.HasPass(), true)
+		testx.AssertEqual(t, m.Auth(acl.DefaultUser, "secret"), nil)
+		testx.AssertEqual(t, m.Auth(acl.DefaultUser, "wrong"), acl.ErrNoUser)
+		testx.AssertEqual(t, m.List(), []string{"user default on #" + secretHash + " ~* &* +@all"})
+	})
+}
+
+func TestSetUser(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		m, _ := acl.NewManager(nil)
+		err := m.SetUser("alice")
+		testx.AssertNoErr(t, err)
+		user, ok := m.GetUser("alice")
+		testx.AssertEqual(t, ok, true)
+		testx.AssertEqual(t, user.String(), "user alice off resetkeys resetchannels -@all")
+		testx.AssertEqual(t, m.Auth("alice", ""), acl.ErrNoUser)
+	})
+	t.Run("rules", func(t *testing.T) {
+		m, _ := acl.NewManager(nil)
+		err := m.SetUser("alice", "on", ">secret", ">other", "<other",
+			"~user:*", "~cache:*", "+@read", "-keys", "+set", "-@all", "+get", "+config|get")
+		testx.AssertNoErr(t, err)
+		user, _ := m.GetUser("alice")
+		testx.AssertEqual(t, user.Flags(), []string{"on"})
+		testx.AssertEqual(t, user.Passwords, []string{secretHash})
+		testx.AssertEqual(t, user.Keys, []string{"user:*", "cache:*"})
+		testx.AssertEqual(t, user.CommandRules(), "-@all +get +config|get")
+		testx.AssertEqual(t, m.Auth("alice", "secret"), nil)
+		testx.AssertEqual(t, m.Auth("alice", "other"), acl.ErrNoUser)
+	})
+	t.Run("override", func(t *testing.T) {
+		m, _ := acl.NewManager(nil)
+		_ = m.SetUser("alice", "+get", "-set", "+@write")
+		_ = m.SetUser("alice", "-get", "allkeys", "~user:*", "nopass", "on")
+		user, _ := m.GetUser("alice")
+		testx.AssertEqual(t, user.CommandRules(), "-@all -set +@write -get")
+		testx.AssertEqual(t, user.Keys, []string{"*"})
+		testx.AssertEqual(t, user.Flags(), []string{"on", "nopass"})
+	})
+	t.Run("reset", func(t *testing.T) {
+		m, _ := acl.NewManager(nil)
+		_ = m.SetUser("alice", "on", ">secret", "allkeys", "allcommands")
+		_ = m.SetUser("alice", "reset")
+		user, _ := m.GetUser("alice")
+		testx.AssertEqual(t, user.String(), "user alice off resetkeys resetchannels -@all")
+	})
+	t.Run("hashes", func(t *testing.T) {
+		m, _ := acl.NewManager(nil)
+		err := m.SetUser("alice", "on", "#"+secretHash)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.Auth("alice", "secret"), nil)
+		err = m.SetUser("alice", "!"+secretHash)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.Auth("alice", "secret"), acl.ErrNoUser)
+	})
+	t.Run("invalid rule", func(t *testing.T) {
+		tests := []struct {
+			rule string
+			err  error
+		}{
+			{"foo", acl.ErrSyntax},
+			{"+", acl.ErrSyntax},
+			{"+@foo", acl.ErrUnknownCategory},
+			{"#abc", acl.ErrInvalidHash},
+			{"<missing", acl.ErrNoPassword},
+		}
+		for _, test := range tests {
+			m, _ := acl.NewManager(nil)
+			err := m.SetUser("alice", "on", test.rule)
+			var rerr *acl.RuleError
+			testx.AssertEqual(t, errors.As(err, &rerr), true)
+			testx.AssertEqual(t, rerr.Rule, test.rule)
+			testx.AssertEqual(t, errors.Is(err, test.err), true)
+
+			// The user is left unchanged.
+			_, ok := m.GetUser("alice")
+			testx.AssertEqual(t, ok, false)
+		}
+	})
+}
+
+func TestDelUser(t *testing.T) {
+	m, _ := acl.NewManager(nil)
+	_ = m.SetUser("alice")
+	_ = m.SetUser("bob")
+	n, err := m.DelUser("alice", "bob", "carol")
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, n, 2)
+	testx.AssertEqual(t, m.Users(), []string{"default"})
+
+	_, err = m.DelUser("default")
+	testx.AssertErr(t, err, acl.ErrDeleteDefault)
+}
+
+func TestCheck(t *testing.T) {
+	m, _ := acl.NewManager(nil)
+	_ = m.SetUser("alice", "on", "nopass", "~user:*", "+@read", "-keys", "+config|get")
+	_ = m.SetUser("bob", "off", "nopass", "allkeys", "allcommands")
+
+	get := acl.Cmd{Name: "get", Categories: []string{"read", "string", "fast"}}
+	tests := []struct {
+		user string
+		cmd  acl.Cmd
+		err  error
+	}{
+		{"default", acl.Cmd{Name: "flushdb", Keys: nil}, nil},
+		{"alice", acl.Cmd{Name: "get", Categories: get.Categories, Keys: []string{"user:1"}}, nil},
+		{"alice", acl.Cmd{Name: "get", Categories: get.Categories, Keys: []string{"cache:1"}}, acl.ErrKey},
+		{"alice", acl.Cmd{Name: "mget", Categories: get.Categories, Keys: []string{"user:1", "cache:1"}}, acl.ErrKey},
+		{"alice", acl.Cmd{Name: "sort_ro", Categories: get.Categories, Keys: []string{"user:1"}}, nil},
+		{"alice", acl.Cmd{Name: "sort_ro", Categories: get.Categories, Keys: []string{"user:1"}, AllKeys: true}, acl.ErrKey},
+		{"default", acl.Cmd{Name: "sort", Keys: []string{"user:1"}, AllKeys: true}, nil},
+		{"alice", acl.Cmd{Name: "keys", Categories: []string{"read", "keyspace"}}, acl.ErrCommand},
+		{"alice", acl.Cmd{Name: "set", Categories: []string{"write", "string"}}, acl.ErrCommand},
+		{"alice", acl.Cmd{Name: "config", Sub: "get"}, nil},
+		{"alice", acl.Cmd{Name: "config", Sub: "set"}, acl.ErrCommand},
+		{"bob", get, acl.ErrNoUser},
+		{"carol", get, acl.ErrNoUser},
+	}
+	for _, test := range tests {
+		err := m.Check(test.user, test.cmd)
+		testx.AssertEqual(t, err, test.err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Run("save and load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "users.acl")
+		m, err := acl.NewManager(&acl.Options{File: path, RequirePass: "secret"})
+		testx.AssertNoErr(t, err)
+		err = m.SetUser("alice", "on", ">secret", "~user:*", "+@read")
+		testx.AssertNoErr(t, err)
+
+		data, err := os.ReadFile(path)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, string(data),
+			"user alice on #"+secretHash+" ~user:* resetchannels -@all +@read\n"+
+				"user default on #"+secretHash+" ~* &* +@all\n")
+
+		// The file overrides the default user.
+		m, err = acl.NewManager(&acl.Options{File: path, RequirePass: "other"})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.Users(), []string{"alice", "default"})
+		testx.AssertEqual(t, m.Auth("default", "secret"), nil)
+		testx.AssertEqual(t, m.Auth("alice", "secret"), nil)
+	})
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "users.acl")
+		m, err := acl.NewManager(&acl.Options{File: path})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.Users(), []string{"default"})
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "users.acl")
+		_ = os.WriteFile(path, []byte("# users\n\nuser alice on +@foo\n"), 0644)
+		_, err := acl.NewManager(&acl.Options{File: path})
+		testx.AssertEqual(t, errors.Is(err, acl.ErrUnknownCategory), true)
+	})
+}
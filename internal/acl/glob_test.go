@@ -0,0 +1,41 @@
+package acl
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "user:1", true},
+		{"user:*", "user:1", true},
+		{"user:*", "users", false},
+		{"*:1", "user:1", true},
+		{"u*r*1", "user:1", true},
+		{"u**1", "user:1", true},
+		{"user:?", "user:1", true},
+		{"user:?", "user:12", false},
+		{"user:[12]", "user:2", true},
+		{"user:[12]", "user:3", false},
+		{"user:[^12]", "user:3", true},
+		{"user:[^12]", "user:1", false},
+		{"user:[0-9]", "user:7", true},
+		{"user:[9-0]", "user:7", true},
+		{"user:[a-z]", "user:7", false},
+		{`user:\*`, "user:*", true},
+		{`user:\*`, "user:1", false},
+		{`[\]]`, "]", true},
+		{"name", "name", true},
+		{"name", "names", false},
+		{"", "", true},
+		{"", "name", false},
+	}
+	for _, test := range tests {
+		got := matchGlob(test.pattern, test.s)
+		if got != test.want {
+			t.Errorf("matchGlob(%q, %q): want %v, got %v", test.pattern, test.s, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package acl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// Categories are the command categories (same as in Redis).
+var Categories = []string{
+	"admin", "bitmap", "blocking", "connection", "dangerous",
+	"fast", "geo", "hash", "hyperloglog", "keyspace", "list",
+	"pubsub", "read", "scripting", "set", "slow", "sortedset",
+	"stream", "string", "transaction", "write",
+}
+
+// User is an ACL user.
+type User struct {
+	Name      string
+	Enabled   bool
+	NoPass    bool
+	Passwords []string // SHA-256 password hashes (hex-encoded)
+	Commands  []string // command rules (like +@read or -flushall), applied in order
+	Keys      []string // key patterns
+	Channels  []string // channel patterns (not enforced)
+}
+
+// newUser creates a user with no permissions.
+func newUser(name string) *User {
+	return &User{Name: name, Commands: []string{"-@all"}}
+}
+
+// clone returns a deep copy of the user.
+func (u *User) clone() *User {
+	c := *u
+	c.Passwords = slices.Clone(u.Passwords)
+	c.Commands = slices.Clone(u.Commands)
+	c.Keys = slices.Clone(u.Keys)
+	c.Channels = slices.Clone(u.Channels)
+	return &c
+}
+
+// Flags returns the user flags (as in ACL GETUSER).
+func (u *User) Flags() []string {
+	flags := []string{"off"}
+	if u.Enabled {
+		flags[0] = "on"
+	}
+	if u.NoPass {
+		flags = append(flags, "nopass")
+	}
+	return flags
+}
+
+// String returns the user description
+// in the ACL LIST (and ACL file) format.
+func (u *User) String() string {
+	parts := []string{"user", u.Name}
+	parts = append(parts, u.Flags()...)
+	for _, hash := range u.Passwords {
+		parts = append(parts, "#"+hash)
+	}
+	parts = append(parts, u.KeyRules())
+	parts = append(parts, u.ChannelRules())
+	parts = append(parts, u.CommandRules())
+	return strings.Join(parts, " ")
+}
+
+// CommandRules returns the command rules as a string.
+func (u *User) CommandRules() string {
+	return strings.Join(u.Commands, " ")
+}
+
+// KeyRules returns the key patterns as a string.
+func (u *User) KeyRules() string {
+	if len(u.Keys) == 0 {
+		return "resetkeys"
+	}
+	rules := make([]string, len(u.Keys))
+	for i, pattern := range u.Keys {
+		rules[i] = "~" + pattern
+	}
+	return strings.Join(rules, " ")
+}
+
+// ChannelRules returns the channel patterns as a string.
+func (u *User) ChannelRules() string {
+	if len(u.Channels) == 0 {
+		return "resetchannels"
+	}
+	rules := make([]string, len(u.Channels))
+	for i, pattern := range u.Channels {
+		rules[i] = "&" + pattern
+	}
+	return strings.Join(rules, " ")
+}
+
+// CanRun reports whether the user is allowed to run the command.
+// The rules are applied in order, so the last matching one wins.
+func (u *User) CanRun(cmd Cmd) bool {
+	allowed := false
+	for _, rule := range u.Commands {
+		if cmd.match(rule[1:]) {
+			allowed = rule[0] == '+'
+		}
+	}
+	return allowed
+}
+
+// CanAccess reports whether the user is allowed
+// to access all of the keys.
+func (u *User) CanAccess(keys []string) bool {
+	for _, key := range keys {
+		if !slices.ContainsFunc(u.Keys, func(pattern string) bool {
+			return matchGlob(pattern, key)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanAccessAll reports whether the user is allowed
+// to access any key (has the ~* pattern).
+func (u *User) CanAccessAll() bool {
+	return slices.Contains(u.Keys, "*")
+}
+
+// checkPass reports whether the password is valid for the user.
+func (u *User) checkPass(pass string) bool {
+	if !u.Enabled {
+		return false
+	}
+	if u.NoPass {
+		return true
+	}
+	return slices.Contains(u.Passwords, hashPass(pass))
+}
+
+// apply changes the user according to the rule.
+func (u *User) apply(rule string) error {
+	switch strings.ToLower(rule) {
+	case "on":
+		u.Enabled = true
+		return nil
+	case "off":
+		u.Enabled = false
+		return nil
+	case "nopass":
+		u.NoPass = true
+		u.Passwords = nil
+		return nil
+	case "resetpass":
+		u.NoPass = false
+		u.Passwords = nil
+		return nil
+	case "allkeys":
+		u.Keys = []string{"*"}
+		return nil
+	case "resetkeys":
+		u.Keys = nil
+		return nil
+	case "allchannels":
+		u.Channels = []string{"*"}
+		return nil
+	case "resetchannels":
+		u.Channels = nil
+		return nil
+	case "allcommands":
+		u.Commands = []string{"+@all"}
+		return nil
+	case "nocommands":
+		u.Commands = []string{"-@all"}
+		return nil
+	case "reset":
+		*u = User{Name: u.Name, Commands: []string{"-@all"}}
+		return nil
+	}
+
+	if rule == "" {
+		return ErrSyntax
+	}
+	arg := rule[1:]
+	switch rule[0] {
+	case '>':
+		return u.addPass(hashPass(arg))
+	case '<':
+		return u.removePass(hashPass(arg))
+	case '#':
+		if !validHash(arg) {
+			return ErrInvalidHash
+		}
+		return u.addPass(strings.ToLower(arg))
+	case '!':
+		if !validHash(arg) {
+			return ErrInvalidHash
+		}
+		return u.removePass(strings.ToLower(arg))
+	case '~':
+		u.Keys = addPattern(u.Keys, arg)
+		return nil
+	case '&':
+		u.Channels = addPattern(u.Channels, arg)
+		return nil
+	case '+', '-':
+		return u.addCommandRule(rule)
+	}
+	return ErrSyntax
+}
+
+// addPass adds the password hash.
+func (u *User) addPass(hash string) error {
+	u.NoPass = false
+	if !slices.Contains(u.Passwords, hash) {
+		u.Passwords = append(u.Passwords, hash)
+	}
+	return nil
+}
+
+// removePass removes the password hash.
+func (u *User) removePass(hash string) error {
+	idx := slices.Index(u.Passwords, hash)
+	if idx == -1 {
+		return ErrNoPassword
+	}
+	u.Passwords = slices.Delete(u.Passwords, idx, idx+1)
+	return nil
+}
+
+// addCommandRule adds a +command, -command, +@category
+// or -@category rule. Drops the previous rules that
+// the new one overrides.
+func (u *User) addCommandRule(rule string) error {
+	rule = strings.ToLower(rule)
+	target := rule[1:]
+	if target == "" || target == "@" {
+		return ErrSyntax
+	}
+	if cat, ok := strings.CutPrefix(target, "@"); ok && cat != "all" {
+		if !slices.Contains(Categories, cat) {
+			return ErrUnknownCategory
+		}
+	}
+	if target == "@all" {
+		u.Commands = []string{rule}
+		return nil
+	}
+	u.Commands = slices.DeleteFunc(u.Commands, func(r string) bool {
+		return r[1:] == target
+	})
+	u.Commands = append(u.Commands, rule)
+	return nil
+}
+
+// addPattern adds the pattern unless it's already there.
+// The "*" pattern replaces all others.
+func addPattern(patterns []string, pattern string) []string {
+	if pattern == "*" {
+		return []string{"*"}
+	}
+	if slices.Contains(patterns, pattern) || slices.Contains(patterns, "*") {
+		return patterns
+	}
+	return append(patterns, pattern)
+}
+
+// hashPass returns the SHA-256 hash of the password (hex-encoded).
+func hashPass(pass string) string {
+	sum := sha256.Sum256([]byte(pass))
+	return hex.EncodeToString(sum[:])
+}
+
+// validHash reports whether the string is a hex-encoded SHA-256 hash.
+func validHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
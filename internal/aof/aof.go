@@ -0,0 +1,392 @@
+// Package aof implements the append-only command log
+// used for point-in-time recovery. The server appends successful
+// write commands to the log, and the log is replayed on top of
+// a snapshot to restore the database to a given moment.
+//
+// The log uses the Redis AOF format: commands are RESP arrays
+// of bulk strings, preceded by a #TS:unix-time-seconds annotation
+// whenever the time changes. Commands that depend on the current
+// time or on randomness are logged in a deterministic form
+// (see [Rewrite]).
+package aof
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Fsync policies.
+const (
+	FsyncAlways   = "always"   // sync after every write
+	FsyncEverySec = "everysec" // sync once per second
+	FsyncNo       = "no"       // let the operating system decide
+)
+
+// rotateLayout is the time layout of the rotated file name suffix.
+// Sorting the file names sorts the files by rotation time.
+const rotateLayout = "20060102T150405.000"
+
+var (
+	ErrFormat        = errors.New("aof: invalid file format")
+	ErrUnknownFsync  = errors.New("aof: unknown fsync policy")
+	ErrLoggerClosed  = errors.New("aof: logger is closed")
+	errTruncatedFile = fmt.Errorf("%w: truncated file", ErrFormat)
+)
+
+// Options configure the logger.
+type Options struct {
+	// Fsync is the fsync policy. If empty, uses FsyncEverySec.
+	Fsync string
+	// MaxSize is the file size in bytes that triggers rotation:
+	// the file is renamed to path.timestamp and a new file is started.
+	// If zero, the file is never rotated.
+	MaxSize int64
+}
+
+// Logger appends write commands to the log file.
+// It is safe for concurrent use.
+type Logger struct {
+	path string
+	opts Options
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	lastTS int64 // last written timestamp annotation
+	lastDB int   // last selected database (-1 if unknown)
+	dirty  bool  // there are writes that are not synced yet
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens the log file for appending, creating it if necessary.
+// The opts parameter is optional.
+func Open(path string, opts *Options) (*Logger, error) {
+	l := &Logger{path: path, opts: Options{Fsync: FsyncEverySec}}
+	if opts != nil {
+		if opts.Fsync != "" {
+			l.opts.Fsync = opts.Fsync
+		}
+		l.opts.MaxSize = opts.MaxSize
+	}
+	switch l.opts.Fsync {
+	case FsyncAlways, FsyncEverySec, FsyncNo:
+	default:
+		return nil, ErrUnknownFsync
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	if l.opts.Fsync == FsyncEverySec {
+		go l.syncEverySec()
+	} else {
+		close(l.done)
+	}
+	return l, nil
+}
+
+// Path returns the log file path.
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Append appends the commands run in the given database
+// to the log as a single entry. Each command is a list of
+// arguments, starting with the name. Several commands are
+// wrapped in MULTI/EXEC, so that they are replayed atomically.
+// The entry is preceded by SELECT if the database differs
+// from the previous entry's (the commands themselves may
+// also include SELECT). Rotates the file if it grows too large.
+func (l *Logger) Append(db int, cmds ...[][]byte) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+
+	var buf bytes.Buffer
+	if ts := time.Now().Unix(); ts != l.lastTS {
+		fmt.Fprintf(&buf, "#TS:%d\r\n", ts)
+		l.lastTS = ts
+	}
+	if db != l.lastDB {
+		writeCommand(&buf, selectArgs(db))
+		l.lastDB = db
+	}
+	if len(cmds) > 1 {
+		writeCommand(&buf, [][]byte{[]byte("multi")})
+	}
+	for _, args := range cmds {
+		writeCommand(&buf, args)
+		if index, ok := parseSelect(args); ok {
+			l.lastDB = index
+		}
+	}
+	if len(cmds) > 1 {
+		writeCommand(&buf, [][]byte{[]byte("exec")})
+	}
+
+	n, err := l.file.Write(buf.Bytes())
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	l.dirty = true
+	if l.opts.Fsync == FsyncAlways {
+		if err := l.sync(); err != nil {
+			return err
+		}
+	}
+	if l.opts.MaxSize > 0 && l.size >= l.opts.MaxSize {
+		return l.rotate()
+	}
+	return nil
+}
+
+// Rotate renames the log file to path.timestamp
+// and starts a new one.
+func (l *Logger) Rotate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	return l.rotate()
+}
+
+// Close syncs and closes the log file.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.stop)
+	l.mu.Unlock()
+	<-l.done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.sync(); err != nil {
+		_ = l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// open opens the log file at the path.
+func (l *Logger) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	l.lastTS = 0
+	// A new file starts in the default database. An existing
+	// one may end in any database, so the first entry selects
+	// the database explicitly.
+	l.lastDB = 0
+	if l.size > 0 {
+		l.lastDB = -1
+	}
+	l.dirty = false
+	return nil
+}
+
+// rotate renames the log file and opens a new one.
+func (l *Logger) rotate() error {
+	if err := l.sync(); err != nil {
+		return err
+	}
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	rotated := l.path + "." + time.Now().Format(rotateLayout)
+	if err := os.Rename(l.path, rotated); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// sync commits the written data to stable storage
+// (unless the policy leaves it to the operating system).
+func (l *Logger) sync() error {
+	if !l.dirty || l.opts.Fsync == FsyncNo {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+// syncEverySec syncs the log file once per second until closed.
+func (l *Logger) syncEverySec() {
+	defer close(l.done)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			_ = l.sync()
+			l.mu.Unlock()
+		}
+	}
+}
+
+// writeCommand writes a command as an array of bulk strings.
+func writeCommand(buf *bytes.Buffer, args [][]byte) {
+	fmt.Fprintf(buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(buf, "$%d\r\n", len(arg))
+		buf.Write(arg)
+		buf.WriteString("\r\n")
+	}
+}
+
+// selectArgs returns the arguments of the SELECT command.
+func selectArgs(db int) [][]byte {
+	return [][]byte{[]byte("select"), []byte(strconv.Itoa(db))}
+}
+
+// parseSelect returns the database index
+// if the command is SELECT.
+func parseSelect(args [][]byte) (int, bool) {
+	if len(args) != 2 || !bytes.EqualFold(args[0], []byte("select")) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(string(args[1]))
+	return index, err == nil
+}
+
+// Entry is a command read from the log.
+type Entry struct {
+	Time time.Time // time of the latest annotation (zero if none)
+	Args [][]byte  // command name and arguments
+}
+
+// Reader reads commands from a log file.
+type Reader struct {
+	r  *bufio.Reader
+	ts time.Time
+}
+
+// NewReader creates a reader for the log.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next command from the log.
+// Returns io.EOF at the end of the log, and ErrFormat
+// if the log is invalid or its last command is incomplete
+// (the server stopped in the middle of a write).
+func (r *Reader) Next() (Entry, error) {
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			return Entry{}, io.EOF
+		}
+		if err != nil {
+			return Entry{}, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '#':
+			// Annotation, only timestamps are meaningful.
+			if ts, ok := bytes.CutPrefix(line, []byte("#TS:")); ok {
+				sec, err := strconv.ParseInt(string(ts), 10, 64)
+				if err != nil {
+					return Entry{}, ErrFormat
+				}
+				r.ts = time.Unix(sec, 0)
+			}
+		case '*':
+			n, err := strconv.Atoi(string(line[1:]))
+			if err != nil || n <= 0 {
+				return Entry{}, ErrFormat
+			}
+			args := make([][]byte, n)
+			for i := range args {
+				if args[i], err = r.readBulk(); err != nil {
+					return Entry{}, err
+				}
+			}
+			return Entry{Time: r.ts, Args: args}, nil
+		default:
+			return Entry{}, ErrFormat
+		}
+	}
+}
+
+// readBulk reads a bulk string.
+func (r *Reader) readBulk() ([]byte, error) {
+	line, err := r.readLine()
+	if err == io.EOF {
+		return nil, errTruncatedFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '$' {
+		return nil, ErrFormat
+	}
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 {
+		return nil, ErrFormat
+	}
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errTruncatedFile
+		}
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// readLine reads a line without the line terminator.
+// Returns io.EOF only at the end of the log,
+// and errTruncatedFile for an incomplete last line.
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadBytes('\n')
+	if err == io.EOF {
+		if len(line) == 0 {
+			return nil, io.EOF
+		}
+		return nil, errTruncatedFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r")), nil
+}
@@ -0,0 +1,209 @@
+package aof_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		log, err := aof.Open(path, nil)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, log.Path(), path)
+		testx.AssertNoErr(t, log.Close())
+		_, err = os.Stat(path)
+		testx.AssertNoErr(t, err)
+	})
+	t.Run("append", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		log, _ := aof.Open(path, nil)
+		_ = log.Append(0, args("set", "name", "alice"))
+		_ = log.Close()
+
+		log, _ = aof.Open(path, nil)
+		_ = log.Append(0, args("set", "name", "bob"))
+		_ = log.Close()
+
+		// The reopened file may end in any database,
+		// so the next entry selects it explicitly.
+		entries := readAll(t, path)
+		testx.AssertEqual(t, len(entries), 3)
+		testx.AssertEqual(t, str(entries[1].Args), "select 0")
+		testx.AssertEqual(t, str(entries[2].Args), "set name bob")
+	})
+	t.Run("unknown fsync", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		_, err := aof.Open(path, &aof.Options{Fsync: "sometimes"})
+		testx.AssertErr(t, err, aof.ErrUnknownFsync)
+	})
+}
+
+func TestAppend(t *testing.T) {
+	for _, fsync := range []string{aof.FsyncAlways, aof.FsyncEverySec, aof.FsyncNo} {
+		t.Run(fsync, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "appendonly.aof")
+			log, err := aof.Open(path, &aof.Options{Fsync: fsync})
+			testx.AssertNoErr(t, err)
+
+			start := time.Now().Truncate(time.Second)
+			err = log.Append(0, args("set", "name", "alice smith"))
+			testx.AssertNoErr(t, err)
+			err = log.Append(0, args("incr", "count"), args("rpush", "list", "\r\n"))
+			testx.AssertNoErr(t, err)
+			testx.AssertNoErr(t, log.Close())
+
+			entries := readAll(t, path)
+			got := make([]string, len(entries))
+			for i, e := range entries {
+				got[i] = str(e.Args)
+				testx.AssertEqual(t, e.Time.Before(start), false)
+			}
+			testx.AssertEqual(t, got, []string{
+				"set name alice smith", "multi", "incr count", "rpush list \r\n", "exec",
+			})
+		})
+	}
+	t.Run("select", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		log, _ := aof.Open(path, nil)
+		_ = log.Append(0, args("set", "name", "alice"))
+		_ = log.Append(2, args("set", "name", "bob"))
+		_ = log.Append(2, args("set", "age", "25"), args("select", "3"), args("incr", "count"))
+		_ = log.Append(3, args("set", "name", "cindy"))
+		_ = log.Close()
+
+		entries := readAll(t, path)
+		got := make([]string, len(entries))
+		for i, e := range entries {
+			got[i] = str(e.Args)
+		}
+		testx.AssertEqual(t, got, []string{
+			"set name alice",
+			"select 2", "set name bob",
+			"multi", "set age 25", "select 3", "incr count", "exec",
+			"set name cindy",
+		})
+	})
+	t.Run("closed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		log, _ := aof.Open(path, nil)
+		_ = log.Close()
+		err := log.Append(0, args("set", "name", "alice"))
+		testx.AssertErr(t, err, aof.ErrLoggerClosed)
+	})
+}
+
+func TestRotate(t *testing.T) {
+	t.Run("max size", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "appendonly.aof")
+		log, _ := aof.Open(path, &aof.Options{MaxSize: 40})
+		for _, name := range []string{"alice", "bob", "cindy"} {
+			err := log.Append(0, args("set", "name", name))
+			testx.AssertNoErr(t, err)
+			time.Sleep(2 * time.Millisecond)
+		}
+		_ = log.Close()
+
+		// Each entry exceeds the maximum size,
+		// so every file has a single command.
+		files, _ := filepath.Glob(path + ".*")
+		testx.AssertEqual(t, len(files), 3)
+		var got []string
+		for _, file := range append(files, path) {
+			for _, e := range readAll(t, file) {
+				got = append(got, str(e.Args))
+			}
+		}
+		testx.AssertEqual(t, got, []string{"set name alice", "set name bob", "set name cindy"})
+	})
+	t.Run("manual", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "appendonly.aof")
+		log, _ := aof.Open(path, nil)
+		_ = log.Append(0, args("set", "name", "alice"))
+		err := log.Rotate()
+		testx.AssertNoErr(t, err)
+		_ = log.Append(0, args("set", "name", "bob"))
+		_ = log.Close()
+
+		files, _ := filepath.Glob(path + ".*")
+		testx.AssertEqual(t, len(files), 1)
+		entries := readAll(t, path)
+		testx.AssertEqual(t, len(entries), 1)
+		testx.AssertEqual(t, entries[0].Time.IsZero(), false)
+	})
+}
+
+func TestReader(t *testing.T) {
+	t.Run("redis format", func(t *testing.T) {
+		data := "#TS:1700000000\r\n*2\r\n$6\r\nSELECT\r\n$1\r\n0\r\n" +
+			"*3\r\n$3\r\nset\r\n$4\r\nname\r\n$5\r\nalice\r\n"
+		rd := aof.NewReader(strings.NewReader(data))
+		e, err := rd.Next()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, str(e.Args), "SELECT 0")
+		testx.AssertEqual(t, e.Time, time.Unix(1700000000, 0))
+		e, err = rd.Next()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, str(e.Args), "set name alice")
+		_, err = rd.Next()
+		testx.AssertErr(t, err, io.EOF)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		data := "*3\r\n$3\r\nset\r\n$4\r\nname\r\n$5\r\nali"
+		rd := aof.NewReader(strings.NewReader(data))
+		_, err := rd.Next()
+		testx.AssertEqual(t, errors.Is(err, aof.ErrFormat), true)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		rd := aof.NewReader(strings.NewReader("set name alice\r\n"))
+		_, err := rd.Next()
+		testx.AssertErr(t, err, aof.ErrFormat)
+	})
+}
+
+func args(vals ...string) [][]byte {
+	out := make([][]byte, len(vals))
+	for i, val := range vals {
+		out[i] = []byte(val)
+	}
+	return out
+}
+
+func str(args [][]byte) string {
+	vals := make([]string, len(args))
+	for i, arg := range args {
+		vals[i] = string(arg)
+	}
+	return strings.Join(vals, " ")
+}
+
+func readAll(tb testing.TB, path string) []aof.Entry {
+	tb.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+	var entries []aof.Entry
+	rd := aof.NewReader(file)
+	for {
+		e, err := rd.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+}
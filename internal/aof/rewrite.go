@@ -0,0 +1,163 @@
+package aof
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// Rewrite returns the command in a form that gives the same result
+// when replayed later, given the result of running it at the moment:
+//
+//   - relative expiration times (EXPIRE, SETEX, SET ... EX, HEXPIRE,
+//     RESTORE and the like) become absolute Unix times in milliseconds;
+//   - SPOP becomes SREM of the popped elements.
+//
+// Returns the original command if no rewrite is needed,
+// and nil if there is nothing to log (SPOP popped nothing).
+// The args slice starts with the lowercase command name.
+func Rewrite(args [][]byte, res any, now time.Time) [][]byte {
+	switch string(args[0]) {
+	case "expire":
+		return rewriteTTL(args, "pexpireat", 2, time.Second, now)
+	case "pexpire":
+		return rewriteTTL(args, "pexpireat", 2, time.Millisecond, now)
+	case "hexpire":
+		return rewriteTTL(args, "hpexpireat", 2, time.Second, now)
+	case "hpexpire":
+		return rewriteTTL(args, "hpexpireat", 2, time.Millisecond, now)
+	case "setex", "psetex":
+		// SETEX key seconds value -> SET key value PXAT ms
+		if len(args) != 4 {
+			return args
+		}
+		unit := time.Second
+		if string(args[0]) == "psetex" {
+			unit = time.Millisecond
+		}
+		at, ok := expireAt(args[2], unit, now)
+		if !ok {
+			return args
+		}
+		return [][]byte{[]byte("set"), args[1], args[3], []byte("pxat"), at}
+	case "set":
+		return rewriteOption(args, 3, len(args), now)
+	case "hsetex", "hgetex":
+		// The options come before the FIELDS argument.
+		end := len(args)
+		for i := 2; i < len(args); i++ {
+			if bytes.EqualFold(args[i], []byte("fields")) {
+				end = i
+				break
+			}
+		}
+		return rewriteOption(args, 2, end, now)
+	case "restore":
+		return rewriteRestore(args, now)
+	case "spop":
+		return rewriteSPop(args, res)
+	default:
+		return args
+	}
+}
+
+// rewriteTTL replaces the command name and the relative
+// expiration time at the given position with an absolute one.
+func rewriteTTL(args [][]byte, name string, pos int, unit time.Duration, now time.Time) [][]byte {
+	if len(args) <= pos {
+		return args
+	}
+	at, ok := expireAt(args[pos], unit, now)
+	if !ok {
+		return args
+	}
+	out := make([][]byte, len(args))
+	copy(out, args)
+	out[0] = []byte(name)
+	out[pos] = at
+	return out
+}
+
+// rewriteOption replaces the EX seconds or PX milliseconds option
+// among args[start:end] with PXAT unix-time-milliseconds.
+func rewriteOption(args [][]byte, start, end int, now time.Time) [][]byte {
+	for i := start; i < end-1; i++ {
+		var unit time.Duration
+		switch {
+		case bytes.EqualFold(args[i], []byte("ex")):
+			unit = time.Second
+		case bytes.EqualFold(args[i], []byte("px")):
+			unit = time.Millisecond
+		default:
+			continue
+		}
+		at, ok := expireAt(args[i+1], unit, now)
+		if !ok {
+			return args
+		}
+		out := make([][]byte, len(args))
+		copy(out, args)
+		out[i] = []byte("pxat")
+		out[i+1] = at
+		return out
+	}
+	return args
+}
+
+// rewriteRestore replaces the relative ttl of RESTORE
+// with an absolute one, adding the ABSTTL option.
+// A zero ttl keeps the expiration time from the dump,
+// so it stays as is.
+func rewriteRestore(args [][]byte, now time.Time) [][]byte {
+	if len(args) < 4 || bytes.Equal(args[2], []byte("0")) {
+		return args
+	}
+	for _, arg := range args[4:] {
+		if bytes.EqualFold(arg, []byte("absttl")) {
+			return args
+		}
+	}
+	at, ok := expireAt(args[2], time.Millisecond, now)
+	if !ok {
+		return args
+	}
+	out := make([][]byte, len(args), len(args)+1)
+	copy(out, args)
+	out[2] = at
+	return append(out, []byte("absttl"))
+}
+
+// rewriteSPop replaces SPOP with SREM of the popped elements.
+func rewriteSPop(args [][]byte, res any) [][]byte {
+	var elems []core.Value
+	switch val := res.(type) {
+	case core.Value:
+		if val != nil {
+			elems = []core.Value{val}
+		}
+	case []core.Value:
+		elems = val
+	}
+	if len(args) < 2 || len(elems) == 0 {
+		return nil
+	}
+	out := make([][]byte, 0, len(elems)+2)
+	out = append(out, []byte("srem"), args[1])
+	for _, elem := range elems {
+		out = append(out, elem)
+	}
+	return out
+}
+
+// expireAt converts a relative expiration time in the given units
+// to a Unix time in milliseconds.
+func expireAt(ttl []byte, unit time.Duration, now time.Time) ([]byte, bool) {
+	n, err := strconv.ParseInt(string(ttl), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	at := now.Add(time.Duration(n) * unit).UnixMilli()
+	return []byte(strconv.FormatInt(at, 10)), true
+}
@@ -0,0 +1,70 @@
+package aof_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestRewrite(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	tests := []struct {
+		cmd  string
+		res  any
+		want string
+	}{
+		{cmd: "set name alice", want: "set name alice"},
+		{cmd: "set name alice EX 60", want: "set name alice pxat 1700000060000"},
+		{cmd: "set name alice NX px 500", want: "set name alice NX pxat 1700000000500"},
+		{cmd: "set name alice EXAT 1800000000", want: "set name alice EXAT 1800000000"},
+		{cmd: "setex name 60 alice", want: "set name alice pxat 1700000060000"},
+		{cmd: "psetex name 500 alice", want: "set name alice pxat 1700000000500"},
+		{cmd: "expire name 60 NX", want: "pexpireat name 1700000060000 NX"},
+		{cmd: "pexpire name 500", want: "pexpireat name 1700000000500"},
+		{cmd: "expireat name 1800000000", want: "expireat name 1800000000"},
+		{
+			cmd:  "hexpire person 60 FIELDS 1 name",
+			want: "hpexpireat person 1700000060000 FIELDS 1 name",
+		},
+		{
+			cmd:  "hpexpire person 500 FIELDS 1 name",
+			want: "hpexpireat person 1700000000500 FIELDS 1 name",
+		},
+		{
+			cmd:  "hsetex person EX 60 FIELDS 1 name alice",
+			want: "hsetex person pxat 1700000060000 FIELDS 1 name alice",
+		},
+		{
+			cmd:  "hsetex person FIELDS 2 ex 10 px 20",
+			want: "hsetex person FIELDS 2 ex 10 px 20",
+		},
+		{
+			cmd:  "hgetex person PX 500 FIELDS 1 name",
+			want: "hgetex person pxat 1700000000500 FIELDS 1 name",
+		},
+		{cmd: "restore name 0 dump", want: "restore name 0 dump"},
+		{cmd: "restore name 500 dump REPLACE", want: "restore name 1700000000500 dump REPLACE absttl"},
+		{
+			cmd:  "restore name 1800000000000 dump ABSTTL",
+			want: "restore name 1800000000000 dump ABSTTL",
+		},
+		{cmd: "spop key", res: core.Value("one"), want: "srem key one"},
+		{
+			cmd:  "spop key 2",
+			res:  []core.Value{core.Value("one"), core.Value("two")},
+			want: "srem key one two",
+		},
+		{cmd: "spop key", res: core.Value(nil), want: ""},
+		{cmd: "spop key 2", res: []core.Value{}, want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			got := aof.Rewrite(args(strings.Fields(test.cmd)...), test.res, now)
+			testx.AssertEqual(t, str(got), test.want)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package clients tracks the connected clients
+// (the CLIENT command family).
+package clients
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUser is the user of the clients
+// that have not authenticated.
+const DefaultUser = "default"
+
+// Client describes a client connection.
+// Safe for concurrent use.
+type Client struct {
+	ID      int64     // unique client id
+	Addr    string    // client address
+	LAddr   string    // server address the client connected to
+	Created time.Time // when the client connected
+
+	close func() error // closes the connection
+
+	mu      sync.Mutex
+	name    string
+	libName string
+	libVer  string
+	user    string
+	authed  bool
+	db      int
+	proto   int       // RESP protocol version
+	cmd     string    // last command
+	active  time.Time // last command time
+	multi   int       // number of queued commands (-1 if not in MULTI)
+	monitor bool
+	noEvict bool
+	killed  bool
+
+	// Client-side caching (CLIENT TRACKING).
+	tracking   *TrackingOptions   // nil if the tracking is off
+	caching    string             // CLIENT CACHING mode for the next command
+	subscribed bool               // subscribed to __redis__:invalidate
+	notify     func(Invalidation) // delivers the invalidation messages
+}
+
+// Name returns the client name.
+func (c *Client) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+// SetName sets the client name (empty name clears it).
+func (c *Client) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+// SetLibName sets the client library name.
+func (c *Client) SetLibName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.libName = name
+}
+
+// SetLibVer sets the client library version.
+func (c *Client) SetLibVer(ver string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.libVer = ver
+}
+
+// User returns the name of the authenticated user.
+func (c *Client) User() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.user
+}
+
+// Authenticated reports whether the client has authenticated.
+func (c *Client) Authenticated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.authed
+}
+
+// SetUser sets the user the client has authenticated as.
+func (c *Client) SetUser(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.user = name
+	c.authed = true
+}
+
+// DB returns the selected database index.
+func (c *Client) DB() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.db
+}
+
+// SetDB sets the selected database index.
+func (c *Client) SetDB(db int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db = db
+}
+
+// Proto returns the RESP protocol version.
+func (c *Client) Proto() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.proto
+}
+
+// SetProto sets the RESP protocol version (HELLO).
+func (c *Client) SetProto(proto int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.proto = proto
+}
+
+// SetNoEvict sets the no-evict mode.
+// Redka never evicts keys, so the mode
+// is only reported in the client flags.
+func (c *Client) SetNoEvict(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.noEvict = on
+}
+
+// SetMonitor marks the client as monitoring (MONITOR).
+func (c *Client) SetMonitor() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.monitor = true
+}
+
+// Touch records the command the client is running.
+func (c *Client) Touch(cmd string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cmd = cmd
+	c.active = time.Now()
+}
+
+// SetMulti sets the number of queued commands
+// (-1 if the client is not in a transaction).
+func (c *Client) SetMulti(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.multi = n
+}
+
+// Killed reports whether the client has been killed
+// while running a command. Such clients should be
+// disconnected after the command reply is sent.
+func (c *Client) Killed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.killed
+}
+
+// String returns the client description
+// in the CLIENT LIST format.
+func (c *Client) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "id=%d addr=%s laddr=%s fd=-1 name=%s", c.ID, c.Addr, c.LAddr, c.name)
+	fmt.Fprintf(&b, " age=%d idle=%d flags=%s db=%d",
+		int64(now.Sub(c.Created).Seconds()), int64(now.Sub(c.active).Seconds()), c.flags(), c.db)
+	fmt.Fprintf(&b, " sub=%d psub=0 ssub=0 multi=%d watch=0", c.subs(), c.multi)
+	b.WriteString(" qbuf=0 qbuf-free=0 argv-mem=0 multi-mem=0 rbs=0 rbp=0 obl=0 oll=0 omem=0 tot-mem=0")
+	fmt.Fprintf(&b, " events=r cmd=%s user=%s redir=%d resp=%d", c.cmd, c.user, c.redir(), c.proto)
+	fmt.Fprintf(&b, " lib-name=%s lib-ver=%s", c.libName, c.libVer)
+	return b.String()
+}
+
+// flags returns the client flags in the CLIENT LIST format.
+func (c *Client) flags() string {
+	var flags string
+	if c.monitor {
+		flags += "O"
+	}
+	if c.subscribed {
+		flags += "P"
+	}
+	if c.multi >= 0 {
+		flags += "x"
+	}
+	if c.tracking != nil {
+		flags += "t"
+		if c.tracking.BCast {
+			flags += "B"
+		}
+	}
+	if c.noEvict {
+		flags += "e"
+	}
+	if flags == "" {
+		flags = "N"
+	}
+	return flags
+}
+
+// subs returns the number of subscribed channels.
+func (c *Client) subs() int {
+	if c.subscribed {
+		return 1
+	}
+	return 0
+}
+
+// redir returns the id of the client the invalidation
+// messages are redirected to (0 if none, -1 if the
+// tracking is off).
+func (c *Client) redir() int64 {
+	if c.tracking == nil {
+		return -1
+	}
+	return c.tracking.Redirect
+}
+
+// kill closes the client connection.
+// If the client is running the current command (self),
+// only marks it as killed, so that the connection
+// is closed after the reply is sent.
+func (c *Client) kill(self bool) {
+	c.mu.Lock()
+	c.killed = true
+	c.mu.Unlock()
+	if !self {
+		_ = c.close()
+	}
+}
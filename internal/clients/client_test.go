@@ -0,0 +1,38 @@
+package clients_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestClientString(t *testing.T) {
+	reg := clients.NewRegistry()
+	c := reg.Add("127.0.0.1:5000", "127.0.0.1:6379", noClose)
+	re := regexp.MustCompile(`^id=1 addr=127\.0\.0\.1:5000 laddr=127\.0\.0\.1:6379 fd=-1 name= ` +
+		`age=0 idle=0 flags=N db=0 .* multi=-1 .* cmd=NULL user=default .* lib-name= lib-ver=$`)
+	testx.AssertEqual(t, re.MatchString(c.String()), true)
+
+	c.SetName("alice")
+	c.SetLibName("go-redis")
+	c.SetLibVer("9.0.0")
+	c.SetMulti(2)
+	c.SetNoEvict(true)
+	c.Touch("get")
+	re = regexp.MustCompile(`^id=1 .* name=alice .* flags=xe .* multi=2 .* ` +
+		`cmd=get .* lib-name=go-redis lib-ver=9\.0\.0$`)
+	testx.AssertEqual(t, re.MatchString(c.String()), true)
+	testx.AssertEqual(t, c.Name(), "alice")
+
+	c.SetMulti(-1)
+	c.SetNoEvict(false)
+	c.SetMonitor()
+	re = regexp.MustCompile(` flags=O `)
+	testx.AssertEqual(t, re.MatchString(c.String()), true)
+}
+
+func noClose() error {
+	return nil
+}
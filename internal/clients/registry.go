@@ -0,0 +1,220 @@
+package clients
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pause modes.
+const (
+	PauseWrite = "write" // pause write commands
+	PauseAll   = "all"   // pause all commands
+)
+
+// Filter selects clients to kill (CLIENT KILL).
+// Zero fields match any client.
+type Filter struct {
+	ID     int64         // client id
+	Addr   string        // client address
+	LAddr  string        // server address
+	User   string        // authenticated user
+	MaxAge time.Duration // connected for longer than
+	SkipMe bool          // skip the client running the command
+}
+
+// match reports whether the client matches the filter.
+func (f Filter) match(c *Client, now time.Time) bool {
+	if f.ID != 0 && c.ID != f.ID {
+		return false
+	}
+	if f.Addr != "" && c.Addr != f.Addr {
+		return false
+	}
+	if f.LAddr != "" && c.LAddr != f.LAddr {
+		return false
+	}
+	if f.User != "" && c.User() != f.User {
+		return false
+	}
+	if f.MaxAge != 0 && now.Sub(c.Created) < f.MaxAge {
+		return false
+	}
+	return true
+}
+
+// Registry tracks the connected clients.
+// Safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	nextID  int64
+	clients map[int64]*Client
+
+	// Client pause (CLIENT PAUSE).
+	pauseMu    sync.Mutex
+	pauseMode  string
+	pauseUntil time.Time
+	unpaused   chan struct{} // closed on unpause
+
+	// Client-side caching (CLIENT TRACKING).
+	trackMu sync.Mutex
+	tracked map[string]map[int64]struct{} // key -> ids of the clients that read it
+	bcast   map[int64]*Client             // clients in the broadcasting mode
+	ntrack  atomic.Int64                  // number of tracking clients
+}
+
+// NewRegistry creates a new client registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		nextID:  1,
+		clients: map[int64]*Client{},
+		tracked: map[string]map[int64]struct{}{},
+		bcast:   map[int64]*Client{},
+	}
+}
+
+// Add registers a new client. The close function
+// closes the client connection (used by Kill).
+func (r *Registry) Add(addr, laddr string, close func() error) *Client {
+	now := time.Now()
+	c := &Client{
+		Addr:    addr,
+		LAddr:   laddr,
+		Created: now,
+		close:   close,
+		user:    DefaultUser,
+		cmd:     "NULL",
+		active:  now,
+		multi:   -1,
+		proto:   2,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c.ID = r.nextID
+	r.nextID++
+	r.clients[c.ID] = c
+	return c
+}
+
+// Remove unregisters the client and turns off its tracking.
+func (r *Registry) Remove(c *Client) {
+	_ = r.SetTracking(c, nil)
+	c.SetNotify(nil)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, c.ID)
+}
+
+// Len returns the number of connected clients.
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.clients)
+}
+
+// List returns the clients with the given ids
+// (all clients if none are given), sorted by id.
+func (r *Registry) List(ids ...int64) []*Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*Client
+	if len(ids) == 0 {
+		list = make([]*Client, 0, len(r.clients))
+		for _, c := range r.clients {
+			list = append(list, c)
+		}
+	} else {
+		for _, id := range ids {
+			if c, ok := r.clients[id]; ok {
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Kill closes the connections of the clients matching
+// the filter, and returns the number of killed clients.
+// The self client is the one running the command;
+// it is disconnected after the command reply is sent.
+func (r *Registry) Kill(f Filter, self *Client) int {
+	now := time.Now()
+	var killed []*Client
+	r.mu.Lock()
+	for _, c := range r.clients {
+		if f.SkipMe && c == self {
+			continue
+		}
+		if f.match(c, now) {
+			killed = append(killed, c)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, c := range killed {
+		c.kill(c == self)
+	}
+	return len(killed)
+}
+
+// Pause suspends the client commands for the given
+// duration. In the PauseWrite mode, suspends only
+// the write commands, in the PauseAll mode - all
+// commands. Extends the current pause if it ends
+// earlier, and switches it to the PauseAll mode
+// if requested.
+func (r *Registry) Pause(timeout time.Duration, mode string) {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+	until := time.Now().Add(timeout)
+	if r.unpaused == nil || time.Now().After(r.pauseUntil) {
+		r.unpaused = make(chan struct{})
+		r.pauseMode = mode
+		r.pauseUntil = until
+		return
+	}
+	if until.After(r.pauseUntil) {
+		r.pauseUntil = until
+	}
+	if mode == PauseAll {
+		r.pauseMode = PauseAll
+	}
+}
+
+// Unpause resumes the suspended commands.
+func (r *Registry) Unpause() {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+	if r.unpaused != nil {
+		close(r.unpaused)
+		r.unpaused = nil
+	}
+}
+
+// Wait blocks while the clients are paused.
+// If write is false, blocks only in the PauseAll mode.
+func (r *Registry) Wait(write bool) {
+	for {
+		r.pauseMu.Lock()
+		unpaused, mode, until := r.unpaused, r.pauseMode, r.pauseUntil
+		r.pauseMu.Unlock()
+
+		if unpaused == nil || (!write && mode != PauseAll) {
+			return
+		}
+		wait := time.Until(until)
+		if wait <= 0 {
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-unpaused:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
@@ -0,0 +1,135 @@
+package clients_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestRegistryList(t *testing.T) {
+	reg := clients.NewRegistry()
+	c1 := reg.Add("127.0.0.1:5001", "127.0.0.1:6379", noClose)
+	c2 := reg.Add("127.0.0.1:5002", "127.0.0.1:6379", noClose)
+	c3 := reg.Add("127.0.0.1:5003", "127.0.0.1:6379", noClose)
+	testx.AssertEqual(t, reg.Len(), 3)
+	testx.AssertEqual(t, []int64{c1.ID, c2.ID, c3.ID}, []int64{1, 2, 3})
+
+	testx.AssertEqual(t, ids(reg.List()), []int64{1, 2, 3})
+	testx.AssertEqual(t, ids(reg.List(3, 1, 42)), []int64{1, 3})
+
+	reg.Remove(c2)
+	testx.AssertEqual(t, reg.Len(), 2)
+	testx.AssertEqual(t, ids(reg.List()), []int64{1, 3})
+
+	// Ids are not reused.
+	c4 := reg.Add("127.0.0.1:5004", "127.0.0.1:6379", noClose)
+	testx.AssertEqual(t, c4.ID, int64(4))
+}
+
+func TestRegistryKill(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter clients.Filter
+		want   []int64
+	}{
+		{"id", clients.Filter{ID: 2}, []int64{2}},
+		{"addr", clients.Filter{Addr: "127.0.0.1:5003"}, []int64{3}},
+		{"laddr", clients.Filter{LAddr: "127.0.0.1:6380"}, []int64{3}},
+		{"user", clients.Filter{User: clients.DefaultUser}, []int64{1, 2, 3}},
+		{"unknown user", clients.Filter{User: "bob"}, nil},
+		{"max age", clients.Filter{MaxAge: time.Hour}, nil},
+		{"skip me", clients.Filter{SkipMe: true}, []int64{2, 3}},
+		{"self", clients.Filter{ID: 1}, []int64{1}},
+		{"no match", clients.Filter{ID: 1, Addr: "127.0.0.1:5002"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := clients.NewRegistry()
+			var closed []int64
+			add := func(addr, laddr string) *clients.Client {
+				var c *clients.Client
+				c = reg.Add(addr, laddr, func() error {
+					closed = append(closed, c.ID)
+					return nil
+				})
+				return c
+			}
+			self := add("127.0.0.1:5001", "127.0.0.1:6379")
+			add("127.0.0.1:5002", "127.0.0.1:6379")
+			add("127.0.0.1:5003", "127.0.0.1:6380")
+
+			n := reg.Kill(test.filter, self)
+			testx.AssertEqual(t, n, len(test.want))
+
+			// The self client is marked as killed,
+			// but its connection is not closed.
+			var killed []int64
+			for _, c := range reg.List() {
+				if c.Killed() {
+					killed = append(killed, c.ID)
+				}
+			}
+			testx.AssertEqual(t, killed, test.want)
+			for _, id := range closed {
+				testx.AssertEqual(t, id != self.ID, true)
+			}
+		})
+	}
+}
+
+func TestRegistryPause(t *testing.T) {
+	t.Run("write", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		reg.Pause(50*time.Millisecond, clients.PauseWrite)
+
+		start := time.Now()
+		reg.Wait(false)
+		testx.AssertEqual(t, time.Since(start) < 25*time.Millisecond, true)
+
+		reg.Wait(true)
+		testx.AssertEqual(t, time.Since(start) >= 50*time.Millisecond, true)
+	})
+	t.Run("all", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		reg.Pause(50*time.Millisecond, clients.PauseAll)
+
+		start := time.Now()
+		reg.Wait(false)
+		testx.AssertEqual(t, time.Since(start) >= 50*time.Millisecond, true)
+	})
+	t.Run("unpause", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		reg.Pause(time.Hour, clients.PauseWrite)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			reg.Unpause()
+		}()
+
+		start := time.Now()
+		reg.Wait(true)
+		testx.AssertEqual(t, time.Since(start) < time.Second, true)
+
+		// Unpausing twice is fine.
+		reg.Unpause()
+	})
+	t.Run("extend", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		reg.Pause(10*time.Millisecond, clients.PauseWrite)
+		reg.Pause(50*time.Millisecond, clients.PauseAll)
+		reg.Pause(time.Millisecond, clients.PauseWrite)
+
+		start := time.Now()
+		reg.Wait(false)
+		testx.AssertEqual(t, time.Since(start) >= 50*time.Millisecond, true)
+	})
+}
+
+func ids(list []*clients.Client) []int64 {
+	out := make([]int64, len(list))
+	for i, c := range list {
+		out[i] = c.ID
+	}
+	return out
+}
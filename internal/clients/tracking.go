@@ -0,0 +1,325 @@
+package clients
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// InvalidateChannel is the channel for the invalidation
+// messages of the clients that redirect them.
+const InvalidateChannel = "__redis__:invalidate"
+
+// Caching modes set by CLIENT CACHING
+// (apply to the next command only).
+const (
+	CachingYes = "yes" // track the keys read (OPTIN mode)
+	CachingNo  = "no"  // don't track the keys read (OPTOUT mode)
+)
+
+var (
+	ErrBCastSwitch   = errors.New("clients: can't switch BCAST mode while tracking")
+	ErrNoRedirect    = errors.New("clients: no client to redirect to")
+	ErrPrefixOverlap = errors.New("clients: tracking prefixes overlap")
+)
+
+// TrackingOptions configure the client-side caching
+// support (CLIENT TRACKING).
+type TrackingOptions struct {
+	// BCast enables the broadcasting mode: the client
+	// is notified about all modified keys matching
+	// the prefixes, instead of the keys it has read.
+	BCast bool
+	// Prefixes limit the broadcasting mode to the keys
+	// starting with one of the prefixes (all keys if empty).
+	Prefixes []string
+	// OptIn tracks the keys only for the commands
+	// that follow CLIENT CACHING YES.
+	OptIn bool
+	// OptOut tracks the keys except for the commands
+	// that follow CLIENT CACHING NO.
+	OptOut bool
+	// NoLoop skips the keys modified by the client itself.
+	NoLoop bool
+	// Redirect is the id of the client to send the
+	// invalidation messages to (0 means the client itself).
+	Redirect int64
+}
+
+// hasPrefix reports whether the key matches
+// any of the broadcasting prefixes.
+func (o *TrackingOptions) hasPrefix(key string) bool {
+	if len(o.Prefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(o.Prefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// Invalidation is a message about modified keys
+// sent to a tracking client.
+type Invalidation struct {
+	// Keys are the modified keys.
+	// Nil means all keys (FLUSHDB, FLUSHALL or SWAPDB).
+	Keys []string
+	// Channel is true if the message should be sent to
+	// the InvalidateChannel (when redirecting)
+	// instead of as an invalidate push message.
+	Channel bool
+}
+
+// Tracking returns a copy of the tracking options,
+// or nil if the tracking is off.
+func (c *Client) Tracking() *TrackingOptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tracking == nil {
+		return nil
+	}
+	opts := *c.tracking
+	opts.Prefixes = slices.Clone(c.tracking.Prefixes)
+	return &opts
+}
+
+// Caching returns the caching mode for the next
+// command (CachingYes, CachingNo or empty if not set).
+func (c *Client) Caching() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caching
+}
+
+// SetCaching sets the caching mode for
+// the next command (CLIENT CACHING).
+func (c *Client) SetCaching(mode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caching = mode
+}
+
+// Subscribed reports whether the client is subscribed
+// to the __redis__:invalidate channel.
+func (c *Client) Subscribed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscribed
+}
+
+// SetSubscribed marks the client as subscribed
+// (or not) to the __redis__:invalidate channel.
+func (c *Client) SetSubscribed(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribed = on
+}
+
+// ReceivesPushes reports whether the client may receive
+// invalidation messages (it's tracking or subscribed).
+func (c *Client) ReceivesPushes() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tracking != nil || c.subscribed
+}
+
+// SetNotify sets the function that delivers the invalidation
+// messages to the client. The function must not block.
+func (c *Client) SetNotify(notify func(msg Invalidation)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notify = notify
+}
+
+// tracksReads reports whether the keys read
+// by the next command should be tracked.
+func (c *Client) tracksReads() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.tracking == nil || c.tracking.BCast:
+		return false
+	case c.tracking.OptIn:
+		return c.caching == CachingYes
+	case c.tracking.OptOut:
+		return c.caching != CachingNo
+	default:
+		return true
+	}
+}
+
+// send delivers the invalidation message to the client.
+// RESP2 clients can only receive messages on the
+// __redis__:invalidate channel they have subscribed to.
+func (c *Client) send(msg Invalidation) {
+	c.mu.Lock()
+	notify := c.notify
+	ok := c.subscribed || (!msg.Channel && c.proto >= 3)
+	c.mu.Unlock()
+	if notify != nil && ok {
+		notify(msg)
+	}
+}
+
+// SetTracking turns the tracking on (with the given options)
+// or off (if opts is nil). If the tracking is already on,
+// adds the new prefixes to the existing ones.
+// Returns ErrNoRedirect if there is no client to redirect
+// the messages to, ErrBCastSwitch when trying to switch
+// the broadcasting mode without turning the tracking off,
+// and ErrPrefixOverlap if the prefixes overlap.
+func (r *Registry) SetTracking(c *Client, opts *TrackingOptions) error {
+	if opts != nil && opts.Redirect != 0 {
+		r.mu.Lock()
+		_, ok := r.clients[opts.Redirect]
+		r.mu.Unlock()
+		if !ok {
+			return ErrNoRedirect
+		}
+	}
+
+	r.trackMu.Lock()
+	defer r.trackMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.tracking
+	if opts == nil {
+		if old != nil {
+			c.tracking = nil
+			c.caching = ""
+			delete(r.bcast, c.ID)
+			r.ntrack.Add(-1)
+		}
+		return nil
+	}
+
+	if old != nil && old.BCast != opts.BCast {
+		return ErrBCastSwitch
+	}
+	var prefixes []string
+	if old != nil {
+		prefixes = slices.Clone(old.Prefixes)
+	}
+	for _, prefix := range opts.Prefixes {
+		if slices.Contains(prefixes, prefix) {
+			continue
+		}
+		for _, other := range prefixes {
+			if strings.HasPrefix(prefix, other) || strings.HasPrefix(other, prefix) {
+				return ErrPrefixOverlap
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	tracking := *opts
+	tracking.Prefixes = prefixes
+	c.tracking = &tracking
+	if old == nil {
+		r.ntrack.Add(1)
+	}
+	if tracking.BCast {
+		r.bcast[c.ID] = c
+	}
+	return nil
+}
+
+// Track records the keys read by the client, so that the client
+// is notified when they are modified. Does nothing if the client
+// is not tracking, is in the broadcasting mode, or has opted out
+// of tracking the current command.
+func (r *Registry) Track(c *Client, keys []string) {
+	if r.ntrack.Load() == 0 || len(keys) == 0 || !c.tracksReads() {
+		return
+	}
+	r.trackMu.Lock()
+	defer r.trackMu.Unlock()
+	for _, key := range keys {
+		ids, ok := r.tracked[key]
+		if !ok {
+			ids = map[int64]struct{}{}
+			r.tracked[key] = ids
+		}
+		ids[c.ID] = struct{}{}
+	}
+}
+
+// Invalidate notifies the tracking clients that the keys
+// have been modified by the given client. The keys are
+// no longer tracked until the clients read them again.
+func (r *Registry) Invalidate(keys []string, by *Client) {
+	if r.ntrack.Load() == 0 || len(keys) == 0 {
+		return
+	}
+	modified := map[int64][]string{}
+	add := func(id int64, key string) {
+		if !slices.Contains(modified[id], key) {
+			modified[id] = append(modified[id], key)
+		}
+	}
+
+	r.trackMu.Lock()
+	for _, key := range keys {
+		for id := range r.tracked[key] {
+			add(id, key)
+		}
+		delete(r.tracked, key)
+		for id, c := range r.bcast {
+			c.mu.Lock()
+			match := c.tracking != nil && c.tracking.hasPrefix(key)
+			c.mu.Unlock()
+			if match {
+				add(id, key)
+			}
+		}
+	}
+	r.trackMu.Unlock()
+
+	for id, keys := range modified {
+		r.notify(id, keys, by)
+	}
+}
+
+// InvalidateAll notifies all tracking clients that
+// the database has been flushed by the given client.
+func (r *Registry) InvalidateAll(by *Client) {
+	if r.ntrack.Load() == 0 {
+		return
+	}
+	r.trackMu.Lock()
+	clear(r.tracked)
+	r.trackMu.Unlock()
+
+	for _, c := range r.List() {
+		r.notify(c.ID, nil, by)
+	}
+}
+
+// notify sends the invalidation message about the keys
+// to the tracking client or to its redirect target.
+func (r *Registry) notify(id int64, keys []string, by *Client) {
+	r.mu.Lock()
+	c := r.clients[id]
+	r.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	tracking := c.tracking
+	c.mu.Unlock()
+	if tracking == nil || (tracking.NoLoop && c == by) {
+		return
+	}
+	if tracking.Redirect == 0 {
+		c.send(Invalidation{Keys: keys})
+		return
+	}
+
+	r.mu.Lock()
+	target := r.clients[tracking.Redirect]
+	r.mu.Unlock()
+	if target != nil {
+		target.send(Invalidation{Keys: keys, Channel: true})
+	}
+}
@@ -0,0 +1,153 @@
+package clients_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestTrackingDefault(t *testing.T) {
+	t.Run("read then modify", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{})
+		writer := reg.Add("127.0.0.1:5002", "127.0.0.1:6379", noClose)
+
+		reg.Track(c, []string{"name", "age"})
+		reg.Invalidate([]string{"name", "city"}, writer)
+		testx.AssertEqual(t, *msgs, []clients.Invalidation{{Keys: []string{"name"}}})
+
+		// The key is no longer tracked until read again.
+		reg.Invalidate([]string{"name"}, writer)
+		testx.AssertEqual(t, len(*msgs), 1)
+	})
+	t.Run("noloop", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{NoLoop: true})
+		reg.Track(c, []string{"name"})
+		reg.Invalidate([]string{"name"}, c)
+		testx.AssertEqual(t, len(*msgs), 0)
+	})
+	t.Run("optin", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{OptIn: true})
+		reg.Track(c, []string{"name"})
+		c.SetCaching(clients.CachingYes)
+		reg.Track(c, []string{"age"})
+		reg.Invalidate([]string{"name", "age"}, nil)
+		testx.AssertEqual(t, *msgs, []clients.Invalidation{{Keys: []string{"age"}}})
+	})
+	t.Run("optout", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{OptOut: true})
+		reg.Track(c, []string{"name"})
+		c.SetCaching(clients.CachingNo)
+		reg.Track(c, []string{"age"})
+		reg.Invalidate([]string{"name", "age"}, nil)
+		testx.AssertEqual(t, *msgs, []clients.Invalidation{{Keys: []string{"name"}}})
+	})
+	t.Run("off", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{})
+		reg.Track(c, []string{"name"})
+		err := reg.SetTracking(c, nil)
+		testx.AssertNoErr(t, err)
+		reg.Invalidate([]string{"name"}, nil)
+		testx.AssertEqual(t, len(*msgs), 0)
+		testx.AssertEqual(t, c.Tracking() == nil, true)
+	})
+	t.Run("resp2", func(t *testing.T) {
+		reg := clients.NewRegistry()
+		c, msgs := addTracking(t, reg, &clients.TrackingOptions{})
+		c.SetProto(2)
+		reg.Track(c, []string{"name"})
+		reg.Invalidate([]string{"name"}, nil)
+		testx.AssertEqual(t, len(*msgs), 0)
+	})
+}
+
+func TestTrackingBCast(t *testing.T) {
+	reg := clients.NewRegistry()
+	c, msgs := addTracking(t, reg, &clients.TrackingOptions{
+		BCast: true, Prefixes: []string{"user:", "post:"},
+	})
+	// Keys don't need to be read in the broadcasting mode.
+	reg.Invalidate([]string{"user:1", "city", "post:2"}, nil)
+	testx.AssertEqual(t, *msgs, []clients.Invalidation{{Keys: []string{"user:1", "post:2"}}})
+
+	t.Run("add prefix", func(t *testing.T) {
+		err := reg.SetTracking(c, &clients.TrackingOptions{BCast: true, Prefixes: []string{"city"}})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, c.Tracking().Prefixes, []string{"user:", "post:", "city"})
+	})
+	t.Run("overlap", func(t *testing.T) {
+		err := reg.SetTracking(c, &clients.TrackingOptions{BCast: true, Prefixes: []string{"user:admin:"}})
+		testx.AssertEqual(t, err, clients.ErrPrefixOverlap)
+	})
+	t.Run("switch mode", func(t *testing.T) {
+		err := reg.SetTracking(c, &clients.TrackingOptions{})
+		testx.AssertEqual(t, err, clients.ErrBCastSwitch)
+	})
+}
+
+func TestTrackingRedirect(t *testing.T) {
+	reg := clients.NewRegistry()
+	target := reg.Add("127.0.0.1:5001", "127.0.0.1:6379", noClose)
+	var msgs []clients.Invalidation
+	target.SetNotify(func(msg clients.Invalidation) {
+		msgs = append(msgs, msg)
+	})
+
+	c := reg.Add("127.0.0.1:5002", "127.0.0.1:6379", noClose)
+	err := reg.SetTracking(c, &clients.TrackingOptions{Redirect: 42})
+	testx.AssertEqual(t, err, clients.ErrNoRedirect)
+	err = reg.SetTracking(c, &clients.TrackingOptions{Redirect: target.ID})
+	testx.AssertNoErr(t, err)
+
+	// The target is not subscribed yet.
+	reg.Track(c, []string{"name"})
+	reg.Invalidate([]string{"name"}, nil)
+	testx.AssertEqual(t, len(msgs), 0)
+
+	target.SetSubscribed(true)
+	reg.Track(c, []string{"name"})
+	reg.Invalidate([]string{"name"}, nil)
+	testx.AssertEqual(t, msgs, []clients.Invalidation{{Keys: []string{"name"}, Channel: true}})
+
+	re := regexp.MustCompile(` flags=t .* redir=1 `)
+	testx.AssertEqual(t, re.MatchString(c.String()), true)
+	re = regexp.MustCompile(` flags=P .* sub=1 `)
+	testx.AssertEqual(t, re.MatchString(target.String()), true)
+}
+
+func TestTrackingInvalidateAll(t *testing.T) {
+	reg := clients.NewRegistry()
+	c1, msgs1 := addTracking(t, reg, &clients.TrackingOptions{})
+	_, msgs2 := addTracking(t, reg, &clients.TrackingOptions{BCast: true})
+	reg.Track(c1, []string{"name"})
+
+	reg.InvalidateAll(nil)
+	testx.AssertEqual(t, *msgs1, []clients.Invalidation{{}})
+	testx.AssertEqual(t, *msgs2, []clients.Invalidation{{}})
+
+	// The tracked keys are cleared.
+	reg.Invalidate([]string{"name"}, nil)
+	testx.AssertEqual(t, len(*msgs1), 1)
+}
+
+// addTracking adds a RESP3 client with the tracking turned on,
+// and returns the client and the messages it receives.
+func addTracking(t *testing.T, reg *clients.Registry, opts *clients.TrackingOptions) (*clients.Client, *[]clients.Invalidation) {
+	t.Helper()
+	c := reg.Add("127.0.0.1:5000", "127.0.0.1:6379", noClose)
+	c.SetProto(3)
+	var msgs []clients.Invalidation
+	c.SetNotify(func(msg clients.Invalidation) {
+		msgs = append(msgs, msg)
+	})
+	if err := reg.SetTracking(c, opts); err != nil {
+		t.Fatal(err)
+	}
+	return c, &msgs
+}
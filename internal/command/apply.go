@@ -0,0 +1,38 @@
+package command
+
+import (
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Apply parses and runs a command within the transaction,
+// discarding the command output. Used to apply the commands
+// received from a Redis-protocol primary (see the repl package).
+func Apply(tx *redka.Tx, args [][]byte) error {
+	cmd, err := Parse(args)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.Run(discard{}, redis.RedkaTx(tx))
+	return err
+}
+
+// discard is a writer that ignores the command output.
+type discard struct{}
+
+func (discard) WriteAny(v any)              {}
+func (discard) WriteArray(count int)        {}
+func (discard) WriteBool(b bool)            {}
+func (discard) WriteBulk(bulk []byte)       {}
+func (discard) WriteBulkString(bulk string) {}
+func (discard) WriteDouble(num float64)     {}
+func (discard) WriteError(msg string)       {}
+func (discard) WriteInt(num int)            {}
+func (discard) WriteInt64(num int64)        {}
+func (discard) WriteMap(count int)          {}
+func (discard) WriteNull()                  {}
+func (discard) WritePush(count int)         {}
+func (discard) WriteRaw(data []byte)        {}
+func (discard) WriteSet(count int)          {}
+func (discard) WriteString(str string)      {}
+func (discard) WriteUint64(num uint64)      {}
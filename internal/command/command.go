@@ -9,6 +9,7 @@ import (
 	"github.com/flarco/redka/internal/command/hash"
 	"github.com/flarco/redka/internal/command/key"
 	"github.com/flarco/redka/internal/command/list"
+	"github.com/flarco/redka/internal/command/pubsub"
 	"github.com/flarco/redka/internal/command/server"
 	"github.com/flarco/redka/internal/command/set"
 	str "github.com/flarco/redka/internal/command/string"
@@ -22,8 +23,12 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 	b := redis.NewBaseCmd(args)
 	switch name {
 	// server
+	case "acl":
+		return server.ParseACL(b)
+	case "bgsave":
+		return server.ParseBgSave(b)
 	case "command":
-		return server.ParseOK(b)
+		return server.ParseCommand(b)
 	case "config":
 		return server.ParseConfig(b)
 	case "dbsize":
@@ -31,49 +36,101 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 	case "flushdb":
 		return key.ParseFlushDB(b)
 	case "flushall":
-		return key.ParseFlushDB(b)
+		return key.ParseFlushAll(b)
 	case "info":
-		return server.ParseOK(b)
+		return server.ParseInfo(b)
+	case "lastsave":
+		return server.ParseLastSave(b)
+	case "latency":
+		return server.ParseLatency(b)
 	case "lolwut":
 		return server.ParseLolwut(b)
+	case "replicaof":
+		return server.ParseReplicaOf(b)
+	case "repllog":
+		return server.ParseReplLog(b)
+	case "role":
+		return server.ParseRole(b)
+	case "save":
+		return server.ParseSave(b)
+	case "slaveof":
+		return server.ParseReplicaOf(b)
+	case "slowlog":
+		return server.ParseSlowLog(b)
+	case "swapdb":
+		return server.ParseSwapDB(b)
 
 	// connection
+	case "auth":
+		return conn.ParseAuth(b)
+	case "client":
+		return conn.ParseClient(b)
 	case "echo":
 		return conn.ParseEcho(b)
+	case "hello":
+		return conn.ParseHello(b)
 	case "ping":
 		return conn.ParsePing(b)
 	case "select":
 		return conn.ParseSelect(b)
 
+	// pubsub
+	case "subscribe":
+		return pubsub.ParseSubscribe(b)
+	case "unsubscribe":
+		return pubsub.ParseUnsubscribe(b)
+
 	// key
+	case "copy":
+		return key.ParseCopy(b)
 	case "del":
 		return key.ParseDel(b)
+	case "dump":
+		return key.ParseDump(b)
 	case "exists":
 		return key.ParseExists(b)
 	case "expire":
 		return key.ParseExpire(b, 1000)
 	case "expireat":
 		return key.ParseExpireAt(b, 1000)
+	case "expiretime":
+		return key.ParseExpireTime(b, 1000)
 	case "keys":
 		return key.ParseKeys(b)
+	case "move":
+		return key.ParseMove(b)
 	case "persist":
 		return key.ParsePersist(b)
 	case "pexpire":
 		return key.ParseExpire(b, 1)
 	case "pexpireat":
 		return key.ParseExpireAt(b, 1)
+	case "pexpiretime":
+		return key.ParseExpireTime(b, 1)
+	case "pttl":
+		return key.ParseTTL(b, 1)
 	case "randomkey":
 		return key.ParseRandomKey(b)
 	case "rename":
 		return key.ParseRename(b)
 	case "renamenx":
 		return key.ParseRenameNX(b)
+	case "restore":
+		return key.ParseRestore(b)
 	case "scan":
 		return key.ParseScan(b)
+	case "sort":
+		return key.ParseSort(b, false)
+	case "sort_ro":
+		return key.ParseSort(b, true)
+	case "touch":
+		return key.ParseTouch(b)
 	case "ttl":
-		return key.ParseTTL(b)
+		return key.ParseTTL(b, 1000)
 	case "type":
 		return key.ParseType(b)
+	case "unlink":
+		return key.ParseUnlink(b)
 
 	// list
 	case "lindex":
@@ -136,10 +193,20 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return hash.ParseHDel(b)
 	case "hexists":
 		return hash.ParseHExists(b)
+	case "hexpire":
+		return hash.ParseHExpire(b, 1000)
+	case "hexpireat":
+		return hash.ParseHExpireAt(b, 1000)
+	case "hexpiretime":
+		return hash.ParseHExpireTime(b, 1000)
 	case "hget":
 		return hash.ParseHGet(b)
 	case "hgetall":
 		return hash.ParseHGetAll(b)
+	case "hgetdel":
+		return hash.ParseHGetDel(b)
+	case "hgetex":
+		return hash.ParseHGetEx(b)
 	case "hincrby":
 		return hash.ParseHIncrBy(b)
 	case "hincrbyfloat":
@@ -152,12 +219,30 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return hash.ParseHMGet(b)
 	case "hmset":
 		return hash.ParseHMSet(b)
+	case "hpersist":
+		return hash.ParseHPersist(b)
+	case "hpexpire":
+		return hash.ParseHExpire(b, 1)
+	case "hpexpireat":
+		return hash.ParseHExpireAt(b, 1)
+	case "hpexpiretime":
+		return hash.ParseHExpireTime(b, 1)
+	case "hpttl":
+		return hash.ParseHTTL(b, 1)
+	case "hrandfield":
+		return hash.ParseHRandField(b)
 	case "hscan":
 		return hash.ParseHScan(b)
 	case "hset":
 		return hash.ParseHSet(b)
+	case "hsetex":
+		return hash.ParseHSetEx(b)
 	case "hsetnx":
 		return hash.ParseHSetNX(b)
+	case "hstrlen":
+		return hash.ParseHStrLen(b)
+	case "httl":
+		return hash.ParseHTTL(b, 1000)
 	case "hvals":
 		return hash.ParseHVals(b)
 
@@ -172,12 +257,16 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return set.ParseSDiffStore(b)
 	case "sinter":
 		return set.ParseSInter(b)
+	case "sintercard":
+		return set.ParseSInterCard(b)
 	case "sinterstore":
 		return set.ParseSInterStore(b)
 	case "sismember":
 		return set.ParseSIsMember(b)
 	case "smembers":
 		return set.ParseSMembers(b)
+	case "smismember":
+		return set.ParseSMIsMember(b)
 	case "smove":
 		return set.ParseSMove(b)
 	case "spop":
@@ -0,0 +1,53 @@
+package conn
+
+import (
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Authenticates the connection.
+// AUTH [username] password
+// https://redis.io/commands/auth
+type Auth struct {
+	redis.BaseCmd
+	user string
+	pass string
+}
+
+func ParseAuth(b redis.BaseCmd) (Auth, error) {
+	cmd := Auth{BaseCmd: b}
+	switch len(cmd.Args()) {
+	case 1:
+		cmd.pass = string(cmd.Args()[0])
+	case 2:
+		cmd.user = string(cmd.Args()[0])
+		cmd.pass = string(cmd.Args()[1])
+	default:
+		return Auth{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (c Auth) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.ACL() == nil || red.Client() == nil {
+		w.WriteError(c.Error(redis.ErrNoACL))
+		return nil, redis.ErrNoACL
+	}
+	user := c.user
+	if user == "" {
+		// AUTH password authenticates the default user,
+		// so it's an error if the user has no password.
+		if !red.ACL().HasPass() {
+			w.WriteError(redis.ErrNoPass.Error())
+			return nil, redis.ErrNoPass
+		}
+		user = acl.DefaultUser
+	}
+	if err := red.ACL().Auth(user, c.pass); err != nil {
+		w.WriteError(redis.ErrWrongPass.Error())
+		return nil, redis.ErrWrongPass
+	}
+	red.Client().SetUser(user)
+	w.WriteString("OK")
+	return true, nil
+}
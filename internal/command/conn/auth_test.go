@@ -0,0 +1,101 @@
+package conn
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestAuthParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		user string
+		pass string
+		err  error
+	}{
+		{cmd: "auth", err: redis.ErrInvalidArgNum},
+		{cmd: "auth secret", pass: "secret"},
+		{cmd: "auth alice secret", user: "alice", pass: "secret"},
+		{cmd: "auth alice secret more", err: redis.ErrInvalidArgNum},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseAuth, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.user, test.user)
+			testx.AssertEqual(t, cmd.pass, test.pass)
+		})
+	}
+}
+
+func TestAuthExec(t *testing.T) {
+	t.Run("requirepass", func(t *testing.T) {
+		red, client := getAuth(t, "secret")
+
+		cmd := redis.MustParse(ParseAuth, "auth wrong")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrWrongPass)
+		testx.AssertEqual(t, conn.Out(), redis.ErrWrongPass.Error())
+		testx.AssertEqual(t, client.Authenticated(), false)
+
+		cmd = redis.MustParse(ParseAuth, "auth secret")
+		conn = redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, client.Authenticated(), true)
+		testx.AssertEqual(t, client.User(), acl.DefaultUser)
+	})
+	t.Run("user", func(t *testing.T) {
+		red, client := getAuth(t, "")
+		err := red.ACL().SetUser("alice", "on", ">alicepass", "+@all")
+		testx.AssertNoErr(t, err)
+
+		cmd := redis.MustParse(ParseAuth, "auth alice secret")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrWrongPass)
+
+		cmd = redis.MustParse(ParseAuth, "auth bob alicepass")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrWrongPass)
+
+		cmd = redis.MustParse(ParseAuth, "auth alice alicepass")
+		conn := redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, client.User(), "alice")
+	})
+	t.Run("no password", func(t *testing.T) {
+		red, _ := getAuth(t, "")
+		cmd := redis.MustParse(ParseAuth, "auth secret")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoPass)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoPass.Error())
+	})
+	t.Run("no acl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		cmd := redis.MustParse(ParseAuth, "auth secret")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoACL)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoACL.Error()+" (auth)")
+	})
+}
+
+func getAuth(t *testing.T, pass string) (redis.Redka, *clients.Client) {
+	db, red := getDB(t)
+	t.Cleanup(func() { db.Close() })
+	acls, err := acl.NewManager(&acl.Options{RequirePass: pass})
+	testx.AssertNoErr(t, err)
+	client := clients.NewRegistry().Add("127.0.0.1:5001", "127.0.0.1:6379", nil)
+	return red.WithACL(acls).WithClient(client), client
+}
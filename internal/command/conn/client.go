@@ -0,0 +1,131 @@
+package conn
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Container command for client connection commands.
+// CLIENT subcommand [argument ...]
+// https://redis.io/commands/client
+type Client struct {
+	redis.BaseCmd
+	subcmd   string
+	caching  ClientCaching
+	kill     ClientKill
+	list     ClientList
+	noEvict  ClientNoEvict
+	pause    ClientPause
+	setInfo  ClientSetInfo
+	setName  ClientSetName
+	tracking ClientTracking
+}
+
+func ParseClient(b redis.BaseCmd) (Client, error) {
+	// Extract the subcommand.
+	cmd := Client{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return Client{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "caching":
+		cmd.caching, err = ParseClientCaching(args)
+	case "getname", "getredir", "id", "info", "trackinginfo", "unpause":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	case "kill":
+		cmd.kill, err = ParseClientKill(args)
+	case "list":
+		cmd.list, err = ParseClientList(args)
+	case "no-evict":
+		cmd.noEvict, err = ParseClientNoEvict(args)
+	case "pause":
+		cmd.pause, err = ParseClientPause(args)
+	case "setinfo":
+		cmd.setInfo, err = ParseClientSetInfo(args)
+	case "setname":
+		cmd.setName, err = ParseClientSetName(args)
+	case "tracking":
+		cmd.tracking, err = ParseClientTracking(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Client{}, err
+	}
+	return cmd, nil
+}
+
+func (c Client) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.Clients() == nil || red.Client() == nil {
+		w.WriteError(c.Error(redis.ErrNoClients))
+		return nil, redis.ErrNoClients
+	}
+	client := red.Client()
+	switch c.subcmd {
+	case "caching":
+		return c.caching.Run(w, red)
+	case "getname":
+		name := client.Name()
+		if name == "" {
+			w.WriteNull()
+			return name, nil
+		}
+		w.WriteBulkString(name)
+		return name, nil
+	case "getredir":
+		redir := int64(-1)
+		if opts := client.Tracking(); opts != nil {
+			redir = opts.Redirect
+		}
+		w.WriteInt64(redir)
+		return redir, nil
+	case "id":
+		w.WriteInt64(client.ID)
+		return client.ID, nil
+	case "info":
+		info := client.String() + "\n"
+		w.WriteBulkString(info)
+		return info, nil
+	case "kill":
+		return c.kill.Run(w, red)
+	case "list":
+		return c.list.Run(w, red)
+	case "no-evict":
+		return c.noEvict.Run(w, red)
+	case "pause":
+		return c.pause.Run(w, red)
+	case "setinfo":
+		return c.setInfo.Run(w, red)
+	case "setname":
+		return c.setName.Run(w, red)
+	case "tracking":
+		return c.tracking.Run(w, red)
+	case "trackinginfo":
+		return writeTrackingInfo(w, red), nil
+	default:
+		red.Clients().Unpause()
+		w.WriteString("OK")
+		return true, nil
+	}
+}
+
+// validName reports whether the client name (or library name)
+// has no spaces, newlines or other special characters.
+func validName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] <= ' ' || name[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,398 @@
+package conn
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestClientParse(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		subcmd string
+		err    error
+	}{
+		{cmd: "client", err: redis.ErrInvalidArgNum},
+		{cmd: "client id", subcmd: "id"},
+		{cmd: "client ID 1", err: redis.ErrInvalidArgNum},
+		{cmd: "client info", subcmd: "info"},
+		{cmd: "client getname", subcmd: "getname"},
+		{cmd: "client setname alice", subcmd: "setname"},
+		{cmd: "client setname", err: redis.ErrInvalidArgNum},
+		{cmd: "client setname alice bob", err: redis.ErrInvalidArgNum},
+		{cmd: "client setinfo lib-name go-redis", subcmd: "setinfo"},
+		{cmd: "client setinfo LIB-VER 9.0.0", subcmd: "setinfo"},
+		{cmd: "client setinfo lib-name", err: redis.ErrInvalidArgNum},
+		{cmd: "client setinfo lib-foo bar", err: redis.ErrSyntaxError},
+		{cmd: "client list", subcmd: "list"},
+		{cmd: "client list type normal id 1 2", subcmd: "list"},
+		{cmd: "client list type foo", err: redis.ErrSyntaxError},
+		{cmd: "client list id one", err: redis.ErrInvalidInt},
+		{cmd: "client list id", err: redis.ErrSyntaxError},
+		{cmd: "client kill 127.0.0.1:5000", subcmd: "kill"},
+		{cmd: "client kill id 1 skipme no", subcmd: "kill"},
+		{cmd: "client kill", err: redis.ErrInvalidArgNum},
+		{cmd: "client kill id 1 skipme", err: redis.ErrSyntaxError},
+		{cmd: "client kill id one", err: redis.ErrInvalidInt},
+		{cmd: "client kill skipme maybe", err: redis.ErrSyntaxError},
+		{cmd: "client kill foo bar", err: redis.ErrSyntaxError},
+		{cmd: "client pause 100", subcmd: "pause"},
+		{cmd: "client pause 100 write", subcmd: "pause"},
+		{cmd: "client pause", err: redis.ErrInvalidArgNum},
+		{cmd: "client pause -1", err: redis.ErrNegativeTimeout},
+		{cmd: "client pause one", err: redis.ErrInvalidInt},
+		{cmd: "client pause 100 some", err: redis.ErrSyntaxError},
+		{cmd: "client unpause", subcmd: "unpause"},
+		{cmd: "client no-evict on", subcmd: "no-evict"},
+		{cmd: "client no-evict maybe", err: redis.ErrSyntaxError},
+		{cmd: "client tracking on", subcmd: "tracking"},
+		{cmd: "client tracking off", subcmd: "tracking"},
+		{cmd: "client tracking on redirect 2 noloop", subcmd: "tracking"},
+		{cmd: "client tracking on bcast prefix user: prefix post:", subcmd: "tracking"},
+		{cmd: "client tracking", err: redis.ErrInvalidArgNum},
+		{cmd: "client tracking maybe", err: redis.ErrSyntaxError},
+		{cmd: "client tracking on redirect", err: redis.ErrSyntaxError},
+		{cmd: "client tracking on redirect two", err: redis.ErrInvalidInt},
+		{cmd: "client tracking on prefix user:", err: redis.ErrPrefixNoBCast},
+		{cmd: "client tracking on optin optout", err: redis.ErrOptInOptOut},
+		{cmd: "client tracking on bcast optin", err: redis.ErrBCastOptIn},
+		{cmd: "client tracking on foo", err: redis.ErrSyntaxError},
+		{cmd: "client caching yes", subcmd: "caching"},
+		{cmd: "client caching maybe", err: redis.ErrSyntaxError},
+		{cmd: "client getredir", subcmd: "getredir"},
+		{cmd: "client trackinginfo", subcmd: "trackinginfo"},
+		{cmd: "client foo", err: redis.ErrUnknownSubcmd},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseClient, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.subcmd, test.subcmd)
+		})
+	}
+}
+
+func TestClientParseArgs(t *testing.T) {
+	t.Run("setname", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient, "client setname alice")
+		testx.AssertEqual(t, cmd.setName.name, "alice")
+
+		_, err := redis.Parse(ParseClient, "client setname alice\nbob")
+		testx.AssertEqual(t, err, redis.ErrInvalidClientName)
+	})
+	t.Run("list", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient, "client list type slave id 1 2")
+		testx.AssertEqual(t, cmd.list, ClientList{typ: "replica", ids: []int64{1, 2}})
+	})
+	t.Run("kill legacy", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient, "client kill 127.0.0.1:5000")
+		testx.AssertEqual(t, cmd.kill, ClientKill{
+			legacy: true,
+			filter: clients.Filter{Addr: "127.0.0.1:5000"},
+		})
+	})
+	t.Run("kill filters", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient,
+			"client kill id 1 type normal user alice addr 127.0.0.1:5000 "+
+				"laddr 127.0.0.1:6379 skipme no maxage 60")
+		testx.AssertEqual(t, cmd.kill, ClientKill{
+			typ: "normal",
+			filter: clients.Filter{
+				ID:     1,
+				Addr:   "127.0.0.1:5000",
+				LAddr:  "127.0.0.1:6379",
+				User:   "alice",
+				MaxAge: time.Minute,
+			},
+		})
+	})
+	t.Run("pause", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient, "client pause 100")
+		testx.AssertEqual(t, cmd.pause, ClientPause{
+			timeout: 100 * time.Millisecond, mode: clients.PauseAll,
+		})
+		cmd = redis.MustParse(ParseClient, "client pause 100 WRITE")
+		testx.AssertEqual(t, cmd.pause.mode, clients.PauseWrite)
+	})
+	t.Run("tracking", func(t *testing.T) {
+		cmd := redis.MustParse(ParseClient, "client tracking on redirect 2 bcast prefix a prefix b noloop")
+		testx.AssertEqual(t, cmd.tracking, ClientTracking{
+			on: true,
+			opts: clients.TrackingOptions{
+				BCast: true, Prefixes: []string{"a", "b"}, NoLoop: true, Redirect: 2,
+			},
+		})
+		cmd = redis.MustParse(ParseClient, "client tracking OFF")
+		testx.AssertEqual(t, cmd.tracking, ClientTracking{on: false})
+	})
+}
+
+func TestClientExec(t *testing.T) {
+	t.Run("id", func(t *testing.T) {
+		red, _, _ := getClients(t)
+		cmd := redis.MustParse(ParseClient, "client id")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, int64(1))
+		testx.AssertEqual(t, conn.Out(), "1")
+	})
+	t.Run("setname and getname", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client getname")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "")
+		testx.AssertEqual(t, conn.Out(), "(nil)")
+
+		cmd = redis.MustParse(ParseClient, "client setname alice")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		cmd = redis.MustParse(ParseClient, "client getname")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "alice")
+		testx.AssertEqual(t, conn.Out(), "alice")
+	})
+	t.Run("setinfo", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client setinfo lib-name go-redis")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		cmd = redis.MustParse(ParseClient, "client setinfo lib-ver 9.0.0")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		info := red.Client().String()
+		testx.AssertEqual(t, strings.HasSuffix(info, " lib-name=go-redis lib-ver=9.0.0"), true)
+	})
+	t.Run("info", func(t *testing.T) {
+		red, _, _ := getClients(t)
+		cmd := redis.MustParse(ParseClient, "client info")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, red.Client().String()+"\n")
+		testx.AssertEqual(t, strings.HasPrefix(conn.Out(), "id=1 addr=127.0.0.1:5001 "), true)
+	})
+	t.Run("list", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client list")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		lines := strings.Split(res.(string), "\n")
+		testx.AssertEqual(t, len(lines), 3)
+		testx.AssertEqual(t, strings.HasPrefix(lines[0], "id=1 addr=127.0.0.1:5001 "), true)
+		testx.AssertEqual(t, strings.HasPrefix(lines[1], "id=2 addr=127.0.0.1:5002 "), true)
+		testx.AssertEqual(t, lines[2], "")
+
+		cmd = redis.MustParse(ParseClient, "client list id 2")
+		res, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, strings.HasPrefix(res.(string), "id=2 "), true)
+
+		cmd = redis.MustParse(ParseClient, "client list type pubsub")
+		res, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "")
+	})
+	t.Run("kill legacy", func(t *testing.T) {
+		red, reg, closed := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client kill 127.0.0.1:5002")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, *closed, []int64{2})
+		testx.AssertEqual(t, reg.List()[1].Killed(), true)
+
+		cmd = redis.MustParse(ParseClient, "client kill 127.0.0.1:5003")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoSuchClient)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoSuchClient.Error())
+	})
+	t.Run("kill filters", func(t *testing.T) {
+		red, _, closed := getClients(t)
+
+		// Skips the current client by default.
+		cmd := redis.MustParse(ParseClient, "client kill user default")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "1")
+		testx.AssertEqual(t, *closed, []int64{2})
+
+		// Only normal clients exist.
+		cmd = redis.MustParse(ParseClient, "client kill type master")
+		res, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+
+		// The current client is disconnected
+		// after the reply is sent.
+		cmd = redis.MustParse(ParseClient, "client kill id 1 skipme no")
+		res, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, *closed, []int64{2})
+		testx.AssertEqual(t, red.Client().Killed(), true)
+	})
+	t.Run("pause and unpause", func(t *testing.T) {
+		red, reg, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client pause 60000 write")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		cmd = redis.MustParse(ParseClient, "client unpause")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		start := time.Now()
+		reg.Wait(true)
+		testx.AssertEqual(t, time.Since(start) < time.Second, true)
+	})
+	t.Run("no-evict", func(t *testing.T) {
+		red, _, _ := getClients(t)
+		cmd := redis.MustParse(ParseClient, "client no-evict on")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, strings.Contains(red.Client().String(), " flags=e "), true)
+	})
+	t.Run("tracking", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client getredir")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, int64(-1))
+
+		cmd = redis.MustParse(ParseClient, "client tracking on redirect 2 noloop")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		cmd = redis.MustParse(ParseClient, "client getredir")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, int64(2))
+		testx.AssertEqual(t, conn.Out(), "2")
+
+		cmd = redis.MustParse(ParseClient, "client trackinginfo")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []string{"on", "noloop"})
+		testx.AssertEqual(t, conn.Out(), "6,flags,2,on,noloop,redirect,2,prefixes,0")
+
+		cmd = redis.MustParse(ParseClient, "client tracking off")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, red.Client().Tracking() == nil, true)
+	})
+	t.Run("tracking errors", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client tracking on redirect 42")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoRedirect)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoRedirect.Error())
+
+		cmd = redis.MustParse(ParseClient, "client tracking on bcast prefix user:")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+
+		cmd = redis.MustParse(ParseClient, "client tracking on bcast prefix user:admin:")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrPrefixOverlap)
+
+		cmd = redis.MustParse(ParseClient, "client tracking on")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrBCastSwitch)
+	})
+	t.Run("caching", func(t *testing.T) {
+		red, _, _ := getClients(t)
+
+		cmd := redis.MustParse(ParseClient, "client caching yes")
+		_, err := cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrCachingMode)
+
+		track := redis.MustParse(ParseClient, "client tracking on optin")
+		_, err = track.Run(redis.NewFakeConn(), red)
+		testx.AssertNoErr(t, err)
+
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, red.Client().Caching(), clients.CachingYes)
+
+		cmd = redis.MustParse(ParseClient, "client caching no")
+		_, err = cmd.Run(redis.NewFakeConn(), red)
+		testx.AssertErr(t, err, redis.ErrCachingNo)
+	})
+	t.Run("no clients", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		cmd := redis.MustParse(ParseClient, "client id")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoClients)
+	})
+}
+
+// getClients returns a Redka instance with a client registry
+// of two clients (the first one is the current client),
+// and the ids of the clients whose connections were closed.
+func getClients(t *testing.T) (redis.Redka, *clients.Registry, *[]int64) {
+	db, red := getDB(t)
+	t.Cleanup(func() { db.Close() })
+	reg := clients.NewRegistry()
+	var closed []int64
+	add := func(addr string) *clients.Client {
+		var c *clients.Client
+		c = reg.Add(addr, "127.0.0.1:6379", func() error {
+			closed = append(closed, c.ID)
+			return nil
+		})
+		return c
+	}
+	self := add("127.0.0.1:5001")
+	add("127.0.0.1:5002")
+	return red.WithClients(reg).WithClient(self), reg, &closed
+}
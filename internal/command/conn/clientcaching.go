@@ -0,0 +1,50 @@
+package conn
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Controls the tracking of the keys in the next command executed
+// by the connection (client-side caching in OPTIN or OPTOUT mode).
+// CLIENT CACHING <YES | NO>
+// https://redis.io/commands/client-caching
+type ClientCaching struct {
+	mode string
+}
+
+func ParseClientCaching(args [][]byte) (ClientCaching, error) {
+	if len(args) != 1 {
+		return ClientCaching{}, redis.ErrInvalidArgNum
+	}
+	switch strings.ToLower(string(args[0])) {
+	case "yes":
+		return ClientCaching{mode: clients.CachingYes}, nil
+	case "no":
+		return ClientCaching{mode: clients.CachingNo}, nil
+	default:
+		return ClientCaching{}, redis.ErrSyntaxError
+	}
+}
+
+func (c ClientCaching) Run(w redis.Writer, red redis.Redka) (any, error) {
+	opts := red.Client().Tracking()
+	var err error
+	switch {
+	case opts == nil || (!opts.OptIn && !opts.OptOut):
+		err = redis.ErrCachingMode
+	case c.mode == clients.CachingYes && !opts.OptIn:
+		err = redis.ErrCachingYes
+	case c.mode == clients.CachingNo && !opts.OptOut:
+		err = redis.ErrCachingNo
+	}
+	if err != nil {
+		w.WriteError(err.Error())
+		return nil, err
+	}
+	red.Client().SetCaching(c.mode)
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,100 @@
+package conn
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Terminates open connections.
+// CLIENT KILL <ip:port | <[ID client-id] | [TYPE <NORMAL | MASTER |
+// SLAVE | REPLICA | PUBSUB>] | [USER username] | [ADDR ip:port] |
+// [LADDR ip:port] | [SKIPME <YES | NO>] | [MAXAGE maxage]
+// [[ID client-id] | ...]>>
+// https://redis.io/commands/client-kill
+type ClientKill struct {
+	legacy bool // CLIENT KILL ip:port
+	typ    string
+	filter clients.Filter
+}
+
+func ParseClientKill(args [][]byte) (ClientKill, error) {
+	if len(args) == 0 {
+		return ClientKill{}, redis.ErrInvalidArgNum
+	}
+
+	// Old form: CLIENT KILL ip:port.
+	if len(args) == 1 {
+		cmd := ClientKill{legacy: true}
+		cmd.filter.Addr = string(args[0])
+		return cmd, nil
+	}
+
+	// New form: CLIENT KILL filter value [filter value ...].
+	if len(args)%2 != 0 {
+		return ClientKill{}, redis.ErrSyntaxError
+	}
+	cmd := ClientKill{filter: clients.Filter{SkipMe: true}}
+	for ; len(args) > 0; args = args[2:] {
+		val := string(args[1])
+		switch strings.ToLower(string(args[0])) {
+		case "id":
+			id, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || id <= 0 {
+				return ClientKill{}, redis.ErrInvalidInt
+			}
+			cmd.filter.ID = id
+		case "type":
+			typ, err := parseClientType(args[1])
+			if err != nil {
+				return ClientKill{}, err
+			}
+			cmd.typ = typ
+		case "user":
+			cmd.filter.User = val
+		case "addr":
+			cmd.filter.Addr = val
+		case "laddr":
+			cmd.filter.LAddr = val
+		case "skipme":
+			switch strings.ToLower(val) {
+			case "yes":
+				cmd.filter.SkipMe = true
+			case "no":
+				cmd.filter.SkipMe = false
+			default:
+				return ClientKill{}, redis.ErrSyntaxError
+			}
+		case "maxage":
+			sec, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || sec <= 0 {
+				return ClientKill{}, redis.ErrInvalidInt
+			}
+			cmd.filter.MaxAge = time.Duration(sec) * time.Second
+		default:
+			return ClientKill{}, redis.ErrSyntaxError
+		}
+	}
+	return cmd, nil
+}
+
+func (c ClientKill) Run(w redis.Writer, red redis.Redka) (any, error) {
+	// All Redka clients are normal clients.
+	n := 0
+	if c.typ == "" || c.typ == "normal" {
+		n = red.Clients().Kill(c.filter, red.Client())
+	}
+	if !c.legacy {
+		w.WriteInt(n)
+		return n, nil
+	}
+	if n == 0 {
+		w.WriteError(redis.ErrNoSuchClient.Error())
+		return nil, redis.ErrNoSuchClient
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package conn
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Lists open connections.
+// CLIENT LIST [TYPE <NORMAL | MASTER | REPLICA | PUBSUB>]
+// [ID client-id [client-id ...]]
+// https://redis.io/commands/client-list
+type ClientList struct {
+	typ string
+	ids []int64
+}
+
+func ParseClientList(args [][]byte) (ClientList, error) {
+	var cmd ClientList
+	for len(args) > 0 {
+		switch strings.ToLower(string(args[0])) {
+		case "type":
+			if len(args) < 2 {
+				return ClientList{}, redis.ErrSyntaxError
+			}
+			typ, err := parseClientType(args[1])
+			if err != nil {
+				return ClientList{}, err
+			}
+			cmd.typ = typ
+			args = args[2:]
+		case "id":
+			if len(args) < 2 {
+				return ClientList{}, redis.ErrSyntaxError
+			}
+			for _, arg := range args[1:] {
+				id, err := strconv.ParseInt(string(arg), 10, 64)
+				if err != nil || id <= 0 {
+					return ClientList{}, redis.ErrInvalidInt
+				}
+				cmd.ids = append(cmd.ids, id)
+			}
+			args = nil
+		default:
+			return ClientList{}, redis.ErrSyntaxError
+		}
+	}
+	return cmd, nil
+}
+
+func (c ClientList) Run(w redis.Writer, red redis.Redka) (any, error) {
+	var b strings.Builder
+	// All Redka clients are normal clients.
+	if c.typ == "" || c.typ == "normal" {
+		for _, client := range red.Clients().List(c.ids...) {
+			b.WriteString(client.String())
+			b.WriteByte('\n')
+		}
+	}
+	list := b.String()
+	w.WriteBulkString(list)
+	return list, nil
+}
+
+// parseClientType parses a client type.
+// Returns the normalized type name.
+func parseClientType(arg []byte) (string, error) {
+	typ := strings.ToLower(string(arg))
+	switch typ {
+	case "normal", "master", "replica", "pubsub":
+		return typ, nil
+	case "slave":
+		return "replica", nil
+	default:
+		return "", redis.ErrSyntaxError
+	}
+}
@@ -0,0 +1,36 @@
+package conn
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sets the client eviction mode of the connection.
+// Redka never evicts keys, so the mode is only
+// reported in the client flags.
+// CLIENT NO-EVICT <ON | OFF>
+// https://redis.io/commands/client-no-evict
+type ClientNoEvict struct {
+	on bool
+}
+
+func ParseClientNoEvict(args [][]byte) (ClientNoEvict, error) {
+	if len(args) != 1 {
+		return ClientNoEvict{}, redis.ErrInvalidArgNum
+	}
+	switch strings.ToLower(string(args[0])) {
+	case "on":
+		return ClientNoEvict{on: true}, nil
+	case "off":
+		return ClientNoEvict{on: false}, nil
+	default:
+		return ClientNoEvict{}, redis.ErrSyntaxError
+	}
+}
+
+func (c ClientNoEvict) Run(w redis.Writer, red redis.Redka) (any, error) {
+	red.Client().SetNoEvict(c.on)
+	w.WriteString("OK")
+	return true, nil
+}
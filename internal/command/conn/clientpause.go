@@ -0,0 +1,52 @@
+package conn
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Suspends commands processing.
+// CLIENT PAUSE timeout [WRITE | ALL]
+// https://redis.io/commands/client-pause
+type ClientPause struct {
+	timeout time.Duration
+	mode    string
+}
+
+func ParseClientPause(args [][]byte) (ClientPause, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return ClientPause{}, redis.ErrInvalidArgNum
+	}
+	ms, err := strconv.Atoi(string(args[0]))
+	if err != nil {
+		return ClientPause{}, redis.ErrInvalidInt
+	}
+	if ms < 0 {
+		return ClientPause{}, redis.ErrNegativeTimeout
+	}
+	cmd := ClientPause{
+		timeout: time.Duration(ms) * time.Millisecond,
+		mode:    clients.PauseAll,
+	}
+	if len(args) == 2 {
+		switch strings.ToLower(string(args[1])) {
+		case "write":
+			cmd.mode = clients.PauseWrite
+		case "all":
+			cmd.mode = clients.PauseAll
+		default:
+			return ClientPause{}, redis.ErrSyntaxError
+		}
+	}
+	return cmd, nil
+}
+
+func (c ClientPause) Run(w redis.Writer, red redis.Redka) (any, error) {
+	red.Clients().Pause(c.timeout, c.mode)
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,42 @@
+package conn
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sets information specific to the client library.
+// CLIENT SETINFO <LIB-NAME libname | LIB-VER libver>
+// https://redis.io/commands/client-setinfo
+type ClientSetInfo struct {
+	attr  string
+	value string
+}
+
+func ParseClientSetInfo(args [][]byte) (ClientSetInfo, error) {
+	if len(args) != 2 {
+		return ClientSetInfo{}, redis.ErrInvalidArgNum
+	}
+	cmd := ClientSetInfo{
+		attr:  strings.ToLower(string(args[0])),
+		value: string(args[1]),
+	}
+	if cmd.attr != "lib-name" && cmd.attr != "lib-ver" {
+		return ClientSetInfo{}, redis.ErrSyntaxError
+	}
+	if !validName(cmd.value) {
+		return ClientSetInfo{}, redis.ErrSyntaxError
+	}
+	return cmd, nil
+}
+
+func (c ClientSetInfo) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if c.attr == "lib-name" {
+		red.Client().SetLibName(c.value)
+	} else {
+		red.Client().SetLibVer(c.value)
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,29 @@
+package conn
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sets the connection name.
+// CLIENT SETNAME connection-name
+// https://redis.io/commands/client-setname
+type ClientSetName struct {
+	name string
+}
+
+func ParseClientSetName(args [][]byte) (ClientSetName, error) {
+	if len(args) != 1 {
+		return ClientSetName{}, redis.ErrInvalidArgNum
+	}
+	cmd := ClientSetName{name: string(args[0])}
+	if !validName(cmd.name) {
+		return ClientSetName{}, redis.ErrInvalidClientName
+	}
+	return cmd, nil
+}
+
+func (c ClientSetName) Run(w redis.Writer, red redis.Redka) (any, error) {
+	red.Client().SetName(c.name)
+	w.WriteString("OK")
+	return true, nil
+}
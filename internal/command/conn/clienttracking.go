@@ -0,0 +1,156 @@
+package conn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Enables or disables the server-assisted client-side caching
+// for the connection. The server notifies the client when
+// the keys it has read (or, in BCAST mode, any keys matching
+// the prefixes) are modified.
+// CLIENT TRACKING <ON | OFF> [REDIRECT client-id] [PREFIX prefix
+// [PREFIX prefix ...]] [BCAST] [OPTIN] [OPTOUT] [NOLOOP]
+// https://redis.io/commands/client-tracking
+type ClientTracking struct {
+	on   bool
+	opts clients.TrackingOptions
+}
+
+func ParseClientTracking(args [][]byte) (ClientTracking, error) {
+	if len(args) == 0 {
+		return ClientTracking{}, redis.ErrInvalidArgNum
+	}
+	var cmd ClientTracking
+	switch strings.ToLower(string(args[0])) {
+	case "on":
+		cmd.on = true
+	case "off":
+		cmd.on = false
+	default:
+		return ClientTracking{}, redis.ErrSyntaxError
+	}
+
+	for args = args[1:]; len(args) > 0; args = args[1:] {
+		switch strings.ToLower(string(args[0])) {
+		case "redirect":
+			if len(args) < 2 {
+				return ClientTracking{}, redis.ErrSyntaxError
+			}
+			id, err := strconv.ParseInt(string(args[1]), 10, 64)
+			if err != nil {
+				return ClientTracking{}, redis.ErrInvalidInt
+			}
+			cmd.opts.Redirect = id
+			args = args[1:]
+		case "prefix":
+			if len(args) < 2 {
+				return ClientTracking{}, redis.ErrSyntaxError
+			}
+			cmd.opts.Prefixes = append(cmd.opts.Prefixes, string(args[1]))
+			args = args[1:]
+		case "bcast":
+			cmd.opts.BCast = true
+		case "optin":
+			cmd.opts.OptIn = true
+		case "optout":
+			cmd.opts.OptOut = true
+		case "noloop":
+			cmd.opts.NoLoop = true
+		default:
+			return ClientTracking{}, redis.ErrSyntaxError
+		}
+	}
+
+	if len(cmd.opts.Prefixes) > 0 && !cmd.opts.BCast {
+		return ClientTracking{}, redis.ErrPrefixNoBCast
+	}
+	if cmd.opts.OptIn && cmd.opts.OptOut {
+		return ClientTracking{}, redis.ErrOptInOptOut
+	}
+	if cmd.opts.BCast && (cmd.opts.OptIn || cmd.opts.OptOut) {
+		return ClientTracking{}, redis.ErrBCastOptIn
+	}
+	return cmd, nil
+}
+
+func (c ClientTracking) Run(w redis.Writer, red redis.Redka) (any, error) {
+	var opts *clients.TrackingOptions
+	if c.on {
+		opts = &c.opts
+	}
+	err := red.Clients().SetTracking(red.Client(), opts)
+	switch {
+	case errors.Is(err, clients.ErrNoRedirect):
+		err = redis.ErrNoRedirect
+	case errors.Is(err, clients.ErrBCastSwitch):
+		err = redis.ErrBCastSwitch
+	case errors.Is(err, clients.ErrPrefixOverlap):
+		err = redis.ErrPrefixOverlap
+	}
+	if err != nil {
+		w.WriteError(err.Error())
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
+
+// writeTrackingInfo writes the tracking state of the client
+// (CLIENT TRACKINGINFO) and returns the flags.
+func writeTrackingInfo(w redis.Writer, red redis.Redka) []string {
+	opts := red.Client().Tracking()
+	if opts == nil {
+		w.WriteMap(3)
+		w.WriteBulkString("flags")
+		w.WriteArray(1)
+		w.WriteBulkString("off")
+		w.WriteBulkString("redirect")
+		w.WriteInt(-1)
+		w.WriteBulkString("prefixes")
+		w.WriteArray(0)
+		return []string{"off"}
+	}
+
+	flags := []string{"on"}
+	if opts.BCast {
+		flags = append(flags, "bcast")
+	}
+	if opts.OptIn {
+		flags = append(flags, "optin")
+		if red.Client().Caching() == clients.CachingYes {
+			flags = append(flags, "caching-yes")
+		}
+	}
+	if opts.OptOut {
+		flags = append(flags, "optout")
+		if red.Client().Caching() == clients.CachingNo {
+			flags = append(flags, "caching-no")
+		}
+	}
+	if opts.NoLoop {
+		flags = append(flags, "noloop")
+	}
+	if opts.Redirect != 0 && len(red.Clients().List(opts.Redirect)) == 0 {
+		flags = append(flags, "broken_redirect")
+	}
+
+	w.WriteMap(3)
+	w.WriteBulkString("flags")
+	w.WriteArray(len(flags))
+	for _, flag := range flags {
+		w.WriteBulkString(flag)
+	}
+	w.WriteBulkString("redirect")
+	w.WriteInt64(opts.Redirect)
+	w.WriteBulkString("prefixes")
+	w.WriteArray(len(opts.Prefixes))
+	for _, prefix := range opts.Prefixes {
+		w.WriteBulkString(prefix)
+	}
+	return flags
+}
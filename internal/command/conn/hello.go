@@ -0,0 +1,129 @@
+package conn
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Handshakes with the server: switches the protocol version,
+// and optionally authenticates and sets the client name.
+// HELLO [protover [AUTH username password] [SETNAME clientname]]
+// https://redis.io/commands/hello
+type Hello struct {
+	redis.BaseCmd
+	proto int // 0 keeps the current version
+	auth  bool
+	user  string
+	pass  string
+	name  string
+}
+
+func ParseHello(b redis.BaseCmd) (Hello, error) {
+	cmd := Hello{BaseCmd: b}
+	args := cmd.Args()
+	if len(args) == 0 {
+		return cmd, nil
+	}
+
+	proto, err := strconv.Atoi(string(args[0]))
+	if err != nil {
+		return Hello{}, redis.ErrInvalidProto
+	}
+	if proto != redis.RESP2 && proto != redis.RESP3 {
+		return Hello{}, redis.ErrNoProto
+	}
+	cmd.proto = proto
+
+	for args = args[1:]; len(args) > 0; {
+		switch strings.ToLower(string(args[0])) {
+		case "auth":
+			if len(args) < 3 {
+				return Hello{}, redis.ErrSyntaxError
+			}
+			cmd.auth = true
+			cmd.user, cmd.pass = string(args[1]), string(args[2])
+			args = args[3:]
+		case "setname":
+			if len(args) < 2 {
+				return Hello{}, redis.ErrSyntaxError
+			}
+			cmd.name = string(args[1])
+			if !validName(cmd.name) {
+				return Hello{}, redis.ErrInvalidClientName
+			}
+			args = args[2:]
+		default:
+			return Hello{}, redis.ErrSyntaxError
+		}
+	}
+	return cmd, nil
+}
+
+func (c Hello) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if c.auth {
+		if red.ACL() == nil || red.Client() == nil {
+			w.WriteError(c.Error(redis.ErrNoACL))
+			return nil, redis.ErrNoACL
+		}
+		if err := red.ACL().Auth(c.user, c.pass); err != nil {
+			w.WriteError(redis.ErrWrongPass.Error())
+			return nil, redis.ErrWrongPass
+		}
+	} else if red.ACL() != nil && red.Client() != nil && !red.Client().Authenticated() {
+		w.WriteError(redis.ErrNoAuthHello.Error())
+		return nil, redis.ErrNoAuthHello
+	}
+
+	// Writers that don't support switching
+	// the protocol version only speak RESP2.
+	proto := redis.RESP2
+	pw, ok := w.(redis.ProtoWriter)
+	if ok {
+		proto = pw.Proto()
+	}
+	if c.proto != 0 {
+		if !ok && c.proto != redis.RESP2 {
+			w.WriteError(redis.ErrNoProto.Error())
+			return nil, redis.ErrNoProto
+		}
+		proto = c.proto
+	}
+
+	// All checks have passed, so apply the changes.
+	var id int64
+	if client := red.Client(); client != nil {
+		if c.auth {
+			client.SetUser(c.user)
+		}
+		if c.name != "" {
+			client.SetName(c.name)
+		}
+		id = client.ID
+	}
+	if ok {
+		pw.SetProto(proto)
+	}
+
+	role := "master"
+	if red.Repl() != nil && red.Repl().ReadOnly() {
+		role = "replica"
+	}
+	w.WriteMap(7)
+	w.WriteBulkString("server")
+	w.WriteBulkString("redis")
+	w.WriteBulkString("version")
+	w.WriteBulkString(redis.Version)
+	w.WriteBulkString("proto")
+	w.WriteInt(proto)
+	w.WriteBulkString("id")
+	w.WriteInt64(id)
+	w.WriteBulkString("mode")
+	w.WriteBulkString("standalone")
+	w.WriteBulkString("role")
+	w.WriteBulkString(role)
+	w.WriteBulkString("modules")
+	w.WriteArray(0)
+	return proto, nil
+}
@@ -0,0 +1,111 @@
+package conn
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHelloParse(t *testing.T) {
+	tests := []struct {
+		cmd   string
+		proto int
+		user  string
+		name  string
+		err   error
+	}{
+		{cmd: "hello"},
+		{cmd: "hello 2", proto: 2},
+		{cmd: "hello 3", proto: 3},
+		{cmd: "hello 3 auth alice secret", proto: 3, user: "alice"},
+		{cmd: "hello 3 setname conn1", proto: 3, name: "conn1"},
+		{cmd: "hello 3 AUTH alice secret SETNAME conn1", proto: 3, user: "alice", name: "conn1"},
+		{cmd: "hello 1", err: redis.ErrNoProto},
+		{cmd: "hello 4", err: redis.ErrNoProto},
+		{cmd: "hello three", err: redis.ErrInvalidProto},
+		{cmd: "hello 3 auth alice", err: redis.ErrSyntaxError},
+		{cmd: "hello 3 setname", err: redis.ErrSyntaxError},
+		{cmd: "hello 3 setname conn\n1", err: redis.ErrInvalidClientName},
+		{cmd: "hello 3 foo", err: redis.ErrSyntaxError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHello, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.proto, test.proto)
+			testx.AssertEqual(t, cmd.user, test.user)
+			testx.AssertEqual(t, cmd.name, test.name)
+		})
+	}
+}
+
+func TestHelloExec(t *testing.T) {
+	t.Run("switch protocol", func(t *testing.T) {
+		red, client := getAuth(t, "")
+		client.SetUser("default")
+
+		cmd := redis.MustParse(ParseHello, "hello 3")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 3)
+		testx.AssertEqual(t, conn.Proto(), 3)
+		testx.AssertEqual(t, conn.Out(),
+			"14,server,redis,version,"+redis.Version+",proto,3,id,1,"+
+				"mode,standalone,role,master,modules,0")
+	})
+	t.Run("current protocol", func(t *testing.T) {
+		red, client := getAuth(t, "")
+		client.SetUser("default")
+
+		cmd := redis.MustParse(ParseHello, "hello")
+		conn := redis.NewFakeConn()
+		conn.SetProto(redis.RESP3)
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 3)
+		testx.AssertEqual(t, conn.Proto(), 3)
+	})
+	t.Run("auth and setname", func(t *testing.T) {
+		red, client := getAuth(t, "secret")
+
+		cmd := redis.MustParse(ParseHello, "hello 3 auth default wrong setname conn1")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrWrongPass)
+		testx.AssertEqual(t, conn.Out(), redis.ErrWrongPass.Error())
+		testx.AssertEqual(t, conn.Proto(), 2)
+		testx.AssertEqual(t, client.Name(), "")
+
+		cmd = redis.MustParse(ParseHello, "hello 3 auth default secret setname conn1")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Proto(), 3)
+		testx.AssertEqual(t, client.Authenticated(), true)
+		testx.AssertEqual(t, client.Name(), "conn1")
+	})
+	t.Run("not authenticated", func(t *testing.T) {
+		red, _ := getAuth(t, "secret")
+		cmd := redis.MustParse(ParseHello, "hello 3")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoAuthHello)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoAuthHello.Error())
+		testx.AssertEqual(t, conn.Proto(), 2)
+	})
+	t.Run("no clients", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		cmd := redis.MustParse(ParseHello, "hello 2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 2)
+		testx.AssertEqual(t, conn.Out(),
+			"14,server,redis,version,"+redis.Version+",proto,2,id,0,"+
+				"mode,standalone,role,master,modules,0")
+	})
+}
@@ -6,7 +6,8 @@ import (
 )
 
 // Changes the selected database.
-// Since Redka doesn't support multiple databases, this command is a no-op.
+// The database applies to the following commands
+// of the connection (including the rest of a transaction).
 // SELECT index
 // https://redis.io/commands/select
 type Select struct {
@@ -26,6 +27,20 @@ func ParseSelect(b redis.BaseCmd) (Select, error) {
 }
 
 func (c Select) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	if c.index < 0 || c.index >= redis.Databases {
+		w.WriteError(c.Error(redis.ErrDBIndex))
+		return nil, redis.ErrDBIndex
+	}
+	// Writers that don't support switching
+	// the database only work with the default one.
+	dw, ok := w.(redis.DBWriter)
+	if !ok && c.index != 0 {
+		w.WriteError(c.Error(redis.ErrDBIndex))
+		return nil, redis.ErrDBIndex
+	}
+	if ok {
+		dw.SetDB(c.index)
+	}
 	w.WriteString("OK")
 	return true, nil
 }
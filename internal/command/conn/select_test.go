@@ -50,22 +50,43 @@ func TestSelectExec(t *testing.T) {
 	tests := []struct {
 		cmd string
 		res any
+		db  int
 		out string
 	}{
 		{
 			cmd: "select 5",
 			res: true,
+			db:  5,
 			out: "OK",
 		},
+		{
+			cmd: "select 0",
+			res: true,
+			db:  0,
+			out: "OK",
+		},
+		{
+			cmd: "select 16",
+			res: nil,
+			db:  2,
+			out: redis.ErrDBIndex.Error() + " (select)",
+		},
+		{
+			cmd: "select -1",
+			res: nil,
+			db:  2,
+			out: redis.ErrDBIndex.Error() + " (select)",
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.cmd, func(t *testing.T) {
 			conn := redis.NewFakeConn()
+			conn.SetDB(2)
 			cmd := redis.MustParse(ParseSelect, test.cmd)
-			res, err := cmd.Run(conn, red)
-			testx.AssertNoErr(t, err)
+			res, _ := cmd.Run(conn, red)
 			testx.AssertEqual(t, res, test.res)
+			testx.AssertEqual(t, conn.DB(), test.db)
 			testx.AssertEqual(t, conn.Out(), test.out)
 		})
 	}
@@ -0,0 +1,103 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rhash"
+)
+
+// Sets the expiration time of hash fields in seconds.
+// HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+// https://redis.io/commands/hexpire
+type HExpire struct {
+	redis.BaseCmd
+	key    string
+	ttl    time.Duration
+	fields []string
+	cond   expireCond
+}
+
+func ParseHExpire(b redis.BaseCmd, multi int) (HExpire, error) {
+	cmd := HExpire{BaseCmd: b}
+
+	var ttl, nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&ttl),
+		cmd.cond.parser(),
+		parseFields(&cmd.fields, &nFields),
+	).Required(5).Run(cmd.Args())
+	if err != nil {
+		return HExpire{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HExpire{}, redis.ErrInvalidArgNum
+	}
+	if ttl < 0 {
+		return HExpire{}, redis.ErrInvalidExpireTime
+	}
+
+	cmd.ttl = time.Duration(multi*ttl) * time.Millisecond
+	return cmd, nil
+}
+
+func (cmd HExpire) Run(w redis.Writer, red redis.Redka) (any, error) {
+	c := red.Hash().ExpireWith(cmd.key, cmd.fields...).TTL(cmd.ttl)
+	res, err := cmd.cond.apply(c).Run()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	writeInts(w, res)
+	return res, nil
+}
+
+// expireCond is a condition for setting
+// the expiration time of hash fields.
+type expireCond struct {
+	nx, xx, gt, lt bool
+}
+
+// parser returns a parser for the NX | XX | GT | LT argument.
+func (c *expireCond) parser() parser.ParserFunc {
+	return parser.OneOf(
+		parser.Flag("nx", &c.nx),
+		parser.Flag("xx", &c.xx),
+		parser.Flag("gt", &c.gt),
+		parser.Flag("lt", &c.lt),
+	)
+}
+
+// apply configures the expire command with the condition.
+func (c expireCond) apply(cmd rhash.ExpireCmd) rhash.ExpireCmd {
+	switch {
+	case c.nx:
+		return cmd.IfNoTTL()
+	case c.xx:
+		return cmd.IfHasTTL()
+	case c.gt:
+		return cmd.IfGreater()
+	case c.lt:
+		return cmd.IfLess()
+	}
+	return cmd
+}
+
+// parseFields returns a parser for the
+// FIELDS numfields field [field ...] argument.
+func parseFields(dest *[]string, nFields *int) parser.ParserFunc {
+	return parser.Named("fields",
+		parser.Int(nFields),
+		parser.StringsN(dest, nFields),
+	)
+}
+
+// writeInts writes a slice of integers as an array.
+func writeInts(w redis.Writer, vals []int) {
+	w.WriteArray(len(vals))
+	for _, v := range vals {
+		w.WriteInt(v)
+	}
+}
@@ -0,0 +1,170 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHExpireParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HExpire
+		err  error
+	}{
+		{
+			cmd:  "hexpire",
+			want: HExpire{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpire person 60",
+			want: HExpire{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpire person 60 fields 1",
+			want: HExpire{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpire person 60 fields 1 name",
+			want: HExpire{key: "person", ttl: 60 * time.Second, fields: []string{"name"}},
+			err:  nil,
+		},
+		{
+			cmd: "hexpire person 60 fields 2 name age",
+			want: HExpire{key: "person", ttl: 60 * time.Second,
+				fields: []string{"name", "age"}},
+			err: nil,
+		},
+		{
+			cmd: "hexpire person 60 nx fields 1 name",
+			want: HExpire{key: "person", ttl: 60 * time.Second,
+				fields: []string{"name"}, cond: expireCond{nx: true}},
+			err: nil,
+		},
+		{
+			cmd: "hexpire person 60 lt fields 1 name",
+			want: HExpire{key: "person", ttl: 60 * time.Second,
+				fields: []string{"name"}, cond: expireCond{lt: true}},
+			err: nil,
+		},
+		{
+			cmd:  "hexpire person 60 nx xx fields 1 name",
+			want: HExpire{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "hexpire person 60 fields 3 name age",
+			want: HExpire{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpire person 60 fields 1 name age",
+			want: HExpire{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "hexpire person 60 fields 0 name",
+			want: HExpire{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "hexpire person -10 fields 1 name",
+			want: HExpire{},
+			err:  redis.ErrInvalidExpireTime,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (HExpire, error) {
+		return ParseHExpire(b, 1000)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.ttl, test.want.ttl)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+				testx.AssertEqual(t, cmd.cond, test.want.cond)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHExpireExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (HExpire, error) {
+		return ParseHExpire(b, 1000)
+	}
+	t.Run("set", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(parse, "hexpire person 60 fields 2 name city")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{1, -2})
+		testx.AssertEqual(t, conn.Out(), "2,1,-2")
+
+		expireAt := time.Now().Add(60 * time.Second)
+		exp, _ := db.Hash().Expiry("person", "name", "age")
+		testx.AssertEqual(t, *exp[0].ETime/1000, expireAt.UnixMilli()/1000)
+		testx.AssertEqual(t, exp[1].ETime, (*int64)(nil))
+	})
+	t.Run("condition", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		cmd := redis.MustParse(parse, "hexpire person 60 nx fields 2 name age")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{0, 1})
+		testx.AssertEqual(t, conn.Out(), "2,0,1")
+	})
+	t.Run("zero", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(parse, "hexpire person 0 fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{2})
+		testx.AssertEqual(t, conn.Out(), "1,2")
+
+		exists, _ := db.Hash().Exists("person", "name")
+		testx.AssertEqual(t, exists, false)
+		hlen, _ := db.Hash().Len("person")
+		testx.AssertEqual(t, hlen, 1)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(parse, "hexpire person 60 fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{-2})
+		testx.AssertEqual(t, conn.Out(), "1,-2")
+	})
+}
@@ -0,0 +1,54 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sets the expiration time of hash fields to a Unix timestamp.
+// HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+// https://redis.io/commands/hexpireat
+type HExpireAt struct {
+	redis.BaseCmd
+	key    string
+	at     time.Time
+	fields []string
+	cond   expireCond
+}
+
+func ParseHExpireAt(b redis.BaseCmd, multi int) (HExpireAt, error) {
+	cmd := HExpireAt{BaseCmd: b}
+
+	var at, nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&at),
+		cmd.cond.parser(),
+		parseFields(&cmd.fields, &nFields),
+	).Required(5).Run(cmd.Args())
+	if err != nil {
+		return HExpireAt{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HExpireAt{}, redis.ErrInvalidArgNum
+	}
+	if at < 0 {
+		return HExpireAt{}, redis.ErrInvalidExpireTime
+	}
+
+	cmd.at = time.UnixMilli(int64(multi * at))
+	return cmd, nil
+}
+
+func (cmd HExpireAt) Run(w redis.Writer, red redis.Redka) (any, error) {
+	c := red.Hash().ExpireWith(cmd.key, cmd.fields...).At(cmd.at)
+	res, err := cmd.cond.apply(c).Run()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	writeInts(w, res)
+	return res, nil
+}
@@ -0,0 +1,104 @@
+package hash
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHExpireAtParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HExpireAt
+		err  error
+	}{
+		{
+			cmd:  "hexpireat",
+			want: HExpireAt{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpireat person 1700000000",
+			want: HExpireAt{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "hexpireat person 1700000000 fields 1 name",
+			want: HExpireAt{key: "person", at: time.UnixMilli(1700000000 * 1000),
+				fields: []string{"name"}},
+			err: nil,
+		},
+		{
+			cmd: "hexpireat person 1700000000 gt fields 2 name age",
+			want: HExpireAt{key: "person", at: time.UnixMilli(1700000000 * 1000),
+				fields: []string{"name", "age"}, cond: expireCond{gt: true}},
+			err: nil,
+		},
+		{
+			cmd:  "hexpireat person now fields 1 name",
+			want: HExpireAt{},
+			err:  redis.ErrInvalidInt,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (HExpireAt, error) {
+		return ParseHExpireAt(b, 1000)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.at, test.want.at)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+				testx.AssertEqual(t, cmd.cond, test.want.cond)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHExpireAtExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (HExpireAt, error) {
+		return ParseHExpireAt(b, 1000)
+	}
+	t.Run("future", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		at := time.Now().Add(60 * time.Second).Unix()
+		cmd := redis.MustParse(parse, "hexpireat person "+strconv.FormatInt(at, 10)+" fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{1})
+		testx.AssertEqual(t, conn.Out(), "1,1")
+
+		exp, _ := db.Hash().Expiry("person", "name")
+		testx.AssertEqual(t, *exp[0].ETime, at*1000)
+	})
+	t.Run("past", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(parse, "hexpireat person 1700000000 fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{2})
+		testx.AssertEqual(t, conn.Out(), "1,2")
+
+		exists, _ := db.Key().Exists("person")
+		testx.AssertEqual(t, exists, false)
+	})
+}
@@ -0,0 +1,50 @@
+package hash
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the expiration time of hash fields as a Unix timestamp in seconds.
+// HEXPIRETIME key FIELDS numfields field [field ...]
+// https://redis.io/commands/hexpiretime
+type HExpireTime struct {
+	redis.BaseCmd
+	key    string
+	fields []string
+	unit   int
+}
+
+func ParseHExpireTime(b redis.BaseCmd, unit int) (HExpireTime, error) {
+	cmd := HExpireTime{BaseCmd: b, unit: unit}
+	var nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parseFields(&cmd.fields, &nFields),
+	).Required(4).Run(cmd.Args())
+	if err != nil {
+		return HExpireTime{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HExpireTime{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd HExpireTime) Run(w redis.Writer, red redis.Redka) (any, error) {
+	expiry, err := red.Hash().Expiry(cmd.key, cmd.fields...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	unit := int64(cmd.unit)
+	res := make([]int, len(expiry))
+	for i, exp := range expiry {
+		res[i] = expiryCode(exp, func(etime int64) int {
+			return int(etime / unit)
+		})
+	}
+	writeInts(w, res)
+	return res, nil
+}
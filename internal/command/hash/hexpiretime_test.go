@@ -0,0 +1,88 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHExpireTimeParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HExpireTime
+		err  error
+	}{
+		{
+			cmd:  "hexpiretime",
+			want: HExpireTime{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpiretime person fields 1",
+			want: HExpireTime{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hexpiretime person fields 1 name",
+			want: HExpireTime{key: "person", fields: []string{"name"}},
+			err:  nil,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (HExpireTime, error) {
+		return ParseHExpireTime(b, 1000)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHExpireTimeExec(t *testing.T) {
+	t.Run("seconds", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		at := time.Now().Add(60 * time.Second).Truncate(time.Second)
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+		_, _ = db.Hash().ExpireWith("person", "name").At(at).Run()
+
+		parse := func(b redis.BaseCmd) (HExpireTime, error) {
+			return ParseHExpireTime(b, 1000)
+		}
+		cmd := redis.MustParse(parse, "hexpiretime person fields 3 name age city")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{int(at.Unix()), -1, -2})
+	})
+	t.Run("milliseconds", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		at := time.Now().Add(60 * time.Second).Truncate(time.Millisecond)
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().ExpireWith("person", "name").At(at).Run()
+
+		parse := func(b redis.BaseCmd) (HExpireTime, error) {
+			return ParseHExpireTime(b, 1)
+		}
+		cmd := redis.MustParse(parse, "hpexpiretime person fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{int(at.UnixMilli())})
+	})
+}
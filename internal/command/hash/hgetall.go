@@ -25,7 +25,7 @@ func (cmd HGetAll) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	w.WriteArray(len(items) * 2)
+	w.WriteMap(len(items))
 	for field, val := range items {
 		w.WriteBulkString(field)
 		w.WriteBulk(val)
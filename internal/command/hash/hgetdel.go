@@ -0,0 +1,55 @@
+package hash
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the values of fields and deletes them from a hash.
+// HGETDEL key FIELDS numfields field [field ...]
+// https://redis.io/commands/hgetdel
+type HGetDel struct {
+	redis.BaseCmd
+	key    string
+	fields []string
+}
+
+func ParseHGetDel(b redis.BaseCmd) (HGetDel, error) {
+	cmd := HGetDel{BaseCmd: b}
+	var nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parseFields(&cmd.fields, &nFields),
+	).Required(4).Run(cmd.Args())
+	if err != nil {
+		return HGetDel{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HGetDel{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd HGetDel) Run(w redis.Writer, red redis.Redka) (any, error) {
+	items, err := red.Hash().GetDelete(cmd.key, cmd.fields...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	// Write the values in the order of fields.
+	// Missing fields will have nil values.
+	w.WriteArray(len(cmd.fields))
+	vals := make([]core.Value, len(cmd.fields))
+	for i, field := range cmd.fields {
+		v, ok := items[field]
+		vals[i] = v
+		if ok {
+			w.WriteBulk(v.Bytes())
+		} else {
+			w.WriteNull()
+		}
+	}
+	return vals, nil
+}
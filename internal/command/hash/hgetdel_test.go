@@ -0,0 +1,84 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHGetDelParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HGetDel
+		err  error
+	}{
+		{
+			cmd:  "hgetdel",
+			want: HGetDel{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hgetdel person name",
+			want: HGetDel{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hgetdel person fields 2 name age",
+			want: HGetDel{key: "person", fields: []string{"name", "age"}},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetdel person fields 1 name age",
+			want: HGetDel{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHGetDel, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHGetDelExec(t *testing.T) {
+	t.Run("get and delete", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(ParseHGetDel, "hgetdel person fields 2 name city")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value("alice"), core.Value(nil)})
+		testx.AssertEqual(t, conn.Out(), "2,alice,(nil)")
+
+		exists, _ := db.Hash().Exists("person", "name")
+		testx.AssertEqual(t, exists, false)
+		hlen, _ := db.Hash().Len("person")
+		testx.AssertEqual(t, hlen, 1)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHGetDel, "hgetdel person fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value(nil)})
+		testx.AssertEqual(t, conn.Out(), "1,(nil)")
+	})
+}
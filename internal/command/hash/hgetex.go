@@ -0,0 +1,101 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the values of hash fields and optionally
+// sets or removes their expiration time.
+// HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds |
+// PXAT unix-time-milliseconds | PERSIST] FIELDS numfields field [field ...]
+// https://redis.io/commands/hgetex
+type HGetEx struct {
+	redis.BaseCmd
+	key     string
+	fields  []string
+	ttl     time.Duration
+	at      time.Time
+	persist bool
+}
+
+func ParseHGetEx(b redis.BaseCmd) (HGetEx, error) {
+	cmd := HGetEx{BaseCmd: b}
+
+	var ttlSec, ttlMs, atSec, atMs, nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.OneOf(
+			parser.Named("ex", parser.Int(&ttlSec)),
+			parser.Named("px", parser.Int(&ttlMs)),
+			parser.Named("exat", parser.Int(&atSec)),
+			parser.Named("pxat", parser.Int(&atMs)),
+			parser.Flag("persist", &cmd.persist),
+		),
+		parseFields(&cmd.fields, &nFields),
+	).Required(4).Run(cmd.Args())
+	if err != nil {
+		return HGetEx{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HGetEx{}, redis.ErrInvalidArgNum
+	}
+
+	// Set the expiration time.
+	if ttlSec < 0 || ttlMs < 0 || atSec < 0 || atMs < 0 {
+		return HGetEx{}, redis.ErrInvalidExpireTime
+	}
+	if ttlSec > 0 {
+		cmd.ttl = time.Duration(ttlSec) * time.Second
+	} else if ttlMs > 0 {
+		cmd.ttl = time.Duration(ttlMs) * time.Millisecond
+	} else if atSec > 0 {
+		cmd.at = time.Unix(int64(atSec), 0)
+	} else if atMs > 0 {
+		cmd.at = time.UnixMilli(int64(atMs))
+	}
+
+	return cmd, nil
+}
+
+func (cmd HGetEx) Run(w redis.Writer, red redis.Redka) (any, error) {
+	// Get the values before changing the expiration time,
+	// so that fields expiring right away are still returned.
+	items, err := red.Hash().GetMany(cmd.key, cmd.fields...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	// Set or remove the expiration time.
+	switch {
+	case cmd.persist:
+		_, err = red.Hash().Persist(cmd.key, cmd.fields...)
+	case cmd.ttl > 0:
+		_, err = red.Hash().ExpireWith(cmd.key, cmd.fields...).TTL(cmd.ttl).Run()
+	case !cmd.at.IsZero():
+		_, err = red.Hash().ExpireWith(cmd.key, cmd.fields...).At(cmd.at).Run()
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	// Write the values in the order of fields.
+	// Missing fields will have nil values.
+	w.WriteArray(len(cmd.fields))
+	vals := make([]core.Value, len(cmd.fields))
+	for i, field := range cmd.fields {
+		v, ok := items[field]
+		vals[i] = v
+		if ok {
+			w.WriteBulk(v.Bytes())
+		} else {
+			w.WriteNull()
+		}
+	}
+	return vals, nil
+}
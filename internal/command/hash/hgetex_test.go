@@ -0,0 +1,140 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHGetExParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HGetEx
+		err  error
+	}{
+		{
+			cmd:  "hgetex",
+			want: HGetEx{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hgetex person fields 1",
+			want: HGetEx{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hgetex person fields 1 name",
+			want: HGetEx{key: "person", fields: []string{"name"}},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetex person ex 60 fields 1 name",
+			want: HGetEx{key: "person", fields: []string{"name"}, ttl: 60 * time.Second},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetex person px 500 fields 1 name",
+			want: HGetEx{key: "person", fields: []string{"name"}, ttl: 500 * time.Millisecond},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetex person exat 1700000000 fields 1 name",
+			want: HGetEx{key: "person", fields: []string{"name"}, at: time.Unix(1700000000, 0)},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetex person persist fields 2 name age",
+			want: HGetEx{key: "person", fields: []string{"name", "age"}, persist: true},
+			err:  nil,
+		},
+		{
+			cmd:  "hgetex person ex 60 persist fields 1 name",
+			want: HGetEx{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "hgetex person ex -1 fields 1 name",
+			want: HGetEx{},
+			err:  redis.ErrInvalidExpireTime,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHGetEx, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+				testx.AssertEqual(t, cmd.ttl, test.want.ttl)
+				testx.AssertEqual(t, cmd.at, test.want.at)
+				testx.AssertEqual(t, cmd.persist, test.want.persist)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHGetExExec(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHGetEx, "hgetex person fields 2 name age")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value("alice"), core.Value(nil)})
+		testx.AssertEqual(t, conn.Out(), "2,alice,(nil)")
+	})
+	t.Run("expire", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHGetEx, "hgetex person ex 60 fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value("alice")})
+		testx.AssertEqual(t, conn.Out(), "1,alice")
+
+		expireAt := time.Now().Add(60 * time.Second)
+		exp, _ := db.Hash().Expiry("person", "name")
+		testx.AssertEqual(t, *exp[0].ETime/1000, expireAt.UnixMilli()/1000)
+	})
+	t.Run("persist", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		cmd := redis.MustParse(ParseHGetEx, "hgetex person persist fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value("alice")})
+
+		exp, _ := db.Hash().Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime, (*int64)(nil))
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHGetEx, "hgetex person ex 60 fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value(nil)})
+		testx.AssertEqual(t, conn.Out(), "1,(nil)")
+	})
+}
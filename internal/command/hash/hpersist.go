@@ -0,0 +1,41 @@
+package hash
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Removes the expiration time of hash fields.
+// HPERSIST key FIELDS numfields field [field ...]
+// https://redis.io/commands/hpersist
+type HPersist struct {
+	redis.BaseCmd
+	key    string
+	fields []string
+}
+
+func ParseHPersist(b redis.BaseCmd) (HPersist, error) {
+	cmd := HPersist{BaseCmd: b}
+	var nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parseFields(&cmd.fields, &nFields),
+	).Required(4).Run(cmd.Args())
+	if err != nil {
+		return HPersist{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HPersist{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd HPersist) Run(w redis.Writer, red redis.Redka) (any, error) {
+	res, err := red.Hash().Persist(cmd.key, cmd.fields...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	writeInts(w, res)
+	return res, nil
+}
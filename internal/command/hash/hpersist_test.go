@@ -0,0 +1,83 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHPersistParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HPersist
+		err  error
+	}{
+		{
+			cmd:  "hpersist",
+			want: HPersist{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hpersist person",
+			want: HPersist{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hpersist person fields 1 name",
+			want: HPersist{key: "person", fields: []string{"name"}},
+			err:  nil,
+		},
+		{
+			cmd:  "hpersist person fields 2 name",
+			want: HPersist{},
+			err:  redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHPersist, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHPersistExec(t *testing.T) {
+	t.Run("persist", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		cmd := redis.MustParse(ParseHPersist, "hpersist person fields 3 name age city")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{1, -1, -2})
+		testx.AssertEqual(t, conn.Out(), "3,1,-1,-2")
+
+		exp, _ := db.Hash().Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime, (*int64)(nil))
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHPersist, "hpersist person fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{-2})
+		testx.AssertEqual(t, conn.Out(), "1,-2")
+	})
+}
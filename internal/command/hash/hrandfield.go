@@ -0,0 +1,76 @@
+package hash
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns one or more random fields from a hash.
+// HRANDFIELD key [count [WITHVALUES]]
+// https://redis.io/commands/hrandfield
+type HRandField struct {
+	redis.BaseCmd
+	key        string
+	count      int
+	withCount  bool
+	withValues bool
+}
+
+func ParseHRandField(b redis.BaseCmd) (HRandField, error) {
+	cmd := HRandField{BaseCmd: b}
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.count),
+		parser.Flag("withvalues", &cmd.withValues),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return HRandField{}, err
+	}
+	cmd.withCount = len(cmd.Args()) >= 2
+	if cmd.withValues && len(cmd.Args()) != 3 {
+		// WITHVALUES requires a count.
+		return HRandField{}, redis.ErrSyntaxError
+	}
+	return cmd, nil
+}
+
+func (cmd HRandField) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if cmd.withCount {
+		return cmd.runMany(w, red)
+	}
+	item, err := red.Hash().Random(cmd.key)
+	if err == core.ErrNotFound {
+		w.WriteNull()
+		return nil, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteBulkString(item.Field)
+	return item.Field, nil
+}
+
+// runMany returns count fields. Positive count returns distinct
+// fields, negative count allows the same field multiple times.
+func (cmd HRandField) runMany(w redis.Writer, red redis.Redka) (any, error) {
+	items, err := red.Hash().RandomMany(cmd.key, cmd.count)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	if cmd.withValues {
+		w.WriteArray(len(items) * 2)
+		for _, it := range items {
+			w.WriteBulkString(it.Field)
+			w.WriteBulk(it.Value)
+		}
+		return items, nil
+	}
+	w.WriteArray(len(items))
+	for _, it := range items {
+		w.WriteBulkString(it.Field)
+	}
+	return items, nil
+}
@@ -0,0 +1,129 @@
+package hash
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rhash"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHRandFieldParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HRandField
+		err  error
+	}{
+		{
+			cmd:  "hrandfield",
+			want: HRandField{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hrandfield person",
+			want: HRandField{key: "person"},
+			err:  nil,
+		},
+		{
+			cmd:  "hrandfield person 5",
+			want: HRandField{key: "person", count: 5, withCount: true},
+			err:  nil,
+		},
+		{
+			cmd:  "hrandfield person -5 withvalues",
+			want: HRandField{key: "person", count: -5, withCount: true, withValues: true},
+			err:  nil,
+		},
+		{
+			cmd:  "hrandfield person withvalues",
+			want: HRandField{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "hrandfield person 5 withvalues name",
+			want: HRandField{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHRandField, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.count, test.want.count)
+				testx.AssertEqual(t, cmd.withCount, test.want.withCount)
+				testx.AssertEqual(t, cmd.withValues, test.want.withValues)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHRandFieldExec(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(ParseHRandField, "hrandfield person")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		field := res.(string)
+		testx.AssertEqual(t, field == "name" || field == "age", true)
+		testx.AssertEqual(t, conn.Out(), field)
+	})
+	t.Run("positive count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(ParseHRandField, "hrandfield person 5")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		items := res.([]rhash.HashItem)
+		fields := []string{items[0].Field, items[1].Field}
+		slices.Sort(fields)
+		testx.AssertEqual(t, fields, []string{"age", "name"})
+		testx.AssertEqual(t, len(conn.Out()), len("2,name,age"))
+	})
+	t.Run("negative count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHRandField, "hrandfield person -3 withvalues")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(res.([]rhash.HashItem)), 3)
+		testx.AssertEqual(t, conn.Out(), "6,name,alice,name,alice,name,alice")
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHRandField, "hrandfield person")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), "(nil)")
+
+		cmd = redis.MustParse(ParseHRandField, "hrandfield person 3")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+}
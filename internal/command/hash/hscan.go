@@ -6,14 +6,15 @@ import (
 )
 
 // Iterates over fields and values of a hash.
-// HSCAN key cursor [MATCH pattern] [COUNT count]
+// HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]
 // https://redis.io/commands/hscan
 type HScan struct {
 	redis.BaseCmd
-	key    string
-	cursor int
-	match  string
-	count  int
+	key      string
+	cursor   int
+	match    string
+	count    int
+	noValues bool
 }
 
 func ParseHScan(b redis.BaseCmd) (HScan, error) {
@@ -24,6 +25,7 @@ func ParseHScan(b redis.BaseCmd) (HScan, error) {
 		parser.Int(&cmd.cursor),
 		parser.Named("match", parser.String(&cmd.match)),
 		parser.Named("count", parser.Int(&cmd.count)),
+		parser.Flag("novalues", &cmd.noValues),
 	).Required(2).Run(cmd.Args())
 	if err != nil {
 		return HScan{}, err
@@ -46,6 +48,13 @@ func (cmd HScan) Run(w redis.Writer, red redis.Redka) (any, error) {
 
 	w.WriteArray(2)
 	w.WriteInt(res.Cursor)
+	if cmd.noValues {
+		w.WriteArray(len(res.Items))
+		for _, it := range res.Items {
+			w.WriteBulkString(it.Field)
+		}
+		return res, nil
+	}
 	w.WriteArray(len(res.Items) * 2)
 	for _, it := range res.Items {
 		w.WriteBulkString(it.Field)
@@ -11,12 +11,13 @@ import (
 
 func TestHScanParse(t *testing.T) {
 	tests := []struct {
-		cmd    string
-		key    string
-		cursor int
-		match  string
-		count  int
-		err    error
+		cmd      string
+		key      string
+		cursor   int
+		match    string
+		count    int
+		noValues bool
+		err      error
 	}{
 		{
 			cmd:    "hscan",
@@ -74,6 +75,15 @@ func TestHScanParse(t *testing.T) {
 			count:  5,
 			err:    nil,
 		},
+		{
+			cmd:      "hscan person 15 match k2* count 5 novalues",
+			key:      "person",
+			cursor:   15,
+			match:    "k2*",
+			count:    5,
+			noValues: true,
+			err:      nil,
+		},
 		{
 			cmd:    "hscan person ten",
 			key:    "",
@@ -109,6 +119,7 @@ func TestHScanParse(t *testing.T) {
 				testx.AssertEqual(t, cmd.cursor, test.cursor)
 				testx.AssertEqual(t, cmd.match, test.match)
 				testx.AssertEqual(t, cmd.count, test.count)
+				testx.AssertEqual(t, cmd.noValues, test.noValues)
 			} else {
 				testx.AssertEqual(t, cmd, HScan{})
 			}
@@ -238,4 +249,16 @@ func TestHScanExec(t *testing.T) {
 			testx.AssertEqual(t, conn.Out(), "2,0,0")
 		}
 	})
+	t.Run("hscan novalues", func(t *testing.T) {
+		cmd := redis.MustParse(ParseHScan, "hscan key 0 match f2* novalues")
+		conn := redis.NewFakeConn()
+
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+
+		sres := res.(rhash.ScanResult)
+		testx.AssertEqual(t, sres.Cursor, 4)
+		testx.AssertEqual(t, len(sres.Items), 2)
+		testx.AssertEqual(t, conn.Out(), "2,4,2,f21,f22")
+	})
 }
@@ -0,0 +1,104 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sets the values of fields and optionally their expiration time.
+// HSETEX key [FNX | FXX] [EX seconds | PX milliseconds |
+// EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]
+// FIELDS numfields field value [field value ...]
+// https://redis.io/commands/hsetex
+type HSetEx struct {
+	redis.BaseCmd
+	key     string
+	items   map[string]any
+	ifFNX   bool
+	ifFXX   bool
+	ttl     time.Duration
+	at      time.Time
+	keepTTL bool
+}
+
+func ParseHSetEx(b redis.BaseCmd) (HSetEx, error) {
+	cmd := HSetEx{BaseCmd: b}
+
+	var ttlSec, ttlMs, atSec, atMs, nFields int
+	var pairs []string
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.OneOf(
+			parser.Flag("fnx", &cmd.ifFNX),
+			parser.Flag("fxx", &cmd.ifFXX),
+		),
+		parser.OneOf(
+			parser.Named("ex", parser.Int(&ttlSec)),
+			parser.Named("px", parser.Int(&ttlMs)),
+			parser.Named("exat", parser.Int(&atSec)),
+			parser.Named("pxat", parser.Int(&atMs)),
+			parser.Flag("keepttl", &cmd.keepTTL),
+		),
+		parser.Named("fields",
+			parser.Int(&nFields),
+			parser.Strings(&pairs),
+		),
+	).Required(5).Run(cmd.Args())
+	if err != nil {
+		return HSetEx{}, err
+	}
+	if nFields <= 0 || len(pairs) != nFields*2 {
+		return HSetEx{}, redis.ErrInvalidArgNum
+	}
+	cmd.items = make(map[string]any, nFields)
+	for i := 0; i < len(pairs); i += 2 {
+		cmd.items[pairs[i]] = []byte(pairs[i+1])
+	}
+
+	// Set the expiration time.
+	if ttlSec < 0 || ttlMs < 0 || atSec < 0 || atMs < 0 {
+		return HSetEx{}, redis.ErrInvalidExpireTime
+	}
+	if ttlSec > 0 {
+		cmd.ttl = time.Duration(ttlSec) * time.Second
+	} else if ttlMs > 0 {
+		cmd.ttl = time.Duration(ttlMs) * time.Millisecond
+	} else if atSec > 0 {
+		cmd.at = time.Unix(int64(atSec), 0)
+	} else if atMs > 0 {
+		cmd.at = time.UnixMilli(int64(atMs))
+	}
+
+	return cmd, nil
+}
+
+func (cmd HSetEx) Run(w redis.Writer, red redis.Redka) (any, error) {
+	c := red.Hash().SetWith(cmd.key, cmd.items)
+	if cmd.ifFNX {
+		c = c.IfNoneExist()
+	}
+	if cmd.ifFXX {
+		c = c.IfAllExist()
+	}
+	if cmd.ttl > 0 {
+		c = c.TTL(cmd.ttl)
+	} else if !cmd.at.IsZero() {
+		c = c.At(cmd.at)
+	} else if cmd.keepTTL {
+		c = c.KeepTTL()
+	}
+
+	ok, err := c.Run()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	if ok {
+		w.WriteInt(1)
+	} else {
+		w.WriteInt(0)
+	}
+	return ok, nil
+}
@@ -0,0 +1,165 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHSetExParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HSetEx
+		err  error
+	}{
+		{
+			cmd:  "hsetex",
+			want: HSetEx{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hsetex person fields 1 name",
+			want: HSetEx{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hsetex person fields 1 name alice",
+			want: HSetEx{key: "person", items: map[string]any{"name": []byte("alice")}},
+			err:  nil,
+		},
+		{
+			cmd: "hsetex person fnx ex 60 fields 2 name alice age 25",
+			want: HSetEx{key: "person", ifFNX: true, ttl: 60 * time.Second,
+				items: map[string]any{"name": []byte("alice"), "age": []byte("25")}},
+			err: nil,
+		},
+		{
+			cmd: "hsetex person fxx keepttl fields 1 name alice",
+			want: HSetEx{key: "person", ifFXX: true, keepTTL: true,
+				items: map[string]any{"name": []byte("alice")}},
+			err: nil,
+		},
+		{
+			cmd: "hsetex person pxat 1700000000000 fields 1 name alice",
+			want: HSetEx{key: "person", at: time.UnixMilli(1700000000000),
+				items: map[string]any{"name": []byte("alice")}},
+			err: nil,
+		},
+		{
+			cmd:  "hsetex person fnx fxx fields 1 name alice",
+			want: HSetEx{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "hsetex person fields 2 name alice",
+			want: HSetEx{},
+			err:  redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHSetEx, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.items, test.want.items)
+				testx.AssertEqual(t, cmd.ifFNX, test.want.ifFNX)
+				testx.AssertEqual(t, cmd.ifFXX, test.want.ifFXX)
+				testx.AssertEqual(t, cmd.ttl, test.want.ttl)
+				testx.AssertEqual(t, cmd.at, test.want.at)
+				testx.AssertEqual(t, cmd.keepTTL, test.want.keepTTL)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHSetExExec(t *testing.T) {
+	t.Run("set with ttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHSetEx, "hsetex person ex 60 fields 2 name alice age 25")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		name, _ := db.Hash().Get("person", "name")
+		testx.AssertEqual(t, name.String(), "alice")
+		expireAt := time.Now().Add(60 * time.Second)
+		exp, _ := db.Hash().Expiry("person", "name", "age")
+		testx.AssertEqual(t, *exp[0].ETime/1000, expireAt.UnixMilli()/1000)
+		testx.AssertEqual(t, *exp[1].ETime/1000, expireAt.UnixMilli()/1000)
+	})
+	t.Run("keepttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		cmd := redis.MustParse(ParseHSetEx, "hsetex person keepttl fields 1 name bob")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+
+		name, _ := db.Hash().Get("person", "name")
+		testx.AssertEqual(t, name.String(), "bob")
+		exp, _ := db.Hash().Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime != nil, true)
+	})
+	t.Run("fnx", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHSetEx, "hsetex person fnx fields 2 name bob age 25")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+
+		name, _ := db.Hash().Get("person", "name")
+		testx.AssertEqual(t, name.String(), "alice")
+		exists, _ := db.Hash().Exists("person", "age")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("fxx", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		cmd := redis.MustParse(ParseHSetEx, "hsetex person fxx fields 2 name bob age 30")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		age, _ := db.Hash().Get("person", "age")
+		testx.AssertEqual(t, age.String(), "30")
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("person", "alice")
+
+		cmd := redis.MustParse(ParseHSetEx, "hsetex person fields 1 name alice")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, core.ErrKeyType)
+		testx.AssertEqual(t, conn.Out(), core.ErrKeyType.Error()+" (hsetex)")
+	})
+}
@@ -0,0 +1,39 @@
+package hash
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the length of the value of a field in a hash.
+// HSTRLEN key field
+// https://redis.io/commands/hstrlen
+type HStrLen struct {
+	redis.BaseCmd
+	key   string
+	field string
+}
+
+func ParseHStrLen(b redis.BaseCmd) (HStrLen, error) {
+	cmd := HStrLen{BaseCmd: b}
+	if len(cmd.Args()) != 2 {
+		return HStrLen{}, redis.ErrInvalidArgNum
+	}
+	cmd.key = string(cmd.Args()[0])
+	cmd.field = string(cmd.Args()[1])
+	return cmd, nil
+}
+
+func (cmd HStrLen) Run(w redis.Writer, red redis.Redka) (any, error) {
+	val, err := red.Hash().Get(cmd.key, cmd.field)
+	if err == core.ErrNotFound {
+		w.WriteInt(0)
+		return 0, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteInt(len(val))
+	return len(val), nil
+}
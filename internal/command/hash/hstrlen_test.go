@@ -0,0 +1,90 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHStrLenParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HStrLen
+		err  error
+	}{
+		{
+			cmd:  "hstrlen",
+			want: HStrLen{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hstrlen person",
+			want: HStrLen{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "hstrlen person name",
+			want: HStrLen{key: "person", field: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "hstrlen person name age",
+			want: HStrLen{},
+			err:  redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseHStrLen, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.field, test.want.field)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHStrLenExec(t *testing.T) {
+	t.Run("field found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHStrLen, "hstrlen person name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 5)
+		testx.AssertEqual(t, conn.Out(), "5")
+	})
+	t.Run("field not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseHStrLen, "hstrlen person age")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseHStrLen, "hstrlen person name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+}
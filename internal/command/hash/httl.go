@@ -0,0 +1,66 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rhash"
+)
+
+// Returns the time-to-live of hash fields in seconds.
+// HTTL key FIELDS numfields field [field ...]
+// https://redis.io/commands/httl
+type HTTL struct {
+	redis.BaseCmd
+	key    string
+	fields []string
+	unit   int
+}
+
+func ParseHTTL(b redis.BaseCmd, unit int) (HTTL, error) {
+	cmd := HTTL{BaseCmd: b, unit: unit}
+	var nFields int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parseFields(&cmd.fields, &nFields),
+	).Required(4).Run(cmd.Args())
+	if err != nil {
+		return HTTL{}, err
+	}
+	if len(cmd.fields) == 0 {
+		return HTTL{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd HTTL) Run(w redis.Writer, red redis.Redka) (any, error) {
+	expiry, err := red.Hash().Expiry(cmd.key, cmd.fields...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	unit := int64(cmd.unit)
+	res := make([]int, len(expiry))
+	for i, exp := range expiry {
+		res[i] = expiryCode(exp, func(etime int64) int {
+			return int(etime/unit - now/unit)
+		})
+	}
+	writeInts(w, res)
+	return res, nil
+}
+
+// expiryCode returns -2 if the field does not exist,
+// -1 if it has no expiration time, or the result of conv otherwise.
+func expiryCode(exp rhash.Expiry, conv func(etime int64) int) int {
+	if !exp.Exists {
+		return rhash.FieldNotFound
+	}
+	if exp.ETime == nil {
+		return rhash.FieldNoTTL
+	}
+	return conv(*exp.ETime)
+}
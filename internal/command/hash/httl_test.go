@@ -0,0 +1,111 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestHTTLParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want HTTL
+		err  error
+	}{
+		{
+			cmd:  "httl",
+			want: HTTL{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "httl person",
+			want: HTTL{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "httl person fields 1 name",
+			want: HTTL{key: "person", fields: []string{"name"}},
+			err:  nil,
+		},
+		{
+			cmd:  "httl person fields 2 name age",
+			want: HTTL{key: "person", fields: []string{"name", "age"}},
+			err:  nil,
+		},
+		{
+			cmd:  "httl person name age city",
+			want: HTTL{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (HTTL, error) {
+		return ParseHTTL(b, 1000)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.fields, test.want.fields)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestHTTLExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (HTTL, error) {
+		return ParseHTTL(b, 1000)
+	}
+	t.Run("ttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(60 * time.Second).Run()
+
+		cmd := redis.MustParse(parse, "httl person fields 3 name age city")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{60, -1, -2})
+		testx.AssertEqual(t, conn.Out(), "3,60,-1,-2")
+	})
+	t.Run("pttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().ExpireWith("person", "name").TTL(60 * time.Second).Run()
+
+		parse := func(b redis.BaseCmd) (HTTL, error) {
+			return ParseHTTL(b, 1)
+		}
+		cmd := redis.MustParse(parse, "hpttl person fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		ttl := res.([]int)[0]
+		if ttl < 59000 || ttl > 60000 {
+			t.Errorf("unexpected ttl %d", ttl)
+		}
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(parse, "httl person fields 1 name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{-2})
+		testx.AssertEqual(t, conn.Out(), "1,-2")
+	})
+}
@@ -0,0 +1,52 @@
+package key
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Copies the value of a key to a new key.
+// COPY source destination [REPLACE]
+// https://redis.io/commands/copy
+type Copy struct {
+	redis.BaseCmd
+	src     string
+	dst     string
+	replace bool
+}
+
+func ParseCopy(b redis.BaseCmd) (Copy, error) {
+	cmd := Copy{BaseCmd: b}
+	err := parser.New(
+		parser.String(&cmd.src),
+		parser.String(&cmd.dst),
+		parser.Flag("replace", &cmd.replace),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return Copy{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd Copy) Run(w redis.Writer, red redis.Redka) (any, error) {
+	ok, err := red.Key().Copy(cmd.src, cmd.dst, cmd.replace)
+	if err == core.ErrNotFound {
+		w.WriteInt(0)
+		return false, nil
+	}
+	if err == core.ErrNotAllowed {
+		w.WriteError(cmd.Error(redis.ErrSameObject))
+		return nil, redis.ErrSameObject
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	if !ok {
+		w.WriteInt(0)
+		return false, nil
+	}
+	w.WriteInt(1)
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestCopyParse(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		src     string
+		dst     string
+		replace bool
+		err     error
+	}{
+		{
+			cmd: "copy",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "copy name",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "copy name title",
+			src: "name",
+			dst: "title",
+			err: nil,
+		},
+		{
+			cmd:     "copy name title replace",
+			src:     "name",
+			dst:     "title",
+			replace: true,
+			err:     nil,
+		},
+		{
+			cmd: "copy name title age",
+			err: redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseCopy, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.src, test.src)
+				testx.AssertEqual(t, cmd.dst, test.dst)
+				testx.AssertEqual(t, cmd.replace, test.replace)
+			} else {
+				testx.AssertEqual(t, cmd, Copy{})
+			}
+		})
+	}
+}
+
+func TestCopyExec(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseCopy, "copy person user")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		name, _ := db.Hash().Get("user", "name")
+		testx.AssertEqual(t, name.String(), "alice")
+		n, _ := db.Hash().Len("user")
+		testx.AssertEqual(t, n, 1)
+	})
+
+	t.Run("dst exists", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("title", "bob")
+
+		cmd := redis.MustParse(ParseCopy, "copy name title")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "bob")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.List().PushBack("title", "bob")
+
+		cmd := redis.MustParse(ParseCopy, "copy name title replace")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "alice")
+	})
+
+	t.Run("src not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseCopy, "copy name title")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+
+	t.Run("same key", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseCopy, "copy name name")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrSameObject)
+		testx.AssertEqual(t, conn.Out(), redis.ErrSameObject.Error()+" (copy)")
+	})
+}
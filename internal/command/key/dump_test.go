@@ -0,0 +1,74 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestDumpParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		key string
+		err error
+	}{
+		{
+			cmd: "dump",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "dump name",
+			key: "name",
+			err: nil,
+		},
+		{
+			cmd: "dump name age",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseDump, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.key)
+			} else {
+				testx.AssertEqual(t, cmd, Dump{})
+			}
+		})
+	}
+}
+
+func TestDumpExec(t *testing.T) {
+	t.Run("dump", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseDump, "dump name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+
+		want, _ := db.Key().Dump("name")
+		testx.AssertEqual(t, res, want)
+		testx.AssertEqual(t, conn.Out(), string(want))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseDump, "dump name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), "(nil)")
+	})
+}
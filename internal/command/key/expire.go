@@ -6,15 +6,17 @@ import (
 	"github.com/flarco/redka/internal/core"
 	"github.com/flarco/redka/internal/parser"
 	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rkey"
 )
 
 // Sets the expiration time of a key in seconds.
-// EXPIRE key seconds
+// EXPIRE key seconds [NX | XX | GT | LT]
 // https://redis.io/commands/expire
 type Expire struct {
 	redis.BaseCmd
-	key string
-	ttl time.Duration
+	key  string
+	ttl  time.Duration
+	cond expireCond
 }
 
 func ParseExpire(b redis.BaseCmd, multi int) (Expire, error) {
@@ -24,6 +26,7 @@ func ParseExpire(b redis.BaseCmd, multi int) (Expire, error) {
 	err := parser.New(
 		parser.String(&cmd.key),
 		parser.Int(&ttl),
+		cmd.cond.parser(),
 	).Required(2).Run(cmd.Args())
 	if err != nil {
 		return Expire{}, err
@@ -34,15 +37,72 @@ func ParseExpire(b redis.BaseCmd, multi int) (Expire, error) {
 }
 
 func (cmd Expire) Run(w redis.Writer, red redis.Redka) (any, error) {
-	err := red.Key().Expire(cmd.key, cmd.ttl)
-	if err != nil && err != core.ErrNotFound {
+	err := red.Key().Expire(cmd.key, cmd.ttl, cmd.cond.conds()...)
+	if err != nil && err != core.ErrNotFound && err != core.ErrNotAllowed {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	if err == core.ErrNotFound {
+	if err != nil {
 		w.WriteInt(0)
 		return false, nil
 	}
 	w.WriteInt(1)
 	return true, nil
 }
+
+// expireCond is a condition for setting
+// the expiration time of a key.
+type expireCond struct {
+	nx, xx, gt, lt bool
+}
+
+// parser returns a parser for the NX | XX | GT | LT arguments.
+// The flags may be combined, except for NX with any other flag,
+// and GT with LT.
+func (c *expireCond) parser() parser.ParserFunc {
+	flags := []parser.ParserFunc{
+		parser.Flag("nx", &c.nx),
+		parser.Flag("xx", &c.xx),
+		parser.Flag("gt", &c.gt),
+		parser.Flag("lt", &c.lt),
+	}
+	return func(args [][]byte) (bool, [][]byte, error) {
+		fired := false
+		for len(args) > 0 {
+			n := len(args)
+			for _, flag := range flags {
+				var ok bool
+				if ok, args, _ = flag(args); ok {
+					break
+				}
+			}
+			if len(args) == n {
+				break
+			}
+			fired = true
+		}
+		if c.nx && (c.xx || c.gt || c.lt) || c.gt && c.lt {
+			return true, args, parser.ErrSyntaxError
+		}
+		return fired, args, nil
+	}
+}
+
+// conds returns the conditions as a list
+// suitable for the rkey expire methods.
+func (c expireCond) conds() []rkey.ExpireCond {
+	var conds []rkey.ExpireCond
+	if c.nx {
+		conds = append(conds, rkey.IfNoTTL)
+	}
+	if c.xx {
+		conds = append(conds, rkey.IfHasTTL)
+	}
+	if c.gt {
+		conds = append(conds, rkey.IfGreater)
+	}
+	if c.lt {
+		conds = append(conds, rkey.IfLess)
+	}
+	return conds
+}
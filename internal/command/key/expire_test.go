@@ -39,6 +39,42 @@ func TestExpireParse(t *testing.T) {
 			ttl: 0,
 			err: redis.ErrInvalidInt,
 		},
+		{
+			cmd: "expire name 60 nx",
+			key: "name",
+			ttl: 60 * 1000 * time.Millisecond,
+			err: nil,
+		},
+		{
+			cmd: "expire name 60 nx xx",
+			key: "",
+			ttl: 0,
+			err: redis.ErrSyntaxError,
+		},
+		{
+			cmd: "expire name 60 xx gt",
+			key: "name",
+			ttl: 60 * 1000 * time.Millisecond,
+			err: nil,
+		},
+		{
+			cmd: "expire name 60 lt xx",
+			key: "name",
+			ttl: 60 * 1000 * time.Millisecond,
+			err: nil,
+		},
+		{
+			cmd: "expire name 60 nx gt",
+			key: "",
+			ttl: 0,
+			err: redis.ErrSyntaxError,
+		},
+		{
+			cmd: "expire name 60 gt lt",
+			key: "",
+			ttl: 0,
+			err: redis.ErrSyntaxError,
+		},
 		{
 			cmd: "expire name 60 age 60",
 			key: "",
@@ -155,4 +191,76 @@ func TestExpireExec(t *testing.T) {
 		key, _ := db.Key().Get("age")
 		testx.AssertEqual(t, key.Exists(), false)
 	})
+
+	t.Run("nx", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+		_ = db.Str().Set("age", 25)
+
+		cmd := redis.MustParse(parse, "expire name 30 nx")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+
+		cmd = redis.MustParse(parse, "expire age 30 nx")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+	})
+
+	t.Run("gt", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+
+		cmd := redis.MustParse(parse, "expire name 30 gt")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+
+		cmd = redis.MustParse(parse, "expire name 90 gt")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+
+		expireAt := time.Now().Add(90 * time.Second)
+		key, _ := db.Key().Get("name")
+		testx.AssertEqual(t, *key.ETime/1000, expireAt.UnixMilli()/1000)
+	})
+
+	t.Run("xx gt", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+		_ = db.Str().Set("age", 25)
+
+		cmd := redis.MustParse(parse, "expire age 90 xx gt")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+
+		cmd = redis.MustParse(parse, "expire name 30 xx gt")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+
+		cmd = redis.MustParse(parse, "expire name 90 xx gt")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+	})
 }
@@ -9,12 +9,13 @@ import (
 )
 
 // Sets the expiration time of a key to a Unix timestamp.
-// EXPIREAT key unix-time-seconds
+// EXPIREAT key unix-time-seconds [NX | XX | GT | LT]
 // https://redis.io/commands/expireat
 type ExpireAt struct {
 	redis.BaseCmd
-	key string
-	at  time.Time
+	key  string
+	at   time.Time
+	cond expireCond
 }
 
 func ParseExpireAt(b redis.BaseCmd, multi int) (ExpireAt, error) {
@@ -24,6 +25,7 @@ func ParseExpireAt(b redis.BaseCmd, multi int) (ExpireAt, error) {
 	err := parser.New(
 		parser.String(&cmd.key),
 		parser.Int(&at),
+		cmd.cond.parser(),
 	).Required(2).Run(cmd.Args())
 	if err != nil {
 		return ExpireAt{}, err
@@ -34,12 +36,12 @@ func ParseExpireAt(b redis.BaseCmd, multi int) (ExpireAt, error) {
 }
 
 func (cmd ExpireAt) Run(w redis.Writer, red redis.Redka) (any, error) {
-	err := red.Key().ExpireAt(cmd.key, cmd.at)
-	if err != nil && err != core.ErrNotFound {
+	err := red.Key().ExpireAt(cmd.key, cmd.at, cmd.cond.conds()...)
+	if err != nil && err != core.ErrNotFound && err != core.ErrNotAllowed {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	if err == core.ErrNotFound {
+	if err != nil {
 		w.WriteInt(0)
 		return false, nil
 	}
@@ -0,0 +1,43 @@
+package key
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the expiration time of a key as a Unix timestamp in seconds.
+// EXPIRETIME key
+// https://redis.io/commands/expiretime
+type ExpireTime struct {
+	redis.BaseCmd
+	key  string
+	unit int
+}
+
+func ParseExpireTime(b redis.BaseCmd, unit int) (ExpireTime, error) {
+	cmd := ExpireTime{BaseCmd: b, unit: unit}
+	if len(cmd.Args()) != 1 {
+		return ExpireTime{}, redis.ErrInvalidArgNum
+	}
+	cmd.key = string(cmd.Args()[0])
+	return cmd, nil
+}
+
+func (cmd ExpireTime) Run(w redis.Writer, red redis.Redka) (any, error) {
+	k, err := red.Key().Get(cmd.key)
+	if err == core.ErrNotFound {
+		w.WriteInt(-2)
+		return -2, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	if k.ETime == nil {
+		w.WriteInt(-1)
+		return -1, nil
+	}
+	at := int(*k.ETime / int64(cmd.unit))
+	w.WriteInt(at)
+	return at, nil
+}
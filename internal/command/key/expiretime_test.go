@@ -0,0 +1,105 @@
+package key
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestExpireTimeParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		key string
+		err error
+	}{
+		{
+			cmd: "expiretime",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "expiretime name",
+			key: "name",
+			err: nil,
+		},
+		{
+			cmd: "expiretime name age",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (ExpireTime, error) {
+		return ParseExpireTime(b, 1000)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.key)
+			} else {
+				testx.AssertEqual(t, cmd, ExpireTime{})
+			}
+		})
+	}
+}
+
+func TestExpireTimeExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (ExpireTime, error) {
+		return ParseExpireTime(b, 1000)
+	}
+	pparse := func(b redis.BaseCmd) (ExpireTime, error) {
+		return ParseExpireTime(b, 1)
+	}
+
+	t.Run("has ttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		at := time.Now().Add(60 * time.Second)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Key().ExpireAt("name", at)
+
+		cmd := redis.MustParse(parse, "expiretime name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, int(at.Unix()))
+
+		cmd = redis.MustParse(pparse, "pexpiretime name")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, int(at.UnixMilli()))
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(parse, "expiretime name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, -1)
+		testx.AssertEqual(t, conn.Out(), "-1")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(parse, "expiretime name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, -2)
+		testx.AssertEqual(t, conn.Out(), "-2")
+	})
+}
@@ -0,0 +1,28 @@
+package key
+
+import "github.com/flarco/redka/internal/redis"
+
+// Remove all keys from all databases.
+// FLUSHALL
+// https://redis.io/commands/flushall
+type FlushAll struct {
+	redis.BaseCmd
+}
+
+func ParseFlushAll(b redis.BaseCmd) (FlushAll, error) {
+	cmd := FlushAll{BaseCmd: b}
+	if len(cmd.Args()) != 0 {
+		return FlushAll{}, redis.ErrSyntaxError
+	}
+	return cmd, nil
+}
+
+func (cmd FlushAll) Run(w redis.Writer, red redis.Redka) (any, error) {
+	err := red.Key().DeleteAllDatabases()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
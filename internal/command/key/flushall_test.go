@@ -0,0 +1,62 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestFlushAllParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		err error
+	}{
+		{
+			cmd: "flushall",
+			err: nil,
+		},
+		{
+			cmd: "flushall name",
+			err: redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseFlushAll, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err != nil {
+				testx.AssertEqual(t, cmd, FlushAll{})
+			}
+		})
+	}
+}
+
+func TestFlushAllExec(t *testing.T) {
+	db, red := getDB(t)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Select(1).Str().Set("name", "bob")
+
+	// FLUSHDB only removes the keys of the selected database.
+	cmd := redis.MustParse(ParseFlushDB, "flushdb")
+	_, err := cmd.Run(redis.NewFakeConn(), red)
+	testx.AssertNoErr(t, err)
+	count, _ := db.Select(1).Key().Len()
+	testx.AssertEqual(t, count, 1)
+
+	_ = db.Str().Set("name", "alice")
+	allCmd := redis.MustParse(ParseFlushAll, "flushall")
+	conn := redis.NewFakeConn()
+	res, err := allCmd.Run(conn, red)
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, res, true)
+	testx.AssertEqual(t, conn.Out(), "OK")
+
+	count, _ = db.Key().Len()
+	testx.AssertEqual(t, count, 0)
+	count, _ = db.Select(1).Key().Len()
+	testx.AssertEqual(t, count, 0)
+}
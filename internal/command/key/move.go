@@ -0,0 +1,54 @@
+package key
+
+import (
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Moves a key to another database.
+// MOVE key db
+// https://redis.io/commands/move
+type Move struct {
+	redis.BaseCmd
+	key string
+	db  int
+}
+
+func ParseMove(b redis.BaseCmd) (Move, error) {
+	cmd := Move{BaseCmd: b}
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.db),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return Move{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd Move) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if cmd.db < 0 || cmd.db >= redis.Databases {
+		w.WriteError(cmd.Error(redis.ErrDBIndex))
+		return nil, redis.ErrDBIndex
+	}
+	if cmd.db == redis.SelectedDB(w) {
+		w.WriteError(cmd.Error(redis.ErrSameObject))
+		return nil, redis.ErrSameObject
+	}
+	ok, err := red.Key().Move(cmd.key, cmd.db)
+	if err == core.ErrNotFound {
+		w.WriteInt(0)
+		return false, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	if !ok {
+		w.WriteInt(0)
+		return false, nil
+	}
+	w.WriteInt(1)
+	return true, nil
+}
@@ -0,0 +1,129 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestMoveParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Move
+		err  error
+	}{
+		{
+			cmd:  "move",
+			want: Move{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "move name",
+			want: Move{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "move name 1",
+			want: Move{key: "name", db: 1},
+			err:  nil,
+		},
+		{
+			cmd:  "move name one",
+			want: Move{},
+			err:  redis.ErrInvalidInt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseMove, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.db, test.want.db)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestMoveExec(t *testing.T) {
+	t.Run("move", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseMove, "move name 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		exists, _ := db.Key().Exists("name")
+		testx.AssertEqual(t, exists, false)
+		name, _ := db.Select(1).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("target exists", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(1).Str().Set("name", "bob")
+
+		cmd := redis.MustParse(ParseMove, "move name 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseMove, "move name 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, false)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+	t.Run("same database", func(t *testing.T) {
+		db, _ := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseMove, "move name 2")
+		conn := redis.NewFakeConn()
+		conn.SetDB(2)
+		res, err := cmd.Run(conn, redis.RedkaDB(db.Select(2)))
+		testx.AssertErr(t, err, redis.ErrSameObject)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrSameObject.Error()+" (move)")
+	})
+	t.Run("out of range", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseMove, "move name 16")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrDBIndex)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrDBIndex.Error()+" (move)")
+
+		exists, _ := db.Key().Exists("name")
+		testx.AssertEqual(t, exists, true)
+	})
+}
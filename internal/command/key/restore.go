@@ -0,0 +1,76 @@
+package key
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Creates a key from the serialized representation of a value.
+// RESTORE key ttl serialized-value [REPLACE] [ABSTTL]
+// [IDLETIME seconds] [FREQ frequency]
+// https://redis.io/commands/restore
+//
+// The serialized value includes the expiration time of the dumped key.
+// A zero ttl keeps that time, a positive one overrides it.
+// IDLETIME and FREQ are accepted but ignored, since Redka
+// does not track key access.
+type Restore struct {
+	redis.BaseCmd
+	key     string
+	ttl     int
+	data    []byte
+	replace bool
+	absTTL  bool
+}
+
+func ParseRestore(b redis.BaseCmd) (Restore, error) {
+	cmd := Restore{BaseCmd: b}
+	var idleTime, freq int
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.ttl),
+		parser.Bytes(&cmd.data),
+		parser.Flag("replace", &cmd.replace),
+		parser.Flag("absttl", &cmd.absTTL),
+		parser.Named("idletime", parser.Int(&idleTime)),
+		parser.Named("freq", parser.Int(&freq)),
+	).Required(3).Run(cmd.Args())
+	if err != nil {
+		return Restore{}, err
+	}
+	if cmd.ttl < 0 || idleTime < 0 || freq < 0 {
+		return Restore{}, redis.ErrInvalidExpireTime
+	}
+	return cmd, nil
+}
+
+func (cmd Restore) Run(w redis.Writer, red redis.Redka) (any, error) {
+	restore := red.Key().RestoreWith(cmd.key, cmd.data)
+	if cmd.replace {
+		restore = restore.Replace()
+	}
+	if cmd.ttl > 0 {
+		if cmd.absTTL {
+			restore = restore.At(time.UnixMilli(int64(cmd.ttl)))
+		} else {
+			restore = restore.TTL(time.Duration(cmd.ttl) * time.Millisecond)
+		}
+	}
+
+	err := restore.Run()
+	if err != nil {
+		switch err {
+		case core.ErrNotAllowed:
+			err = redis.ErrBusyKey
+		case core.ErrValueType:
+			err = redis.ErrInvalidDump
+		}
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
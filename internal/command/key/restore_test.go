@@ -0,0 +1,142 @@
+package key
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestRestoreParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Restore
+		err  error
+	}{
+		{
+			cmd:  "restore name 0",
+			want: Restore{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "restore name 0 data",
+			want: Restore{key: "name", ttl: 0, data: []byte("data")},
+			err:  nil,
+		},
+		{
+			cmd:  "restore name 1000 data replace absttl idletime 10 freq 5",
+			want: Restore{key: "name", ttl: 1000, data: []byte("data"), replace: true, absTTL: true},
+			err:  nil,
+		},
+		{
+			cmd:  "restore name -1 data",
+			want: Restore{},
+			err:  redis.ErrInvalidExpireTime,
+		},
+		{
+			cmd:  "restore name ttl data",
+			want: Restore{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "restore name 0 data other",
+			want: Restore{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseRestore, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.ttl, test.want.ttl)
+				testx.AssertEqual(t, cmd.data, test.want.data)
+				testx.AssertEqual(t, cmd.replace, test.want.replace)
+				testx.AssertEqual(t, cmd.absTTL, test.want.absTTL)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestRestoreExec(t *testing.T) {
+	// restoreCmd builds the command directly,
+	// since the dump may contain any bytes.
+	restoreCmd := func(t *testing.T, args ...[]byte) Restore {
+		args = append([][]byte{[]byte("restore")}, args...)
+		cmd, err := ParseRestore(redis.NewBaseCmd(args))
+		testx.AssertNoErr(t, err)
+		return cmd
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Hash().Set("person", "name", "alice")
+		data, _ := db.Key().Dump("person")
+
+		cmd := restoreCmd(t, []byte("user"), []byte("0"), data)
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		name, _ := db.Hash().Get("user", "name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		data, _ := db.Key().Dump("name")
+
+		cmd := restoreCmd(t, []byte("title"), []byte("60000"), data)
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+
+		key, _ := db.Key().Get("title")
+		testx.AssertEqual(t, *key.ETime/1000, time.Now().Add(60*time.Second).UnixMilli()/1000)
+	})
+
+	t.Run("busy key", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("title", "bob")
+		data, _ := db.Key().Dump("name")
+
+		cmd := restoreCmd(t, []byte("title"), []byte("0"), data)
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrBusyKey)
+		testx.AssertEqual(t, conn.Out(), redis.ErrBusyKey.Error()+" (restore)")
+
+		cmd = restoreCmd(t, []byte("title"), []byte("0"), data, []byte("replace"))
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "alice")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := restoreCmd(t, []byte("name"), []byte("0"), []byte("alice"))
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrInvalidDump)
+		testx.AssertEqual(t, conn.Out(), redis.ErrInvalidDump.Error()+" (restore)")
+	})
+}
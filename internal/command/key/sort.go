@@ -0,0 +1,139 @@
+package key
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Sorts the elements in a list, a set, or a sorted set,
+// optionally storing the result.
+// SORT key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]]
+// [ASC | DESC] [ALPHA] [STORE destination]
+// https://redis.io/commands/sort
+//
+// SORT_RO is the read-only variant that does not support STORE.
+// SORT_RO key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]]
+// [ASC | DESC] [ALPHA]
+// https://redis.io/commands/sort_ro
+type Sort struct {
+	redis.BaseCmd
+	key    string
+	by     string
+	offset int
+	count  int
+	get    []string
+	desc   bool
+	alpha  bool
+	dest   string
+}
+
+func ParseSort(b redis.BaseCmd, readOnly bool) (Sort, error) {
+	cmd := Sort{BaseCmd: b, count: -1}
+	var asc bool
+	parsers := []parser.ParserFunc{
+		parser.String(&cmd.key),
+		parser.Named("by", parser.String(&cmd.by)),
+		parser.Named("limit", parser.Int(&cmd.offset), parser.Int(&cmd.count)),
+		parser.NamedStrings("get", &cmd.get),
+		parser.OneOf(
+			parser.Flag("asc", &asc),
+			parser.Flag("desc", &cmd.desc),
+		),
+		parser.Flag("alpha", &cmd.alpha),
+	}
+	if !readOnly {
+		parsers = append(parsers, parser.Named("store", parser.String(&cmd.dest)))
+	}
+	err := parser.New(parsers...).Required(1).Run(cmd.Args())
+	if err != nil {
+		return Sort{}, err
+	}
+	return cmd, nil
+}
+
+// Keys returns the source key and the STORE destination, if any.
+func (cmd Sort) Keys() []string {
+	if cmd.dest != "" {
+		return []string{cmd.key, cmd.dest}
+	}
+	return []string{cmd.key}
+}
+
+// AllKeys reports whether the command may access any key
+// by the BY or GET patterns. The BY pattern without '*'
+// disables sorting, and the # GET pattern returns the
+// element itself, so these look up no keys.
+func (cmd Sort) AllKeys() bool {
+	if strings.Contains(cmd.by, "*") {
+		return true
+	}
+	for _, pattern := range cmd.get {
+		if pattern != "#" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWrite reports whether the command modifies the database,
+// which is only when it stores the result.
+func (cmd Sort) IsWrite() bool {
+	return cmd.dest != ""
+}
+
+// IsReadOnly reports whether the command only reads the database,
+// which is unless it stores the result.
+func (cmd Sort) IsReadOnly() bool {
+	return cmd.dest == ""
+}
+
+func (cmd Sort) Run(w redis.Writer, red redis.Redka) (any, error) {
+	sort := red.Key().SortWith(cmd.key).
+		By(cmd.by).
+		Get(cmd.get...).
+		Limit(cmd.offset, cmd.count)
+	if cmd.desc {
+		sort = sort.Desc()
+	}
+	if cmd.alpha {
+		sort = sort.Alpha()
+	}
+
+	// Store the result if requested.
+	if cmd.dest != "" {
+		n, err := sort.Store(cmd.dest)
+		if err != nil {
+			return nil, cmd.writeError(w, err)
+		}
+		w.WriteInt(n)
+		return n, nil
+	}
+
+	// Otherwise, return the result.
+	vals, err := sort.Run()
+	if err != nil {
+		return nil, cmd.writeError(w, err)
+	}
+	w.WriteArray(len(vals))
+	for _, val := range vals {
+		if val == nil {
+			w.WriteNull()
+		} else {
+			w.WriteBulk(val)
+		}
+	}
+	return vals, nil
+}
+
+// writeError writes the sort error, translating
+// the non-numeric weight error to the Redis one.
+func (cmd Sort) writeError(w redis.Writer, err error) error {
+	if err == core.ErrValueType {
+		err = redis.ErrInvalidSortScore
+	}
+	w.WriteError(cmd.Error(err))
+	return err
+}
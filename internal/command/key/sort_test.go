@@ -0,0 +1,185 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSortParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Sort
+		err  error
+	}{
+		{
+			cmd:  "sort",
+			want: Sort{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "sort ids",
+			want: Sort{key: "ids", count: -1},
+			err:  nil,
+		},
+		{
+			cmd: "sort ids by weight_* limit 0 10 get # get name_* desc alpha store dest",
+			want: Sort{
+				key: "ids", by: "weight_*", offset: 0, count: 10,
+				get: []string{"#", "name_*"}, desc: true, alpha: true, dest: "dest",
+			},
+			err: nil,
+		},
+		{
+			cmd:  "sort ids alpha asc",
+			want: Sort{key: "ids", count: -1, alpha: true},
+			err:  nil,
+		},
+		{
+			cmd:  "sort ids asc desc",
+			want: Sort{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "sort ids limit 10",
+			want: Sort{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "sort ids get",
+			want: Sort{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (Sort, error) {
+		return ParseSort(b, false)
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.by, test.want.by)
+				testx.AssertEqual(t, cmd.offset, test.want.offset)
+				testx.AssertEqual(t, cmd.count, test.want.count)
+				testx.AssertEqual(t, cmd.get, test.want.get)
+				testx.AssertEqual(t, cmd.desc, test.want.desc)
+				testx.AssertEqual(t, cmd.alpha, test.want.alpha)
+				testx.AssertEqual(t, cmd.dest, test.want.dest)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+
+	t.Run("write", func(t *testing.T) {
+		cmd := redis.MustParse(parse, "sort ids")
+		testx.AssertEqual(t, cmd.IsWrite(), false)
+		testx.AssertEqual(t, cmd.IsReadOnly(), true)
+		cmd = redis.MustParse(parse, "sort ids store dest")
+		testx.AssertEqual(t, cmd.IsWrite(), true)
+		testx.AssertEqual(t, cmd.IsReadOnly(), false)
+	})
+	t.Run("sort_ro store", func(t *testing.T) {
+		parse := func(b redis.BaseCmd) (Sort, error) {
+			return ParseSort(b, true)
+		}
+		_, err := redis.Parse(parse, "sort_ro ids store dest")
+		testx.AssertEqual(t, err, redis.ErrSyntaxError)
+	})
+	t.Run("keys", func(t *testing.T) {
+		tests := []struct {
+			cmd     string
+			keys    []string
+			allKeys bool
+		}{
+			{"sort ids", []string{"ids"}, false},
+			{"sort ids store dest", []string{"ids", "dest"}, false},
+			{"sort ids by nosort get #", []string{"ids"}, false},
+			{"sort ids by weight_*", []string{"ids"}, true},
+			{"sort ids get # get name_*", []string{"ids"}, true},
+		}
+		for _, test := range tests {
+			cmd := redis.MustParse(parse, test.cmd)
+			testx.AssertEqual(t, cmd.Keys(), test.keys)
+			testx.AssertEqual(t, cmd.AllKeys(), test.allKeys)
+		}
+	})
+}
+
+func TestSortExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (Sort, error) {
+		return ParseSort(b, false)
+	}
+
+	t.Run("sort", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.List().PushBack("ids", "3")
+		_, _ = db.List().PushBack("ids", "1")
+		_, _ = db.List().PushBack("ids", "2")
+
+		cmd := redis.MustParse(parse, "sort ids desc")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.([]core.Value), []core.Value{
+			core.Value("3"), core.Value("2"), core.Value("1"),
+		})
+		testx.AssertEqual(t, conn.Out(), "3,3,2,1")
+	})
+
+	t.Run("by and get", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Set().Add("ids", "1", "2", "3")
+		_ = db.Str().Set("weight_1", 30)
+		_ = db.Str().Set("weight_2", 10)
+		_ = db.Str().Set("weight_3", 20)
+		_, _ = db.Hash().Set("user_2", "name", "alice")
+		_, _ = db.Hash().Set("user_3", "name", "bob")
+
+		cmd := redis.MustParse(parse, "sort ids by weight_* get # get user_*->name")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(), "6,2,alice,3,bob,1,(nil)")
+	})
+
+	t.Run("store", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Set().Add("names", "bob", "alice")
+
+		cmd := redis.MustParse(parse, "sort names alpha store sorted")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 2)
+		testx.AssertEqual(t, conn.Out(), "2")
+
+		vals, _ := db.List().Range("sorted", 0, -1)
+		testx.AssertEqual(t, vals, []core.Value{core.Value("alice"), core.Value("bob")})
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Set().Add("names", "bob", "alice")
+
+		cmd := redis.MustParse(parse, "sort names")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrInvalidSortScore)
+		testx.AssertEqual(t, conn.Out(), redis.ErrInvalidSortScore.Error()+" (sort)")
+	})
+}
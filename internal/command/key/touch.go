@@ -0,0 +1,36 @@
+package key
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the number of existing keys among specified.
+// Redka does not track access times, so this is the same as EXISTS.
+// TOUCH key [key ...]
+// https://redis.io/commands/touch
+type Touch struct {
+	redis.BaseCmd
+	keys []string
+}
+
+func ParseTouch(b redis.BaseCmd) (Touch, error) {
+	cmd := Touch{BaseCmd: b}
+	err := parser.New(
+		parser.Strings(&cmd.keys),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return Touch{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd Touch) Run(w redis.Writer, red redis.Redka) (any, error) {
+	count, err := red.Key().Count(cmd.keys...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteInt(count)
+	return count, nil
+}
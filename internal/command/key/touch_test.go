@@ -0,0 +1,59 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestTouchParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+		err  error
+	}{
+		{
+			cmd:  "touch",
+			want: nil,
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "touch name",
+			want: []string{"name"},
+			err:  nil,
+		},
+		{
+			cmd:  "touch name age",
+			want: []string{"name", "age"},
+			err:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseTouch, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.keys, test.want)
+			} else {
+				testx.AssertEqual(t, cmd, Touch{})
+			}
+		})
+	}
+}
+
+func TestTouchExec(t *testing.T) {
+	db, red := getDB(t)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("age", 50)
+
+	cmd := redis.MustParse(ParseTouch, "touch name age city")
+	conn := redis.NewFakeConn()
+	res, err := cmd.Run(conn, red)
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, res, 2)
+	testx.AssertEqual(t, conn.Out(), "2")
+}
@@ -12,11 +12,12 @@ import (
 // https://redis.io/commands/ttl
 type TTL struct {
 	redis.BaseCmd
-	key string
+	key  string
+	unit int
 }
 
-func ParseTTL(b redis.BaseCmd) (TTL, error) {
-	cmd := TTL{BaseCmd: b}
+func ParseTTL(b redis.BaseCmd, unit int) (TTL, error) {
+	cmd := TTL{BaseCmd: b, unit: unit}
 	if len(cmd.Args()) != 1 {
 		return TTL{}, redis.ErrInvalidArgNum
 	}
@@ -38,7 +39,8 @@ func (cmd TTL) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteInt(-1)
 		return -1, nil
 	}
-	ttl := int(*k.ETime/1000 - time.Now().Unix())
+	unit := int64(cmd.unit)
+	ttl := int(*k.ETime/unit - time.Now().UnixMilli()/unit)
 	w.WriteInt(ttl)
 	return ttl, nil
 }
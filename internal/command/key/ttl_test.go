@@ -31,9 +31,13 @@ func TestTTLParse(t *testing.T) {
 		},
 	}
 
+	parse := func(b redis.BaseCmd) (TTL, error) {
+		return ParseTTL(b, 1000)
+	}
+
 	for _, test := range tests {
 		t.Run(test.cmd, func(t *testing.T) {
-			cmd, err := redis.Parse(ParseTTL, test.cmd)
+			cmd, err := redis.Parse(parse, test.cmd)
 			testx.AssertEqual(t, err, test.err)
 			if err == nil {
 				testx.AssertEqual(t, cmd.key, test.key)
@@ -45,13 +49,17 @@ func TestTTLParse(t *testing.T) {
 }
 
 func TestTTLExec(t *testing.T) {
+	parse := func(b redis.BaseCmd) (TTL, error) {
+		return ParseTTL(b, 1000)
+	}
+
 	t.Run("has ttl", func(t *testing.T) {
 		db, red := getDB(t)
 		defer db.Close()
 
 		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
 
-		cmd := redis.MustParse(ParseTTL, "ttl name")
+		cmd := redis.MustParse(parse, "ttl name")
 		conn := redis.NewFakeConn()
 		res, err := cmd.Run(conn, red)
 		testx.AssertNoErr(t, err)
@@ -65,7 +73,7 @@ func TestTTLExec(t *testing.T) {
 
 		_ = db.Str().Set("name", "alice")
 
-		cmd := redis.MustParse(ParseTTL, "ttl name")
+		cmd := redis.MustParse(parse, "ttl name")
 		conn := redis.NewFakeConn()
 		res, err := cmd.Run(conn, red)
 		testx.AssertNoErr(t, err)
@@ -77,11 +85,28 @@ func TestTTLExec(t *testing.T) {
 		db, red := getDB(t)
 		defer db.Close()
 
-		cmd := redis.MustParse(ParseTTL, "ttl name")
+		cmd := redis.MustParse(parse, "ttl name")
 		conn := redis.NewFakeConn()
 		res, err := cmd.Run(conn, red)
 		testx.AssertNoErr(t, err)
 		testx.AssertEqual(t, res, -2)
 		testx.AssertEqual(t, conn.Out(), "-2")
 	})
+
+	t.Run("pttl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+
+		parse := func(b redis.BaseCmd) (TTL, error) {
+			return ParseTTL(b, 1)
+		}
+		cmd := redis.MustParse(parse, "pttl name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		ttl := res.(int)
+		testx.AssertEqual(t, ttl > 59000 && ttl <= 60000, true)
+	})
 }
@@ -0,0 +1,36 @@
+package key
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Deletes one or more keys.
+// Redka deletes keys synchronously, so this is the same as DEL.
+// UNLINK key [key ...]
+// https://redis.io/commands/unlink
+type Unlink struct {
+	redis.BaseCmd
+	keys []string
+}
+
+func ParseUnlink(b redis.BaseCmd) (Unlink, error) {
+	cmd := Unlink{BaseCmd: b}
+	err := parser.New(
+		parser.Strings(&cmd.keys),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return Unlink{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd Unlink) Run(w redis.Writer, red redis.Redka) (any, error) {
+	count, err := red.Key().Delete(cmd.keys...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteInt(count)
+	return count, nil
+}
@@ -0,0 +1,29 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+func getDB(tb testing.TB) (*redka.DB, redis.Redka) {
+	tb.Helper()
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db, redis.RedkaDB(db)
+}
+
+// getClient returns a Redka instance with a client registry
+// of a single (current) client.
+func getClient(tb testing.TB) redis.Redka {
+	tb.Helper()
+	db, red := getDB(tb)
+	tb.Cleanup(func() { db.Close() })
+	reg := clients.NewRegistry()
+	self := reg.Add("127.0.0.1:5001", "127.0.0.1:6379", func() error { return nil })
+	return red.WithClients(reg).WithClient(self)
+}
@@ -0,0 +1,48 @@
+package pubsub
+
+import (
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Listens for messages published to channels.
+// Only the __redis__:invalidate channel is supported
+// (for the redirected client-side caching invalidations).
+// SUBSCRIBE channel [channel ...]
+// https://redis.io/commands/subscribe
+type Subscribe struct {
+	redis.BaseCmd
+	channels []string
+}
+
+func ParseSubscribe(b redis.BaseCmd) (Subscribe, error) {
+	cmd := Subscribe{BaseCmd: b}
+	err := parser.New(
+		parser.Strings(&cmd.channels),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return Subscribe{}, err
+	}
+	for _, channel := range cmd.channels {
+		if channel != clients.InvalidateChannel {
+			return Subscribe{}, redis.ErrUnknownChannel
+		}
+	}
+	return cmd, nil
+}
+
+func (c Subscribe) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.Client() == nil {
+		w.WriteError(c.Error(redis.ErrNoClients))
+		return nil, redis.ErrNoClients
+	}
+	red.Client().SetSubscribed(true)
+	for _, channel := range c.channels {
+		w.WritePush(3)
+		w.WriteBulkString("subscribe")
+		w.WriteBulkString(channel)
+		w.WriteInt(1)
+	}
+	return len(c.channels), nil
+}
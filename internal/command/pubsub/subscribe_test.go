@@ -0,0 +1,65 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSubscribeParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+		err  error
+	}{
+		{
+			cmd:  "subscribe",
+			want: nil,
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "subscribe __redis__:invalidate",
+			want: []string{"__redis__:invalidate"},
+			err:  nil,
+		},
+		{
+			cmd:  "subscribe news",
+			want: nil,
+			err:  redis.ErrUnknownChannel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSubscribe, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.channels, test.want)
+			} else {
+				testx.AssertEqual(t, cmd, Subscribe{})
+			}
+		})
+	}
+}
+
+func TestSubscribeExec(t *testing.T) {
+	t.Run("subscribe", func(t *testing.T) {
+		red := getClient(t)
+		cmd := redis.MustParse(ParseSubscribe, "subscribe __redis__:invalidate")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "3,subscribe,__redis__:invalidate,1")
+		testx.AssertEqual(t, red.Client().Subscribed(), true)
+	})
+	t.Run("no clients", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		cmd := redis.MustParse(ParseSubscribe, "subscribe __redis__:invalidate")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoClients)
+	})
+}
@@ -0,0 +1,62 @@
+package pubsub
+
+import (
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Stops listening to messages posted to channels.
+// Without arguments, unsubscribes from all channels.
+// UNSUBSCRIBE [channel [channel ...]]
+// https://redis.io/commands/unsubscribe
+type Unsubscribe struct {
+	redis.BaseCmd
+	channels []string
+}
+
+func ParseUnsubscribe(b redis.BaseCmd) (Unsubscribe, error) {
+	cmd := Unsubscribe{BaseCmd: b}
+	err := parser.New(
+		parser.Strings(&cmd.channels),
+	).Run(cmd.Args())
+	if err != nil {
+		return Unsubscribe{}, err
+	}
+	return cmd, nil
+}
+
+func (c Unsubscribe) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.Client() == nil {
+		w.WriteError(c.Error(redis.ErrNoClients))
+		return nil, redis.ErrNoClients
+	}
+	client := red.Client()
+	channels := c.channels
+	if len(channels) == 0 {
+		if !client.Subscribed() {
+			// Not subscribed to any channel.
+			w.WritePush(3)
+			w.WriteBulkString("unsubscribe")
+			w.WriteNull()
+			w.WriteInt(0)
+			return 0, nil
+		}
+		channels = []string{clients.InvalidateChannel}
+	}
+
+	for _, channel := range channels {
+		if channel == clients.InvalidateChannel {
+			client.SetSubscribed(false)
+		}
+		count := 0
+		if client.Subscribed() {
+			count = 1
+		}
+		w.WritePush(3)
+		w.WriteBulkString("unsubscribe")
+		w.WriteBulkString(channel)
+		w.WriteInt(count)
+	}
+	return len(channels), nil
+}
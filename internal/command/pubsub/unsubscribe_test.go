@@ -0,0 +1,60 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestUnsubscribeParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{cmd: "unsubscribe", want: nil},
+		{cmd: "unsubscribe __redis__:invalidate", want: []string{"__redis__:invalidate"}},
+		{cmd: "unsubscribe one two", want: []string{"one", "two"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseUnsubscribe, test.cmd)
+			testx.AssertNoErr(t, err)
+			testx.AssertEqual(t, cmd.channels, test.want)
+		})
+	}
+}
+
+func TestUnsubscribeExec(t *testing.T) {
+	t.Run("all", func(t *testing.T) {
+		red := getClient(t)
+		red.Client().SetSubscribed(true)
+		cmd := redis.MustParse(ParseUnsubscribe, "unsubscribe")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "3,unsubscribe,__redis__:invalidate,0")
+		testx.AssertEqual(t, red.Client().Subscribed(), false)
+	})
+	t.Run("not subscribed", func(t *testing.T) {
+		red := getClient(t)
+		cmd := redis.MustParse(ParseUnsubscribe, "unsubscribe")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+		testx.AssertEqual(t, conn.Out(), "3,unsubscribe,(nil),0")
+	})
+	t.Run("channels", func(t *testing.T) {
+		red := getClient(t)
+		red.Client().SetSubscribed(true)
+		cmd := redis.MustParse(ParseUnsubscribe, "unsubscribe news __redis__:invalidate")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 2)
+		testx.AssertEqual(t, conn.Out(), "3,unsubscribe,news,1,3,unsubscribe,__redis__:invalidate,0")
+	})
+}
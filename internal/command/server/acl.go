@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Container command for access control list commands.
+// ACL subcommand [argument ...]
+// https://redis.io/commands/acl
+type ACL struct {
+	redis.BaseCmd
+	subcmd  string
+	delUser ACLDelUser
+	getUser ACLGetUser
+	setUser ACLSetUser
+}
+
+func ParseACL(b redis.BaseCmd) (ACL, error) {
+	// Extract the subcommand.
+	cmd := ACL{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return ACL{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "deluser":
+		cmd.delUser, err = ParseACLDelUser(args)
+	case "getuser":
+		cmd.getUser, err = ParseACLGetUser(args)
+	case "setuser":
+		cmd.setUser, err = ParseACLSetUser(args)
+	case "list", "users", "whoami":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return ACL{}, err
+	}
+	return cmd, nil
+}
+
+func (c ACL) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.ACL() == nil {
+		w.WriteError(c.Error(redis.ErrNoACL))
+		return nil, redis.ErrNoACL
+	}
+	switch c.subcmd {
+	case "deluser":
+		return c.delUser.Run(w, red)
+	case "getuser":
+		return c.getUser.Run(w, red)
+	case "setuser":
+		return c.setUser.Run(w, red)
+	case "list":
+		return writeStrings(w, red.ACL().List())
+	case "users":
+		return writeStrings(w, red.ACL().Users())
+	default:
+		user := acl.DefaultUser
+		if red.Client() != nil {
+			user = red.Client().User()
+		}
+		w.WriteBulkString(user)
+		return user, nil
+	}
+}
+
+// writeStrings writes the strings as an array.
+func writeStrings(w redis.Writer, vals []string) (any, error) {
+	w.WriteArray(len(vals))
+	for _, val := range vals {
+		w.WriteBulkString(val)
+	}
+	return vals, nil
+}
+
+// aclError returns the error message for
+// the ACL SETUSER rule error.
+func aclError(err error) string {
+	var rerr *acl.RuleError
+	if !errors.As(err, &rerr) {
+		return "ERR " + err.Error()
+	}
+	var reason string
+	switch {
+	case errors.Is(err, acl.ErrUnknownCategory), errors.Is(err, acl.ErrUnknownCommand):
+		reason = "Unknown command or category name in ACL"
+	case errors.Is(err, acl.ErrInvalidHash):
+		reason = "The password hash must be exactly 64 characters and contain only lowercase hexadecimal characters"
+	case errors.Is(err, acl.ErrNoPassword):
+		reason = "The password you are trying to remove from the user does not exist"
+	default:
+		reason = "Syntax error"
+	}
+	return fmt.Sprintf("ERR Error in ACL SETUSER modifier '%s': %s", rerr.Rule, reason)
+}
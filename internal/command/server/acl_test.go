@@ -0,0 +1,182 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestACLParse(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		subcmd string
+		err    error
+	}{
+		{cmd: "acl", err: redis.ErrInvalidArgNum},
+		{cmd: "acl setuser alice", subcmd: "setuser"},
+		{cmd: "acl SETUSER alice on >secret ~* +@read", subcmd: "setuser"},
+		{cmd: "acl setuser", err: redis.ErrInvalidArgNum},
+		{cmd: "acl getuser alice", subcmd: "getuser"},
+		{cmd: "acl getuser", err: redis.ErrInvalidArgNum},
+		{cmd: "acl getuser alice bob", err: redis.ErrInvalidArgNum},
+		{cmd: "acl deluser alice bob", subcmd: "deluser"},
+		{cmd: "acl deluser", err: redis.ErrInvalidArgNum},
+		{cmd: "acl list", subcmd: "list"},
+		{cmd: "acl list 1", err: redis.ErrInvalidArgNum},
+		{cmd: "acl users", subcmd: "users"},
+		{cmd: "acl whoami", subcmd: "whoami"},
+		{cmd: "acl cat", err: redis.ErrUnknownSubcmd},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseACL, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.subcmd, test.subcmd)
+		})
+	}
+}
+
+func TestACLParseArgs(t *testing.T) {
+	cmd := redis.MustParse(ParseACL, "acl setuser alice on >secret ~user:* +@read")
+	testx.AssertEqual(t, cmd.setUser, ACLSetUser{
+		name:  "alice",
+		rules: []string{"on", ">secret", "~user:*", "+@read"},
+	})
+	cmd = redis.MustParse(ParseACL, "acl deluser alice bob")
+	testx.AssertEqual(t, cmd.delUser, ACLDelUser{names: []string{"alice", "bob"}})
+}
+
+func TestACLExec(t *testing.T) {
+	t.Run("setuser and getuser", func(t *testing.T) {
+		red, _ := getACL(t)
+
+		cmd := redis.MustParse(ParseACL, "acl setuser alice on nopass ~user:* +@read -keys")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		cmd = redis.MustParse(ParseACL, "acl getuser alice")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.(acl.User).Name, "alice")
+		testx.AssertEqual(t, conn.Out(),
+			"12,flags,2,on,nopass,passwords,0,commands,-@all +@read -keys,"+
+				"keys,~user:*,channels,resetchannels,selectors,0")
+
+		cmd = redis.MustParse(ParseACL, "acl getuser bob")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), "(nil)")
+	})
+	t.Run("setuser invalid", func(t *testing.T) {
+		red, _ := getACL(t)
+		tests := []struct {
+			cmd  string
+			want string
+		}{
+			{
+				cmd:  "acl setuser alice on +nosuchcmd",
+				want: "ERR Error in ACL SETUSER modifier '+nosuchcmd': Unknown command or category name in ACL",
+			},
+			{
+				cmd:  "acl setuser alice on +@nosuchcat",
+				want: "ERR Error in ACL SETUSER modifier '+@nosuchcat': Unknown command or category name in ACL",
+			},
+			{
+				cmd:  "acl setuser alice <secret",
+				want: "ERR Error in ACL SETUSER modifier '<secret': The password you are trying to remove from the user does not exist",
+			},
+			{
+				cmd:  "acl setuser alice foo",
+				want: "ERR Error in ACL SETUSER modifier 'foo': Syntax error",
+			},
+		}
+		for _, test := range tests {
+			cmd := redis.MustParse(ParseACL, test.cmd)
+			conn := redis.NewFakeConn()
+			_, err := cmd.Run(conn, red)
+			testx.AssertEqual(t, err != nil, true)
+			testx.AssertEqual(t, conn.Out(), test.want)
+		}
+		_, ok := red.ACL().GetUser("alice")
+		testx.AssertEqual(t, ok, false)
+	})
+	t.Run("list and users", func(t *testing.T) {
+		red, _ := getACL(t)
+		err := red.ACL().SetUser("alice", "on", "~*", "+get")
+		testx.AssertNoErr(t, err)
+
+		cmd := redis.MustParse(ParseACL, "acl users")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []string{"alice", "default"})
+		testx.AssertEqual(t, conn.Out(), "2,alice,default")
+
+		cmd = redis.MustParse(ParseACL, "acl list")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, conn.Out(),
+			"2,user alice on ~* resetchannels -@all +get,"+
+				"user default on nopass ~* &* +@all")
+	})
+	t.Run("deluser", func(t *testing.T) {
+		red, reg := getACL(t)
+		err := red.ACL().SetUser("alice", "on", "nopass", "+@all")
+		testx.AssertNoErr(t, err)
+		alice := reg.Add("127.0.0.1:5002", "127.0.0.1:6379", func() error { return nil })
+		alice.SetUser("alice")
+
+		cmd := redis.MustParse(ParseACL, "acl deluser alice bob")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "1")
+		testx.AssertEqual(t, alice.Killed(), true)
+
+		cmd = redis.MustParse(ParseACL, "acl deluser default")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, red)
+		testx.AssertErr(t, err, acl.ErrDeleteDefault)
+		testx.AssertEqual(t, conn.Out(), "ERR The 'default' user cannot be removed")
+	})
+	t.Run("whoami", func(t *testing.T) {
+		red, _ := getACL(t)
+		cmd := redis.MustParse(ParseACL, "acl whoami")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "default")
+		testx.AssertEqual(t, conn.Out(), "default")
+	})
+	t.Run("no acl", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		cmd := redis.MustParse(ParseACL, "acl users")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoACL)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoACL.Error()+" (acl)")
+	})
+}
+
+func getACL(t *testing.T) (redis.Redka, *clients.Registry) {
+	db, red := getDB(t)
+	t.Cleanup(func() { db.Close() })
+	acls, err := acl.NewManager(nil)
+	testx.AssertNoErr(t, err)
+	reg := clients.NewRegistry()
+	self := reg.Add("127.0.0.1:5001", "127.0.0.1:6379", func() error { return nil })
+	return red.WithACL(acls).WithClients(reg).WithClient(self), reg
+}
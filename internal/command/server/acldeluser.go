@@ -0,0 +1,44 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Deletes ACL users, and terminates their connections.
+// ACL DELUSER username [username ...]
+// https://redis.io/commands/acl-deluser
+type ACLDelUser struct {
+	names []string
+}
+
+func ParseACLDelUser(args [][]byte) (ACLDelUser, error) {
+	if len(args) < 1 {
+		return ACLDelUser{}, redis.ErrInvalidArgNum
+	}
+	cmd := ACLDelUser{names: make([]string, len(args))}
+	for i, arg := range args {
+		cmd.names[i] = string(arg)
+	}
+	return cmd, nil
+}
+
+func (c ACLDelUser) Run(w redis.Writer, red redis.Redka) (any, error) {
+	n, err := red.ACL().DelUser(c.names...)
+	if err == acl.ErrDeleteDefault {
+		w.WriteError("ERR The 'default' user cannot be removed")
+		return nil, err
+	}
+	if err != nil {
+		w.WriteError("ERR " + err.Error())
+		return nil, err
+	}
+	if red.Clients() != nil && n > 0 {
+		for _, name := range c.names {
+			red.Clients().Kill(clients.Filter{User: name}, red.Client())
+		}
+	}
+	w.WriteInt(n)
+	return n, nil
+}
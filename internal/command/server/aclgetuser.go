@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Lists the ACL rules of a user.
+// ACL GETUSER username
+// https://redis.io/commands/acl-getuser
+type ACLGetUser struct {
+	name string
+}
+
+func ParseACLGetUser(args [][]byte) (ACLGetUser, error) {
+	if len(args) != 1 {
+		return ACLGetUser{}, redis.ErrInvalidArgNum
+	}
+	return ACLGetUser{name: string(args[0])}, nil
+}
+
+func (c ACLGetUser) Run(w redis.Writer, red redis.Redka) (any, error) {
+	user, ok := red.ACL().GetUser(c.name)
+	if !ok {
+		w.WriteNull()
+		return nil, nil
+	}
+	w.WriteMap(6)
+	w.WriteBulkString("flags")
+	_, _ = writeStrings(w, user.Flags())
+	w.WriteBulkString("passwords")
+	_, _ = writeStrings(w, user.Passwords)
+	w.WriteBulkString("commands")
+	w.WriteBulkString(user.CommandRules())
+	w.WriteBulkString("keys")
+	w.WriteBulkString(user.KeyRules())
+	w.WriteBulkString("channels")
+	w.WriteBulkString(user.ChannelRules())
+	w.WriteBulkString("selectors")
+	w.WriteArray(0)
+	return user, nil
+}
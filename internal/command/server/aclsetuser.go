@@ -0,0 +1,65 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Creates and modifies an ACL user and its rules.
+// ACL SETUSER username [rule [rule ...]]
+// https://redis.io/commands/acl-setuser
+type ACLSetUser struct {
+	name  string
+	rules []string
+}
+
+func ParseACLSetUser(args [][]byte) (ACLSetUser, error) {
+	if len(args) < 1 {
+		return ACLSetUser{}, redis.ErrInvalidArgNum
+	}
+	cmd := ACLSetUser{name: string(args[0])}
+	for _, arg := range args[1:] {
+		cmd.rules = append(cmd.rules, string(arg))
+	}
+	return cmd, nil
+}
+
+func (c ACLSetUser) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if err := checkCmdRules(c.rules); err != nil {
+		w.WriteError(aclError(err))
+		return nil, err
+	}
+	if err := red.ACL().SetUser(c.name, c.rules...); err != nil {
+		w.WriteError(aclError(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
+
+// checkCmdRules checks that the +command and -command
+// rules refer to known commands.
+func checkCmdRules(rules []string) error {
+	for _, rule := range rules {
+		if len(rule) < 2 || (rule[0] != '+' && rule[0] != '-') || rule[1] == '@' {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.ToLower(rule[1:]), "|")
+		if !knownCmd(name) {
+			return &acl.RuleError{Rule: rule, Err: acl.ErrUnknownCommand}
+		}
+	}
+	return nil
+}
+
+// knownCmd reports whether the command exists.
+func knownCmd(name string) bool {
+	switch name {
+	case "multi", "exec", "discard":
+		return true
+	}
+	_, ok := redis.LookupCmd(name)
+	return ok
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Asynchronously saves the database to disk.
+// BGSAVE [SCHEDULE]
+// https://redis.io/commands/bgsave
+type BgSave struct {
+	redis.BaseCmd
+	schedule bool
+}
+
+func ParseBgSave(b redis.BaseCmd) (BgSave, error) {
+	cmd := BgSave{BaseCmd: b}
+	err := parser.New(
+		parser.Flag("schedule", &cmd.schedule),
+	).Run(cmd.Args())
+	if err != nil {
+		return BgSave{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd BgSave) Run(w redis.Writer, red redis.Redka) (any, error) {
+	saver := red.Saver()
+	if saver == nil {
+		w.WriteError(cmd.Error(redis.ErrNoPersistence))
+		return nil, redis.ErrNoPersistence
+	}
+
+	if cmd.schedule {
+		if saver.Schedule() {
+			w.WriteString("Background saving started")
+			return true, nil
+		}
+		w.WriteString("Background saving scheduled")
+		return false, nil
+	}
+
+	err := saver.BgSave()
+	if err == persist.ErrInProgress {
+		w.WriteError(cmd.Error(redis.ErrBgSaveInProgress))
+		return nil, err
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("Background saving started")
+	return true, nil
+}
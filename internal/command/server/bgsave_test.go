@@ -0,0 +1,88 @@
+package server
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestBgSaveParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want BgSave
+		err  error
+	}{
+		{
+			cmd:  "bgsave",
+			want: BgSave{},
+			err:  nil,
+		},
+		{
+			cmd:  "bgsave schedule",
+			want: BgSave{schedule: true},
+			err:  nil,
+		},
+		{
+			cmd:  "bgsave now",
+			want: BgSave{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseBgSave, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.schedule, test.want.schedule)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestBgSaveExec(t *testing.T) {
+	t.Run("bgsave", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		saver := getSaver(t, db)
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseBgSave, "bgsave")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithSaver(saver))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "Background saving started")
+
+		saver.Wait()
+		_, err = os.Stat(saver.Path())
+		testx.AssertNoErr(t, err)
+	})
+	t.Run("schedule", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		saver := getSaver(t, db)
+
+		cmd := redis.MustParse(ParseBgSave, "bgsave schedule")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithSaver(saver))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "Background saving started")
+		saver.Wait()
+	})
+	t.Run("not configured", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseBgSave, "bgsave")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoPersistence)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoPersistence.Error()+" (bgsave)")
+	})
+}
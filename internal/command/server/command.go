@@ -0,0 +1,74 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Container command for command introspection commands.
+// Without a subcommand, returns detailed information about all commands.
+// COMMAND [subcommand]
+// https://redis.io/commands/command
+type Command struct {
+	redis.BaseCmd
+	subcmd string
+	count  CommandCount
+	docs   CommandDocs
+	info   CommandInfo
+}
+
+func ParseCommand(b redis.BaseCmd) (Command, error) {
+	// Without a subcommand, COMMAND is the same as COMMAND INFO.
+	cmd := Command{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		cmd.subcmd = "info"
+		return cmd, nil
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "count":
+		cmd.count, err = ParseCommandCount(args)
+	case "docs":
+		cmd.docs, err = ParseCommandDocs(args)
+	case "info":
+		cmd.info, err = ParseCommandInfo(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Command{}, err
+	}
+	return cmd, nil
+}
+
+func (c Command) Run(w redis.Writer, red redis.Redka) (any, error) {
+	switch c.subcmd {
+	case "count":
+		return c.count.Run(w, red)
+	case "docs":
+		return c.docs.Run(w, red)
+	default:
+		return c.info.Run(w, red)
+	}
+}
+
+// lookupCmds returns the descriptions of the named commands,
+// or of all commands if no names are given. Unknown commands
+// have an empty description.
+func lookupCmds(names []string) []redis.CmdInfo {
+	if len(names) == 0 {
+		return redis.AllCmds()
+	}
+	infos := make([]redis.CmdInfo, len(names))
+	for i, name := range names {
+		infos[i], _ = redis.LookupCmd(strings.ToLower(name))
+	}
+	return infos
+}
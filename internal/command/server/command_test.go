@@ -0,0 +1,104 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestCommandParse(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		subcmd string
+		err    error
+	}{
+		{
+			cmd:    "command",
+			subcmd: "info",
+			err:    nil,
+		},
+		{
+			cmd:    "command count",
+			subcmd: "count",
+			err:    nil,
+		},
+		{
+			cmd:    "command count get",
+			subcmd: "",
+			err:    redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:    "command INFO get set",
+			subcmd: "info",
+			err:    nil,
+		},
+		{
+			cmd:    "command docs",
+			subcmd: "docs",
+			err:    nil,
+		},
+		{
+			cmd:    "command getkeys get name",
+			subcmd: "",
+			err:    redis.ErrUnknownSubcmd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseCommand, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.subcmd, test.subcmd)
+		})
+	}
+}
+
+func TestCommandExec(t *testing.T) {
+	t.Run("command", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseCommand, "command")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(res.([]redis.CmdInfo)), len(redis.AllCmds()))
+	})
+	t.Run("count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseCommand, "command count")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, len(redis.AllCmds()))
+	})
+	t.Run("info", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseCommand, "command info GET mset unknown")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(res.([]redis.CmdInfo)), 3)
+		testx.AssertEqual(t, conn.Out(), "3,"+
+			"10,get,2,2,readonly,fast,1,1,1,3,@read,@string,@fast,0,0,0,"+
+			"10,mset,-3,2,write,denyoom,1,-1,2,3,@write,@string,@slow,0,0,0,"+
+			"(nil)")
+	})
+	t.Run("docs", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseCommand, "command docs get unknown")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(res.([]redis.CmdInfo)), 1)
+		testx.AssertEqual(t, conn.Out(),
+			"2,get,4,summary,Returns the string value of a key.,group,string")
+	})
+}
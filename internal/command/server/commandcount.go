@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns a count of commands.
+// COMMAND COUNT
+// https://redis.io/commands/command-count
+type CommandCount struct{}
+
+func ParseCommandCount(args [][]byte) (CommandCount, error) {
+	if len(args) != 0 {
+		return CommandCount{}, redis.ErrInvalidArgNum
+	}
+	return CommandCount{}, nil
+}
+
+func (c CommandCount) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	n := len(redis.AllCmds())
+	w.WriteInt(n)
+	return n, nil
+}
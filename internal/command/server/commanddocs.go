@@ -0,0 +1,43 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns documentary information about one, multiple or all commands.
+// COMMAND DOCS [command-name [command-name ...]]
+// https://redis.io/commands/command-docs
+type CommandDocs struct {
+	names []string
+}
+
+func ParseCommandDocs(args [][]byte) (CommandDocs, error) {
+	cmd := CommandDocs{names: make([]string, len(args))}
+	for i, arg := range args {
+		cmd.names[i] = string(arg)
+	}
+	return cmd, nil
+}
+
+func (c CommandDocs) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	// Unknown commands are skipped.
+	var infos []redis.CmdInfo
+	for _, info := range lookupCmds(c.names) {
+		if info.Name != "" {
+			infos = append(infos, info)
+		}
+	}
+
+	// The reply is a map of command names to their docs,
+	// written as a flat array for RESP2 clients.
+	w.WriteMap(len(infos))
+	for _, info := range infos {
+		w.WriteBulkString(info.Name)
+		w.WriteMap(2)
+		w.WriteBulkString("summary")
+		w.WriteBulkString(info.Summary)
+		w.WriteBulkString("group")
+		w.WriteBulkString(info.Group)
+	}
+	return infos, nil
+}
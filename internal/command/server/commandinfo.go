@@ -0,0 +1,56 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns information about one, multiple or all commands.
+// COMMAND INFO [command-name [command-name ...]]
+// https://redis.io/commands/command-info
+type CommandInfo struct {
+	names []string
+}
+
+func ParseCommandInfo(args [][]byte) (CommandInfo, error) {
+	cmd := CommandInfo{names: make([]string, len(args))}
+	for i, arg := range args {
+		cmd.names[i] = string(arg)
+	}
+	return cmd, nil
+}
+
+func (c CommandInfo) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	infos := lookupCmds(c.names)
+	w.WriteArray(len(infos))
+	for _, info := range infos {
+		if info.Name == "" {
+			w.WriteNull()
+			continue
+		}
+		writeCmdInfo(w, info)
+	}
+	return infos, nil
+}
+
+// writeCmdInfo writes the command description
+// in the format of the COMMAND INFO reply.
+func writeCmdInfo(w redis.Writer, info redis.CmdInfo) {
+	w.WriteArray(10)
+	w.WriteBulkString(info.Name)
+	w.WriteInt(info.Arity)
+	w.WriteArray(len(info.Flags))
+	for _, flag := range info.Flags {
+		w.WriteString(flag)
+	}
+	w.WriteInt(info.FirstKey)
+	w.WriteInt(info.LastKey)
+	w.WriteInt(info.Step)
+	cats := info.Categories()
+	w.WriteArray(len(cats))
+	for _, cat := range cats {
+		w.WriteString("@" + cat)
+	}
+	w.WriteArray(0) // tips
+	w.WriteArray(0) // key specifications
+	w.WriteArray(0) // subcommands
+}
@@ -58,6 +58,6 @@ func TestConfigExec(t *testing.T) {
 		res, err := cmd.Run(conn, red)
 		testx.AssertNoErr(t, err)
 		testx.AssertEqual(t, res, true)
-		testx.AssertEqual(t, conn.Out(), "2,databases,1")
+		testx.AssertEqual(t, conn.Out(), "2,databases,16")
 	})
 }
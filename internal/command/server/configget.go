@@ -23,8 +23,8 @@ func ParseConfigGet(args [][]byte) (ConfigGet, error) {
 }
 
 func (c ConfigGet) Run(w redis.Writer, _ redis.Redka) (any, error) {
-	w.WriteArray(2)
+	w.WriteMap(1)
 	w.WriteString("databases")
-	w.WriteInt(1)
+	w.WriteInt(redis.Databases)
 	return true, nil
 }
@@ -49,6 +49,22 @@ func TestDBSizeExec(t *testing.T) {
 		testx.AssertEqual(t, conn.Out(), "2")
 	})
 
+	t.Run("selected database", func(t *testing.T) {
+		db, _ := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(1).Str().Set("name", "bob")
+		_ = db.Select(1).Str().Set("age", 25)
+
+		cmd := redis.MustParse(ParseDBSize, "dbsize")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.RedkaDB(db.Select(1)))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 2)
+		testx.AssertEqual(t, conn.Out(), "2")
+	})
+
 	t.Run("empty", func(t *testing.T) {
 		db, red := getDB(t)
 		defer db.Close()
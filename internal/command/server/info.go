@@ -0,0 +1,229 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rkey"
+	"github.com/flarco/redka/internal/stats"
+)
+
+// Returns information and statistics about the server.
+// INFO [section [section ...]]
+// https://redis.io/commands/info
+type Info struct {
+	redis.BaseCmd
+	sections []string
+}
+
+func ParseInfo(b redis.BaseCmd) (Info, error) {
+	cmd := Info{BaseCmd: b}
+	for _, arg := range cmd.Args() {
+		cmd.sections = append(cmd.sections, strings.ToLower(string(arg)))
+	}
+	return cmd, nil
+}
+
+func (cmd Info) Run(w redis.Writer, red redis.Redka) (any, error) {
+	// Without a statistics collector (e.g. when running
+	// commands outside the server), the counters are zero.
+	var srv stats.Server
+	var dbStats redka.Stats
+	if red.Stats() != nil {
+		srv = red.Stats().Server()
+		var err error
+		dbStats, err = red.Stats().DB()
+		if err != nil {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+	}
+
+	var sections []string
+	if cmd.wants("server") {
+		sections = append(sections, serverSection(srv))
+	}
+	if cmd.wants("clients") {
+		sections = append(sections, clientsSection(srv))
+	}
+	if cmd.wants("memory") {
+		sections = append(sections, memorySection(dbStats))
+	}
+	if cmd.wants("persistence") {
+		sections = append(sections, persistenceSection(red.Saver()))
+	}
+	if cmd.wants("stats") {
+		sections = append(sections, statsSection(srv, dbStats))
+	}
+	if cmd.wants("keyspace") {
+		counts, err := red.Key().DBCounts()
+		if err != nil {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+		sections = append(sections, keyspaceSection(counts))
+	}
+	if cmd.wants("keytypes") {
+		counts, err := red.Key().Counts()
+		if err != nil {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+		sections = append(sections, keytypesSection(counts))
+	}
+
+	out := strings.Join(sections, "\r\n")
+	w.WriteBulkString(out)
+	return out, nil
+}
+
+// wants reports whether the section is requested.
+func (cmd Info) wants(section string) bool {
+	if len(cmd.sections) == 0 {
+		return true
+	}
+	for _, s := range cmd.sections {
+		if s == section || s == "all" || s == "default" || s == "everything" {
+			return true
+		}
+	}
+	return false
+}
+
+// serverSection returns the server section.
+func serverSection(srv stats.Server) string {
+	var b strings.Builder
+	b.WriteString("# Server\r\n")
+	fmt.Fprintf(&b, "redis_version:%s\r\n", redis.Version)
+	fmt.Fprintf(&b, "redka_version:%s\r\n", srv.Version)
+	fmt.Fprintf(&b, "redis_mode:standalone\r\n")
+	fmt.Fprintf(&b, "os:%s %s\r\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "arch_bits:%d\r\n", strconv.IntSize)
+	fmt.Fprintf(&b, "process_id:%d\r\n", os.Getpid())
+	fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", int64(srv.Uptime/time.Second))
+	fmt.Fprintf(&b, "uptime_in_days:%d\r\n", int64(srv.Uptime/(24*time.Hour)))
+	return b.String()
+}
+
+// clientsSection returns the clients section.
+func clientsSection(srv stats.Server) string {
+	var b strings.Builder
+	b.WriteString("# Clients\r\n")
+	fmt.Fprintf(&b, "connected_clients:%d\r\n", srv.Clients)
+	return b.String()
+}
+
+// memorySection returns the memory section. The data is
+// stored in the database file, so besides the memory used
+// by the server process, it reports the SQLite file sizes.
+func memorySection(st redka.Stats) string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	var b strings.Builder
+	b.WriteString("# Memory\r\n")
+	fmt.Fprintf(&b, "used_memory:%d\r\n", mem.HeapAlloc)
+	fmt.Fprintf(&b, "used_memory_rss:%d\r\n", mem.Sys)
+	fmt.Fprintf(&b, "sqlite_page_count:%d\r\n", st.PageCount)
+	fmt.Fprintf(&b, "sqlite_page_size:%d\r\n", st.PageSize)
+	fmt.Fprintf(&b, "sqlite_db_size:%d\r\n", st.PageCount*st.PageSize)
+	fmt.Fprintf(&b, "sqlite_wal_size:%d\r\n", st.WALSize)
+	return b.String()
+}
+
+// statsSection returns the stats section.
+func statsSection(srv stats.Server, st redka.Stats) string {
+	var b strings.Builder
+	b.WriteString("# Stats\r\n")
+	fmt.Fprintf(&b, "total_connections_received:%d\r\n", srv.Connections)
+	fmt.Fprintf(&b, "total_commands_processed:%d\r\n", srv.Commands)
+	fmt.Fprintf(&b, "instantaneous_ops_per_sec:%d\r\n", srv.OpsPerSec)
+	fmt.Fprintf(&b, "expired_keys:%d\r\n", st.ExpiredKeys)
+	fmt.Fprintf(&b, "expired_subkeys:%d\r\n", st.ExpiredFields)
+	fmt.Fprintf(&b, "evicted_keys:0\r\n")
+	return b.String()
+}
+
+// keyspaceSection returns the keyspace section
+// (Redis omits the empty databases).
+func keyspaceSection(counts map[int]rkey.KeyCounts) string {
+	var b strings.Builder
+	b.WriteString("# Keyspace\r\n")
+	indexes := make([]int, 0, len(counts))
+	for index := range counts {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	for _, index := range indexes {
+		c := counts[index]
+		fmt.Fprintf(&b, "db%d:keys=%d,expires=%d,avg_ttl=0\r\n", index, c.Keys, c.Expires)
+	}
+	return b.String()
+}
+
+// keytypesSection returns the number of keys by type
+// in the selected database (Redka-specific section).
+func keytypesSection(counts rkey.KeyCounts) string {
+	types := []core.TypeID{
+		core.TypeString, core.TypeList, core.TypeSet, core.TypeHash, core.TypeZSet,
+	}
+	var b strings.Builder
+	b.WriteString("# Keytypes\r\n")
+	for _, typ := range types {
+		name := core.Key{Type: typ}.TypeName()
+		fmt.Fprintf(&b, "%s_keys:%d\r\n", name, counts.Types[typ])
+	}
+	return b.String()
+}
+
+// persistenceSection returns the persistence section.
+func persistenceSection(saver redis.RSaver) string {
+	var status persist.Status
+	if saver != nil {
+		status = saver.Status()
+	}
+
+	lastSave := int64(0)
+	if !status.LastSave.IsZero() {
+		lastSave = status.LastSave.Unix()
+	}
+	lastStatus := "ok"
+	if !status.LastOK && saver != nil {
+		lastStatus = "err"
+	}
+	lastTime := int64(-1)
+	if status.LastDuration > 0 {
+		lastTime = int64(status.LastDuration / time.Second)
+	}
+	currentTime := int64(-1)
+	if status.InProgress {
+		currentTime = int64(time.Since(status.CurrentStart) / time.Second)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Persistence\r\n")
+	fmt.Fprintf(&b, "loading:0\r\n")
+	fmt.Fprintf(&b, "rdb_bgsave_in_progress:%d\r\n", boolInt(status.InProgress))
+	fmt.Fprintf(&b, "rdb_last_save_time:%d\r\n", lastSave)
+	fmt.Fprintf(&b, "rdb_last_bgsave_status:%s\r\n", lastStatus)
+	fmt.Fprintf(&b, "rdb_last_bgsave_time_sec:%d\r\n", lastTime)
+	fmt.Fprintf(&b, "rdb_current_bgsave_time_sec:%d\r\n", currentTime)
+	fmt.Fprintf(&b, "aof_enabled:0\r\n")
+	return b.String()
+}
+
+// boolInt converts a bool to 0 or 1.
+func boolInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
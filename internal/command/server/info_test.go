@@ -0,0 +1,161 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestInfoParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{
+			cmd:  "info",
+			want: nil,
+		},
+		{
+			cmd:  "info Persistence",
+			want: []string{"persistence"},
+		},
+		{
+			cmd:  "info server clients",
+			want: []string{"server", "clients"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseInfo, test.cmd)
+			testx.AssertNoErr(t, err)
+			testx.AssertEqual(t, cmd.sections, test.want)
+		})
+	}
+}
+
+func TestInfoExec(t *testing.T) {
+	t.Run("persistence", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		saver := getSaver(t, db)
+		_ = saver.Save()
+
+		cmd := redis.MustParse(ParseInfo, "info persistence")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithSaver(saver))
+		testx.AssertNoErr(t, err)
+		out := res.(string)
+		testx.AssertEqual(t, strings.HasPrefix(out, "# Persistence\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "rdb_bgsave_in_progress:0\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "rdb_last_bgsave_status:ok\r\n"), true)
+	})
+	t.Run("default", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseInfo, "info")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		out := res.(string)
+		for _, section := range []string{
+			"Server", "Clients", "Memory", "Persistence", "Stats", "Keyspace", "Keytypes",
+		} {
+			testx.AssertEqual(t, strings.Contains(out, "# "+section+"\r\n"), true)
+		}
+	})
+	t.Run("server stats", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{Version: "1.0.0"})
+		collector.Connect()
+		collector.Command("get", time.Millisecond, false)
+
+		cmd := redis.MustParse(ParseInfo, "info server clients stats")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(collector))
+		testx.AssertNoErr(t, err)
+		out := res.(string)
+		testx.AssertEqual(t, strings.HasPrefix(out, "# Server\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "redka_version:1.0.0\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "connected_clients:1\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "total_commands_processed:1\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "expired_keys:0\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "# Memory"), false)
+	})
+	t.Run("memory", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseInfo, "info memory")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(stats.New(db, nil)))
+		testx.AssertNoErr(t, err)
+		out := res.(string)
+		testx.AssertEqual(t, strings.HasPrefix(out, "# Memory\r\n"), true)
+		testx.AssertEqual(t, strings.Contains(out, "sqlite_page_size:4096\r\n"), true)
+	})
+	t.Run("keyspace", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpires("age", 25, time.Minute)
+		_, _ = db.Hash().Set("person", "name", "alice")
+
+		cmd := redis.MustParse(ParseInfo, "info keyspace keytypes")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "# Keyspace\r\n"+
+			"db0:keys=3,expires=1,avg_ttl=0\r\n"+
+			"\r\n"+
+			"# Keytypes\r\n"+
+			"string_keys:2\r\n"+
+			"list_keys:0\r\n"+
+			"set_keys:0\r\n"+
+			"hash_keys:1\r\n"+
+			"zset_keys:0\r\n")
+	})
+	t.Run("keyspace databases", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(12).Str().Set("name", "bob")
+		_ = db.Select(3).Str().Set("name", "cindy")
+		_ = db.Select(3).Str().Set("age", 25)
+
+		cmd := redis.MustParse(ParseInfo, "info keyspace")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "# Keyspace\r\n"+
+			"db0:keys=1,expires=0,avg_ttl=0\r\n"+
+			"db3:keys=2,expires=0,avg_ttl=0\r\n"+
+			"db12:keys=1,expires=0,avg_ttl=0\r\n")
+	})
+	t.Run("empty keyspace", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseInfo, "info keyspace")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "# Keyspace\r\n")
+	})
+	t.Run("unknown section", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseInfo, "info unknown")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, "")
+	})
+}
@@ -0,0 +1,29 @@
+package server
+
+import "github.com/flarco/redka/internal/redis"
+
+// Returns the Unix timestamp of the last successful save to disk.
+// LASTSAVE
+// https://redis.io/commands/lastsave
+type LastSave struct {
+	redis.BaseCmd
+}
+
+func ParseLastSave(b redis.BaseCmd) (LastSave, error) {
+	cmd := LastSave{BaseCmd: b}
+	if len(cmd.Args()) != 0 {
+		return LastSave{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd LastSave) Run(w redis.Writer, red redis.Redka) (any, error) {
+	saver := red.Saver()
+	if saver == nil {
+		w.WriteError(cmd.Error(redis.ErrNoPersistence))
+		return nil, redis.ErrNoPersistence
+	}
+	ts := int(saver.LastSave().Unix())
+	w.WriteInt(ts)
+	return ts, nil
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestLastSaveParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		err error
+	}{
+		{
+			cmd: "lastsave",
+			err: nil,
+		},
+		{
+			cmd: "lastsave now",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseLastSave, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err != nil {
+				testx.AssertEqual(t, cmd, LastSave{})
+			}
+		})
+	}
+}
+
+func TestLastSaveExec(t *testing.T) {
+	t.Run("lastsave", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		saver := getSaver(t, db)
+		_ = saver.Save()
+
+		cmd := redis.MustParse(ParseLastSave, "lastsave")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithSaver(saver))
+		testx.AssertNoErr(t, err)
+		want := int(saver.LastSave().Unix())
+		testx.AssertEqual(t, res, want)
+		testx.AssertEqual(t, conn.Out(), strconv.Itoa(want))
+	})
+}
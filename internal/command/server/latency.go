@@ -0,0 +1,62 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Container command for latency monitor commands.
+// LATENCY subcommand [argument ...]
+// https://redis.io/commands/latency
+type Latency struct {
+	redis.BaseCmd
+	subcmd  string
+	history LatencyHistory
+	latest  LatencyLatest
+	reset   LatencyReset
+}
+
+func ParseLatency(b redis.BaseCmd) (Latency, error) {
+	// Extract the subcommand.
+	cmd := Latency{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return Latency{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "history":
+		cmd.history, err = ParseLatencyHistory(args)
+	case "latest":
+		cmd.latest, err = ParseLatencyLatest(args)
+	case "reset":
+		cmd.reset, err = ParseLatencyReset(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Latency{}, err
+	}
+	return cmd, nil
+}
+
+func (c Latency) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.Stats() == nil {
+		w.WriteError(c.Error(redis.ErrNoStats))
+		return nil, redis.ErrNoStats
+	}
+	switch c.subcmd {
+	case "history":
+		return c.history.Run(w, red)
+	case "latest":
+		return c.latest.Run(w, red)
+	default:
+		return c.reset.Run(w, red)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestLatencyParse(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		subcmd string
+		err    error
+	}{
+		{cmd: "latency", err: redis.ErrInvalidArgNum},
+		{cmd: "latency latest", subcmd: "latest"},
+		{cmd: "latency latest command", err: redis.ErrInvalidArgNum},
+		{cmd: "latency HISTORY command", subcmd: "history"},
+		{cmd: "latency history", err: redis.ErrInvalidArgNum},
+		{cmd: "latency reset", subcmd: "reset"},
+		{cmd: "latency reset command expire-cycle", subcmd: "reset"},
+		{cmd: "latency doctor", err: redis.ErrUnknownSubcmd},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseLatency, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.subcmd, test.subcmd)
+		})
+	}
+}
+
+func TestLatencyExec(t *testing.T) {
+	t.Run("latest", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{LatencyThreshold: time.Millisecond})
+		collector.Latency().Add(stats.EventCommand, 25*time.Millisecond)
+
+		cmd := redis.MustParse(ParseLatency, "latency latest")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(collector))
+		testx.AssertNoErr(t, err)
+		events := res.([]stats.LatencyEvent)
+		testx.AssertEqual(t, len(events), 1)
+		ts := events[0].Latest.Time.Unix()
+		testx.AssertEqual(t, conn.Out(), "1,4,command,"+itoa(ts)+",25,25")
+	})
+	t.Run("history", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{LatencyThreshold: time.Millisecond})
+		collector.Latency().Add(stats.EventExpireCycle, 120*time.Millisecond)
+
+		cmd := redis.MustParse(ParseLatency, "latency history expire-cycle")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(collector))
+		testx.AssertNoErr(t, err)
+		samples := res.([]stats.LatencySample)
+		testx.AssertEqual(t, len(samples), 1)
+		ts := samples[0].Time.Unix()
+		testx.AssertEqual(t, conn.Out(), "1,2,"+itoa(ts)+",120")
+	})
+	t.Run("reset", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{LatencyThreshold: time.Millisecond})
+		collector.Latency().Add(stats.EventCommand, time.Second)
+
+		cmd := redis.MustParse(ParseLatency, "latency reset")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(collector))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "1")
+		testx.AssertEqual(t, len(collector.Latency().Latest()), 0)
+	})
+	t.Run("no stats", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseLatency, "latency latest")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoStats)
+	})
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns timestamp-latency samples for an event.
+// LATENCY HISTORY event
+// https://redis.io/commands/latency-history
+type LatencyHistory struct {
+	event string
+}
+
+func ParseLatencyHistory(args [][]byte) (LatencyHistory, error) {
+	if len(args) != 1 {
+		return LatencyHistory{}, redis.ErrInvalidArgNum
+	}
+	return LatencyHistory{event: string(args[0])}, nil
+}
+
+func (c LatencyHistory) Run(w redis.Writer, red redis.Redka) (any, error) {
+	samples := red.Stats().Latency().History(c.event)
+	w.WriteArray(len(samples))
+	for _, s := range samples {
+		w.WriteArray(2)
+		w.WriteInt64(s.Time.Unix())
+		w.WriteInt64(s.Latency.Milliseconds())
+	}
+	return samples, nil
+}
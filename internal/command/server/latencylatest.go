@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the latest latency samples for all events.
+// LATENCY LATEST
+// https://redis.io/commands/latency-latest
+type LatencyLatest struct{}
+
+func ParseLatencyLatest(args [][]byte) (LatencyLatest, error) {
+	if len(args) != 0 {
+		return LatencyLatest{}, redis.ErrInvalidArgNum
+	}
+	return LatencyLatest{}, nil
+}
+
+func (c LatencyLatest) Run(w redis.Writer, red redis.Redka) (any, error) {
+	events := red.Stats().Latency().Latest()
+	w.WriteArray(len(events))
+	for _, e := range events {
+		w.WriteArray(4)
+		w.WriteBulkString(e.Name)
+		w.WriteInt64(e.Latest.Time.Unix())
+		w.WriteInt64(e.Latest.Latency.Milliseconds())
+		w.WriteInt64(e.Max.Milliseconds())
+	}
+	return events, nil
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Resets the latency data for one or more events.
+// LATENCY RESET [event [event ...]]
+// https://redis.io/commands/latency-reset
+type LatencyReset struct {
+	events []string
+}
+
+func ParseLatencyReset(args [][]byte) (LatencyReset, error) {
+	cmd := LatencyReset{events: make([]string, len(args))}
+	for i, arg := range args {
+		cmd.events[i] = string(arg)
+	}
+	return cmd, nil
+}
+
+func (c LatencyReset) Run(w redis.Writer, red redis.Redka) (any, error) {
+	n := red.Stats().Latency().Reset(c.events...)
+	w.WriteInt(n)
+	return n, nil
+}
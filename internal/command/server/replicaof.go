@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Makes the server a replica of another server,
+// or turns a replica into a leader (NO ONE).
+// The leader is either a host and port, or a path
+// to a database file on the same host.
+// REPLICAOF host port | NO ONE | path
+// https://redis.io/commands/replicaof
+type ReplicaOf struct {
+	redis.BaseCmd
+	addr string
+}
+
+func ParseReplicaOf(b redis.BaseCmd) (ReplicaOf, error) {
+	cmd := ReplicaOf{BaseCmd: b}
+	args := cmd.Args()
+	switch len(args) {
+	case 1:
+		cmd.addr = string(args[0])
+	case 2:
+		host, port := string(args[0]), string(args[1])
+		if strings.EqualFold(host, "no") && strings.EqualFold(port, "one") {
+			return cmd, nil
+		}
+		if _, err := strconv.Atoi(port); err != nil {
+			return ReplicaOf{}, redis.ErrInvalidInt
+		}
+		cmd.addr = net.JoinHostPort(host, port)
+	default:
+		return ReplicaOf{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd ReplicaOf) Run(w redis.Writer, red redis.Redka) (any, error) {
+	manager := red.Repl()
+	if manager == nil {
+		w.WriteError(cmd.Error(redis.ErrNoReplication))
+		return nil, redis.ErrNoReplication
+	}
+	if err := manager.ReplicaOf(cmd.addr); err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/repl"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestReplicaOfParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		addr string
+		err  error
+	}{
+		{
+			cmd:  "replicaof",
+			addr: "",
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "replicaof localhost 6379",
+			addr: "localhost:6379",
+			err:  nil,
+		},
+		{
+			cmd:  "replicaof no one",
+			addr: "",
+			err:  nil,
+		},
+		{
+			cmd:  "replicaof NO ONE",
+			addr: "",
+			err:  nil,
+		},
+		{
+			cmd:  "replicaof /data/leader.db",
+			addr: "/data/leader.db",
+			err:  nil,
+		},
+		{
+			cmd:  "replicaof localhost port",
+			addr: "",
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "replicaof localhost 6379 now",
+			addr: "",
+			err:  redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseReplicaOf, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.addr, test.addr)
+			} else {
+				testx.AssertEqual(t, cmd, ReplicaOf{})
+			}
+		})
+	}
+}
+
+func TestReplicaOfExec(t *testing.T) {
+	t.Run("replicaof", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		manager := repl.NewManager(db, nil)
+		defer manager.Close()
+
+		cmd := redis.MustParse(ParseReplicaOf, "replicaof localhost 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithRepl(manager))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, manager.ReadOnly(), true)
+		testx.AssertEqual(t, manager.Role().Addr, "localhost:1")
+	})
+	t.Run("no one", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		manager := repl.NewManager(db, nil)
+		defer manager.Close()
+		_ = manager.ReplicaOf("localhost:1")
+
+		cmd := redis.MustParse(ParseReplicaOf, "replicaof no one")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithRepl(manager))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, manager.ReadOnly(), false)
+	})
+	t.Run("no replication", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseReplicaOf, "replicaof localhost 6379")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoReplication)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoReplication.Error()+" (replicaof)")
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rkey"
+)
+
+// replLogCount is the default number of changes
+// returned by the REPLLOG command.
+const replLogCount = 1000
+
+// Returns the keys changed after the given change log position
+// (as database index and key name pairs), along with the new position.
+// Used by replicas to follow the changes. With COUNT 0, returns
+// only the latest position.
+// Redka-specific, does not exist in Redis.
+// REPLLOG position [COUNT count]
+type ReplLog struct {
+	redis.BaseCmd
+	after int
+	count int
+}
+
+func ParseReplLog(b redis.BaseCmd) (ReplLog, error) {
+	cmd := ReplLog{BaseCmd: b, count: replLogCount}
+	err := parser.New(
+		parser.Int(&cmd.after),
+		parser.Named("count", parser.Int(&cmd.count)),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return ReplLog{}, err
+	}
+	if cmd.after < 0 || cmd.count < 0 {
+		return ReplLog{}, redis.ErrNegativeCount
+	}
+	return cmd, nil
+}
+
+func (cmd ReplLog) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if cmd.count == 0 {
+		pos, err := red.Key().LastChange()
+		if err != nil {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+		w.WriteArray(1)
+		w.WriteInt64(pos)
+		return rkey.ChangeResult{Pos: pos, Keys: []rkey.ChangedKey{}}, nil
+	}
+
+	res, err := red.Key().Changes(int64(cmd.after), cmd.count)
+	switch err {
+	case nil:
+	case rkey.ErrChangeLogDisabled:
+		w.WriteError(cmd.Error(redis.ErrChangeLogDisabled))
+		return nil, err
+	case rkey.ErrChangeLogTruncated:
+		w.WriteError(cmd.Error(redis.ErrChangeLogTruncated))
+		return nil, err
+	default:
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+
+	w.WriteArray(1 + 2*len(res.Keys))
+	w.WriteInt64(res.Pos)
+	for _, key := range res.Keys {
+		w.WriteInt(key.DB)
+		w.WriteBulkString(key.Key)
+	}
+	return res, nil
+}
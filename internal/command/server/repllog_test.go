@@ -0,0 +1,121 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/rkey"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestReplLogParse(t *testing.T) {
+	tests := []struct {
+		cmd   string
+		after int
+		count int
+		err   error
+	}{
+		{
+			cmd:   "repllog",
+			after: 0,
+			count: 0,
+			err:   redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:   "repllog 10",
+			after: 10,
+			count: 1000,
+			err:   nil,
+		},
+		{
+			cmd:   "repllog 10 count 5",
+			after: 10,
+			count: 5,
+			err:   nil,
+		},
+		{
+			cmd:   "repllog -1",
+			after: 0,
+			count: 0,
+			err:   redis.ErrNegativeCount,
+		},
+		{
+			cmd:   "repllog 10 count -1",
+			after: 0,
+			count: 0,
+			err:   redis.ErrNegativeCount,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseReplLog, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.after, test.after)
+				testx.AssertEqual(t, cmd.count, test.count)
+			} else {
+				testx.AssertEqual(t, cmd, ReplLog{})
+			}
+		})
+	}
+}
+
+func TestReplLogExec(t *testing.T) {
+	t.Run("changes", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.EnableChangeLog()
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(1).Str().Set("age", 25)
+		pos, _ := db.Key().LastChange()
+
+		cmd := redis.MustParse(ParseReplLog, "repllog 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		want := rkey.ChangeResult{Pos: pos, Keys: []rkey.ChangedKey{
+			{DB: 0, Key: "name"}, {DB: 1, Key: "age"},
+		}}
+		testx.AssertEqual(t, res, want)
+		testx.AssertEqual(t, conn.Out(), "5,"+strconv.FormatInt(pos, 10)+",0,name,1,age")
+	})
+	t.Run("last change", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.EnableChangeLog()
+		_ = db.Str().Set("name", "alice")
+		pos, _ := db.Key().LastChange()
+
+		cmd := redis.MustParse(ParseReplLog, "repllog 0 count 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, rkey.ChangeResult{Pos: pos, Keys: []rkey.ChangedKey{}})
+		testx.AssertEqual(t, conn.Out(), "1,"+strconv.FormatInt(pos, 10))
+	})
+	t.Run("truncated", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.EnableChangeLog()
+
+		cmd := redis.MustParse(ParseReplLog, "repllog 10")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, rkey.ErrChangeLogTruncated)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrChangeLogTruncated.Error()+" (repllog)")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseReplLog, "repllog 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, rkey.ErrChangeLogDisabled)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrChangeLogDisabled.Error()+" (repllog)")
+	})
+}
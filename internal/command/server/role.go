@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/repl"
+)
+
+// Returns the replication role.
+// ROLE
+// https://redis.io/commands/role
+type Role struct {
+	redis.BaseCmd
+}
+
+func ParseRole(b redis.BaseCmd) (Role, error) {
+	cmd := Role{BaseCmd: b}
+	if len(cmd.Args()) != 0 {
+		return Role{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd Role) Run(w redis.Writer, red redis.Redka) (any, error) {
+	var role repl.Role
+	if red.Repl() != nil {
+		role = red.Repl().Role()
+	} else {
+		pos, err := red.Key().LastChange()
+		if err != nil {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+		role = repl.Role{Name: repl.RoleLeader, Pos: pos}
+	}
+
+	if role.Name == repl.RoleLeader {
+		// The leader does not track its replicas,
+		// so the replica list is always empty.
+		w.WriteArray(3)
+		w.WriteBulkString(role.Name)
+		w.WriteInt64(role.Pos)
+		w.WriteArray(0)
+		return role, nil
+	}
+
+	// The leader is either a host:port address
+	// or a database file path (reported with port 0).
+	host, port := role.Addr, 0
+	if h, p, err := net.SplitHostPort(role.Addr); err == nil {
+		if n, err := strconv.Atoi(p); err == nil {
+			host, port = h, n
+		}
+	}
+	w.WriteArray(5)
+	w.WriteBulkString(role.Name)
+	w.WriteBulkString(host)
+	w.WriteInt(port)
+	w.WriteBulkString(role.State)
+	w.WriteInt64(role.Pos)
+	return role, nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/repl"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestRoleParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		err error
+	}{
+		{
+			cmd: "role",
+			err: nil,
+		},
+		{
+			cmd: "role master",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseRole, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err != nil {
+				testx.AssertEqual(t, cmd, Role{})
+			}
+		})
+	}
+}
+
+func TestRoleExec(t *testing.T) {
+	t.Run("leader", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		manager := repl.NewManager(db, nil)
+		defer manager.Close()
+
+		cmd := redis.MustParse(ParseRole, "role")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithRepl(manager))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.(repl.Role).Name, repl.RoleLeader)
+		testx.AssertEqual(t, conn.Out(), "3,master,0,0")
+	})
+	t.Run("replica", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		manager := repl.NewManager(db, nil)
+		defer manager.Close()
+		_ = manager.ReplicaOf("localhost:1")
+
+		cmd := redis.MustParse(ParseRole, "role")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithRepl(manager))
+		testx.AssertNoErr(t, err)
+		role := res.(repl.Role)
+		testx.AssertEqual(t, role.Name, repl.RoleReplica)
+		testx.AssertEqual(t, conn.Out(), "5,slave,localhost,1,"+role.State+",0")
+	})
+	t.Run("no replication", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseRole, "role")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.(repl.Role).Name, repl.RoleLeader)
+		testx.AssertEqual(t, conn.Out(), "3,master,0,0")
+	})
+}
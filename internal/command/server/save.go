@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Synchronously saves the database to disk.
+// SAVE
+// https://redis.io/commands/save
+type Save struct {
+	redis.BaseCmd
+}
+
+func ParseSave(b redis.BaseCmd) (Save, error) {
+	cmd := Save{BaseCmd: b}
+	if len(cmd.Args()) != 0 {
+		return Save{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd Save) Run(w redis.Writer, red redis.Redka) (any, error) {
+	saver := red.Saver()
+	if saver == nil {
+		w.WriteError(cmd.Error(redis.ErrNoPersistence))
+		return nil, redis.ErrNoPersistence
+	}
+	err := saver.Save()
+	if err == persist.ErrInProgress {
+		w.WriteError(cmd.Error(redis.ErrBgSaveInProgress))
+		return nil, err
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
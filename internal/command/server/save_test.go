@@ -0,0 +1,65 @@
+package server
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSaveParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		err error
+	}{
+		{
+			cmd: "save",
+			err: nil,
+		},
+		{
+			cmd: "save now",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSave, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err != nil {
+				testx.AssertEqual(t, cmd, Save{})
+			}
+		})
+	}
+}
+
+func TestSaveExec(t *testing.T) {
+	t.Run("save", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		saver := getSaver(t, db)
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseSave, "save")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithSaver(saver))
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		_, err = os.Stat(saver.Path())
+		testx.AssertNoErr(t, err)
+	})
+	t.Run("not configured", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseSave, "save")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoPersistence)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoPersistence.Error()+" (save)")
+	})
+}
@@ -1,9 +1,11 @@
 package server
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/persist"
 	"github.com/flarco/redka/internal/redis"
 )
 
@@ -15,3 +17,24 @@ func getDB(tb testing.TB) (*redka.DB, redis.Redka) {
 	}
 	return db, redis.RedkaDB(db)
 }
+
+func getSaver(tb testing.TB, db *redka.DB) *persist.Saver {
+	tb.Helper()
+	saver, err := persist.New(db, persist.Options{Dir: tb.TempDir()})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return saver
+}
+
+func args(vals ...string) [][]byte {
+	out := make([][]byte, len(vals))
+	for i, val := range vals {
+		out[i] = []byte(val)
+	}
+	return out
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
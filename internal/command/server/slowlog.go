@@ -0,0 +1,65 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Container command for slow log commands.
+// SLOWLOG subcommand [argument ...]
+// https://redis.io/commands/slowlog
+type SlowLog struct {
+	redis.BaseCmd
+	subcmd string
+	get    SlowLogGet
+}
+
+func ParseSlowLog(b redis.BaseCmd) (SlowLog, error) {
+	// Extract the subcommand.
+	cmd := SlowLog{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return SlowLog{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "get":
+		cmd.get, err = ParseSlowLogGet(args)
+	case "len", "reset":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return SlowLog{}, err
+	}
+	return cmd, nil
+}
+
+func (c SlowLog) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if red.Stats() == nil {
+		w.WriteError(c.Error(redis.ErrNoStats))
+		return nil, redis.ErrNoStats
+	}
+	log := red.Stats().SlowLog()
+	switch c.subcmd {
+	case "get":
+		return c.get.Run(w, red)
+	case "len":
+		n := log.Len()
+		w.WriteInt(n)
+		return n, nil
+	default:
+		log.Reset()
+		w.WriteString("OK")
+		return true, nil
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSlowLogParse(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		subcmd string
+		count  int
+		err    error
+	}{
+		{cmd: "slowlog", err: redis.ErrInvalidArgNum},
+		{cmd: "slowlog get", subcmd: "get", count: 10},
+		{cmd: "slowlog GET 5", subcmd: "get", count: 5},
+		{cmd: "slowlog get -1", subcmd: "get", count: -1},
+		{cmd: "slowlog get -2", err: redis.ErrNegativeCount},
+		{cmd: "slowlog get one", err: redis.ErrInvalidInt},
+		{cmd: "slowlog len", subcmd: "len"},
+		{cmd: "slowlog len 1", err: redis.ErrInvalidArgNum},
+		{cmd: "slowlog reset", subcmd: "reset"},
+		{cmd: "slowlog help", err: redis.ErrUnknownSubcmd},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSlowLog, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			testx.AssertEqual(t, cmd.subcmd, test.subcmd)
+			testx.AssertEqual(t, cmd.get.count, test.count)
+		})
+	}
+}
+
+func TestSlowLogExec(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{SlowLogMaxLen: 10})
+		collector.SlowLog().Add(args("get", "name"), "127.0.0.1:5000", "", 1500*time.Microsecond)
+		collector.SlowLog().Add(args("keys", "*"), "127.0.0.1:5001", "alice", 20*time.Millisecond)
+
+		cmd := redis.MustParse(ParseSlowLog, "slowlog get 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red.WithStats(collector))
+		testx.AssertNoErr(t, err)
+		entries := res.([]stats.SlowEntry)
+		testx.AssertEqual(t, len(entries), 1)
+		ts := entries[0].Time.Unix()
+		testx.AssertEqual(t, conn.Out(),
+			"1,6,1,"+itoa(ts)+",20000,2,keys,*,127.0.0.1:5001,alice")
+	})
+	t.Run("len and reset", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		collector := stats.New(db, &stats.Options{SlowLogMaxLen: 10})
+		red = red.WithStats(collector)
+		collector.SlowLog().Add(args("get", "name"), "", "", time.Millisecond)
+
+		cmd := redis.MustParse(ParseSlowLog, "slowlog len")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "1")
+
+		cmd = redis.MustParse(ParseSlowLog, "slowlog reset")
+		conn = redis.NewFakeConn()
+		res, err = cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+		testx.AssertEqual(t, collector.SlowLog().Len(), 0)
+	})
+	t.Run("no stats", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseSlowLog, "slowlog len")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrNoStats)
+		testx.AssertEqual(t, conn.Out(), redis.ErrNoStats.Error()+" (slowlog)")
+	})
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the slow log's entries.
+// SLOWLOG GET [count]
+// https://redis.io/commands/slowlog-get
+type SlowLogGet struct {
+	count int
+}
+
+func ParseSlowLogGet(args [][]byte) (SlowLogGet, error) {
+	cmd := SlowLogGet{count: 10}
+	err := parser.New(
+		parser.Int(&cmd.count),
+	).Required(0).Run(args)
+	if err != nil {
+		return SlowLogGet{}, err
+	}
+	if cmd.count < -1 {
+		return SlowLogGet{}, redis.ErrNegativeCount
+	}
+	return cmd, nil
+}
+
+func (c SlowLogGet) Run(w redis.Writer, red redis.Redka) (any, error) {
+	entries := red.Stats().SlowLog().Entries(c.count)
+	w.WriteArray(len(entries))
+	for _, e := range entries {
+		w.WriteArray(6)
+		w.WriteInt64(e.ID)
+		w.WriteInt64(e.Time.Unix())
+		w.WriteInt64(e.Duration.Microseconds())
+		w.WriteArray(len(e.Args))
+		for _, arg := range e.Args {
+			w.WriteBulkString(arg)
+		}
+		w.WriteBulkString(e.Addr)
+		w.WriteBulkString(e.Name)
+	}
+	return entries, nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Swaps two databases, so that the clients connected
+// to one database see the keys of the other one.
+// SWAPDB index1 index2
+// https://redis.io/commands/swapdb
+type SwapDB struct {
+	redis.BaseCmd
+	index1 int
+	index2 int
+}
+
+func ParseSwapDB(b redis.BaseCmd) (SwapDB, error) {
+	cmd := SwapDB{BaseCmd: b}
+	err := parser.New(
+		parser.Int(&cmd.index1),
+		parser.Int(&cmd.index2),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return SwapDB{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd SwapDB) Run(w redis.Writer, red redis.Redka) (any, error) {
+	for _, index := range []int{cmd.index1, cmd.index2} {
+		if index < 0 || index >= redis.Databases {
+			w.WriteError(cmd.Error(redis.ErrDBIndex))
+			return nil, redis.ErrDBIndex
+		}
+	}
+	err := red.Key().SwapDB(cmd.index1, cmd.index2)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSwapDBParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want SwapDB
+		err  error
+	}{
+		{
+			cmd:  "swapdb",
+			want: SwapDB{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "swapdb 0",
+			want: SwapDB{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "swapdb 0 1",
+			want: SwapDB{index1: 0, index2: 1},
+			err:  nil,
+		},
+		{
+			cmd:  "swapdb 0 one",
+			want: SwapDB{},
+			err:  redis.ErrInvalidInt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSwapDB, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.index1, test.want.index1)
+				testx.AssertEqual(t, cmd.index2, test.want.index2)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSwapDBExec(t *testing.T) {
+	t.Run("swap", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(1).Str().Set("name", "bob")
+		_ = db.Select(1).Str().Set("age", 25)
+
+		cmd := redis.MustParse(ParseSwapDB, "swapdb 0 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "bob")
+		count, _ := db.Key().Len()
+		testx.AssertEqual(t, count, 2)
+		name, _ = db.Select(1).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("same database", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseSwapDB, "swapdb 0 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, true)
+		testx.AssertEqual(t, conn.Out(), "OK")
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("out of range", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseSwapDB, "swapdb 0 16")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertErr(t, err, redis.ErrDBIndex)
+		testx.AssertEqual(t, res, nil)
+		testx.AssertEqual(t, conn.Out(), redis.ErrDBIndex.Error()+" (swapdb)")
+	})
+}
@@ -0,0 +1,42 @@
+package set
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Returns the number of members of the intersect of multiple sets.
+// SINTERCARD numkeys key [key ...] [LIMIT limit]
+// https://redis.io/commands/sintercard
+type SInterCard struct {
+	redis.BaseCmd
+	keys  []string
+	limit int
+}
+
+func ParseSInterCard(b redis.BaseCmd) (SInterCard, error) {
+	cmd := SInterCard{BaseCmd: b}
+	var nKeys int
+	err := parser.New(
+		parser.Int(&nKeys),
+		parser.StringsN(&cmd.keys, &nKeys),
+		parser.Named("limit", parser.Int(&cmd.limit)),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return SInterCard{}, err
+	}
+	if cmd.limit < 0 {
+		return SInterCard{}, redis.ErrNegativeCount
+	}
+	return cmd, nil
+}
+
+func (cmd SInterCard) Run(w redis.Writer, red redis.Redka) (any, error) {
+	n, err := red.Set().InterLen(cmd.limit, cmd.keys...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteInt(n)
+	return n, nil
+}
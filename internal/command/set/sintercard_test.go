@@ -0,0 +1,124 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSInterCardParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want SInterCard
+		err  error
+	}{
+		{
+			cmd:  "sintercard",
+			want: SInterCard{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "sintercard 1",
+			want: SInterCard{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "sintercard 1 key",
+			want: SInterCard{keys: []string{"key"}},
+			err:  nil,
+		},
+		{
+			cmd:  "sintercard 2 k1 k2",
+			want: SInterCard{keys: []string{"k1", "k2"}},
+			err:  nil,
+		},
+		{
+			cmd:  "sintercard 2 k1 k2 limit 10",
+			want: SInterCard{keys: []string{"k1", "k2"}, limit: 10},
+			err:  nil,
+		},
+		{
+			cmd:  "sintercard 2 k1 k2 limit -1",
+			want: SInterCard{},
+			err:  redis.ErrNegativeCount,
+		},
+		{
+			cmd:  "sintercard 3 k1 k2",
+			want: SInterCard{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "sintercard 1 k1 k2",
+			want: SInterCard{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSInterCard, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.keys, test.want.keys)
+				testx.AssertEqual(t, cmd.limit, test.want.limit)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSInterCardExec(t *testing.T) {
+	t.Run("inter", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key1", "one", "two", "thr")
+		_, _ = db.Set().Add("key2", "two", "thr", "fou")
+
+		cmd := redis.MustParse(ParseSInterCard, "sintercard 2 key1 key2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 2)
+		testx.AssertEqual(t, conn.Out(), "2")
+	})
+	t.Run("limit", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key1", "one", "two", "thr")
+		_, _ = db.Set().Add("key2", "two", "thr", "fou")
+
+		cmd := redis.MustParse(ParseSInterCard, "sintercard 2 key1 key2 limit 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 1)
+		testx.AssertEqual(t, conn.Out(), "1")
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key1", "one", "two", "thr")
+
+		cmd := redis.MustParse(ParseSInterCard, "sintercard 2 key1 key2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key1", "one", "two", "thr")
+		_ = db.Str().Set("key2", "one")
+
+		cmd := redis.MustParse(ParseSInterCard, "sintercard 2 key1 key2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, 0)
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
+}
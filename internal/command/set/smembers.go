@@ -25,7 +25,7 @@ func (cmd SMembers) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	w.WriteArray(len(items))
+	w.WriteSet(len(items))
 	for _, val := range items {
 		w.WriteBulk(val)
 	}
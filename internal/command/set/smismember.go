@@ -0,0 +1,44 @@
+package set
+
+import (
+	"github.com/flarco/redka/internal/parser"
+	"github.com/flarco/redka/internal/redis"
+)
+
+// Determines whether multiple members belong to a set.
+// SMISMEMBER key member [member ...]
+// https://redis.io/commands/smismember
+type SMIsMember struct {
+	redis.BaseCmd
+	key     string
+	members []any
+}
+
+func ParseSMIsMember(b redis.BaseCmd) (SMIsMember, error) {
+	cmd := SMIsMember{BaseCmd: b}
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Anys(&cmd.members),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return SMIsMember{}, err
+	}
+	return cmd, nil
+}
+
+func (cmd SMIsMember) Run(w redis.Writer, red redis.Redka) (any, error) {
+	exists, err := red.Set().ExistsMany(cmd.key, cmd.members...)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteArray(len(exists))
+	for _, ok := range exists {
+		if ok {
+			w.WriteInt(1)
+		} else {
+			w.WriteInt(0)
+		}
+	}
+	return exists, nil
+}
@@ -0,0 +1,88 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSMIsMemberParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want SMIsMember
+		err  error
+	}{
+		{
+			cmd:  "smismember",
+			want: SMIsMember{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "smismember key",
+			want: SMIsMember{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "smismember key one",
+			want: SMIsMember{key: "key", members: []any{"one"}},
+			err:  nil,
+		},
+		{
+			cmd:  "smismember key one two",
+			want: SMIsMember{key: "key", members: []any{"one", "two"}},
+			err:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseSMIsMember, test.cmd)
+			testx.AssertEqual(t, err, test.err)
+			if err == nil {
+				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.members, test.want.members)
+			} else {
+				testx.AssertEqual(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSMIsMemberExec(t *testing.T) {
+	t.Run("elems", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one", "thr")
+
+		cmd := redis.MustParse(ParseSMIsMember, "smismember key one two thr")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []bool{true, false, true})
+		testx.AssertEqual(t, conn.Out(), "3,1,0,1")
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseSMIsMember, "smismember key one two")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []bool{false, false})
+		testx.AssertEqual(t, conn.Out(), "2,0,0")
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("key", "one")
+
+		cmd := redis.MustParse(ParseSMIsMember, "smismember key one")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []bool{false})
+		testx.AssertEqual(t, conn.Out(), "1,0")
+	})
+}
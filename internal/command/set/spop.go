@@ -2,27 +2,40 @@ package set
 
 import (
 	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
 	"github.com/flarco/redka/internal/redis"
 )
 
-// Returns a random member from a set after removing it.
-// SPOP key
+// Returns one or more random members from a set after removing them.
+// SPOP key [count]
 // https://redis.io/commands/spop
 type SPop struct {
 	redis.BaseCmd
-	key string
+	key       string
+	count     int
+	withCount bool
 }
 
 func ParseSPop(b redis.BaseCmd) (SPop, error) {
 	cmd := SPop{BaseCmd: b}
-	if len(cmd.Args()) != 1 {
-		return SPop{}, redis.ErrInvalidArgNum
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.count),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return SPop{}, err
+	}
+	cmd.withCount = len(cmd.Args()) == 2
+	if cmd.count < 0 {
+		return SPop{}, redis.ErrNegativeCount
 	}
-	cmd.key = string(cmd.Args()[0])
 	return cmd, nil
 }
 
 func (cmd SPop) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if cmd.withCount {
+		return cmd.runMany(w, red)
+	}
 	elem, err := red.Set().Pop(cmd.key)
 	if err == core.ErrNotFound {
 		w.WriteNull()
@@ -35,3 +48,17 @@ func (cmd SPop) Run(w redis.Writer, red redis.Redka) (any, error) {
 	w.WriteBulk(elem)
 	return elem, nil
 }
+
+// runMany pops up to count members.
+func (cmd SPop) runMany(w redis.Writer, red redis.Redka) (any, error) {
+	elems, err := red.Set().PopMany(cmd.key, cmd.count)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteSet(len(elems))
+	for _, elem := range elems {
+		w.WriteBulk(elem)
+	}
+	return elems, nil
+}
@@ -26,8 +26,18 @@ func TestSPopParse(t *testing.T) {
 		},
 		{
 			cmd:  "spop key 5",
+			want: SPop{key: "key", count: 5, withCount: true},
+			err:  nil,
+		},
+		{
+			cmd:  "spop key -5",
 			want: SPop{},
-			err:  redis.ErrInvalidArgNum,
+			err:  redis.ErrNegativeCount,
+		},
+		{
+			cmd:  "spop key 5 6",
+			want: SPop{},
+			err:  redis.ErrSyntaxError,
 		},
 	}
 
@@ -37,6 +47,8 @@ func TestSPopParse(t *testing.T) {
 			testx.AssertEqual(t, err, test.err)
 			if err == nil {
 				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.count, test.want.count)
+				testx.AssertEqual(t, cmd.withCount, test.want.withCount)
 			} else {
 				testx.AssertEqual(t, cmd, test.want)
 			}
@@ -62,6 +74,47 @@ func TestSPopExec(t *testing.T) {
 		slen, _ := db.Set().Len("key")
 		testx.AssertEqual(t, slen, 2)
 	})
+	t.Run("pop count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one", "two", "thr")
+
+		cmd := redis.MustParse(ParseSPop, "spop key 2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(res.([]core.Value)), 2)
+		testx.AssertEqual(t, conn.Out()[:2], "2,")
+
+		slen, _ := db.Set().Len("key")
+		testx.AssertEqual(t, slen, 1)
+	})
+	t.Run("pop count exceeds len", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one")
+
+		cmd := redis.MustParse(ParseSPop, "spop key 5")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{core.Value("one")})
+		testx.AssertEqual(t, conn.Out(), "1,one")
+
+		slen, _ := db.Set().Len("key")
+		testx.AssertEqual(t, slen, 0)
+	})
+	t.Run("key not found with count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+
+		cmd := redis.MustParse(ParseSPop, "spop key 2")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{})
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
 	t.Run("key not found", func(t *testing.T) {
 		db, red := getDB(t)
 		defer db.Close()
@@ -2,27 +2,37 @@ package set
 
 import (
 	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/parser"
 	"github.com/flarco/redka/internal/redis"
 )
 
-// Get a random member from a set.
-// SRANDMEMBER key
+// Get one or multiple random members from a set.
+// SRANDMEMBER key [count]
 // https://redis.io/commands/srandmember
 type SRandMember struct {
 	redis.BaseCmd
-	key string
+	key       string
+	count     int
+	withCount bool
 }
 
 func ParseSRandMember(b redis.BaseCmd) (SRandMember, error) {
 	cmd := SRandMember{BaseCmd: b}
-	if len(cmd.Args()) != 1 {
-		return SRandMember{}, redis.ErrInvalidArgNum
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.count),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return SRandMember{}, err
 	}
-	cmd.key = string(cmd.Args()[0])
+	cmd.withCount = len(cmd.Args()) == 2
 	return cmd, nil
 }
 
 func (cmd SRandMember) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if cmd.withCount {
+		return cmd.runMany(w, red)
+	}
 	elem, err := red.Set().Random(cmd.key)
 	if err == core.ErrNotFound {
 		w.WriteNull()
@@ -35,3 +45,18 @@ func (cmd SRandMember) Run(w redis.Writer, red redis.Redka) (any, error) {
 	w.WriteBulk(elem)
 	return elem, nil
 }
+
+// runMany returns count members. Positive count returns distinct
+// members, negative count allows the same member multiple times.
+func (cmd SRandMember) runMany(w redis.Writer, red redis.Redka) (any, error) {
+	elems, err := red.Set().RandomMany(cmd.key, cmd.count)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteArray(len(elems))
+	for _, elem := range elems {
+		w.WriteBulk(elem)
+	}
+	return elems, nil
+}
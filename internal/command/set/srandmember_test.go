@@ -26,8 +26,18 @@ func TestSRandMemberParse(t *testing.T) {
 		},
 		{
 			cmd:  "srandmember key 5",
+			want: SRandMember{key: "key", count: 5, withCount: true},
+			err:  nil,
+		},
+		{
+			cmd:  "srandmember key -5",
+			want: SRandMember{key: "key", count: -5, withCount: true},
+			err:  nil,
+		},
+		{
+			cmd:  "srandmember key one",
 			want: SRandMember{},
-			err:  redis.ErrInvalidArgNum,
+			err:  redis.ErrInvalidInt,
 		},
 	}
 
@@ -37,6 +47,8 @@ func TestSRandMemberParse(t *testing.T) {
 			testx.AssertEqual(t, err, test.err)
 			if err == nil {
 				testx.AssertEqual(t, cmd.key, test.want.key)
+				testx.AssertEqual(t, cmd.count, test.want.count)
+				testx.AssertEqual(t, cmd.withCount, test.want.withCount)
 			} else {
 				testx.AssertEqual(t, cmd, test.want)
 			}
@@ -59,6 +71,48 @@ func TestSRandMemberExec(t *testing.T) {
 		s = conn.Out()
 		testx.AssertEqual(t, s == "one" || s == "two" || s == "thr", true)
 	})
+	t.Run("positive count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one", "two", "thr")
+
+		cmd := redis.MustParse(ParseSRandMember, "srandmember key 5")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		elems := res.([]core.Value)
+		sortValues(elems)
+		testx.AssertEqual(t, elems, []core.Value{
+			core.Value("one"), core.Value("thr"), core.Value("two"),
+		})
+		testx.AssertEqual(t, conn.Out()[:2], "3,")
+	})
+	t.Run("negative count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one")
+
+		cmd := redis.MustParse(ParseSRandMember, "srandmember key -3")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{
+			core.Value("one"), core.Value("one"), core.Value("one"),
+		})
+		testx.AssertEqual(t, conn.Out(), "3,one,one,one")
+	})
+	t.Run("zero count", func(t *testing.T) {
+		db, red := getDB(t)
+		defer db.Close()
+		_, _ = db.Set().Add("key", "one")
+
+		cmd := redis.MustParse(ParseSRandMember, "srandmember key 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []core.Value{})
+		testx.AssertEqual(t, conn.Out(), "0")
+	})
 	t.Run("key not found", func(t *testing.T) {
 		db, red := getDB(t)
 		defer db.Close()
@@ -30,7 +30,7 @@ func (cmd SUnion) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	w.WriteArray(len(elems))
+	w.WriteSet(len(elems))
 	for _, elem := range elems {
 		w.WriteBulk(elem)
 	}
@@ -34,6 +34,6 @@ func (cmd ZIncrBy) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	redis.WriteFloat(w, score)
+	w.WriteDouble(score)
 	return score, nil
 }
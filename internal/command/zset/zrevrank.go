@@ -42,7 +42,7 @@ func (cmd ZRevRank) Run(w redis.Writer, red redis.Redka) (any, error) {
 	if cmd.withScore {
 		w.WriteArray(2)
 		w.WriteInt(rank)
-		redis.WriteFloat(w, score)
+		w.WriteDouble(score)
 		return rank, nil
 	}
 	w.WriteInt(rank)
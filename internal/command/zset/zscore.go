@@ -37,6 +37,6 @@ func (cmd ZScore) Run(w redis.Writer, red redis.Redka) (any, error) {
 		w.WriteError(cmd.Error(err))
 		return nil, err
 	}
-	redis.WriteFloat(w, score)
+	w.WriteDouble(score)
 	return score, nil
 }
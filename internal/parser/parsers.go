@@ -199,6 +199,23 @@ func Named(name string, parsers ...ParserFunc) ParserFunc {
 	}
 }
 
+// NamedStrings parses a repeated named argument
+// (like GET pattern [GET pattern ...]) as a slice of strings.
+func NamedStrings(name string, dest *[]string) ParserFunc {
+	return func(args [][]byte) (bool, [][]byte, error) {
+		fired := false
+		for len(args) > 0 && strings.EqualFold(string(args[0]), name) {
+			if len(args) < 2 {
+				return true, args, ErrSyntaxError
+			}
+			*dest = append(*dest, string(args[1]))
+			args = args[2:]
+			fired = true
+		}
+		return fired, args, nil
+	}
+}
+
 // OneOf parses the arguments with one of the given parsers.
 // Returns an error if more than one parser fires.
 func OneOf(parsers ...ParserFunc) ParserFunc {
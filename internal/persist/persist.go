@@ -0,0 +1,213 @@
+// Package persist manages on-demand database snapshots
+// (the SAVE and BGSAVE commands).
+package persist
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/rdb"
+)
+
+// Snapshot file formats.
+const (
+	FormatSQLite = "sqlite" // SQLite database file
+	FormatRDB    = "rdb"    // Redis RDB file
+)
+
+// Default snapshot file names.
+const (
+	DefaultSQLiteName = "dump.db"
+	DefaultRDBName    = "dump.rdb"
+)
+
+var (
+	ErrInProgress    = errors.New("save already in progress")
+	ErrUnknownFormat = errors.New("unknown snapshot format")
+)
+
+// Options configure where and how snapshots are saved.
+type Options struct {
+	// Dir is the directory for snapshot files.
+	// If empty, uses the current directory.
+	Dir string
+	// Filename is the snapshot file name. If empty, uses
+	// DefaultSQLiteName or DefaultRDBName depending on Format.
+	Filename string
+	// Format is the snapshot file format.
+	// If empty, uses FormatSQLite.
+	Format string
+}
+
+// Status describes the state of snapshots.
+type Status struct {
+	InProgress   bool          // a save is in progress
+	CurrentStart time.Time     // when the current save started
+	LastSave     time.Time     // last successful save (or startup) time
+	LastOK       bool          // whether the last save succeeded
+	LastDuration time.Duration // duration of the last save
+}
+
+// Saver saves database snapshots to a file,
+// either synchronously or in the background.
+// Only one save can run at a time.
+type Saver struct {
+	db     *redka.DB
+	path   string
+	format string
+
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	scheduled bool
+	status    Status
+}
+
+// New creates a new snapshot saver for the database.
+func New(db *redka.DB, opts Options) (*Saver, error) {
+	format := opts.Format
+	if format == "" {
+		format = FormatSQLite
+	}
+	filename := opts.Filename
+	switch format {
+	case FormatSQLite:
+		if filename == "" {
+			filename = DefaultSQLiteName
+		}
+	case FormatRDB:
+		if filename == "" {
+			filename = DefaultRDBName
+		}
+	default:
+		return nil, ErrUnknownFormat
+	}
+	s := &Saver{
+		db:     db,
+		path:   filepath.Join(opts.Dir, filename),
+		format: format,
+		status: Status{LastSave: time.Now(), LastOK: true},
+	}
+	return s, nil
+}
+
+// Path returns the snapshot file path.
+func (s *Saver) Path() string {
+	return s.path
+}
+
+// Save saves a snapshot and waits for it to complete.
+// Returns ErrInProgress if another save is running.
+func (s *Saver) Save() error {
+	if !s.start() {
+		return ErrInProgress
+	}
+	return s.run()
+}
+
+// BgSave starts saving a snapshot in the background.
+// Returns ErrInProgress if another save is running.
+func (s *Saver) BgSave() error {
+	if !s.start() {
+		return ErrInProgress
+	}
+	s.wg.Add(1)
+	go s.runBackground()
+	return nil
+}
+
+// Schedule starts saving a snapshot in the background,
+// or schedules it to start when the running save completes.
+// Returns true if the save has started, false if it's scheduled.
+func (s *Saver) Schedule() bool {
+	// Check and schedule under the same lock that the running
+	// save holds when it completes, so the schedule isn't lost.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.InProgress {
+		s.scheduled = true
+		return false
+	}
+	s.status.InProgress = true
+	s.status.CurrentStart = time.Now()
+	s.wg.Add(1)
+	go s.runBackground()
+	return true
+}
+
+// LastSave returns the time of the last successful save.
+func (s *Saver) LastSave() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status.LastSave
+}
+
+// Status returns the current snapshot status.
+func (s *Saver) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Wait waits for the background saves to complete.
+func (s *Saver) Wait() {
+	s.wg.Wait()
+}
+
+// start marks the save as in progress.
+// Returns false if another save is already running.
+func (s *Saver) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.InProgress {
+		return false
+	}
+	s.status.InProgress = true
+	s.status.CurrentStart = time.Now()
+	return true
+}
+
+// runBackground saves a snapshot in the background.
+func (s *Saver) runBackground() {
+	defer s.wg.Done()
+	if err := s.run(); err != nil {
+		slog.Error("background save", "path", s.path, "error", err)
+	} else {
+		slog.Info("background save", "path", s.path)
+	}
+}
+
+// run saves a snapshot and updates the status.
+// The save must be marked as in progress.
+// Starts the scheduled save (if any) when done.
+func (s *Saver) run() error {
+	var err error
+	switch s.format {
+	case FormatRDB:
+		_, err = rdb.SaveFile(s.db, s.path)
+	default:
+		err = s.db.Snapshot(s.path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.status.InProgress = false
+	s.status.LastOK = err == nil
+	s.status.LastDuration = now.Sub(s.status.CurrentStart)
+	s.status.CurrentStart = time.Time{}
+	if err == nil {
+		s.status.LastSave = now
+	}
+	if s.scheduled {
+		s.scheduled = false
+		s.status.InProgress = true
+		s.status.CurrentStart = now
+		s.wg.Add(1)
+		go s.runBackground()
+	}
+	return err
+}
@@ -0,0 +1,209 @@
+package persist_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/rdb"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestNew(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	t.Run("default", func(t *testing.T) {
+		saver, err := persist.New(db, persist.Options{})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, saver.Path(), "dump.db")
+	})
+	t.Run("rdb", func(t *testing.T) {
+		saver, err := persist.New(db, persist.Options{Dir: "/data", Format: persist.FormatRDB})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, saver.Path(), "/data/dump.rdb")
+	})
+	t.Run("filename", func(t *testing.T) {
+		saver, err := persist.New(db, persist.Options{Dir: "/data", Filename: "backup.db"})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, saver.Path(), "/data/backup.db")
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := persist.New(db, persist.Options{Format: "json"})
+		testx.AssertErr(t, err, persist.ErrUnknownFormat)
+	})
+}
+
+func TestSave(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+
+		dir := t.TempDir()
+		saver, _ := persist.New(db, persist.Options{Dir: dir})
+		before := saver.LastSave()
+		err := saver.Save()
+		testx.AssertNoErr(t, err)
+
+		status := saver.Status()
+		testx.AssertEqual(t, status.InProgress, false)
+		testx.AssertEqual(t, status.LastOK, true)
+		testx.AssertEqual(t, status.LastSave.After(before), true)
+
+		snap, err := redka.OpenRead(filepath.Join(dir, "dump.db"), nil)
+		testx.AssertNoErr(t, err)
+		defer snap.Close()
+		name, _ := snap.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("rdb", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+
+		dir := t.TempDir()
+		saver, _ := persist.New(db, persist.Options{Dir: dir, Format: persist.FormatRDB})
+		err := saver.Save()
+		testx.AssertNoErr(t, err)
+
+		file, err := os.Open(filepath.Join(dir, "dump.rdb"))
+		testx.AssertNoErr(t, err)
+		defer file.Close()
+		rd, err := rdb.NewReader(file)
+		testx.AssertNoErr(t, err)
+		e, err := rd.Next()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, e.Key, "name")
+	})
+	t.Run("failed", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		dir := filepath.Join(t.TempDir(), "missing")
+		saver, _ := persist.New(db, persist.Options{Dir: dir})
+		before := saver.LastSave()
+		err := saver.Save()
+		testx.AssertEqual(t, err != nil, true)
+
+		status := saver.Status()
+		testx.AssertEqual(t, status.LastOK, false)
+		testx.AssertEqual(t, status.LastSave, before)
+	})
+}
+
+func TestBgSave(t *testing.T) {
+	t.Run("bgsave", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+
+		dir := t.TempDir()
+		saver, _ := persist.New(db, persist.Options{Dir: dir})
+		err := saver.BgSave()
+		testx.AssertNoErr(t, err)
+		saver.Wait()
+
+		testx.AssertEqual(t, saver.Status().InProgress, false)
+		_, err = os.Stat(filepath.Join(dir, "dump.db"))
+		testx.AssertNoErr(t, err)
+	})
+	t.Run("in progress", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		saver, _ := persist.New(db, persist.Options{Dir: t.TempDir()})
+		err := saver.BgSave()
+		testx.AssertNoErr(t, err)
+		// The background save may complete before the second call,
+		// so retry until the conflict is observed or give up.
+		var got error
+		for i := 0; i < 100 && got == nil; i++ {
+			if got = saver.BgSave(); got == nil {
+				saver.Wait()
+			}
+		}
+		saver.Wait()
+		if got != nil {
+			testx.AssertErr(t, got, persist.ErrInProgress)
+		}
+	})
+	t.Run("schedule", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		saver, _ := persist.New(db, persist.Options{Dir: t.TempDir()})
+		started := saver.Schedule()
+		testx.AssertEqual(t, started, true)
+		saver.Schedule()
+		saver.Wait()
+
+		status := saver.Status()
+		testx.AssertEqual(t, status.InProgress, false)
+		testx.AssertEqual(t, status.LastOK, true)
+		testx.AssertEqual(t, time.Since(status.LastSave) < time.Minute, true)
+	})
+	t.Run("schedule while saving", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		// Enough keys for the saves to overlap.
+		err := db.Update(func(tx *redka.Tx) error {
+			for i := range 10000 {
+				if err := tx.Str().Set("key:"+strconv.Itoa(i), i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		testx.AssertNoErr(t, err)
+
+		dir := t.TempDir()
+		saver, _ := persist.New(db, persist.Options{Dir: dir})
+		for i := range 20 {
+			done := make(chan struct{})
+			go func() {
+				_ = saver.Save()
+				close(done)
+			}()
+			for !saver.Status().InProgress && !isClosed(done) {
+				time.Sleep(time.Millisecond)
+			}
+			// The running save may miss the change,
+			// but the scheduled one must include it.
+			_ = db.Str().Set("name", i)
+			saver.Schedule()
+			<-done
+			saver.Wait()
+
+			snap, err := redka.OpenRead(filepath.Join(dir, "dump.db"), nil)
+			testx.AssertNoErr(t, err)
+			name, _ := snap.Str().Get("name")
+			_ = snap.Close()
+			testx.AssertEqual(t, name.String(), strconv.Itoa(i))
+		}
+	})
+}
+
+// isClosed reports whether the channel is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func getDB(tb testing.TB) *redka.DB {
+	tb.Helper()
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,328 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// decodeZiplist decodes a ziplist into a list of entries.
+// Integer entries are converted to their decimal representation.
+func decodeZiplist(b []byte) ([][]byte, error) {
+	// zlbytes (4), zltail (4), zllen (2), entries, 0xFF.
+	if len(b) < 11 {
+		return nil, ErrFormat
+	}
+	var entries [][]byte
+	p := 10
+	for {
+		if p >= len(b) {
+			return nil, ErrFormat
+		}
+		if b[p] == 0xFF {
+			return entries, nil
+		}
+
+		// Skip the previous entry length.
+		if b[p] == 0xFE {
+			p += 5
+		} else {
+			p++
+		}
+		if p >= len(b) {
+			return nil, ErrFormat
+		}
+
+		// Read the entry according to its encoding.
+		enc := b[p]
+		var n int
+		switch enc >> 6 {
+		case 0:
+			n = int(enc & 0x3f)
+			p++
+		case 1:
+			if p+2 > len(b) {
+				return nil, ErrFormat
+			}
+			n = int(enc&0x3f)<<8 | int(b[p+1])
+			p += 2
+		case 2:
+			if p+5 > len(b) {
+				return nil, ErrFormat
+			}
+			n = int(binary.BigEndian.Uint32(b[p+1:]))
+			p += 5
+		default:
+			val, size, err := ziplistInt(b[p:])
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, strconv.AppendInt(nil, val, 10))
+			p += size
+			continue
+		}
+		if n < 0 || p+n > len(b) {
+			return nil, ErrFormat
+		}
+		entries = append(entries, b[p:p+n])
+		p += n
+	}
+}
+
+// ziplistInt decodes an integer ziplist entry.
+// Returns the value and the entry size (including the encoding byte).
+func ziplistInt(b []byte) (int64, int, error) {
+	enc := b[0]
+	need := map[byte]int{0xC0: 2, 0xD0: 4, 0xE0: 8, 0xF0: 3, 0xFE: 1}
+	if size, ok := need[enc]; ok && len(b) < 1+size {
+		return 0, 0, ErrFormat
+	}
+	switch {
+	case enc == 0xC0:
+		return int64(int16(binary.LittleEndian.Uint16(b[1:]))), 3, nil
+	case enc == 0xD0:
+		return int64(int32(binary.LittleEndian.Uint32(b[1:]))), 5, nil
+	case enc == 0xE0:
+		return int64(binary.LittleEndian.Uint64(b[1:])), 9, nil
+	case enc == 0xF0:
+		v := int32(uint32(b[1])<<8|uint32(b[2])<<16|uint32(b[3])<<24) >> 8
+		return int64(v), 4, nil
+	case enc == 0xFE:
+		return int64(int8(b[1])), 2, nil
+	case enc >= 0xF1 && enc <= 0xFD:
+		return int64(enc&0x0f) - 1, 1, nil
+	}
+	return 0, 0, ErrFormat
+}
+
+// decodeListpack decodes a listpack into a list of entries.
+// Integer entries are converted to their decimal representation.
+func decodeListpack(b []byte) ([][]byte, error) {
+	// total bytes (4), num elements (2), entries, 0xFF.
+	if len(b) < 7 {
+		return nil, ErrFormat
+	}
+	var entries [][]byte
+	p := 6
+	for {
+		if p >= len(b) {
+			return nil, ErrFormat
+		}
+		enc := b[p]
+		if enc == 0xFF {
+			return entries, nil
+		}
+
+		var entry []byte
+		var size int // encoding + data size
+		switch {
+		case enc&0x80 == 0:
+			// 7-bit unsigned int
+			entry = strconv.AppendInt(nil, int64(enc&0x7f), 10)
+			size = 1
+		case enc&0xC0 == 0x80:
+			// 6-bit length string
+			n := int(enc & 0x3f)
+			size = 1 + n
+			if p+size > len(b) {
+				return nil, ErrFormat
+			}
+			entry = b[p+1 : p+size]
+		case enc&0xE0 == 0xC0:
+			// 13-bit signed int
+			if p+2 > len(b) {
+				return nil, ErrFormat
+			}
+			v := int64(enc&0x1f)<<8 | int64(b[p+1])
+			if v >= 1<<12 {
+				v -= 1 << 13
+			}
+			entry = strconv.AppendInt(nil, v, 10)
+			size = 2
+		case enc&0xF0 == 0xE0:
+			// 12-bit length string
+			if p+2 > len(b) {
+				return nil, ErrFormat
+			}
+			n := int(enc&0x0f)<<8 | int(b[p+1])
+			size = 2 + n
+			if p+size > len(b) {
+				return nil, ErrFormat
+			}
+			entry = b[p+2 : p+size]
+		case enc == 0xF0:
+			// 32-bit length string
+			if p+5 > len(b) {
+				return nil, ErrFormat
+			}
+			n := int(binary.LittleEndian.Uint32(b[p+1:]))
+			size = 5 + n
+			if n < 0 || p+size > len(b) {
+				return nil, ErrFormat
+			}
+			entry = b[p+5 : p+size]
+		case enc >= 0xF1 && enc <= 0xF4:
+			// 16, 24, 32 or 64-bit signed int
+			nbytes := map[byte]int{0xF1: 2, 0xF2: 3, 0xF3: 4, 0xF4: 8}[enc]
+			size = 1 + nbytes
+			if p+size > len(b) {
+				return nil, ErrFormat
+			}
+			var u uint64
+			for i := nbytes - 1; i >= 0; i-- {
+				u = u<<8 | uint64(b[p+1+i])
+			}
+			// Sign-extend to 64 bits.
+			shift := 64 - 8*nbytes
+			v := int64(u<<shift) >> shift
+			entry = strconv.AppendInt(nil, v, 10)
+		default:
+			return nil, ErrFormat
+		}
+		entries = append(entries, entry)
+		p += size + listpackBacklen(size)
+	}
+}
+
+// listpackBacklen returns the number of bytes used
+// to store the back length of a listpack entry.
+func listpackBacklen(size int) int {
+	switch {
+	case size <= 127:
+		return 1
+	case size < 16383:
+		return 2
+	case size < 2097151:
+		return 3
+	case size < 268435455:
+		return 4
+	}
+	return 5
+}
+
+// decodeIntset decodes an intset into a list of
+// decimal representations of its integers.
+func decodeIntset(b []byte) ([][]byte, error) {
+	// encoding (4), length (4), contents.
+	if len(b) < 8 {
+		return nil, ErrFormat
+	}
+	width := int(binary.LittleEndian.Uint32(b))
+	n := int(binary.LittleEndian.Uint32(b[4:]))
+	if width != 2 && width != 4 && width != 8 {
+		return nil, ErrFormat
+	}
+	if n < 0 || len(b) < 8+n*width {
+		return nil, ErrFormat
+	}
+	entries := make([][]byte, n)
+	for i := range entries {
+		p := b[8+i*width:]
+		var v int64
+		switch width {
+		case 2:
+			v = int64(int16(binary.LittleEndian.Uint16(p)))
+		case 4:
+			v = int64(int32(binary.LittleEndian.Uint32(p)))
+		case 8:
+			v = int64(binary.LittleEndian.Uint64(p))
+		}
+		entries[i] = strconv.AppendInt(nil, v, 10)
+	}
+	return entries, nil
+}
+
+// decodeZipmap decodes a zipmap into a flat list of field-value pairs.
+func decodeZipmap(b []byte) ([][]byte, error) {
+	// zmlen (1), entries, 0xFF.
+	// Each entry is: len, field, len, free, value, free bytes.
+	readLen := func(p int) (int, int, error) {
+		if p >= len(b) {
+			return 0, 0, ErrFormat
+		}
+		if b[p] < 254 {
+			return int(b[p]), p + 1, nil
+		}
+		if b[p] == 254 && p+5 <= len(b) {
+			return int(binary.LittleEndian.Uint32(b[p+1:])), p + 5, nil
+		}
+		return 0, 0, ErrFormat
+	}
+
+	var pairs [][]byte
+	p := 1
+	for {
+		if p >= len(b) {
+			return nil, ErrFormat
+		}
+		if b[p] == 0xFF {
+			return pairs, nil
+		}
+		n, next, err := readLen(p)
+		if err != nil || next+n > len(b) {
+			return nil, ErrFormat
+		}
+		field := b[next : next+n]
+		p = next + n
+
+		n, next, err = readLen(p)
+		if err != nil || next >= len(b) {
+			return nil, ErrFormat
+		}
+		free := int(b[next])
+		next++
+		if next+n+free > len(b) {
+			return nil, ErrFormat
+		}
+		pairs = append(pairs, field, b[next:next+n])
+		p = next + n + free
+	}
+}
+
+// decompressLZF decompresses LZF-compressed data
+// into a buffer of the expected length.
+func decompressLZF(in []byte, outLen int) ([]byte, error) {
+	if outLen < 0 || outLen > maxStringLen {
+		return nil, ErrFormat
+	}
+	out := make([]byte, 0, outLen)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 32 {
+			// Literal run of ctrl+1 bytes.
+			n := ctrl + 1
+			if i+n > len(in) || len(out)+n > outLen {
+				return nil, ErrFormat
+			}
+			out = append(out, in[i:i+n]...)
+			i += n
+			continue
+		}
+
+		// Back reference.
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, ErrFormat
+			}
+			n += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, ErrFormat
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		n += 2
+		if ref < 0 || len(out)+n > outLen {
+			return nil, ErrFormat
+		}
+		for j := 0; j < n; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != outLen {
+		return nil, ErrFormat
+	}
+	return out, nil
+}
@@ -0,0 +1,144 @@
+package rdb
+
+import (
+	"io"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/core"
+)
+
+// DefaultBatchSize is the default number of keys
+// imported in a single transaction.
+const DefaultBatchSize = 1000
+
+// LoadOptions configure the import of an RDB file.
+type LoadOptions struct {
+	// DB is the Redis database number to import
+	// into the given database. Set to -1 to import keys
+	// from all databases, each into the logical database
+	// with the same number.
+	DB int
+	// BatchSize is the number of keys imported
+	// in a single transaction. Defaults to DefaultBatchSize.
+	BatchSize int
+}
+
+// Load imports the keys from an RDB file into the database.
+// Existing keys with the same names are overwritten,
+// and keys that have already expired are skipped.
+// Returns the number of imported keys.
+func Load(db *redka.DB, r io.Reader, opts *LoadOptions) (int, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	rd, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	batch := make([]Entry, 0, batchSize)
+	for {
+		e, err := rd.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if opts.DB >= 0 && e.DB != opts.DB {
+			continue
+		}
+		if e.ETime != nil && *e.ETime <= time.Now().UnixMilli() {
+			continue
+		}
+		batch = append(batch, e)
+		if len(batch) == batchSize {
+			if err := loadBatch(db, batch, opts.DB < 0); err != nil {
+				return count, err
+			}
+			count += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := loadBatch(db, batch, opts.DB < 0); err != nil {
+			return count, err
+		}
+		count += len(batch)
+	}
+	return count, nil
+}
+
+// loadBatch imports a batch of entries in a single transaction.
+// If selectDB is true, imports each entry into the logical
+// database with the entry's number.
+func loadBatch(db *redka.DB, batch []Entry, selectDB bool) error {
+	return db.Update(func(tx *redka.Tx) error {
+		for _, e := range batch {
+			etx := tx
+			if selectDB {
+				etx = tx.Select(e.DB)
+			}
+			if err := loadEntry(etx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// loadEntry imports a single entry, replacing the existing key if any.
+func loadEntry(tx *redka.Tx, e Entry) error {
+	if _, err := tx.Key().Delete(e.Key); err != nil {
+		return err
+	}
+
+	var err error
+	switch e.Type {
+	case core.TypeString:
+		err = tx.Str().Set(e.Key, e.Value.([]byte))
+	case core.TypeList:
+		for _, elem := range e.Value.([][]byte) {
+			if _, err = tx.List().PushBack(e.Key, elem); err != nil {
+				break
+			}
+		}
+	case core.TypeSet:
+		elems := e.Value.([][]byte)
+		args := make([]any, len(elems))
+		for i, elem := range elems {
+			args[i] = elem
+		}
+		_, err = tx.Set().Add(e.Key, args...)
+	case core.TypeHash:
+		hash := e.Value.(map[string][]byte)
+		items := make(map[string]any, len(hash))
+		for field, value := range hash {
+			items[field] = value
+		}
+		_, err = tx.Hash().SetMany(e.Key, items)
+	case core.TypeZSet:
+		zset := e.Value.(map[string]float64)
+		items := make(map[any]float64, len(zset))
+		for elem, score := range zset {
+			items[elem] = score
+		}
+		_, err = tx.ZSet().AddMany(e.Key, items)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.ETime != nil {
+		return tx.Key().ExpireAt(e.Key, time.UnixMilli(*e.ETime))
+	}
+	return nil
+}
@@ -0,0 +1,510 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestCRC64(t *testing.T) {
+	got := crc64(0, []byte("123456789"))
+	testx.AssertEqual(t, got, uint64(0xe9c6d914c4b8d9ca))
+}
+
+func TestReader(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeString).str("name").str("alice")
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Key, "name")
+		testx.AssertEqual(t, e.Type, core.TypeString)
+		testx.AssertEqual(t, e.Value, []byte("alice"))
+		testx.AssertEqual(t, e.ETime, (*int64)(nil))
+	})
+	t.Run("int string", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeString).str("age")
+		f.byte(0xC1).raw(binary.LittleEndian.AppendUint16(nil, uint16(25000)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, []byte("25000"))
+	})
+	t.Run("lzf string", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeString).str("str")
+		f.byte(0xC3).len(5).len(10).raw([]byte{0x00, 'a', 0xE0, 0x00, 0x00})
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, []byte("aaaaaaaaaa"))
+	})
+	t.Run("list", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeList).str("list").len(2).str("one").str("two")
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Type, core.TypeList)
+		testx.AssertEqual(t, e.Value, [][]byte{[]byte("one"), []byte("two")})
+	})
+	t.Run("quicklist", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeListQuicklist).str("list").len(2)
+		f.str(string(ziplist("one", 2)))
+		f.str(string(ziplist(-300, "four")))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, [][]byte{
+			[]byte("one"), []byte("2"), []byte("-300"), []byte("four"),
+		})
+	})
+	t.Run("quicklist2", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeListQuicklist2).str("list").len(2)
+		f.len(quicklistNodePacked).str(string(listpack("one", 2, -5)))
+		f.len(quicklistNodePlain).str("plain")
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, [][]byte{
+			[]byte("one"), []byte("2"), []byte("-5"), []byte("plain"),
+		})
+	})
+	t.Run("intset", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeSetIntset).str("set").str(string(intset(1, -2, 300)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Type, core.TypeSet)
+		testx.AssertEqual(t, e.Value, [][]byte{[]byte("1"), []byte("-2"), []byte("300")})
+	})
+	t.Run("set listpack", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeSetListpack).str("set").str(string(listpack("one", "two")))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, [][]byte{[]byte("one"), []byte("two")})
+	})
+	t.Run("hash", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeHash).str("person").len(2)
+		f.str("name").str("alice").str("age").str("25")
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Type, core.TypeHash)
+		testx.AssertEqual(t, e.Value, map[string][]byte{
+			"name": []byte("alice"), "age": []byte("25"),
+		})
+	})
+	t.Run("hash ziplist", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeHashZiplist).str("person").str(string(ziplist("name", "alice", "age", 25)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, map[string][]byte{
+			"name": []byte("alice"), "age": []byte("25"),
+		})
+	})
+	t.Run("hash listpack", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeHashListpack).str("person").str(string(listpack("name", "alice", "age", 25)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, map[string][]byte{
+			"name": []byte("alice"), "age": []byte("25"),
+		})
+	})
+	t.Run("zset", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeZSet2).str("race").len(2)
+		f.str("alice").raw(binary.LittleEndian.AppendUint64(nil, math.Float64bits(11.5)))
+		f.str("bob").raw(binary.LittleEndian.AppendUint64(nil, math.Float64bits(22)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Type, core.TypeZSet)
+		testx.AssertEqual(t, e.Value, map[string]float64{"alice": 11.5, "bob": 22})
+	})
+	t.Run("zset listpack", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeZSetListpack).str("race").str(string(listpack("alice", "11.5", "bob", 22)))
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.Value, map[string]float64{"alice": 11.5, "bob": 22})
+	})
+	t.Run("expire", func(t *testing.T) {
+		f := newFixture()
+		f.byte(opExpireTimeMs).raw(binary.LittleEndian.AppendUint64(nil, 1700000000000))
+		f.byte(typeString).str("name").str("alice")
+		e := readOne(t, f)
+		testx.AssertEqual(t, *e.ETime, int64(1700000000000))
+	})
+	t.Run("metadata", func(t *testing.T) {
+		f := newFixture()
+		f.byte(opAux).str("redis-ver").str("7.2.0")
+		f.byte(opSelectDB).len(3)
+		f.byte(opResizeDB).len(1).len(0)
+		f.byte(opIdle).len(100)
+		f.byte(typeString).str("name").str("alice")
+		e := readOne(t, f)
+		testx.AssertEqual(t, e.DB, 3)
+		testx.AssertEqual(t, e.Key, "name")
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		f := newFixture()
+		f.byte(21).str("stream")
+		rd, err := NewReader(bytes.NewReader(f.bytes()))
+		testx.AssertNoErr(t, err)
+		_, err = rd.Next()
+		testx.AssertErr(t, err, UnsupportedTypeError{Type: 21})
+	})
+	t.Run("invalid header", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader([]byte("REDIX0011")))
+		testx.AssertErr(t, err, ErrFormat)
+		_, err = NewReader(bytes.NewReader([]byte("REDIS0099")))
+		testx.AssertErr(t, err, ErrFormat)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeString).str("name")
+		rd, err := NewReader(bytes.NewReader(f.buf.Bytes()))
+		testx.AssertNoErr(t, err)
+		_, err = rd.Next()
+		testx.AssertErr(t, err, ErrFormat)
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		f := newFixture()
+		f.byte(typeString).str("name").str("alice")
+		data := f.bytes()
+		data[len(data)-1] ^= 0xFF
+		rd, err := NewReader(bytes.NewReader(data))
+		testx.AssertNoErr(t, err)
+		_, err = rd.Next()
+		testx.AssertNoErr(t, err)
+		_, err = rd.Next()
+		testx.AssertErr(t, err, ErrChecksum)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	future := time.Now().Add(time.Hour).UnixMilli()
+	past := time.Now().Add(-time.Hour).UnixMilli()
+
+	f := newFixture()
+	f.byte(opSelectDB).len(0)
+	f.byte(typeString).str("name").str("alice")
+	f.byte(opExpireTimeMs).raw(binary.LittleEndian.AppendUint64(nil, uint64(future)))
+	f.byte(typeString).str("temp").str("value")
+	f.byte(opExpireTimeMs).raw(binary.LittleEndian.AppendUint64(nil, uint64(past)))
+	f.byte(typeString).str("expired").str("value")
+	f.byte(typeListQuicklist2).str("list").len(1)
+	f.len(quicklistNodePacked).str(string(listpack("one", "two", 3)))
+	f.byte(typeSetIntset).str("set").str(string(intset(1, 2)))
+	f.byte(typeHashListpack).str("person").str(string(listpack("name", "bob")))
+	f.byte(typeZSetListpack).str("race").str(string(listpack("alice", 11)))
+	f.byte(opSelectDB).len(1)
+	f.byte(typeString).str("other").str("value")
+
+	t.Run("load", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		count, err := Load(db, bytes.NewReader(f.bytes()), &LoadOptions{BatchSize: 2})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 6)
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+
+		key, _ := db.Key().Get("temp")
+		testx.AssertEqual(t, *key.ETime, future)
+		exists, _ := db.Key().Count("expired", "other")
+		testx.AssertEqual(t, exists, 0)
+
+		list, _ := db.List().Range("list", 0, -1)
+		testx.AssertEqual(t, list, []core.Value{
+			core.Value("one"), core.Value("two"), core.Value("3"),
+		})
+		setLen, _ := db.Set().Len("set")
+		testx.AssertEqual(t, setLen, 2)
+		field, _ := db.Hash().Get("person", "name")
+		testx.AssertEqual(t, field.String(), "bob")
+		score, _ := db.ZSet().GetScore("race", "alice")
+		testx.AssertEqual(t, score, 11.0)
+	})
+	t.Run("all databases", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		count, err := Load(db, bytes.NewReader(f.bytes()), &LoadOptions{DB: -1})
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 7)
+		// Each key is imported into the database with the same number.
+		other, _ := db.Select(1).Str().Get("other")
+		testx.AssertEqual(t, other.String(), "value")
+		exists, _ := db.Key().Exists("other")
+		testx.AssertEqual(t, exists, false)
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+		_, _ = db.List().PushBack("name", "old")
+
+		_, err := Load(db, bytes.NewReader(f.bytes()), nil)
+		testx.AssertNoErr(t, err)
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+}
+
+func TestSave(t *testing.T) {
+	src := getDB(t)
+	defer src.Close()
+
+	future := time.Now().Add(time.Hour).UnixMilli()
+	_ = src.Str().Set("name", "alice")
+	_ = src.Str().Set("temp", "value")
+	_ = src.Key().ExpireAt("temp", time.UnixMilli(future))
+	_, _ = src.List().PushBack("list", "one")
+	_, _ = src.List().PushBack("list", "two")
+	_, _ = src.Set().Add("set", "one", "two")
+	_, _ = src.Hash().SetMany("person", map[string]any{"name": "bob", "age": 25})
+	_, _ = src.ZSet().AddMany("race", map[any]float64{"alice": 11, "bob": 22.5})
+
+	t.Run("save", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := Save(src, &buf)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 6)
+		testx.AssertEqual(t, string(buf.Bytes()[:9]), "REDIS0009")
+
+		rd, err := NewReader(&buf)
+		testx.AssertNoErr(t, err)
+		entries := map[string]Entry{}
+		for {
+			e, err := rd.Next()
+			if err == io.EOF {
+				break
+			}
+			testx.AssertNoErr(t, err)
+			entries[e.Key] = e
+		}
+		testx.AssertEqual(t, len(entries), 6)
+		testx.AssertEqual(t, entries["name"].Value, []byte("alice"))
+		testx.AssertEqual(t, *entries["temp"].ETime, future)
+		testx.AssertEqual(t, entries["list"].Value, [][]byte{[]byte("one"), []byte("two")})
+		testx.AssertEqual(t, entries["person"].Value, map[string][]byte{
+			"name": []byte("bob"), "age": []byte("25"),
+		})
+		testx.AssertEqual(t, entries["race"].Value, map[string]float64{"alice": 11, "bob": 22.5})
+	})
+	t.Run("save file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.rdb")
+		count, err := SaveFile(src, path)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 6)
+
+		file, err := os.Open(path)
+		testx.AssertNoErr(t, err)
+		defer file.Close()
+
+		dst := getDB(t)
+		defer dst.Close()
+		count, err = Load(dst, file, nil)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 6)
+		setLen, _ := dst.Set().Len("set")
+		testx.AssertEqual(t, setLen, 2)
+	})
+}
+
+func TestSaveDatabases(t *testing.T) {
+	src := getDB(t)
+	defer src.Close()
+	_ = src.Str().Set("name", "alice")
+	_ = src.Select(3).Str().Set("name", "bob")
+	_ = src.Select(1).Str().Set("age", 25)
+
+	var buf bytes.Buffer
+	count, err := Save(src, &buf)
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, count, 3)
+
+	rd, err := NewReader(bytes.NewReader(buf.Bytes()))
+	testx.AssertNoErr(t, err)
+	var got []string
+	for {
+		e, err := rd.Next()
+		if err == io.EOF {
+			break
+		}
+		testx.AssertNoErr(t, err)
+		got = append(got, fmt.Sprintf("%d:%s", e.DB, e.Key))
+	}
+	testx.AssertEqual(t, got, []string{"0:name", "1:age", "3:name"})
+
+	dst := getDB(t)
+	defer dst.Close()
+	count, err = Load(dst, bytes.NewReader(buf.Bytes()), &LoadOptions{DB: -1})
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, count, 3)
+	name, _ := dst.Select(3).Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+	age, _ := dst.Select(1).Str().Get("age")
+	testx.AssertEqual(t, age.String(), "25")
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		etime := int64(1700000000000)
+		want := []Entry{
+			{DB: 0, Key: "str", Type: core.TypeString, Value: []byte("value")},
+			{DB: 0, Key: "list", Type: core.TypeList, ETime: &etime,
+				Value: [][]byte{[]byte("one"), []byte("two")}},
+			{DB: 2, Key: "set", Type: core.TypeSet, Value: [][]byte{[]byte("one")}},
+			{DB: 2, Key: "hash", Type: core.TypeHash,
+				Value: map[string][]byte{"field": bytes.Repeat([]byte("x"), 20000)}},
+			{DB: 2, Key: "zset", Type: core.TypeZSet, Value: map[string]float64{"elem": -1.5}},
+		}
+
+		var buf bytes.Buffer
+		wr := NewWriter(&buf)
+		for _, e := range want {
+			testx.AssertNoErr(t, wr.Write(e))
+		}
+		testx.AssertNoErr(t, wr.Close())
+
+		rd, err := NewReader(&buf)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, rd.Version(), 9)
+		for _, e := range want {
+			got, err := rd.Next()
+			testx.AssertNoErr(t, err)
+			testx.AssertEqual(t, got, e)
+		}
+		_, err = rd.Next()
+		testx.AssertErr(t, err, io.EOF)
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		wr := NewWriter(io.Discard)
+		err := wr.Write(Entry{Key: "str", Type: core.TypeList, Value: []byte("value")})
+		testx.AssertErr(t, err, ErrFormat)
+	})
+}
+
+// fixture builds an RDB file for tests.
+type fixture struct {
+	buf bytes.Buffer
+}
+
+func newFixture() *fixture {
+	f := &fixture{}
+	f.buf.WriteString("REDIS0011")
+	return f
+}
+
+func (f *fixture) byte(b byte) *fixture {
+	f.buf.WriteByte(b)
+	return f
+}
+
+func (f *fixture) raw(b []byte) *fixture {
+	f.buf.Write(b)
+	return f
+}
+
+func (f *fixture) len(n int) *fixture {
+	switch {
+	case n < 1<<6:
+		f.buf.WriteByte(byte(n))
+	case n < 1<<14:
+		f.buf.Write([]byte{0x40 | byte(n>>8), byte(n)})
+	default:
+		f.buf.WriteByte(0x80)
+		f.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+	return f
+}
+
+func (f *fixture) str(s string) *fixture {
+	f.len(len(s))
+	f.buf.WriteString(s)
+	return f
+}
+
+// bytes returns the complete file contents,
+// including the EOF opcode and the checksum.
+func (f *fixture) bytes() []byte {
+	b := append(bytes.Clone(f.buf.Bytes()), opEOF)
+	return binary.LittleEndian.AppendUint64(b, crc64(0, b))
+}
+
+// ziplist encodes short strings and int16 values as a ziplist.
+func ziplist(vals ...any) []byte {
+	var entries []byte
+	prevlen := 0
+	for _, val := range vals {
+		var entry []byte
+		switch v := val.(type) {
+		case string:
+			entry = append([]byte{byte(len(v))}, v...)
+		case int:
+			entry = binary.LittleEndian.AppendUint16([]byte{0xC0}, uint16(int16(v)))
+		}
+		entries = append(entries, byte(prevlen))
+		entries = append(entries, entry...)
+		prevlen = 1 + len(entry)
+	}
+	b := binary.LittleEndian.AppendUint32(nil, uint32(11+len(entries)))
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(vals)))
+	b = append(b, entries...)
+	return append(b, 0xFF)
+}
+
+// listpack encodes short strings and 13-bit ints as a listpack.
+func listpack(vals ...any) []byte {
+	var entries []byte
+	for _, val := range vals {
+		var entry []byte
+		switch v := val.(type) {
+		case string:
+			entry = append([]byte{0x80 | byte(len(v))}, v...)
+		case int:
+			u := uint16(v) & 0x1fff
+			entry = []byte{0xC0 | byte(u>>8), byte(u)}
+		}
+		entries = append(entries, entry...)
+		entries = append(entries, byte(len(entry)))
+	}
+	b := binary.LittleEndian.AppendUint32(nil, uint32(7+len(entries)))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(vals)))
+	b = append(b, entries...)
+	return append(b, 0xFF)
+}
+
+// intset encodes values as an intset with 16-bit encoding.
+func intset(vals ...int) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, 2)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(vals)))
+	for _, v := range vals {
+		b = binary.LittleEndian.AppendUint16(b, uint16(int16(v)))
+	}
+	return b
+}
+
+func readOne(tb testing.TB, f *fixture) Entry {
+	tb.Helper()
+	rd, err := NewReader(bytes.NewReader(f.bytes()))
+	testx.AssertNoErr(tb, err)
+	e, err := rd.Next()
+	testx.AssertNoErr(tb, err)
+	_, err = rd.Next()
+	testx.AssertErr(tb, err, io.EOF)
+	return e
+}
+
+func getDB(tb testing.TB) *redka.DB {
+	tb.Helper()
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
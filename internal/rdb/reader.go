@@ -0,0 +1,496 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// Reader reads key-value entries from an RDB file.
+type Reader struct {
+	r       *bufio.Reader
+	crc     uint64
+	version int
+	db      int
+	done    bool
+}
+
+// NewReader creates a reader and reads the RDB file header.
+// Returns ErrFormat if the header is invalid
+// or the format version is not supported.
+func NewReader(r io.Reader) (*Reader, error) {
+	rd := &Reader{r: bufio.NewReaderSize(r, 64*1024)}
+	header, err := rd.readN(9)
+	if err != nil {
+		return nil, ErrFormat
+	}
+	if string(header[:5]) != "REDIS" {
+		return nil, ErrFormat
+	}
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil || version < minVersion || version > maxVersion {
+		return nil, ErrFormat
+	}
+	rd.version = version
+	return rd, nil
+}
+
+// Version returns the RDB format version of the file.
+func (rd *Reader) Version() int {
+	return rd.version
+}
+
+// Next returns the next entry from the file.
+// Returns io.EOF when there are no more entries,
+// ErrChecksum if the file checksum does not match,
+// or UnsupportedTypeError for values Redka cannot store.
+func (rd *Reader) Next() (Entry, error) {
+	if rd.done {
+		return Entry{}, io.EOF
+	}
+
+	var etime *int64
+	for {
+		op, err := rd.readByte()
+		if err != nil {
+			return Entry{}, rd.unexpected(err)
+		}
+
+		switch op {
+		case opEOF:
+			rd.done = true
+			return Entry{}, rd.checkSum()
+		case opSelectDB:
+			db, _, err := rd.readLen()
+			if err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+			rd.db = int(db)
+		case opResizeDB:
+			if _, _, err := rd.readLen(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+			if _, _, err := rd.readLen(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+		case opAux:
+			if _, err := rd.readString(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+			if _, err := rd.readString(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+		case opExpireTimeMs:
+			b, err := rd.readN(8)
+			if err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+			ms := int64(binary.LittleEndian.Uint64(b))
+			etime = &ms
+		case opExpireTime:
+			b, err := rd.readN(4)
+			if err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+			ms := int64(binary.LittleEndian.Uint32(b)) * 1000
+			etime = &ms
+		case opIdle:
+			if _, _, err := rd.readLen(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+		case opFreq:
+			if _, err := rd.readByte(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+		case opSlotInfo:
+			for i := 0; i < 3; i++ {
+				if _, _, err := rd.readLen(); err != nil {
+					return Entry{}, rd.unexpected(err)
+				}
+			}
+		case opFunction2:
+			if _, err := rd.readString(); err != nil {
+				return Entry{}, rd.unexpected(err)
+			}
+		case opFunctionPre, opModuleAux:
+			// Cannot skip these without knowing their inner format.
+			return Entry{}, UnsupportedTypeError{Type: op}
+		default:
+			return rd.readEntry(op, etime)
+		}
+	}
+}
+
+// readEntry reads a key-value pair of the given value type.
+func (rd *Reader) readEntry(vtype byte, etime *int64) (Entry, error) {
+	key, err := rd.readString()
+	if err != nil {
+		return Entry{}, rd.unexpected(err)
+	}
+	e := Entry{DB: rd.db, Key: string(key), ETime: etime}
+	e.Type, e.Value, err = rd.readValue(vtype)
+	if err != nil {
+		return Entry{}, rd.unexpected(err)
+	}
+	return e, nil
+}
+
+// readValue reads a value of the given type.
+func (rd *Reader) readValue(vtype byte) (core.TypeID, any, error) {
+	switch vtype {
+	case typeString:
+		val, err := rd.readString()
+		return core.TypeString, val, err
+
+	case typeList:
+		elems, err := rd.readStrings()
+		return core.TypeList, elems, err
+	case typeListZiplist:
+		elems, err := rd.readEncoded(decodeZiplist)
+		return core.TypeList, elems, err
+	case typeListQuicklist, typeListQuicklist2:
+		elems, err := rd.readQuicklist(vtype == typeListQuicklist2)
+		return core.TypeList, elems, err
+
+	case typeSet:
+		elems, err := rd.readStrings()
+		return core.TypeSet, elems, err
+	case typeSetIntset:
+		elems, err := rd.readEncoded(decodeIntset)
+		return core.TypeSet, elems, err
+	case typeSetListpack:
+		elems, err := rd.readEncoded(decodeListpack)
+		return core.TypeSet, elems, err
+
+	case typeHash:
+		n, _, err := rd.readLen()
+		if err != nil {
+			return 0, nil, err
+		}
+		hash := make(map[string][]byte, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			field, err := rd.readString()
+			if err != nil {
+				return 0, nil, err
+			}
+			value, err := rd.readString()
+			if err != nil {
+				return 0, nil, err
+			}
+			hash[string(field)] = value
+		}
+		return core.TypeHash, hash, nil
+	case typeHashZipmap:
+		pairs, err := rd.readEncoded(decodeZipmap)
+		if err != nil {
+			return 0, nil, err
+		}
+		return core.TypeHash, pairsToHash(pairs), nil
+	case typeHashZiplist, typeHashListpack:
+		decode := decodeZiplist
+		if vtype == typeHashListpack {
+			decode = decodeListpack
+		}
+		pairs, err := rd.readEncoded(decode)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(pairs)%2 != 0 {
+			return 0, nil, ErrFormat
+		}
+		return core.TypeHash, pairsToHash(pairs), nil
+
+	case typeZSet, typeZSet2:
+		n, _, err := rd.readLen()
+		if err != nil {
+			return 0, nil, err
+		}
+		zset := make(map[string]float64, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			elem, err := rd.readString()
+			if err != nil {
+				return 0, nil, err
+			}
+			var score float64
+			if vtype == typeZSet2 {
+				score, err = rd.readBinaryDouble()
+			} else {
+				score, err = rd.readDouble()
+			}
+			if err != nil {
+				return 0, nil, err
+			}
+			zset[string(elem)] = score
+		}
+		return core.TypeZSet, zset, nil
+	case typeZSetZiplist, typeZSetListpack:
+		decode := decodeZiplist
+		if vtype == typeZSetListpack {
+			decode = decodeListpack
+		}
+		pairs, err := rd.readEncoded(decode)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(pairs)%2 != 0 {
+			return 0, nil, ErrFormat
+		}
+		zset := make(map[string]float64, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			score, err := strconv.ParseFloat(string(pairs[i+1]), 64)
+			if err != nil {
+				return 0, nil, ErrFormat
+			}
+			zset[string(pairs[i])] = score
+		}
+		return core.TypeZSet, zset, nil
+	}
+
+	return 0, nil, UnsupportedTypeError{Type: vtype}
+}
+
+// readQuicklist reads a list stored as a quicklist of ziplists
+// (version 1) or of listpacks and plain nodes (version 2).
+func (rd *Reader) readQuicklist(v2 bool) ([][]byte, error) {
+	n, _, err := rd.readLen()
+	if err != nil {
+		return nil, err
+	}
+	var elems [][]byte
+	for i := uint64(0); i < n; i++ {
+		container := uint64(quicklistNodePacked)
+		if v2 {
+			container, _, err = rd.readLen()
+			if err != nil {
+				return nil, err
+			}
+		}
+		node, err := rd.readString()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case container == quicklistNodePlain:
+			elems = append(elems, node)
+		case container != quicklistNodePacked:
+			return nil, ErrFormat
+		case v2:
+			nodeElems, err := decodeListpack(node)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, nodeElems...)
+		default:
+			nodeElems, err := decodeZiplist(node)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, nodeElems...)
+		}
+	}
+	return elems, nil
+}
+
+// readEncoded reads a string and decodes it
+// with one of the compact encodings.
+func (rd *Reader) readEncoded(decode func([]byte) ([][]byte, error)) ([][]byte, error) {
+	b, err := rd.readString()
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+// readStrings reads a length-prefixed sequence of strings.
+func (rd *Reader) readStrings() ([][]byte, error) {
+	n, _, err := rd.readLen()
+	if err != nil {
+		return nil, err
+	}
+	elems := make([][]byte, 0, min(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		elem, err := rd.readString()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// readLen reads a length-encoded number. If the encoded flag
+// is set, the number is a special string encoding type.
+func (rd *Reader) readLen() (n uint64, encoded bool, err error) {
+	b, err := rd.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case 0:
+		return uint64(b & 0x3f), false, nil
+	case 1:
+		next, err := rd.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(b&0x3f)<<8 | uint64(next), false, nil
+	case 2:
+		switch b {
+		case 0x80:
+			buf, err := rd.readN(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(buf)), false, nil
+		case 0x81:
+			buf, err := rd.readN(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(buf), false, nil
+		}
+		return 0, false, ErrFormat
+	}
+	return uint64(b & 0x3f), true, nil
+}
+
+// readString reads a string, which may be
+// encoded as an integer or compressed with LZF.
+func (rd *Reader) readString() ([]byte, error) {
+	n, encoded, err := rd.readLen()
+	if err != nil {
+		return nil, err
+	}
+	if !encoded {
+		return rd.readN(int(n))
+	}
+
+	switch n {
+	case 0:
+		b, err := rd.readByte()
+		return strconv.AppendInt(nil, int64(int8(b)), 10), err
+	case 1:
+		b, err := rd.readN(2)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int16(binary.LittleEndian.Uint16(b))), 10), nil
+	case 2:
+		b, err := rd.readN(4)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int32(binary.LittleEndian.Uint32(b))), 10), nil
+	case 3:
+		clen, _, err := rd.readLen()
+		if err != nil {
+			return nil, err
+		}
+		ulen, _, err := rd.readLen()
+		if err != nil {
+			return nil, err
+		}
+		data, err := rd.readN(int(clen))
+		if err != nil {
+			return nil, err
+		}
+		return decompressLZF(data, int(ulen))
+	}
+	return nil, ErrFormat
+}
+
+// readDouble reads a string-encoded double (old zset format).
+func (rd *Reader) readDouble() (float64, error) {
+	n, err := rd.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	b, err := rd.readN(int(n))
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return 0, ErrFormat
+	}
+	return val, nil
+}
+
+// readBinaryDouble reads a little-endian IEEE 754 double.
+func (rd *Reader) readBinaryDouble() (float64, error) {
+	b, err := rd.readN(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// checkSum reads the checksum at the end of the file
+// and compares it to the computed one.
+func (rd *Reader) checkSum() error {
+	if rd.version < 5 {
+		return io.EOF
+	}
+	want := rd.crc
+	b, err := rd.readN(8)
+	if err != nil {
+		return rd.unexpected(err)
+	}
+	got := binary.LittleEndian.Uint64(b)
+	if got != 0 && got != want {
+		return ErrChecksum
+	}
+	return io.EOF
+}
+
+// readByte reads a single byte and updates the checksum.
+func (rd *Reader) readByte() (byte, error) {
+	b, err := rd.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	rd.crc = crc64(rd.crc, []byte{b})
+	return b, nil
+}
+
+// readN reads exactly n bytes and updates the checksum.
+func (rd *Reader) readN(n int) ([]byte, error) {
+	if n < 0 || n > maxStringLen {
+		return nil, ErrFormat
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rd.r, b); err != nil {
+		return nil, err
+	}
+	rd.crc = crc64(rd.crc, b)
+	return b, nil
+}
+
+// unexpected converts a premature end of file to ErrFormat.
+func (rd *Reader) unexpected(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrFormat
+	}
+	return err
+}
+
+// pairsToHash converts a flat field-value list to a map.
+func pairsToHash(pairs [][]byte) map[string][]byte {
+	hash := make(map[string][]byte, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		hash[string(pairs[i])] = pairs[i+1]
+	}
+	return hash
+}
@@ -0,0 +1,148 @@
+package rdb
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/core"
+)
+
+// Save exports all keys from all logical databases to an RDB file.
+// Reads the keys in a single transaction, so the file
+// is a consistent snapshot of the database.
+// Hash field expiration times are not exported,
+// since RDB version 9 does not support them.
+// Returns the number of exported keys.
+func Save(db *redka.DB, w io.Writer) (int, error) {
+	wr := NewWriter(w)
+	count := 0
+	err := db.View(func(tx *redka.Tx) error {
+		counts, err := tx.Key().DBCounts()
+		if err != nil {
+			return err
+		}
+		indexes := make([]int, 0, len(counts))
+		for index := range counts {
+			indexes = append(indexes, index)
+		}
+		slices.Sort(indexes)
+		for _, index := range indexes {
+			n, err := saveDB(tx.Select(index), index, wr)
+			count += n
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, wr.Close()
+}
+
+// saveDB exports the keys of a single logical database.
+// Returns the number of exported keys.
+func saveDB(tx *redka.Tx, index int, wr *Writer) (int, error) {
+	count := 0
+	sc := tx.Key().Scanner("*", core.TypeAny, 0)
+	for sc.Scan() {
+		e, err := readKey(tx, sc.Key())
+		if err != nil {
+			return count, err
+		}
+		e.DB = index
+		if err := wr.Write(e); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, sc.Err()
+}
+
+// SaveFile exports all keys from the database to an RDB file at path.
+// Writes to a temporary file first and renames it when done,
+// so the file at path is either complete or unchanged.
+// Returns the number of exported keys.
+func SaveFile(db *redka.DB, path string) (int, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+
+	count, err := Save(db, file)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	return count, os.Rename(file.Name(), path)
+}
+
+// readKey reads the value of a key into an entry.
+func readKey(tx *redka.Tx, k core.Key) (Entry, error) {
+	e := Entry{Key: k.Key, Type: k.Type, ETime: k.ETime}
+	switch k.Type {
+	case core.TypeString:
+		val, err := tx.Str().Get(k.Key)
+		if err != nil {
+			return Entry{}, err
+		}
+		e.Value = val.Bytes()
+	case core.TypeList:
+		elems, err := tx.List().Range(k.Key, 0, -1)
+		if err != nil {
+			return Entry{}, err
+		}
+		e.Value = valuesToBytes(elems)
+	case core.TypeSet:
+		elems, err := tx.Set().Items(k.Key)
+		if err != nil {
+			return Entry{}, err
+		}
+		e.Value = valuesToBytes(elems)
+	case core.TypeHash:
+		items, err := tx.Hash().Items(k.Key)
+		if err != nil {
+			return Entry{}, err
+		}
+		hash := make(map[string][]byte, len(items))
+		for field, value := range items {
+			hash[field] = value.Bytes()
+		}
+		e.Value = hash
+	case core.TypeZSet:
+		zset := map[string]float64{}
+		sc := tx.ZSet().Scanner(k.Key, "*", 0)
+		for sc.Scan() {
+			item := sc.Item()
+			zset[item.Elem.String()] = item.Score
+		}
+		if err := sc.Err(); err != nil {
+			return Entry{}, err
+		}
+		e.Value = zset
+	default:
+		return Entry{}, core.ErrKeyType
+	}
+	return e, nil
+}
+
+// valuesToBytes converts a list of values to a list of byte slices.
+func valuesToBytes(vals []core.Value) [][]byte {
+	elems := make([][]byte, len(vals))
+	for i, val := range vals {
+		elems[i] = val.Bytes()
+	}
+	return elems
+}
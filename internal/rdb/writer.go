@@ -0,0 +1,142 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// writeVersion is the RDB format version produced by the writer.
+// Version 9 is readable by Redis 5.0 and later.
+const writeVersion = 9
+
+// Writer writes key-value entries to an RDB file.
+// Uses plain (non-compact) encodings for all value types,
+// which every Redis version since 5.0 can load.
+type Writer struct {
+	w   *bufio.Writer
+	crc uint64
+	db  int
+}
+
+// NewWriter creates a writer and writes the RDB file header.
+// Call [Writer.Close] after writing all entries
+// to finish the file.
+func NewWriter(w io.Writer) *Writer {
+	wr := &Writer{w: bufio.NewWriterSize(w, 64*1024), db: -1}
+	wr.write([]byte(fmt.Sprintf("REDIS%04d", writeVersion)))
+	return wr
+}
+
+// Write writes an entry to the file.
+// Entries from the same database should be written together.
+// Returns ErrFormat if the entry value does not match its type.
+func (wr *Writer) Write(e Entry) error {
+	if e.DB != wr.db {
+		wr.writeByte(opSelectDB)
+		wr.writeLen(uint64(e.DB))
+		wr.db = e.DB
+	}
+	if e.ETime != nil {
+		wr.writeByte(opExpireTimeMs)
+		wr.write(binary.LittleEndian.AppendUint64(nil, uint64(*e.ETime)))
+	}
+
+	switch val := e.Value.(type) {
+	case []byte:
+		if e.Type != core.TypeString {
+			return ErrFormat
+		}
+		wr.writeByte(typeString)
+		wr.writeString([]byte(e.Key))
+		wr.writeString(val)
+	case [][]byte:
+		switch e.Type {
+		case core.TypeList:
+			wr.writeByte(typeList)
+		case core.TypeSet:
+			wr.writeByte(typeSet)
+		default:
+			return ErrFormat
+		}
+		wr.writeString([]byte(e.Key))
+		wr.writeLen(uint64(len(val)))
+		for _, elem := range val {
+			wr.writeString(elem)
+		}
+	case map[string][]byte:
+		if e.Type != core.TypeHash {
+			return ErrFormat
+		}
+		wr.writeByte(typeHash)
+		wr.writeString([]byte(e.Key))
+		wr.writeLen(uint64(len(val)))
+		for field, value := range val {
+			wr.writeString([]byte(field))
+			wr.writeString(value)
+		}
+	case map[string]float64:
+		if e.Type != core.TypeZSet {
+			return ErrFormat
+		}
+		wr.writeByte(typeZSet2)
+		wr.writeString([]byte(e.Key))
+		wr.writeLen(uint64(len(val)))
+		for elem, score := range val {
+			wr.writeString([]byte(elem))
+			wr.write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(score)))
+		}
+	default:
+		return ErrFormat
+	}
+	return nil
+}
+
+// Close writes the end of file marker and the checksum,
+// and flushes the buffered data to the underlying writer.
+// Does not close the underlying writer.
+func (wr *Writer) Close() error {
+	wr.writeByte(opEOF)
+	sum := binary.LittleEndian.AppendUint64(nil, wr.crc)
+	if _, err := wr.w.Write(sum); err != nil {
+		return err
+	}
+	return wr.w.Flush()
+}
+
+// writeLen writes a length-encoded number.
+func (wr *Writer) writeLen(n uint64) {
+	switch {
+	case n < 1<<6:
+		wr.writeByte(byte(n))
+	case n < 1<<14:
+		wr.write([]byte{0x40 | byte(n>>8), byte(n)})
+	case n <= math.MaxUint32:
+		wr.write(binary.BigEndian.AppendUint32([]byte{0x80}, uint32(n)))
+	default:
+		wr.write(binary.BigEndian.AppendUint64([]byte{0x81}, n))
+	}
+}
+
+// writeString writes a length-prefixed string.
+func (wr *Writer) writeString(b []byte) {
+	wr.writeLen(uint64(len(b)))
+	wr.write(b)
+}
+
+// writeByte writes a single byte and updates the checksum.
+func (wr *Writer) writeByte(b byte) {
+	wr.write([]byte{b})
+}
+
+// write writes the bytes and updates the checksum.
+// Write errors are sticky in bufio.Writer,
+// so they are reported by Close.
+func (wr *Writer) write(b []byte) {
+	wr.crc = crc64(wr.crc, b)
+	_, _ = wr.w.Write(b)
+}
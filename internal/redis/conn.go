@@ -11,6 +11,8 @@ import (
 type fakeConn struct {
 	parts []string
 	ctx   any
+	proto int
+	db    int
 }
 
 // NewFakeConn creates a new fake connection for testing.
@@ -18,6 +20,21 @@ func NewFakeConn() *fakeConn {
 	return &fakeConn{}
 }
 
+func (c *fakeConn) Proto() int {
+	if c.proto == 0 {
+		return RESP2
+	}
+	return c.proto
+}
+func (c *fakeConn) SetProto(proto int) {
+	c.proto = proto
+}
+func (c *fakeConn) DB() int {
+	return c.db
+}
+func (c *fakeConn) SetDB(index int) {
+	c.db = index
+}
 func (c *fakeConn) RemoteAddr() string {
 	return ""
 }
@@ -51,6 +68,25 @@ func (c *fakeConn) WriteArray(count int) {
 func (c *fakeConn) WriteNull() {
 	c.append("(nil)")
 }
+func (c *fakeConn) WriteMap(count int) {
+	c.append(strconv.Itoa(count * 2))
+}
+func (c *fakeConn) WriteSet(count int) {
+	c.append(strconv.Itoa(count))
+}
+func (c *fakeConn) WritePush(count int) {
+	c.append(strconv.Itoa(count))
+}
+func (c *fakeConn) WriteDouble(num float64) {
+	c.append(strconv.FormatFloat(num, 'f', -1, 64))
+}
+func (c *fakeConn) WriteBool(b bool) {
+	if b {
+		c.append("1")
+	} else {
+		c.append("0")
+	}
+}
 func (c *fakeConn) WriteRaw(data []byte) {
 	c.append(string(data))
 }
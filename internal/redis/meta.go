@@ -0,0 +1,311 @@
+package redis
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Command flags (same as in Redis).
+const (
+	FlagAdmin       = "admin"       // administrative command
+	FlagDenyOOM     = "denyoom"     // may increase memory usage
+	FlagFast        = "fast"        // runs in constant or log time
+	FlagLoading     = "loading"     // allowed while loading the database
+	FlagMovableKeys = "movablekeys" // key positions depend on the arguments
+	FlagNoScript    = "noscript"    // not allowed in scripts
+	FlagReadOnly    = "readonly"    // only reads the database
+	FlagStale       = "stale"       // allowed on a stale replica
+	FlagWrite       = "write"       // modifies the database
+)
+
+// Command groups (same as in Redis).
+const (
+	GroupConnection = "connection"
+	GroupGeneric    = "generic"
+	GroupHash       = "hash"
+	GroupList       = "list"
+	GroupPubSub     = "pubsub"
+	GroupServer     = "server"
+	GroupSet        = "set"
+	GroupSortedSet  = "sorted-set"
+	GroupString     = "string"
+)
+
+// CmdInfo describes a command (see the COMMAND command).
+type CmdInfo struct {
+	// Name is the lowercase command name.
+	Name string
+	// Arity is the number of arguments, including the command name.
+	// Negative arity -N means N or more arguments.
+	Arity int
+	// Flags are the command flags (FlagWrite, FlagReadOnly etc).
+	Flags []string
+	// FirstKey is the position of the first key argument
+	// (0 if the command has no keys), LastKey is the position
+	// of the last one (negative values count from the end),
+	// and Step is the step between the key positions.
+	FirstKey, LastKey, Step int
+	// NumKeys is the position of the numkeys argument, followed
+	// by that many keys (0 if none). Commands with NumKeys set
+	// have the FlagMovableKeys flag.
+	NumKeys int
+	// Group is the command group (GroupString, GroupList etc).
+	Group string
+	// Summary is a short description of the command.
+	Summary string
+}
+
+// Has reports whether the command has the flag.
+func (info CmdInfo) Has(flag string) bool {
+	return slices.Contains(info.Flags, flag)
+}
+
+// Keys returns the key names among the command
+// arguments (starting with the command name).
+func (info CmdInfo) Keys(args [][]byte) []string {
+	var keys []string
+	if info.FirstKey > 0 {
+		last := info.LastKey
+		if last < 0 {
+			last += len(args)
+		}
+		for i := info.FirstKey; i <= last && i < len(args); i += info.Step {
+			keys = append(keys, string(args[i]))
+		}
+	}
+	if info.NumKeys > 0 && info.NumKeys < len(args) {
+		n, err := strconv.Atoi(string(args[info.NumKeys]))
+		if err != nil {
+			return keys
+		}
+		for i := info.NumKeys + 1; i <= info.NumKeys+n && i < len(args); i++ {
+			keys = append(keys, string(args[i]))
+		}
+	}
+	return keys
+}
+
+// Categories returns the ACL categories of the command
+// (without the @ prefix) derived from its flags and group.
+func (info CmdInfo) Categories() []string {
+	var cats []string
+	switch {
+	case info.Has(FlagWrite):
+		cats = append(cats, "write")
+	case info.Has(FlagReadOnly):
+		cats = append(cats, "read")
+	}
+	switch info.Group {
+	case GroupGeneric:
+		cats = append(cats, "keyspace")
+	case GroupSortedSet:
+		cats = append(cats, "sortedset")
+	case GroupServer, "":
+		// server commands have no category of their own
+	default:
+		cats = append(cats, info.Group)
+	}
+	if info.Has(FlagAdmin) {
+		cats = append(cats, "admin", "dangerous")
+	}
+	if info.Has(FlagFast) {
+		cats = append(cats, "fast")
+	} else {
+		cats = append(cats, "slow")
+	}
+	return cats
+}
+
+// LookupCmd returns the description of the command
+// with the given lowercase name.
+func LookupCmd(name string) (CmdInfo, bool) {
+	info, ok := cmdInfos[name]
+	return info, ok
+}
+
+// AllCmds returns the descriptions of all commands sorted by name.
+func AllCmds() []CmdInfo {
+	infos := make([]CmdInfo, 0, len(cmdInfos))
+	for _, info := range cmdInfos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// cmdInfos are the descriptions of the supported commands.
+var cmdInfos = func() map[string]CmdInfo {
+	const (
+		admin    = FlagAdmin
+		denyoom  = FlagDenyOOM
+		fast     = FlagFast
+		loading  = FlagLoading
+		movable  = FlagMovableKeys
+		noscript = FlagNoScript
+		readonly = FlagReadOnly
+		stale    = FlagStale
+		write    = FlagWrite
+	)
+	flags := func(f ...string) []string { return f }
+	infos := []CmdInfo{
+		// server
+		{"acl", -2, flags(admin, noscript, loading, stale), 0, 0, 0, 0, GroupServer, "A container for Access List Control commands."},
+		{"bgsave", -1, flags(admin, noscript), 0, 0, 0, 0, GroupServer, "Asynchronously saves the database to disk."},
+		{"command", -1, flags(loading, stale), 0, 0, 0, 0, GroupServer, "Returns detailed information about all commands."},
+		{"config", -2, flags(admin, noscript, loading, stale), 0, 0, 0, 0, GroupServer, "Returns the effective values of configuration parameters."},
+		{"dbsize", 1, flags(readonly, fast), 0, 0, 0, 0, GroupServer, "Returns the number of keys in the database."},
+		{"flushall", -1, flags(write), 0, 0, 0, 0, GroupServer, "Removes all keys from all databases."},
+		{"flushdb", -1, flags(write), 0, 0, 0, 0, GroupServer, "Removes all keys from the current database."},
+		{"info", -1, flags(loading, stale), 0, 0, 0, 0, GroupServer, "Returns information and statistics about the server."},
+		{"lastsave", 1, flags(loading, stale, fast), 0, 0, 0, 0, GroupServer, "Returns the Unix timestamp of the last successful save to disk."},
+		{"latency", -2, flags(admin, noscript, loading, stale), 0, 0, 0, 0, GroupServer, "A container for latency diagnostics commands."},
+		{"lolwut", -1, flags(readonly, fast), 0, 0, 0, 0, GroupServer, "Displays computer art and the Redka version."},
+		{"replicaof", -2, flags(admin, noscript, stale), 0, 0, 0, 0, GroupServer, "Configures a server as replica of another, or promotes it to a leader."},
+		{"monitor", 1, flags(admin, noscript, loading, stale), 0, 0, 0, 0, GroupServer, "Listens for all requests received by the server in real-time."},
+		{"repllog", -2, flags(readonly, loading, stale), 0, 0, 0, 0, GroupServer, "Returns the keys changed after a change log position."},
+		{"role", 1, flags(noscript, loading, stale, fast), 0, 0, 0, 0, GroupServer, "Returns the replication role."},
+		{"save", 1, flags(admin, noscript), 0, 0, 0, 0, GroupServer, "Synchronously saves the database to disk."},
+		{"slaveof", -2, flags(admin, noscript, stale), 0, 0, 0, 0, GroupServer, "Sets a server as a replica of another, or promotes it to being a leader."},
+		{"slowlog", -2, flags(admin, noscript, loading, stale), 0, 0, 0, 0, GroupServer, "A container for slow log commands."},
+		{"swapdb", 3, flags(write, fast), 0, 0, 0, 0, GroupServer, "Swaps two Redis databases."},
+		// connection
+		{"auth", -2, flags(noscript, loading, stale, fast), 0, 0, 0, 0, GroupConnection, "Authenticates the connection."},
+		{"client", -2, flags(noscript, loading, stale), 0, 0, 0, 0, GroupConnection, "A container for client connection commands."},
+		{"echo", 2, flags(fast), 0, 0, 0, 0, GroupConnection, "Returns the given string."},
+		{"hello", -1, flags(noscript, loading, stale, fast), 0, 0, 0, 0, GroupConnection, "Handshakes with the Redis server."},
+		{"ping", -1, flags(fast), 0, 0, 0, 0, GroupConnection, "Returns the server's liveliness response."},
+		{"select", 2, flags(loading, stale, fast), 0, 0, 0, 0, GroupConnection, "Changes the selected database."},
+		// pubsub
+		{"subscribe", -2, flags(noscript, loading, stale), 0, 0, 0, 0, GroupPubSub, "Listens for messages published to channels."},
+		{"unsubscribe", -1, flags(noscript, loading, stale), 0, 0, 0, 0, GroupPubSub, "Stops listening to messages posted to channels."},
+		// key
+		{"copy", -3, flags(write, denyoom), 1, 2, 1, 0, GroupGeneric, "Copies the value of a key to a new key."},
+		{"del", -2, flags(write), 1, -1, 1, 0, GroupGeneric, "Deletes one or more keys."},
+		{"dump", 2, flags(readonly), 1, 1, 1, 0, GroupGeneric, "Returns a serialized representation of the value stored at a key."},
+		{"exists", -2, flags(readonly, fast), 1, -1, 1, 0, GroupGeneric, "Determines whether one or more keys exist."},
+		{"expire", -3, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Sets the expiration time of a key in seconds."},
+		{"expireat", -3, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Sets the expiration time of a key to a Unix timestamp."},
+		{"expiretime", 2, flags(readonly, fast), 1, 1, 1, 0, GroupGeneric, "Returns the expiration time of a key as a Unix timestamp."},
+		{"keys", 2, flags(readonly), 0, 0, 0, 0, GroupGeneric, "Returns all key names that match a pattern."},
+		{"move", 3, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Moves a key to another database."},
+		{"persist", 2, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Removes the expiration time of a key."},
+		{"pexpire", -3, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Sets the expiration time of a key in milliseconds."},
+		{"pexpireat", -3, flags(write, fast), 1, 1, 1, 0, GroupGeneric, "Sets the expiration time of a key to a Unix milliseconds timestamp."},
+		{"pexpiretime", 2, flags(readonly, fast), 1, 1, 1, 0, GroupGeneric, "Returns the expiration time of a key as a Unix milliseconds timestamp."},
+		{"pttl", 2, flags(readonly, fast), 1, 1, 1, 0, GroupGeneric, "Returns the expiration time in milliseconds of a key."},
+		{"randomkey", 1, flags(readonly), 0, 0, 0, 0, GroupGeneric, "Returns a random key name from the database."},
+		{"rename", 3, flags(write), 1, 2, 1, 0, GroupGeneric, "Renames a key and overwrites the destination."},
+		{"renamenx", 3, flags(write, fast), 1, 2, 1, 0, GroupGeneric, "Renames a key only when the target key name doesn't exist."},
+		{"restore", -4, flags(write, denyoom), 1, 1, 1, 0, GroupGeneric, "Creates a key from the serialized representation of a value."},
+		{"scan", -2, flags(readonly), 0, 0, 0, 0, GroupGeneric, "Iterates over the key names in the database."},
+		{"sort", -2, flags(write, denyoom), 1, 1, 1, 0, GroupGeneric, "Sorts the elements in a list, a set, or a sorted set, optionally storing the result."},
+		{"sort_ro", -2, flags(readonly), 1, 1, 1, 0, GroupGeneric, "Returns the sorted elements of a list, a set, or a sorted set."},
+		{"touch", -2, flags(readonly, fast), 1, -1, 1, 0, GroupGeneric, "Returns the number of existing keys out of those specified."},
+		{"ttl", 2, flags(readonly, fast), 1, 1, 1, 0, GroupGeneric, "Returns the expiration time in seconds of a key."},
+		{"type", 2, flags(readonly, fast), 1, 1, 1, 0, GroupGeneric, "Determines the type of value stored at a key."},
+		{"unlink", -2, flags(write, fast), 1, -1, 1, 0, GroupGeneric, "Deletes one or more keys."},
+		// list
+		{"lindex", 3, flags(readonly), 1, 1, 1, 0, GroupList, "Returns an element from a list by its index."},
+		{"linsert", 5, flags(write, denyoom), 1, 1, 1, 0, GroupList, "Inserts an element before or after another element in a list."},
+		{"llen", 2, flags(readonly, fast), 1, 1, 1, 0, GroupList, "Returns the length of a list."},
+		{"lpop", -2, flags(write, fast), 1, 1, 1, 0, GroupList, "Returns the first elements in a list after removing it."},
+		{"lpush", -3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupList, "Prepends one or more elements to a list."},
+		{"lrange", 4, flags(readonly), 1, 1, 1, 0, GroupList, "Returns a range of elements from a list."},
+		{"lrem", 4, flags(write), 1, 1, 1, 0, GroupList, "Removes elements from a list."},
+		{"lset", 4, flags(write, denyoom), 1, 1, 1, 0, GroupList, "Sets the value of an element in a list by its index."},
+		{"ltrim", 4, flags(write), 1, 1, 1, 0, GroupList, "Removes elements from both ends a list."},
+		{"rpop", -2, flags(write, fast), 1, 1, 1, 0, GroupList, "Returns and removes the last elements of a list."},
+		{"rpoplpush", 3, flags(write, denyoom), 1, 2, 1, 0, GroupList, "Returns the last element of a list after removing and pushing it to another list."},
+		{"rpush", -3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupList, "Appends one or more elements to a list."},
+		// string
+		{"decr", 2, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Decrements the integer value of a key by one."},
+		{"decrby", 3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Decrements a number from the integer value of a key."},
+		{"get", 2, flags(readonly, fast), 1, 1, 1, 0, GroupString, "Returns the string value of a key."},
+		{"getset", 3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Returns the previous string value of a key after setting it to a new value."},
+		{"incr", 2, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Increments the integer value of a key by one."},
+		{"incrby", 3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Increments the integer value of a key by a number."},
+		{"incrbyfloat", 3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Increments the floating point value of a key by a number."},
+		{"mget", -2, flags(readonly, fast), 1, -1, 1, 0, GroupString, "Atomically returns the string values of one or more keys."},
+		{"mset", -3, flags(write, denyoom), 1, -1, 2, 0, GroupString, "Atomically creates or modifies the string values of one or more keys."},
+		{"psetex", 4, flags(write, denyoom), 1, 1, 1, 0, GroupString, "Sets both string value and expiration time in milliseconds of a key."},
+		{"set", -3, flags(write, denyoom), 1, 1, 1, 0, GroupString, "Sets the string value of a key, ignoring its type."},
+		{"setex", 4, flags(write, denyoom), 1, 1, 1, 0, GroupString, "Sets the string value and expiration time of a key."},
+		{"setnx", 3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupString, "Set the string value of a key only when the key doesn't exist."},
+		{"strlen", 2, flags(readonly, fast), 1, 1, 1, 0, GroupString, "Returns the length of a string value."},
+		// hash
+		{"hdel", -3, flags(write, fast), 1, 1, 1, 0, GroupHash, "Deletes one or more fields and their values from a hash."},
+		{"hexists", 3, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Determines whether a field exists in a hash."},
+		{"hexpire", -6, flags(write, fast), 1, 1, 1, 0, GroupHash, "Sets the expiration time of hash fields in seconds."},
+		{"hexpireat", -6, flags(write, fast), 1, 1, 1, 0, GroupHash, "Sets the expiration time of hash fields to a Unix timestamp."},
+		{"hexpiretime", -5, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the expiration time of hash fields as a Unix timestamp."},
+		{"hget", 3, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the value of a field in a hash."},
+		{"hgetall", 2, flags(readonly), 1, 1, 1, 0, GroupHash, "Returns all fields and values in a hash."},
+		{"hgetdel", -5, flags(write, fast), 1, 1, 1, 0, GroupHash, "Returns the values of fields and deletes them from a hash."},
+		{"hgetex", -5, flags(write, fast), 1, 1, 1, 0, GroupHash, "Returns the values of fields and sets or removes their expiration time."},
+		{"hincrby", 4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Increments the integer value of a field in a hash by a number."},
+		{"hincrbyfloat", 4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Increments the floating point value of a field by a number."},
+		{"hkeys", 2, flags(readonly), 1, 1, 1, 0, GroupHash, "Returns all fields in a hash."},
+		{"hlen", 2, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the number of fields in a hash."},
+		{"hmget", -3, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the values of all fields in a hash."},
+		{"hmset", -4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Sets the values of multiple fields."},
+		{"hpersist", -5, flags(write, fast), 1, 1, 1, 0, GroupHash, "Removes the expiration time of hash fields."},
+		{"hpexpire", -6, flags(write, fast), 1, 1, 1, 0, GroupHash, "Sets the expiration time of hash fields in milliseconds."},
+		{"hpexpireat", -6, flags(write, fast), 1, 1, 1, 0, GroupHash, "Sets the expiration time of hash fields to a Unix milliseconds timestamp."},
+		{"hpexpiretime", -5, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the expiration time of hash fields as a Unix milliseconds timestamp."},
+		{"hpttl", -5, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the time-to-live of hash fields in milliseconds."},
+		{"hrandfield", -2, flags(readonly), 1, 1, 1, 0, GroupHash, "Returns one or more random fields from a hash."},
+		{"hscan", -3, flags(readonly), 1, 1, 1, 0, GroupHash, "Iterates over fields and values of a hash."},
+		{"hset", -4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Creates or modifies the value of a field in a hash."},
+		{"hsetex", -6, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Sets the values of fields and optionally their expiration time."},
+		{"hsetnx", 4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupHash, "Sets the value of a field in a hash only when the field doesn't exist."},
+		{"hstrlen", 3, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the length of the value of a field."},
+		{"httl", -5, flags(readonly, fast), 1, 1, 1, 0, GroupHash, "Returns the time-to-live of hash fields in seconds."},
+		{"hvals", 2, flags(readonly), 1, 1, 1, 0, GroupHash, "Returns all values in a hash."},
+		// set
+		{"sadd", -3, flags(write, denyoom, fast), 1, 1, 1, 0, GroupSet, "Adds one or more members to a set."},
+		{"scard", 2, flags(readonly, fast), 1, 1, 1, 0, GroupSet, "Returns the number of members in a set."},
+		{"sdiff", -2, flags(readonly), 1, -1, 1, 0, GroupSet, "Returns the difference of multiple sets."},
+		{"sdiffstore", -3, flags(write, denyoom), 1, -1, 1, 0, GroupSet, "Stores the difference of multiple sets in a key."},
+		{"sinter", -2, flags(readonly), 1, -1, 1, 0, GroupSet, "Returns the intersect of multiple sets."},
+		{"sintercard", -3, flags(readonly, movable), 0, 0, 0, 1, GroupSet, "Returns the number of members of the intersect of multiple sets."},
+		{"sinterstore", -3, flags(write, denyoom), 1, -1, 1, 0, GroupSet, "Stores the intersect of multiple sets in a key."},
+		{"sismember", 3, flags(readonly, fast), 1, 1, 1, 0, GroupSet, "Determines whether a member belongs to a set."},
+		{"smembers", 2, flags(readonly), 1, 1, 1, 0, GroupSet, "Returns all members of a set."},
+		{"smismember", -3, flags(readonly, fast), 1, 1, 1, 0, GroupSet, "Determines whether multiple members belong to a set."},
+		{"smove", 4, flags(write, fast), 1, 2, 1, 0, GroupSet, "Moves a member from one set to another."},
+		{"spop", -2, flags(write, fast), 1, 1, 1, 0, GroupSet, "Returns one or more random members from a set after removing them."},
+		{"srandmember", -2, flags(readonly), 1, 1, 1, 0, GroupSet, "Get one or multiple random members from a set."},
+		{"srem", -3, flags(write, fast), 1, 1, 1, 0, GroupSet, "Removes one or more members from a set."},
+		{"sscan", -3, flags(readonly), 1, 1, 1, 0, GroupSet, "Iterates over members of a set."},
+		{"sunion", -2, flags(readonly), 1, -1, 1, 0, GroupSet, "Returns the union of multiple sets."},
+		{"sunionstore", -3, flags(write, denyoom), 1, -1, 1, 0, GroupSet, "Stores the union of multiple sets in a key."},
+		// sorted set
+		{"zadd", -4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupSortedSet, "Adds one or more members to a sorted set, or updates their scores."},
+		{"zcard", 2, flags(readonly, fast), 1, 1, 1, 0, GroupSortedSet, "Returns the number of members in a sorted set."},
+		{"zcount", 4, flags(readonly, fast), 1, 1, 1, 0, GroupSortedSet, "Returns the count of members in a sorted set that have scores within a range."},
+		{"zincrby", 4, flags(write, denyoom, fast), 1, 1, 1, 0, GroupSortedSet, "Increments the score of a member in a sorted set."},
+		{"zinter", -3, flags(readonly, movable), 0, 0, 0, 1, GroupSortedSet, "Returns the intersect of multiple sorted sets."},
+		{"zinterstore", -4, flags(write, denyoom, movable), 1, 1, 1, 2, GroupSortedSet, "Stores the intersect of multiple sorted sets in a key."},
+		{"zrange", -4, flags(readonly), 1, 1, 1, 0, GroupSortedSet, "Returns members in a sorted set within a range of indexes."},
+		{"zrangebyscore", -4, flags(readonly), 1, 1, 1, 0, GroupSortedSet, "Returns members in a sorted set within a range of scores."},
+		{"zrank", -3, flags(readonly, fast), 1, 1, 1, 0, GroupSortedSet, "Returns the index of a member in a sorted set ordered by ascending scores."},
+		{"zrem", -3, flags(write, fast), 1, 1, 1, 0, GroupSortedSet, "Removes one or more members from a sorted set."},
+		{"zremrangebyrank", 4, flags(write), 1, 1, 1, 0, GroupSortedSet, "Removes members in a sorted set within a range of indexes."},
+		{"zremrangebyscore", 4, flags(write), 1, 1, 1, 0, GroupSortedSet, "Removes members in a sorted set within a range of scores."},
+		{"zrevrange", -4, flags(readonly), 1, 1, 1, 0, GroupSortedSet, "Returns members in a sorted set within a range of indexes in reverse order."},
+		{"zrevrangebyscore", -4, flags(readonly), 1, 1, 1, 0, GroupSortedSet, "Returns members in a sorted set within a range of scores in reverse order."},
+		{"zrevrank", -3, flags(readonly, fast), 1, 1, 1, 0, GroupSortedSet, "Returns the index of a member in a sorted set ordered by descending scores."},
+		{"zscan", -3, flags(readonly), 1, 1, 1, 0, GroupSortedSet, "Iterates over members and scores of a sorted set."},
+		{"zscore", 3, flags(readonly, fast), 1, 1, 1, 0, GroupSortedSet, "Returns the score of a member in a sorted set."},
+		{"zunion", -3, flags(readonly, movable), 0, 0, 0, 1, GroupSortedSet, "Returns the union of multiple sorted sets."},
+		{"zunionstore", -4, flags(write, denyoom, movable), 1, 1, 1, 2, GroupSortedSet, "Stores the union of multiple sorted sets in a key."},
+	}
+	m := make(map[string]CmdInfo, len(infos))
+	for _, info := range infos {
+		m[info.Name] = info
+	}
+	return m
+}()
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestCmdInfoKeys(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{cmd: "ping", want: nil},
+		{cmd: "get name", want: []string{"name"}},
+		{cmd: "del k1 k2 k3", want: []string{"k1", "k2", "k3"}},
+		{cmd: "mset k1 v1 k2 v2", want: []string{"k1", "k2"}},
+		{cmd: "rename src dst", want: []string{"src", "dst"}},
+		{cmd: "sintercard 2 k1 k2 limit 10", want: []string{"k1", "k2"}},
+		{cmd: "zunionstore dst 2 k1 k2 weights 1 2", want: []string{"dst", "k1", "k2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			var args [][]byte
+			for _, arg := range strings.Fields(test.cmd) {
+				args = append(args, []byte(arg))
+			}
+			cmd := NewBaseCmd(args)
+			testx.AssertEqual(t, cmd.Keys(), test.want)
+		})
+	}
+}
+
+func TestCmdInfoFlags(t *testing.T) {
+	cmd := NewBaseCmd([][]byte{[]byte("GET"), []byte("name")})
+	testx.AssertEqual(t, cmd.IsReadOnly(), true)
+	testx.AssertEqual(t, cmd.IsWrite(), false)
+
+	cmd = NewBaseCmd([][]byte{[]byte("set"), []byte("name"), []byte("alice")})
+	testx.AssertEqual(t, cmd.IsReadOnly(), false)
+	testx.AssertEqual(t, cmd.IsWrite(), true)
+
+	cmd = NewBaseCmd([][]byte{[]byte("unknown")})
+	testx.AssertEqual(t, cmd.Info(), CmdInfo{Name: "unknown"})
+	testx.AssertEqual(t, cmd.IsReadOnly(), false)
+	testx.AssertEqual(t, cmd.IsWrite(), false)
+}
+
+func TestCmdInfoCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"get", []string{"read", "string", "fast"}},
+		{"del", []string{"write", "keyspace", "slow"}},
+		{"zadd", []string{"write", "sortedset", "fast"}},
+		{"ping", []string{"connection", "fast"}},
+		{"save", []string{"admin", "dangerous", "slow"}},
+	}
+	for _, test := range tests {
+		info, _ := LookupCmd(test.name)
+		testx.AssertEqual(t, info.Categories(), test.want)
+	}
+}
@@ -11,33 +11,85 @@ import (
 	"github.com/flarco/redka/internal/core"
 )
 
+// Version is the Redis version reported by INFO and HELLO.
+// Some clients check it before using newer commands.
+const Version = "7.4.0"
+
+// Databases is the number of logical databases
+// (SELECT accepts indexes from 0 to Databases-1).
+const Databases = 16
+
 // Redis-like errors.
 var (
-	ErrInvalidArgNum     = errors.New("ERR wrong number of arguments")
-	ErrInvalidCursor     = errors.New("ERR invalid cursor")
-	ErrInvalidExpireTime = errors.New("ERR invalid expire time")
-	ErrInvalidFloat      = errors.New("ERR value is not a float")
-	ErrInvalidInt        = errors.New("ERR value is not an integer")
-	ErrNestedMulti       = errors.New("ERR MULTI calls can not be nested")
-	ErrNotFound          = errors.New("ERR no such key")
-	ErrNotInMulti        = errors.New("ERR EXEC without MULTI")
-	ErrOutOfRange        = errors.New("ERR index out of range")
-	ErrSyntaxError       = errors.New("ERR syntax error")
-	ErrUnknownCmd        = errors.New("ERR unknown command")
-	ErrUnknownSubcmd     = errors.New("ERR unknown subcommand")
+	ErrBgSaveInProgress   = errors.New("ERR Background save already in progress")
+	ErrBCastOptIn         = errors.New("ERR OPTIN and OPTOUT are not compatible with BCAST")
+	ErrBCastSwitch        = errors.New("ERR You can't switch BCAST mode on/off before disabling tracking for this client, and then re-enabling it with a different mode.")
+	ErrBusyKey            = errors.New("BUSYKEY Target key name already exists")
+	ErrCachingNo          = errors.New("ERR CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode.")
+	ErrCachingYes         = errors.New("ERR CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode.")
+	ErrCachingMode        = errors.New("ERR CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled")
+	ErrChangeLogDisabled  = errors.New("ERR change log is disabled")
+	ErrChangeLogTruncated = errors.New("ERR change log truncated")
+	ErrDBIndex            = errors.New("ERR DB index is out of range")
+	ErrExecAbort          = errors.New("EXECABORT Transaction discarded because of previous errors.")
+	ErrInvalidArgNum      = errors.New("ERR wrong number of arguments")
+	ErrInvalidClientName  = errors.New("ERR Client names cannot contain spaces, newlines or special characters.")
+	ErrInvalidCursor      = errors.New("ERR invalid cursor")
+	ErrInvalidDump        = errors.New("ERR DUMP payload version or checksum are wrong")
+	ErrInvalidExpireTime  = errors.New("ERR invalid expire time")
+	ErrInvalidFloat       = errors.New("ERR value is not a float")
+	ErrInvalidInt         = errors.New("ERR value is not an integer")
+	ErrInvalidProto       = errors.New("ERR Protocol version is not an integer or out of range")
+	ErrInvalidSortScore   = errors.New("ERR one or more scores can't be converted into double")
+	ErrNegativeCount      = errors.New("ERR value is out of range, must be positive")
+	ErrNegativeTimeout    = errors.New("ERR timeout is negative")
+	ErrNestedMulti        = errors.New("ERR MULTI calls can not be nested")
+	ErrNotAllowedInMulti  = errors.New("ERR Command not allowed inside a transaction")
+	ErrNotFound           = errors.New("ERR no such key")
+	ErrNotInMulti         = errors.New("ERR EXEC without MULTI")
+	ErrNoACL              = errors.New("ERR access control is not configured")
+	ErrNoAuth             = errors.New("NOAUTH Authentication required.")
+	ErrNoAuthHello        = errors.New("NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time")
+	ErrNoClients          = errors.New("ERR clients are not tracked")
+	ErrNoPass             = errors.New("ERR AUTH <password> called without any password configured for the default user. Are you sure your client is configured correctly?")
+	ErrNoPermKey          = errors.New("NOPERM No permissions to access a key")
+	ErrNoPersistence      = errors.New("ERR persistence is not configured")
+	ErrNoProto            = errors.New("NOPROTO unsupported protocol version")
+	ErrNoReplication      = errors.New("ERR replication is not configured")
+	ErrNoRedirect         = errors.New("ERR The client ID you want redirect to does not exist")
+	ErrNoSuchClient       = errors.New("ERR No such client")
+	ErrNoStats            = errors.New("ERR statistics are not configured")
+	ErrOptInOptOut        = errors.New("ERR You can't use both OPTIN and OPTOUT")
+	ErrOutOfRange         = errors.New("ERR index out of range")
+	ErrPrefixNoBCast      = errors.New("ERR PREFIX option requires BCAST mode to be enabled")
+	ErrPrefixOverlap      = errors.New("ERR Prefixes for a single client must not overlap")
+	ErrReadOnly           = errors.New("READONLY You can't write against a read only replica.")
+	ErrSameObject         = errors.New("ERR source and destination objects are the same")
+	ErrSyntaxError        = errors.New("ERR syntax error")
+	ErrUnknownChannel     = errors.New("ERR only the __redis__:invalidate channel is supported")
+	ErrUnknownCmd         = errors.New("ERR unknown command")
+	ErrUnknownSubcmd      = errors.New("ERR unknown subcommand")
+	ErrWrongPass          = errors.New("WRONGPASS invalid username-password pair or user is disabled.")
 )
 
 // Writer is an interface to write responses to the client.
+// The RESP3 types (maps, sets, doubles, booleans and pushes)
+// are written as their RESP2 equivalents to RESP2 clients.
 type Writer interface {
 	WriteAny(v any)
 	WriteArray(count int)
+	WriteBool(b bool)
 	WriteBulk(bulk []byte)
 	WriteBulkString(bulk string)
+	WriteDouble(num float64)
 	WriteError(msg string)
 	WriteInt(num int)
 	WriteInt64(num int64)
+	WriteMap(count int)
 	WriteNull()
+	WritePush(count int)
 	WriteRaw(data []byte)
+	WriteSet(count int)
 	WriteString(str string)
 	WriteUint64(num uint64)
 }
@@ -50,6 +102,21 @@ type Cmd interface {
 	// String returns the command string representation (name and arguments).
 	String() string
 
+	// Args returns the command arguments (without the name).
+	Args() [][]byte
+
+	// Info returns the command description (arity, flags, key positions).
+	Info() CmdInfo
+
+	// IsWrite reports whether the command modifies the database.
+	IsWrite() bool
+
+	// IsReadOnly reports whether the command only reads the database.
+	IsReadOnly() bool
+
+	// Keys returns the key names among the command arguments.
+	Keys() []string
+
 	// Error translates a domain error to a command error
 	// and returns its string representation.
 	Error(err error) string
@@ -91,6 +158,34 @@ func (cmd BaseCmd) Args() [][]byte {
 	return cmd.args
 }
 
+// Info returns the command description.
+// Unknown commands have only the name set.
+func (cmd BaseCmd) Info() CmdInfo {
+	info, ok := LookupCmd(cmd.name)
+	if !ok {
+		return CmdInfo{Name: cmd.name}
+	}
+	return info
+}
+
+// IsWrite reports whether the command modifies the database.
+func (cmd BaseCmd) IsWrite() bool {
+	return cmd.Info().Has(FlagWrite)
+}
+
+// IsReadOnly reports whether the command only reads the database.
+func (cmd BaseCmd) IsReadOnly() bool {
+	return cmd.Info().Has(FlagReadOnly)
+}
+
+// Keys returns the key names among the command arguments.
+func (cmd BaseCmd) Keys() []string {
+	args := make([][]byte, 0, len(cmd.args)+1)
+	args = append(args, []byte(cmd.name))
+	args = append(args, cmd.args...)
+	return cmd.Info().Keys(args)
+}
+
 // String returns the command string representation (name and arguments).
 func (cmd BaseCmd) String() string {
 	var b strings.Builder
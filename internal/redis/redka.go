@@ -4,50 +4,94 @@ import (
 	"time"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/clients"
 	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/repl"
 	"github.com/flarco/redka/internal/rhash"
 	"github.com/flarco/redka/internal/rkey"
 	"github.com/flarco/redka/internal/rset"
 	"github.com/flarco/redka/internal/rstring"
 	"github.com/flarco/redka/internal/rzset"
+	"github.com/flarco/redka/internal/stats"
 )
 
+// RACL is an access control list manager.
+type RACL interface {
+	Auth(name, pass string) error
+	DelUser(names ...string) (int, error)
+	GetUser(name string) (acl.User, bool)
+	HasPass() bool
+	List() []string
+	SetUser(name string, rules ...string) error
+	Users() []string
+}
+
+// RClients is a client registry.
+type RClients interface {
+	Kill(f clients.Filter, self *clients.Client) int
+	List(ids ...int64) []*clients.Client
+	Pause(timeout time.Duration, mode string)
+	SetTracking(c *clients.Client, opts *clients.TrackingOptions) error
+	Unpause()
+}
+
 // RHash is a hash repository.
 type RHash interface {
 	Delete(key string, fields ...string) (int, error)
 	Exists(key, field string) (bool, error)
+	ExpireWith(key string, fields ...string) rhash.ExpireCmd
+	Expiry(key string, fields ...string) ([]rhash.Expiry, error)
 	Fields(key string) ([]string, error)
 	Get(key, field string) (core.Value, error)
+	GetDelete(key string, fields ...string) (map[string]core.Value, error)
 	GetMany(key string, fields ...string) (map[string]core.Value, error)
 	Incr(key, field string, delta int) (int, error)
 	IncrFloat(key, field string, delta float64) (float64, error)
 	Items(key string) (map[string]core.Value, error)
 	Len(key string) (int, error)
+	Persist(key string, fields ...string) ([]int, error)
+	Random(key string) (rhash.HashItem, error)
+	RandomMany(key string, count int) ([]rhash.HashItem, error)
 	Scan(key string, cursor int, pattern string, pageSize int) (rhash.ScanResult, error)
 	Scanner(key, pattern string, pageSize int) *rhash.Scanner
 	Set(key, field string, value any) (bool, error)
 	SetMany(key string, items map[string]any) (int, error)
 	SetNotExists(key, field string, value any) (bool, error)
+	SetWith(key string, items map[string]any) rhash.SetCmd
 	Values(key string) ([]core.Value, error)
 }
 
 // RKey is a key repository.
 type RKey interface {
+	Changes(after int64, count int) (rkey.ChangeResult, error)
+	Copy(src, dst string, replace bool) (bool, error)
 	Count(keys ...string) (int, error)
+	Counts() (rkey.KeyCounts, error)
+	DBCounts() (map[int]rkey.KeyCounts, error)
 	Delete(keys ...string) (int, error)
 	DeleteAll() error
+	DeleteAllDatabases() error
+	Dump(key string) ([]byte, error)
 	Exists(key string) (bool, error)
-	Expire(key string, ttl time.Duration) error
-	ExpireAt(key string, at time.Time) error
+	Expire(key string, ttl time.Duration, conds ...rkey.ExpireCond) error
+	ExpireAt(key string, at time.Time, conds ...rkey.ExpireCond) error
 	Get(key string) (core.Key, error)
 	Keys(pattern string) ([]core.Key, error)
+	LastChange() (int64, error)
 	Len() (int, error)
+	Move(key string, index int) (bool, error)
 	Persist(key string) error
 	Random() (core.Key, error)
 	Rename(key, newKey string) error
 	RenameNotExists(key, newKey string) (bool, error)
+	Restore(key string, data []byte) error
+	RestoreWith(key string, data []byte) rkey.RestoreCmd
 	Scan(cursor int, pattern string, ktype core.TypeID, count int) (rkey.ScanResult, error)
 	Scanner(pattern string, ktype core.TypeID, pageSize int) *rkey.Scanner
+	SortWith(key string) rkey.SortCmd
+	SwapDB(index1, index2 int) error
 }
 
 // RList is a list repository.
@@ -69,6 +113,22 @@ type RList interface {
 	Trim(key string, start, stop int) (int, error)
 }
 
+// RRepl is a replication manager.
+type RRepl interface {
+	ReadOnly() bool
+	ReplicaOf(addr string) error
+	Role() repl.Role
+}
+
+// RSaver is a database snapshot manager.
+type RSaver interface {
+	BgSave() error
+	LastSave() time.Time
+	Save() error
+	Schedule() bool
+	Status() persist.Status
+}
+
 // RSet is a set repository.
 type RSet interface {
 	Add(key string, elems ...any) (int, error)
@@ -76,19 +136,31 @@ type RSet interface {
 	Diff(keys ...string) ([]core.Value, error)
 	DiffStore(dest string, keys ...string) (int, error)
 	Exists(key, elem any) (bool, error)
+	ExistsMany(key string, elems ...any) ([]bool, error)
 	Inter(keys ...string) ([]core.Value, error)
+	InterLen(limit int, keys ...string) (int, error)
 	InterStore(dest string, keys ...string) (int, error)
 	Items(key string) ([]core.Value, error)
 	Len(key string) (int, error)
 	Move(src, dest string, elem any) error
 	Pop(key string) (core.Value, error)
+	PopMany(key string, count int) ([]core.Value, error)
 	Random(key string) (core.Value, error)
+	RandomMany(key string, count int) ([]core.Value, error)
 	Scan(key string, cursor int, pattern string, count int) (rset.ScanResult, error)
 	Scanner(key, pattern string, pageSize int) *rset.Scanner
 	Union(keys ...string) ([]core.Value, error)
 	UnionStore(dest string, keys ...string) (int, error)
 }
 
+// RStats is a server statistics collector.
+type RStats interface {
+	DB() (redka.Stats, error)
+	Latency() *stats.LatencyMonitor
+	Server() stats.Server
+	SlowLog() *stats.SlowLog
+}
+
 // RStr is a string repository.
 type RStr interface {
 	Get(key string) (core.Value, error)
@@ -126,12 +198,18 @@ type RZSet interface {
 // Redka is an abstraction for *redka.DB and *redka.Tx.
 // Used to execute commands in a unified way.
 type Redka struct {
-	hash RHash
-	key  RKey
-	list RList
-	set  RSet
-	str  RStr
-	zset RZSet
+	acl     RACL
+	client  *clients.Client
+	clients RClients
+	hash    RHash
+	key     RKey
+	list    RList
+	repl    RRepl
+	saver   RSaver
+	set     RSet
+	stats   RStats
+	str     RStr
+	zset    RZSet
 }
 
 // RedkaDB creates a new Redka instance for a database.
@@ -158,6 +236,45 @@ func RedkaTx(tx *redka.Tx) Redka {
 	}
 }
 
+// ACL returns the access control list manager
+// (nil if access control is not configured).
+func (r Redka) ACL() RACL {
+	return r.acl
+}
+
+// WithACL returns a copy of the Redka instance
+// with the given access control list manager.
+func (r Redka) WithACL(acl RACL) Redka {
+	r.acl = acl
+	return r
+}
+
+// Client returns the client running the command
+// (nil if clients are not tracked).
+func (r Redka) Client() *clients.Client {
+	return r.client
+}
+
+// WithClient returns a copy of the Redka instance
+// with the given client.
+func (r Redka) WithClient(client *clients.Client) Redka {
+	r.client = client
+	return r
+}
+
+// Clients returns the client registry
+// (nil if clients are not tracked).
+func (r Redka) Clients() RClients {
+	return r.clients
+}
+
+// WithClients returns a copy of the Redka instance
+// with the given client registry.
+func (r Redka) WithClients(clients RClients) Redka {
+	r.clients = clients
+	return r
+}
+
 // Hash returns the hash repository.
 func (r Redka) Hash() RHash {
 	return r.hash
@@ -173,11 +290,50 @@ func (r Redka) List() RList {
 	return r.list
 }
 
+// Repl returns the replication manager
+// (nil if replication is not configured).
+func (r Redka) Repl() RRepl {
+	return r.repl
+}
+
+// WithRepl returns a copy of the Redka instance
+// with the given replication manager.
+func (r Redka) WithRepl(repl RRepl) Redka {
+	r.repl = repl
+	return r
+}
+
+// Saver returns the snapshot manager
+// (nil if persistence is not configured).
+func (r Redka) Saver() RSaver {
+	return r.saver
+}
+
+// WithSaver returns a copy of the Redka instance
+// with the given snapshot manager.
+func (r Redka) WithSaver(saver RSaver) Redka {
+	r.saver = saver
+	return r
+}
+
 // Set returns the set repository.
 func (r Redka) Set() RSet {
 	return r.set
 }
 
+// Stats returns the statistics collector
+// (nil if statistics are not collected).
+func (r Redka) Stats() RStats {
+	return r.stats
+}
+
+// WithStats returns a copy of the Redka instance
+// with the given statistics collector.
+func (r Redka) WithStats(stats RStats) Redka {
+	r.stats = stats
+	return r
+}
+
 // Str returns the string repository.
 func (r Redka) Str() RStr {
 	return r.str
@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/tidwall/redcon"
+)
+
+// RESP protocol versions.
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+// ProtoWriter is a writer that can switch
+// the protocol version (used by HELLO).
+type ProtoWriter interface {
+	Writer
+	Proto() int
+	SetProto(proto int)
+}
+
+// DBWriter is a writer that can switch the logical
+// database of the connection (used by SELECT).
+type DBWriter interface {
+	Writer
+	DB() int
+	SetDB(index int)
+}
+
+// SelectedDB returns the index of the logical database
+// selected by the connection. Writers that don't support
+// switching the database work with the default one (0).
+func SelectedDB(w Writer) int {
+	if dw, ok := w.(DBWriter); ok {
+		return dw.DB()
+	}
+	return 0
+}
+
+// Conn is a client connection that writes responses
+// in the negotiated protocol version. In RESP2, maps,
+// sets and pushes are written as arrays, doubles
+// as bulk strings, booleans as integers.
+type Conn struct {
+	redcon.Conn
+	proto int
+	db    int
+}
+
+// NewConn wraps the connection to write responses
+// in the given protocol version (RESP2 or RESP3).
+func NewConn(conn redcon.Conn, proto int) *Conn {
+	if proto != RESP3 {
+		proto = RESP2
+	}
+	return &Conn{Conn: conn, proto: proto}
+}
+
+// Proto returns the protocol version.
+func (c *Conn) Proto() int {
+	return c.proto
+}
+
+// SetProto switches the protocol version.
+func (c *Conn) SetProto(proto int) {
+	c.proto = proto
+}
+
+// DB returns the index of the selected logical database.
+func (c *Conn) DB() int {
+	return c.db
+}
+
+// SetDB switches the logical database.
+func (c *Conn) SetDB(index int) {
+	c.db = index
+}
+
+// WriteNull writes a null value.
+func (c *Conn) WriteNull() {
+	if c.proto == RESP3 {
+		c.Conn.WriteRaw([]byte("_\r\n"))
+		return
+	}
+	c.Conn.WriteNull()
+}
+
+// WriteMap writes a map header, followed by
+// count key-value pairs (2*count values).
+func (c *Conn) WriteMap(count int) {
+	if c.proto == RESP3 {
+		c.writeHeader('%', count)
+		return
+	}
+	c.Conn.WriteArray(count * 2)
+}
+
+// WriteSet writes a set header, followed by count values.
+func (c *Conn) WriteSet(count int) {
+	if c.proto == RESP3 {
+		c.writeHeader('~', count)
+		return
+	}
+	c.Conn.WriteArray(count)
+}
+
+// WritePush writes a push message header,
+// followed by count values.
+func (c *Conn) WritePush(count int) {
+	if c.proto == RESP3 {
+		c.writeHeader('>', count)
+		return
+	}
+	c.Conn.WriteArray(count)
+}
+
+// WriteDouble writes a floating-point number.
+func (c *Conn) WriteDouble(num float64) {
+	if c.proto != RESP3 {
+		c.Conn.WriteBulkString(strconv.FormatFloat(num, 'f', -1, 64))
+		return
+	}
+	buf := []byte{','}
+	switch {
+	case math.IsInf(num, 1):
+		buf = append(buf, "inf"...)
+	case math.IsInf(num, -1):
+		buf = append(buf, "-inf"...)
+	case math.IsNaN(num):
+		buf = append(buf, "nan"...)
+	default:
+		buf = strconv.AppendFloat(buf, num, 'f', -1, 64)
+	}
+	buf = append(buf, '\r', '\n')
+	c.Conn.WriteRaw(buf)
+}
+
+// WriteBool writes a boolean value.
+func (c *Conn) WriteBool(b bool) {
+	switch {
+	case c.proto != RESP3 && b:
+		c.Conn.WriteInt(1)
+	case c.proto != RESP3:
+		c.Conn.WriteInt(0)
+	case b:
+		c.Conn.WriteRaw([]byte("#t\r\n"))
+	default:
+		c.Conn.WriteRaw([]byte("#f\r\n"))
+	}
+}
+
+// writeHeader writes an aggregate type header.
+func (c *Conn) writeHeader(prefix byte, count int) {
+	buf := []byte{prefix}
+	buf = strconv.AppendInt(buf, int64(count), 10)
+	buf = append(buf, '\r', '\n')
+	c.Conn.WriteRaw(buf)
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestConnRESP2(t *testing.T) {
+	fake := NewFakeConn()
+	conn := NewConn(fake, RESP2)
+	testx.AssertEqual(t, conn.Proto(), RESP2)
+
+	conn.WriteMap(2)
+	conn.WriteSet(3)
+	conn.WritePush(1)
+	conn.WriteDouble(1.5)
+	conn.WriteBool(true)
+	conn.WriteBool(false)
+	conn.WriteNull()
+	testx.AssertEqual(t, fake.Out(), "4,3,1,1.5,1,0,(nil)")
+}
+
+func TestConnRESP3(t *testing.T) {
+	fake := NewFakeConn()
+	conn := NewConn(fake, RESP3)
+	testx.AssertEqual(t, conn.Proto(), RESP3)
+
+	conn.WriteMap(2)
+	conn.WriteSet(3)
+	conn.WritePush(1)
+	conn.WriteDouble(1.5)
+	conn.WriteDouble(math.Inf(1))
+	conn.WriteDouble(math.Inf(-1))
+	conn.WriteBool(true)
+	conn.WriteBool(false)
+	conn.WriteNull()
+	testx.AssertEqual(t, fake.Out(),
+		"%2\r\n,~3\r\n,>1\r\n,,1.5\r\n,,inf\r\n,,-inf\r\n,#t\r\n,#f\r\n,_\r\n")
+
+	// Other types are the same as in RESP2.
+	fake = NewFakeConn()
+	conn = NewConn(fake, RESP3)
+	conn.WriteArray(2)
+	conn.WriteBulkString("hello")
+	conn.WriteInt(42)
+	testx.AssertEqual(t, fake.Out(), "2,hello,42")
+}
+
+func TestConnSetProto(t *testing.T) {
+	fake := NewFakeConn()
+	conn := NewConn(fake, 0)
+	testx.AssertEqual(t, conn.Proto(), RESP2)
+	conn.WriteMap(1)
+	conn.SetProto(RESP3)
+	conn.WriteMap(1)
+	testx.AssertEqual(t, fake.Out(), "2,%1\r\n")
+}
@@ -0,0 +1,101 @@
+package repl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flarco/redka"
+)
+
+// Replication roles.
+const (
+	RoleLeader  = "master"
+	RoleReplica = "slave"
+)
+
+// Role describes the replication role of the database.
+type Role struct {
+	Name   string // RoleLeader or RoleReplica
+	Pos    int64  // change log position
+	Addr   string // leader address (replica only)
+	State  string // link state (replica only)
+	LastIO int64  // seconds since the last interaction with the leader (replica only)
+}
+
+// Manager switches the database between the leader
+// and replica roles (the REPLICAOF command).
+// The database is a leader unless it replicates another one.
+type Manager struct {
+	db   *redka.DB
+	opts *Options
+
+	mu      sync.Mutex
+	replica *Replica
+}
+
+// NewManager creates a replication manager for the database.
+// The database starts as a leader.
+// The opts parameter is optional and applies to all replicas.
+func NewManager(db *redka.DB, opts *Options) *Manager {
+	return &Manager{db: db, opts: opts}
+}
+
+// ReplicaOf makes the database a replica of the leader at addr
+// (a database file path or a host:port address), discarding
+// all local keys. If addr is empty, stops replicating
+// and makes the database a leader, keeping the copied keys.
+// Does nothing if already replicating the same leader.
+func (m *Manager) ReplicaOf(addr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.replica != nil {
+		if m.replica.Addr() == addr {
+			return nil
+		}
+		m.replica.Stop()
+		m.replica = nil
+	}
+	if addr == "" {
+		return nil
+	}
+	m.replica = NewReplica(m.db, addr, m.opts)
+	m.replica.Start()
+	return nil
+}
+
+// ReadOnly reports whether the database is a replica,
+// and thus only accepts reads.
+func (m *Manager) ReadOnly() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.replica != nil
+}
+
+// Role returns the current replication role.
+func (m *Manager) Role() Role {
+	m.mu.Lock()
+	replica := m.replica
+	m.mu.Unlock()
+
+	if replica == nil {
+		pos, _ := m.db.Key().LastChange()
+		return Role{Name: RoleLeader, Pos: pos}
+	}
+	status := replica.Status()
+	role := Role{
+		Name:   RoleReplica,
+		Pos:    status.Pos,
+		Addr:   status.Addr,
+		State:  status.State,
+		LastIO: -1,
+	}
+	if !status.LastIO.IsZero() {
+		role.LastIO = int64(time.Since(status.LastIO) / time.Second)
+	}
+	return role
+}
+
+// Close stops replicating (if the database is a replica).
+func (m *Manager) Close() {
+	_ = m.ReplicaOf("")
+}
@@ -0,0 +1,360 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flarco/redka/internal/rkey"
+)
+
+// errProtocol is returned when the leader
+// sends an invalid or unexpected reply.
+var errProtocol = errors.New("invalid leader reply")
+
+// remoteError is an error reply sent by the leader.
+type remoteError string
+
+func (e remoteError) Error() string {
+	return string(e)
+}
+
+// remoteSource reads the leader database over
+// the network using the Redis protocol (RESP).
+type remoteSource struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	w       *bufio.Writer
+	timeout time.Duration
+	nread   int64 // number of bytes read so far
+	db      int   // selected logical database
+	// primary is true if the leader does not have a change log
+	// (e.g. it is a Redis server), so the replica has to follow
+	// its command stream (see Replica.stream).
+	primary bool
+}
+
+// dialRemote connects to the leader at the network address,
+// authenticating if the password is not empty.
+func dialRemote(addr string, password string, timeout time.Duration) (*remoteSource, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	s := &remoteSource{
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+		w:       bufio.NewWriter(conn),
+		timeout: timeout,
+	}
+	if password != "" {
+		if _, err := s.do("auth", password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	// Check if the leader supports the change log.
+	_, err = s.LastChange()
+	var rerr remoteError
+	if errors.As(err, &rerr) && strings.Contains(strings.ToLower(string(rerr)), "unknown command") {
+		s.primary = true
+		err = nil
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *remoteSource) Changes(after int64, count int) (rkey.ChangeResult, error) {
+	reply, err := s.do("repllog", strconv.FormatInt(after, 10), "count", strconv.Itoa(count))
+	if err != nil {
+		return rkey.ChangeResult{}, changeError(err)
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return rkey.ChangeResult{}, errProtocol
+	}
+	pos, err := toInt(items[0])
+	if err != nil {
+		return rkey.ChangeResult{}, err
+	}
+	// The keys are database index and key name pairs.
+	if len(items)%2 != 1 {
+		return rkey.ChangeResult{}, errProtocol
+	}
+	res := rkey.ChangeResult{Pos: int64(pos), Keys: make([]rkey.ChangedKey, 0, len(items)/2)}
+	for i := 1; i < len(items); i += 2 {
+		db, err := toInt(items[i])
+		if err != nil {
+			return rkey.ChangeResult{}, err
+		}
+		key, ok := items[i+1].([]byte)
+		if !ok {
+			return rkey.ChangeResult{}, errProtocol
+		}
+		res.Keys = append(res.Keys, rkey.ChangedKey{DB: db, Key: string(key)})
+	}
+	return res, nil
+}
+
+func (s *remoteSource) LastChange() (int64, error) {
+	res, err := s.Changes(0, 0)
+	return res.Pos, err
+}
+
+func (s *remoteSource) DBs() ([]int, error) {
+	reply, err := s.do("info", "keyspace")
+	if err != nil {
+		return nil, err
+	}
+	info, ok := reply.([]byte)
+	if !ok {
+		return nil, errProtocol
+	}
+	// The keyspace section has a dbN:keys=... line
+	// for each database that has keys.
+	var dbs []int
+	for _, line := range strings.Split(string(info), "\n") {
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(name, "db") {
+			continue
+		}
+		db, err := strconv.Atoi(name[2:])
+		if err != nil {
+			return nil, errProtocol
+		}
+		dbs = append(dbs, db)
+	}
+	slices.Sort(dbs)
+	return dbs, nil
+}
+
+func (s *remoteSource) Scan(db int, cursor int, count int) (int, []string, error) {
+	if err := s.selectDB(db); err != nil {
+		return 0, nil, err
+	}
+	reply, err := s.do("scan", strconv.Itoa(cursor), "count", strconv.Itoa(count))
+	if err != nil {
+		return 0, nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) != 2 {
+		return 0, nil, errProtocol
+	}
+	next, err := toInt(items[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	names, ok := items[1].([]any)
+	if !ok {
+		return 0, nil, errProtocol
+	}
+	keys := make([]string, len(names))
+	for i, name := range names {
+		key, ok := name.([]byte)
+		if !ok {
+			return 0, nil, errProtocol
+		}
+		keys[i] = string(key)
+	}
+	return next, keys, nil
+}
+
+func (s *remoteSource) Dump(db int, keys []string) ([][]byte, error) {
+	if err := s.selectDB(db); err != nil {
+		return nil, err
+	}
+	// Pipeline the commands to avoid a round trip per key.
+	s.setDeadline()
+	for _, key := range keys {
+		s.write("dump", key)
+	}
+	if err := s.w.Flush(); err != nil {
+		return nil, err
+	}
+	dumps := make([][]byte, len(keys))
+	for i := range keys {
+		reply, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+		switch val := reply.(type) {
+		case nil:
+		case []byte:
+			dumps[i] = val
+		default:
+			return nil, errProtocol
+		}
+	}
+	return dumps, nil
+}
+
+func (s *remoteSource) Close() error {
+	return s.conn.Close()
+}
+
+// selectDB selects the logical database
+// unless it is already selected.
+func (s *remoteSource) selectDB(db int) error {
+	if db == s.db {
+		return nil
+	}
+	if _, err := s.do("select", strconv.Itoa(db)); err != nil {
+		return err
+	}
+	s.db = db
+	return nil
+}
+
+// do sends a command to the leader and reads the reply.
+func (s *remoteSource) do(args ...string) (any, error) {
+	s.setDeadline()
+	s.write(args...)
+	if err := s.w.Flush(); err != nil {
+		return nil, err
+	}
+	return s.read()
+}
+
+// setDeadline sets the deadline for the next request.
+func (s *remoteSource) setDeadline() {
+	if s.timeout > 0 {
+		_ = s.conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+}
+
+// write writes a command as an array of bulk strings.
+// Errors are reported by the following flush.
+func (s *remoteSource) write(args ...string) {
+	fmt.Fprintf(s.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(s.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// read reads a single reply. Returns simple strings as strings,
+// bulk strings as byte slices (nil for null), integers as ints,
+// arrays as slices of any, and error replies as remoteError.
+func (s *remoteSource) read() (any, error) {
+	line, err := s.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errProtocol
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, remoteError(line[1:])
+	case ':':
+		return strconv.Atoi(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		nread, err := io.ReadFull(s.r, buf)
+		s.nread += int64(nread)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i], err = s.read()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, errProtocol
+	}
+}
+
+// readCommand reads a command sent by the leader
+// as an array of bulk strings.
+func (s *remoteSource) readCommand() ([][]byte, error) {
+	reply, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errProtocol
+	}
+	args := make([][]byte, len(items))
+	for i, item := range items {
+		args[i], ok = item.([]byte)
+		if !ok {
+			return nil, errProtocol
+		}
+	}
+	return args, nil
+}
+
+// readLine reads a single line without the line terminator.
+func (s *remoteSource) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	s.nread += int64(len(line))
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// changeError translates the leader's change log
+// error replies to the corresponding rkey errors.
+func changeError(err error) error {
+	var rerr remoteError
+	if !errors.As(err, &rerr) {
+		return err
+	}
+	for _, known := range []error{rkey.ErrChangeLogDisabled, rkey.ErrChangeLogTruncated} {
+		if strings.Contains(string(rerr), known.Error()) {
+			return known
+		}
+	}
+	return err
+}
+
+// toInt converts an integer or a bulk string reply to an int.
+func toInt(reply any) (int, error) {
+	switch val := reply.(type) {
+	case int:
+		return val, nil
+	case []byte:
+		n, err := strconv.Atoi(string(val))
+		if err != nil {
+			return 0, errProtocol
+		}
+		return n, nil
+	default:
+		return 0, errProtocol
+	}
+}
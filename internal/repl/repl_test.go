@@ -0,0 +1,434 @@
+package repl_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/command"
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/rdb"
+	"github.com/flarco/redka/internal/repl"
+	"github.com/flarco/redka/internal/server"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestReplica(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "leader.db")
+		leader, err := redka.Open(path, &redka.Options{ChangeLog: true})
+		testx.AssertNoErr(t, err)
+		defer leader.Close()
+
+		testReplica(t, leader, path)
+	})
+	t.Run("network", func(t *testing.T) {
+		leader := getDB(t, "leader", true)
+		addr := startServer(t, leader)
+		testReplica(t, leader, addr)
+	})
+}
+
+func TestReplicaDisabled(t *testing.T) {
+	leader := getDB(t, "leader", false)
+	defer leader.Close()
+	_ = leader.Str().Set("name", "alice")
+
+	replica := getDB(t, "replica", false)
+	defer replica.Close()
+	r := repl.NewReplica(replica, startServer(t, leader), &repl.Options{Interval: 10 * time.Millisecond})
+	r.Start()
+	defer r.Stop()
+
+	// The full sync succeeds, but following the changes does not,
+	// so the replica keeps reconnecting.
+	waitFor(t, func() bool {
+		name, _ := replica.Str().Get("name")
+		return name.String() == "alice"
+	})
+	time.Sleep(50 * time.Millisecond)
+	testx.AssertEqual(t, r.Status().State != repl.StateConnected, true)
+}
+
+func TestReplicaStream(t *testing.T) {
+	primary := startPrimary(t)
+	replica := getDB(t, "replica", false)
+	defer replica.Close()
+	_ = replica.Str().Set("stale", "value")
+
+	r := repl.NewReplica(replica, primary.addr, &repl.Options{
+		Interval: 10 * time.Millisecond,
+		Timeout:  100 * time.Millisecond,
+		Apply:    command.Apply,
+	})
+	r.Start()
+	defer r.Stop()
+
+	var offset int64
+	t.Run("full sync", func(t *testing.T) {
+		conn := primary.accept(t)
+		conn.expect(t, "ping")
+		conn.reply(t, "+PONG\r\n")
+		conn.expect(t, "replconf", "capa", "psync2")
+		conn.reply(t, "+OK\r\n")
+		conn.expect(t, "psync", "?", "-1")
+
+		var buf bytes.Buffer
+		wr := rdb.NewWriter(&buf)
+		_ = wr.Write(rdb.Entry{Key: "name", Type: core.TypeString, Value: []byte("alice")})
+		_ = wr.Write(rdb.Entry{Key: "list", Type: core.TypeList, Value: [][]byte{[]byte("one")}})
+		_ = wr.Write(rdb.Entry{DB: 2, Key: "city", Type: core.TypeString, Value: []byte("paris")})
+		_ = wr.Close()
+		conn.reply(t, fmt.Sprintf("+FULLRESYNC abc 100\r\n\n\n$%d\r\n%s", buf.Len(), buf.Bytes()))
+
+		waitFor(t, func() bool {
+			return r.Status().State == repl.StateConnected
+		})
+		testx.AssertEqual(t, r.Status().Pos, int64(100))
+		name, _ := replica.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+		exists, _ := replica.Key().Exists("stale")
+		testx.AssertEqual(t, exists, false)
+		city, _ := replica.Select(2).Str().Get("city")
+		testx.AssertEqual(t, city.String(), "paris")
+
+		// Commands.
+		offset = 100
+		offset += conn.send(t, "select", "0")
+		offset += conn.send(t, "set", "name", "bob")
+		offset += conn.send(t, "multi")
+		offset += conn.send(t, "rpush", "list", "two")
+		offset += conn.send(t, "pexpire", "list", "60000")
+		offset += conn.send(t, "exec")
+		offset += conn.send(t, "select", "1")
+		offset += conn.send(t, "set", "other", "value")
+		offset += conn.send(t, "select", "0")
+		offset += conn.send(t, "ping")
+		getack := conn.send(t, "replconf", "getack", "*")
+
+		// The acknowledged offset does not include the GETACK command.
+		conn.expectAck(t, offset)
+		offset += getack
+		waitFor(t, func() bool {
+			return r.Status().Pos == offset
+		})
+
+		name, _ = replica.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "bob")
+		list, _ := replica.List().Range("list", 0, -1)
+		testx.AssertEqual(t, list, []redka.Value{redka.Value("one"), redka.Value("two")})
+		key, _ := replica.Key().Get("list")
+		testx.AssertEqual(t, key.ETime != nil, true)
+		exists, _ = replica.Key().Exists("other")
+		testx.AssertEqual(t, exists, false)
+		other, _ := replica.Select(1).Str().Get("other")
+		testx.AssertEqual(t, other.String(), "value")
+		_ = conn.Close()
+	})
+	t.Run("partial sync", func(t *testing.T) {
+		conn := primary.accept(t)
+		conn.expect(t, "ping")
+		conn.reply(t, "+PONG\r\n")
+		conn.expect(t, "replconf", "capa", "psync2")
+		conn.reply(t, "+OK\r\n")
+		conn.expect(t, "psync", "abc", strconv.FormatInt(offset+1, 10))
+		conn.reply(t, "+CONTINUE\r\n")
+
+		offset += conn.send(t, "set", "name", "carol")
+		waitFor(t, func() bool {
+			return r.Status().Pos == offset
+		})
+		name, _ := replica.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "carol")
+		_ = conn.Close()
+	})
+}
+
+func TestManager(t *testing.T) {
+	leader := getDB(t, "leader", true)
+	addr := startServer(t, leader)
+	_ = leader.Str().Set("name", "alice")
+
+	db := getDB(t, "replica", false)
+	defer db.Close()
+	_ = db.Str().Set("local", "value")
+	m := repl.NewManager(db, &repl.Options{Interval: 10 * time.Millisecond})
+	defer m.Close()
+
+	t.Run("leader", func(t *testing.T) {
+		testx.AssertEqual(t, m.ReadOnly(), false)
+		role := m.Role()
+		testx.AssertEqual(t, role.Name, repl.RoleLeader)
+	})
+	t.Run("replica", func(t *testing.T) {
+		err := m.ReplicaOf(addr)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.ReadOnly(), true)
+		waitFor(t, func() bool {
+			return m.Role().State == repl.StateConnected
+		})
+
+		role := m.Role()
+		testx.AssertEqual(t, role.Name, repl.RoleReplica)
+		testx.AssertEqual(t, role.Addr, addr)
+		pos, _ := leader.Key().LastChange()
+		testx.AssertEqual(t, role.Pos, pos)
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+		exists, _ := db.Key().Exists("local")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("promote", func(t *testing.T) {
+		err := m.ReplicaOf("")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, m.ReadOnly(), false)
+		testx.AssertEqual(t, m.Role().Name, repl.RoleLeader)
+
+		// The copied keys are kept.
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+}
+
+// testReplica checks that the replica copies
+// the existing keys and follows the changes.
+func testReplica(t *testing.T, leader *redka.DB, addr string) {
+	_ = leader.Str().Set("name", "alice")
+	_, _ = leader.List().PushBack("list", "one")
+	_ = leader.Str().Set("temp", "value")
+	_ = leader.Select(1).Str().Set("city", "paris")
+
+	replica := getDB(t, "replica", false)
+	defer replica.Close()
+	_ = replica.Str().Set("stale", "value")
+	_ = replica.Select(3).Str().Set("stale", "value")
+
+	r := repl.NewReplica(replica, addr, &repl.Options{Interval: 10 * time.Millisecond})
+	r.Start()
+	defer r.Stop()
+
+	// Full sync.
+	waitFor(t, func() bool {
+		return r.Status().State == repl.StateConnected
+	})
+	name, _ := replica.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "alice")
+	exists, _ := replica.Key().Exists("stale")
+	testx.AssertEqual(t, exists, false)
+	exists, _ = replica.Select(3).Key().Exists("stale")
+	testx.AssertEqual(t, exists, false)
+	city, _ := replica.Select(1).Str().Get("city")
+	testx.AssertEqual(t, city.String(), "paris")
+
+	// Changes.
+	_ = leader.Str().Set("name", "bob")
+	_, _ = leader.List().PushBack("list", "two")
+	_, _ = leader.Hash().Set("person", "age", 25)
+	_, _ = leader.ZSet().Add("race", "alice", 11)
+	_ = leader.Key().Expire("person", time.Minute)
+	_, _ = leader.Key().Delete("temp")
+	_ = leader.Select(1).Str().Set("city", "berlin")
+	_ = leader.Select(2).Str().Set("name", "carol")
+	_, _ = leader.Key().Move("list", 2)
+
+	pos, _ := leader.Key().LastChange()
+	waitFor(t, func() bool {
+		return r.Status().Pos == pos
+	})
+	name, _ = replica.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+	exists, _ = replica.Key().Exists("list")
+	testx.AssertEqual(t, exists, false)
+	list, _ := replica.Select(2).List().Range("list", 0, -1)
+	testx.AssertEqual(t, list, []redka.Value{redka.Value("one"), redka.Value("two")})
+	city, _ = replica.Select(1).Str().Get("city")
+	testx.AssertEqual(t, city.String(), "berlin")
+	name, _ = replica.Select(2).Str().Get("name")
+	testx.AssertEqual(t, name.String(), "carol")
+	age, _ := replica.Hash().Get("person", "age")
+	testx.AssertEqual(t, age.String(), "25")
+	key, _ := replica.Key().Get("person")
+	testx.AssertEqual(t, key.ETime != nil, true)
+	score, _ := replica.ZSet().GetScore("race", "alice")
+	testx.AssertEqual(t, score, 11.0)
+	exists, _ = replica.Key().Exists("temp")
+	testx.AssertEqual(t, exists, false)
+}
+
+// startServer starts a server for the database on a free local port.
+// Stops the server (and closes the database) when the test completes.
+func startServer(tb testing.TB, db *redka.DB) string {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	srv := server.New("tcp", addr, db, nil)
+	srv.Start()
+	tb.Cleanup(func() { _ = srv.Stop() })
+	waitFor(tb, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	})
+	return addr
+}
+
+// stubPrimary is a minimal Redis-protocol primary without a change log.
+// The test drives the replication protocol on each accepted connection.
+type stubPrimary struct {
+	addr  string
+	conns chan net.Conn
+}
+
+// startPrimary starts a stub primary on a free local port.
+func startPrimary(tb testing.TB) *stubPrimary {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = ln.Close() })
+	p := &stubPrimary{addr: ln.Addr().String(), conns: make(chan net.Conn, 1)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			p.conns <- conn
+		}
+	}()
+	return p
+}
+
+// accept waits for the replica to connect and answers
+// the change log check like Redis does (unknown command).
+func (p *stubPrimary) accept(tb testing.TB) *stubConn {
+	tb.Helper()
+	select {
+	case conn := <-p.conns:
+		c := &stubConn{Conn: conn, r: bufio.NewReader(conn)}
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		tb.Cleanup(func() { _ = conn.Close() })
+		c.expect(tb, "repllog", "0", "count", "0")
+		c.reply(tb, "-ERR unknown command 'repllog'\r\n")
+		return c
+	case <-time.After(5 * time.Second):
+		tb.Fatal("timeout")
+		return nil
+	}
+}
+
+// stubConn is a replica connection to the stub primary.
+type stubConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// expect reads a command and checks that it matches the arguments.
+func (c *stubConn) expect(tb testing.TB, args ...string) {
+	tb.Helper()
+	got, err := c.read()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	testx.AssertEqual(tb, strings.Join(got, " "), strings.Join(args, " "))
+}
+
+// expectAck reads commands until the replica acknowledges the offset.
+func (c *stubConn) expectAck(tb testing.TB, offset int64) {
+	tb.Helper()
+	want := strconv.FormatInt(offset, 10)
+	for {
+		got, err := c.read()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if len(got) != 3 || got[0] != "replconf" || got[1] != "ack" {
+			tb.Fatalf("want replconf ack, got %v", got)
+		}
+		if got[2] == want {
+			return
+		}
+	}
+}
+
+// reply writes a raw reply.
+func (c *stubConn) reply(tb testing.TB, data string) {
+	tb.Helper()
+	if _, err := io.WriteString(c, data); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// send writes a command as an array of bulk strings
+// and returns its size in bytes.
+func (c *stubConn) send(tb testing.TB, args ...string) int64 {
+	tb.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	c.reply(tb, b.String())
+	return int64(b.Len())
+}
+
+// read reads a command sent by the replica.
+func (c *stubConn) read() ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(c.r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(c.r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = strings.ToLower(string(buf[:size]))
+	}
+	return args, nil
+}
+
+// waitFor waits until the condition is true or fails the test.
+func waitFor(tb testing.TB, cond func() bool) {
+	tb.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			tb.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func getDB(tb testing.TB, name string, changeLog bool) *redka.DB {
+	tb.Helper()
+	path := "file:/" + name + ".db?vfs=memdb"
+	db, err := redka.Open(path, &redka.Options{ChangeLog: changeLog})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,348 @@
+// Package repl implements replication: a read-only replica
+// that continuously copies changed keys from a leader database.
+//
+// The leader records the changed keys (logical database and name)
+// in its change log (see [redka.Options.ChangeLog]). The replica
+// copies all keys of all logical databases once (full sync), then
+// polls the change log and copies the keys changed since the last
+// poll, using the DUMP/RESTORE serialization format.
+// If the replica falls too far behind and the change log is pruned,
+// it starts over with a full sync.
+//
+// The leader is either a database file on the same host (or a shared
+// volume), or a redka server reachable over the network.
+//
+// The replica can also follow a Redis-protocol primary (such as Redis
+// itself) that has no change log: it loads the initial RDB snapshot
+// using PSYNC (or SYNC), then applies the stream of write commands
+// sent by the primary (see [Options.Apply]).
+package repl
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/rkey"
+)
+
+// Default replication settings.
+const (
+	DefaultBatchSize = 1000
+	DefaultInterval  = 100 * time.Millisecond
+	DefaultTimeout   = 5 * time.Second
+)
+
+// errStopped is returned when the replica is stopped
+// in the middle of an operation.
+var errStopped = errors.New("replica stopped")
+
+// Replica link states (same as in Redis).
+const (
+	StateConnect    = "connect"    // not connected yet
+	StateConnecting = "connecting" // connecting to the leader
+	StateSync       = "sync"       // copying all keys (full sync)
+	StateConnected  = "connected"  // following the change log
+)
+
+// Options configure the replica.
+type Options struct {
+	// DriverName is the SQL driver used to open a leader
+	// database file. If empty, uses the default driver.
+	DriverName string
+	// BatchSize is the number of keys copied in a single
+	// transaction. If zero, uses DefaultBatchSize.
+	BatchSize int
+	// Interval is the time between polls of the change log
+	// when there are no changes. If zero, uses DefaultInterval.
+	Interval time.Duration
+	// Timeout is the network timeout for remote leaders.
+	// If zero, uses DefaultTimeout.
+	Timeout time.Duration
+	// Password authenticates to remote leaders (AUTH).
+	// If empty, does not authenticate.
+	Password string
+	// Apply runs a write command received from a Redis-protocol
+	// primary within the transaction. Required to follow such
+	// primaries, optional otherwise.
+	Apply func(tx *redka.Tx, args [][]byte) error
+}
+
+// Status describes the state of the replica.
+type Status struct {
+	Addr   string    // leader address
+	State  string    // link state
+	Pos    int64     // leader change log position (replication offset for Redis primaries)
+	LastIO time.Time // last successful interaction with the leader
+}
+
+// Replica copies keys from the leader database to the local one.
+type Replica struct {
+	db   *redka.DB
+	addr string
+	opts Options
+
+	mu     sync.Mutex
+	status Status
+
+	// replID is the replication ID of a Redis primary,
+	// used to continue the command stream after reconnecting.
+	replID string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewReplica creates a replica of the leader at addr
+// (a database file path or a host:port address).
+// The replica does not copy anything until started.
+// The opts parameter is optional.
+func NewReplica(db *redka.DB, addr string, opts *Options) *Replica {
+	r := &Replica{
+		db:     db,
+		addr:   addr,
+		opts:   applyOptions(opts),
+		status: Status{Addr: addr, State: StateConnect},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	return r
+}
+
+// Start starts copying keys in the background.
+func (r *Replica) Start() {
+	go r.run()
+}
+
+// Stop stops copying keys and waits for the replica to stop.
+// Stop must only be called once after Start.
+func (r *Replica) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// Addr returns the leader address.
+func (r *Replica) Addr() string {
+	return r.addr
+}
+
+// Status returns the current replica status.
+func (r *Replica) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// run connects to the leader and follows its changes
+// until stopped, reconnecting on errors.
+func (r *Replica) run() {
+	defer close(r.done)
+	synced := false
+	for {
+		r.setState(StateConnecting)
+		src, err := openSource(r.addr, &r.opts)
+		if err == nil {
+			if rs, ok := src.(*remoteSource); ok && rs.primary {
+				err = r.stream(rs)
+			} else {
+				err = r.follow(src, &synced)
+			}
+			_ = src.Close()
+		}
+		if err == nil || errors.Is(err, errStopped) {
+			return
+		}
+		if errors.Is(err, rkey.ErrChangeLogTruncated) {
+			slog.Warn("replica: change log truncated, full sync", "leader", r.addr)
+			synced = false
+			continue
+		}
+		slog.Error("replica: follow leader", "leader", r.addr, "error", err)
+		if !r.sleep(r.opts.Timeout) {
+			return
+		}
+	}
+}
+
+// follow copies all keys from the source unless already synced,
+// then copies changed keys until stopped or an error occurs.
+// Returns nil if stopped.
+func (r *Replica) follow(src source, synced *bool) error {
+	if !*synced {
+		if err := r.fullSync(src); err != nil {
+			return err
+		}
+		*synced = true
+		slog.Info("replica: full sync", "leader", r.addr, "pos", r.Status().Pos)
+	}
+
+	for {
+		select {
+		case <-r.stop:
+			return nil
+		default:
+		}
+		pos := r.Status().Pos
+		res, err := src.Changes(pos, r.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		r.setState(StateConnected)
+		if err := r.copyKeys(src, res.Keys); err != nil {
+			return err
+		}
+		r.setPos(res.Pos)
+		if res.Pos == pos && !r.sleep(r.opts.Interval) {
+			return nil
+		}
+	}
+}
+
+// fullSync deletes all local keys (in all logical databases)
+// and copies all keys from the source, each into the logical
+// database it belongs to. Sets the position to the latest change
+// before the copy started, so that the changes made during
+// the copy are copied again later.
+func (r *Replica) fullSync(src source) error {
+	r.setState(StateSync)
+	pos, err := src.LastChange()
+	if err != nil {
+		return err
+	}
+	if err := r.db.Key().DeleteAllDatabases(); err != nil {
+		return err
+	}
+	dbs, err := src.DBs()
+	if err != nil {
+		return err
+	}
+
+	for _, db := range dbs {
+		cursor := 0
+		for {
+			select {
+			case <-r.stop:
+				return errStopped
+			default:
+			}
+			next, names, err := src.Scan(db, cursor, r.opts.BatchSize)
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				break
+			}
+			keys := make([]rkey.ChangedKey, len(names))
+			for i, name := range names {
+				keys[i] = rkey.ChangedKey{DB: db, Key: name}
+			}
+			if err := r.copyKeys(src, keys); err != nil {
+				return err
+			}
+			cursor = next
+		}
+	}
+	r.setPos(pos)
+	return nil
+}
+
+// copyKeys copies the keys from the source in a single transaction,
+// deleting the ones that do not exist in the source.
+func (r *Replica) copyKeys(src source, keys []rkey.ChangedKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Dump the keys of each logical database together.
+	dumps := make([][]byte, len(keys))
+	byDB := map[int][]int{} // key indexes by database
+	for i, key := range keys {
+		byDB[key.DB] = append(byDB[key.DB], i)
+	}
+	for db, idx := range byDB {
+		names := make([]string, len(idx))
+		for j, i := range idx {
+			names[j] = keys[i].Key
+		}
+		data, err := src.Dump(db, names)
+		if err != nil {
+			return err
+		}
+		for j, i := range idx {
+			dumps[i] = data[j]
+		}
+	}
+
+	return r.db.Update(func(tx *redka.Tx) error {
+		for i, key := range keys {
+			ktx := tx.Select(key.DB).Key()
+			if dumps[i] == nil {
+				if _, err := ktx.Delete(key.Key); err != nil {
+					return err
+				}
+				continue
+			}
+			err := ktx.RestoreWith(key.Key, dumps[i]).Replace().Run()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sleep waits for the duration or until the replica is stopped.
+// Returns false if stopped.
+func (r *Replica) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-r.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// setState sets the link state.
+func (r *Replica) setState(state string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.State = state
+}
+
+// setPos sets the change log position
+// and records the interaction with the leader.
+func (r *Replica) setPos(pos int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.Pos = pos
+	r.status.LastIO = time.Now()
+}
+
+// applyOptions applies the default options if necessary.
+func applyOptions(custom *Options) Options {
+	opts := Options{
+		BatchSize: DefaultBatchSize,
+		Interval:  DefaultInterval,
+		Timeout:   DefaultTimeout,
+	}
+	if custom == nil {
+		return opts
+	}
+	opts.DriverName = custom.DriverName
+	opts.Password = custom.Password
+	opts.Apply = custom.Apply
+	if custom.BatchSize > 0 {
+		opts.BatchSize = custom.BatchSize
+	}
+	if custom.Interval > 0 {
+		opts.Interval = custom.Interval
+	}
+	if custom.Timeout > 0 {
+		opts.Timeout = custom.Timeout
+	}
+	return opts
+}
@@ -0,0 +1,125 @@
+package repl
+
+import (
+	"errors"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/core"
+	"github.com/flarco/redka/internal/rkey"
+)
+
+// source is a leader database the replica copies keys from.
+type source interface {
+	// Changes returns the keys changed after the given
+	// change log position, and the new position.
+	Changes(after int64, count int) (rkey.ChangeResult, error)
+	// LastChange returns the latest change log position.
+	LastChange() (int64, error)
+	// DBs returns the indexes of the logical databases
+	// that have keys, in ascending order.
+	DBs() ([]int, error)
+	// Scan returns a page of key names in the logical database
+	// starting after the cursor, and the next cursor
+	// (0 if there are no more keys).
+	Scan(db int, cursor int, count int) (int, []string, error)
+	// Dump returns the serialized values of the keys in
+	// the logical database (nil for keys that do not exist).
+	Dump(db int, keys []string) ([][]byte, error)
+	// Close closes the connection to the leader.
+	Close() error
+}
+
+// openSource connects to the leader at addr, which is either
+// a path to the database file or a host:port network address.
+func openSource(addr string, opts *Options) (source, error) {
+	if isNetAddr(addr) {
+		return dialRemote(addr, opts.Password, opts.Timeout)
+	}
+	if _, err := os.Stat(addr); err != nil {
+		return nil, err
+	}
+	db, err := redka.OpenRead(addr, &redka.Options{DriverName: opts.DriverName})
+	if err != nil {
+		return nil, err
+	}
+	return &localSource{db: db}, nil
+}
+
+// isNetAddr reports whether addr is a host:port network address
+// (rather than a path to a database file).
+func isNetAddr(addr string) bool {
+	if _, err := os.Stat(addr); err == nil {
+		return false
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	_, err = strconv.Atoi(port)
+	return err == nil
+}
+
+// localSource reads the leader database file directly.
+type localSource struct {
+	db *redka.DB
+}
+
+func (s *localSource) Changes(after int64, count int) (rkey.ChangeResult, error) {
+	return s.db.Key().Changes(after, count)
+}
+
+func (s *localSource) LastChange() (int64, error) {
+	return s.db.Key().LastChange()
+}
+
+func (s *localSource) DBs() ([]int, error) {
+	counts, err := s.db.Key().DBCounts()
+	if err != nil {
+		return nil, err
+	}
+	dbs := make([]int, 0, len(counts))
+	for db := range counts {
+		dbs = append(dbs, db)
+	}
+	slices.Sort(dbs)
+	return dbs, nil
+}
+
+func (s *localSource) Scan(db int, cursor int, count int) (int, []string, error) {
+	res, err := s.db.Select(db).Key().Scan(cursor, "*", core.TypeAny, count)
+	if err != nil {
+		return 0, nil, err
+	}
+	keys := make([]string, len(res.Keys))
+	for i, k := range res.Keys {
+		keys[i] = k.Key
+	}
+	return res.Cursor, keys, nil
+}
+
+func (s *localSource) Dump(db int, keys []string) ([][]byte, error) {
+	dumps := make([][]byte, len(keys))
+	err := s.db.View(func(tx *redka.Tx) error {
+		ktx := tx.Select(db).Key()
+		for i, key := range keys {
+			data, err := ktx.Dump(key)
+			if errors.Is(err, core.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			dumps[i] = data
+		}
+		return nil
+	})
+	return dumps, err
+}
+
+func (s *localSource) Close() error {
+	return s.db.Close()
+}
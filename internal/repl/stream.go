@@ -0,0 +1,302 @@
+package repl
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/rdb"
+)
+
+// Command stream settings for Redis-protocol primaries.
+const (
+	// streamTimeout is the maximum time without any data from
+	// the primary (it pings the replicas every 10 seconds by default).
+	streamTimeout = 60 * time.Second
+	// ackInterval is the time between replication offset
+	// acknowledgements sent to the primary.
+	ackInterval = time.Second
+)
+
+// errNoApply is returned when following
+// a Redis primary without the Apply option.
+var errNoApply = errors.New("Apply option is required to follow a Redis primary")
+
+// stream follows a Redis-protocol primary: loads the RDB snapshot
+// (unless the primary agrees to continue from the last offset),
+// then applies the write commands sent by the primary until stopped
+// or an error occurs. Returns nil if stopped.
+func (r *Replica) stream(s *remoteSource) error {
+	if r.opts.Apply == nil {
+		return errNoApply
+	}
+
+	// Reads from the primary block until it sends something,
+	// so close the connection to interrupt them when stopped.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-r.stop:
+			_ = s.conn.Close()
+		case <-done:
+		}
+	}()
+
+	err := r.streamSync(s)
+	if err == nil {
+		err = r.applyStream(s)
+	}
+	if r.stopped() {
+		return nil
+	}
+	return err
+}
+
+// streamSync performs the replication handshake with PSYNC
+// (or SYNC for primaries that do not support it),
+// and loads the RDB snapshot if the primary sends one.
+func (r *Replica) streamSync(s *remoteSource) error {
+	if _, err := s.do("ping"); err != nil {
+		return err
+	}
+	// Older primaries do not know the capability, which is fine.
+	_, _ = s.do("replconf", "capa", "psync2")
+
+	replID, offset := "?", "-1"
+	if r.replID != "" {
+		replID = r.replID
+		offset = strconv.FormatInt(r.Status().Pos+1, 10)
+	}
+	reply, err := s.do("psync", replID, offset)
+	var rerr remoteError
+	if errors.As(err, &rerr) {
+		// The primary does not support PSYNC, fall back to SYNC.
+		s.setDeadline()
+		s.write("sync")
+		if err := s.w.Flush(); err != nil {
+			return err
+		}
+		r.replID = ""
+		if err := r.loadRDB(s); err != nil {
+			return err
+		}
+		r.setPos(0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	line, _ := reply.(string)
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) == 3 && fields[0] == "FULLRESYNC":
+		pos, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return errProtocol
+		}
+		// Forget the previous replication ID until the snapshot
+		// is loaded, so that a failed load is not continued later.
+		r.replID = ""
+		if err := r.loadRDB(s); err != nil {
+			return err
+		}
+		r.replID = fields[1]
+		r.setPos(pos)
+		return nil
+	case len(fields) >= 1 && fields[0] == "CONTINUE":
+		// The replication ID changes when the primary
+		// was itself a replica and got promoted.
+		if len(fields) == 2 {
+			r.replID = fields[1]
+		}
+		slog.Info("replica: partial sync", "leader", r.addr, "pos", r.Status().Pos)
+		return nil
+	default:
+		return errProtocol
+	}
+}
+
+// loadRDB deletes all local keys and loads the RDB snapshot
+// sent by the primary, each key into the logical database
+// it was saved from.
+func (r *Replica) loadRDB(s *remoteSource) error {
+	r.setState(StateSync)
+	rd := timeoutReader{s: s, timeout: streamTimeout}
+
+	// The primary sends newlines to keep the connection alive
+	// while it prepares the snapshot, then the snapshot size.
+	var size int64
+	for {
+		_ = s.conn.SetReadDeadline(time.Now().Add(streamTimeout))
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			continue
+		}
+		if line[0] == '-' {
+			return remoteError(line[1:])
+		}
+		if line[0] != '$' {
+			return errProtocol
+		}
+		size, err = strconv.ParseInt(line[1:], 10, 64)
+		if err != nil || size < 0 {
+			return errProtocol
+		}
+		break
+	}
+
+	if err := r.db.Key().DeleteAllDatabases(); err != nil {
+		return err
+	}
+	body := io.LimitReader(rd, size)
+	count, err := rdb.Load(r.db, body, &rdb.LoadOptions{DB: -1, BatchSize: r.opts.BatchSize})
+	if err != nil {
+		return err
+	}
+	// Skip anything after the end of file marker (such as the checksum).
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	slog.Info("replica: full sync", "leader", r.addr, "keys", count)
+	return nil
+}
+
+// applyStream applies the write commands sent by the primary.
+// Acknowledges the applied offset every second and when
+// requested by the primary (REPLCONF GETACK).
+func (r *Replica) applyStream(s *remoteSource) error {
+	r.setState(StateConnected)
+
+	var mu sync.Mutex // guards writes to the primary
+	ack := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+		s.write("replconf", "ack", strconv.FormatInt(r.Status().Pos, 10))
+		return s.w.Flush()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(ackInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := ack(); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	offset := r.Status().Pos
+	dbNum, inMulti := 0, false
+	var batch []streamCmd
+	for {
+		_ = s.conn.SetReadDeadline(time.Now().Add(streamTimeout))
+		nread := s.nread
+		args, err := s.readCommand()
+		if err != nil {
+			return err
+		}
+		offset += s.nread - nread
+
+		getack := false
+		switch strings.ToLower(string(args[0])) {
+		case "ping":
+		case "select":
+			if len(args) == 2 {
+				dbNum, _ = strconv.Atoi(string(args[1]))
+			}
+		case "replconf":
+			getack = len(args) > 1 && strings.EqualFold(string(args[1]), "getack")
+		case "multi":
+			inMulti = true
+		case "exec":
+			inMulti = false
+		default:
+			batch = append(batch, streamCmd{db: dbNum, args: args})
+		}
+
+		// Apply the buffered commands in a single transaction,
+		// keeping the MULTI/EXEC blocks together.
+		if inMulti || (s.r.Buffered() > 0 && !getack && len(batch) < r.opts.BatchSize) {
+			continue
+		}
+		if err := r.applyCommands(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		if getack {
+			// Like Redis, acknowledge the offset
+			// before the GETACK command itself.
+			r.setPos(offset - (s.nread - nread))
+			if err := ack(); err != nil {
+				return err
+			}
+		}
+		r.setPos(offset)
+	}
+}
+
+// streamCmd is a write command sent by the primary
+// along with the logical database it was selected for.
+type streamCmd struct {
+	db   int
+	args [][]byte
+}
+
+// applyCommands applies the commands in a single transaction,
+// each in its logical database. Logs the commands that fail
+// instead of stopping replication, since the primary
+// has already accepted them.
+func (r *Replica) applyCommands(cmds []streamCmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	return r.db.Update(func(tx *redka.Tx) error {
+		for _, cmd := range cmds {
+			if err := r.opts.Apply(tx.Select(cmd.db), cmd.args); err != nil {
+				slog.Warn("replica: apply command",
+					"leader", r.addr, "command", string(cmd.args[0]), "error", err)
+			}
+		}
+		return nil
+	})
+}
+
+// stopped reports whether the replica has been stopped.
+func (r *Replica) stopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// timeoutReader reads from the remote source,
+// extending the read deadline before each read.
+type timeoutReader struct {
+	s       *remoteSource
+	timeout time.Duration
+}
+
+func (r timeoutReader) Read(p []byte) (int, error) {
+	_ = r.s.conn.SetReadDeadline(time.Now().Add(r.timeout))
+	return r.s.r.Read(p)
+}
@@ -55,17 +55,46 @@ func (d *DB) Delete(key string, fields ...string) (int, error) {
 	return n, err
 }
 
+// DeleteExpired deletes expired fields from all hashes
+// and updates the length of the affected keys.
+// Deletes hashes that have no fields left.
+// Returns the number of fields deleted.
+func (d *DB) DeleteExpired() (int, error) {
+	var n int
+	err := d.Update(func(tx *Tx) error {
+		var err error
+		n, err = tx.DeleteExpired()
+		return err
+	})
+	return n, err
+}
+
 // Exists checks if a field exists in a hash.
 // If the key does not exist or is not a hash, returns false.
 func (d *DB) Exists(key, field string) (bool, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Exists(key, field)
 }
 
+// ExpireWith sets the expiration time of hash fields.
+// Use the returned command to specify the time and conditions.
+func (d *DB) ExpireWith(key string, fields ...string) ExpireCmd {
+	return ExpireCmd{db: d, key: key, fields: fields}
+}
+
+// Expiry returns the expiration state of hash fields,
+// in the order of fields.
+// If the key does not exist or is not a hash,
+// reports every field as non-existing.
+func (d *DB) Expiry(key string, fields ...string) ([]Expiry, error) {
+	tx := NewTx(d.Reader())
+	return tx.Expiry(key, fields...)
+}
+
 // Fields returns all fields in a hash.
 // If the key does not exist or is not a hash, returns an empty slice.
 func (d *DB) Fields(key string) ([]string, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Fields(key)
 }
 
@@ -73,15 +102,28 @@ func (d *DB) Fields(key string) ([]string, error) {
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a hash, returns ErrNotFound.
 func (d *DB) Get(key, field string) (core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Get(key, field)
 }
 
+// GetDelete returns the values of given fields and deletes the fields.
+// Ignores fields that do not exist and do not return them in the map.
+// If the key does not exist or is not a hash, returns an empty map.
+func (d *DB) GetDelete(key string, fields ...string) (map[string]core.Value, error) {
+	var items map[string]core.Value
+	err := d.Update(func(tx *Tx) error {
+		var err error
+		items, err = tx.GetDelete(key, fields...)
+		return err
+	})
+	return items, err
+}
+
 // GetMany returns a map of values for given fields.
 // Ignores fields that do not exist and do not return them in the map.
 // If the key does not exist or is not a hash, returns an empty map.
 func (d *DB) GetMany(key string, fields ...string) (map[string]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.GetMany(key, fields...)
 }
 
@@ -120,17 +162,51 @@ func (d *DB) IncrFloat(key, field string, delta float64) (float64, error) {
 // Items returns a map of all fields and values in a hash.
 // If the key does not exist or is not a hash, returns an empty map.
 func (d *DB) Items(key string) (map[string]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Items(key)
 }
 
 // Len returns the number of fields in a hash.
 // If the key does not exist or is not a hash, returns 0.
 func (d *DB) Len(key string) (int, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Len(key)
 }
 
+// Persist removes the expiration time of hash fields.
+// Returns a result for each field, in the order of fields:
+// FieldUpdated if the expiration time was removed,
+// FieldNoTTL if the field had no expiration time,
+// or FieldNotFound if the field does not exist.
+// If the key does not exist or is not a hash,
+// returns FieldNotFound for every field.
+func (d *DB) Persist(key string, fields ...string) ([]int, error) {
+	var res []int
+	err := d.Update(func(tx *Tx) error {
+		var err error
+		res, err = tx.Persist(key, fields...)
+		return err
+	})
+	return res, err
+}
+
+// Random returns a random field-value pair from a hash.
+// If the key does not exist or is not a hash, returns ErrNotFound.
+func (d *DB) Random(key string) (HashItem, error) {
+	tx := NewTx(d.Reader())
+	return tx.Random(key)
+}
+
+// RandomMany returns random field-value pairs from a hash.
+// If count is positive, returns up to count distinct items.
+// If count is negative, returns exactly -count items,
+// possibly repeating the same item multiple times.
+// If the key does not exist or is not a hash, returns an empty slice.
+func (d *DB) RandomMany(key string, count int) ([]HashItem, error) {
+	tx := NewTx(d.Reader())
+	return tx.RandomMany(key, count)
+}
+
 // Scan iterates over hash items with fields matching pattern.
 // Returns a slice of field-value pairs (see [HashItem]) of size count
 // based on the current state of the cursor. Returns an empty HashItem
@@ -138,7 +214,7 @@ func (d *DB) Len(key string) (int, error) {
 // If the key does not exist or is not a hash, returns a nil slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scan(key, cursor, pattern, count)
 }
 
@@ -148,7 +224,7 @@ func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult
 // or an error occurs. If the key does not exist or is not a hash, stops immediately.
 // Supports glob-style patterns. Set pageSize = 0 for default page size.
 func (d *DB) Scanner(key, pattern string, pageSize int) *Scanner {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scanner(key, pattern, pageSize)
 }
 
@@ -194,9 +270,14 @@ func (d *DB) SetNotExists(key, field string, value any) (bool, error) {
 	return created, err
 }
 
+// SetWith sets the values of hash fields with additional options.
+func (d *DB) SetWith(key string, items map[string]any) SetCmd {
+	return SetCmd{db: d, key: key, items: items}
+}
+
 // Values returns all values in a hash.
 // If the key does not exist or is not a hash, returns an empty slice.
 func (d *DB) Values(key string) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Values(key)
 }
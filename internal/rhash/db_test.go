@@ -4,6 +4,7 @@ import (
 	"slices"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/flarco/redka"
 	"github.com/flarco/redka/internal/core"
@@ -108,6 +109,53 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestDeleteExpired(t *testing.T) {
+	t.Run("some", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+		_, _ = hash.ExpireWith("person", "age").TTL(time.Millisecond).Run()
+
+		time.Sleep(2 * time.Millisecond)
+		count, err := hash.DeleteExpired()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+		items, _ := hash.Items("person")
+		testx.AssertEqual(t, items, map[string]core.Value{"name": core.Value("alice")})
+	})
+	t.Run("all", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Millisecond).Run()
+
+		time.Sleep(2 * time.Millisecond)
+		count, err := hash.DeleteExpired()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 1)
+
+		exists, _ := db.Key().Exists("person")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("none", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		count, err := hash.DeleteExpired()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, count, 0)
+	})
+}
+
 func TestExists(t *testing.T) {
 	db, hash := getDB(t)
 	defer db.Close()
@@ -135,6 +183,143 @@ func TestExists(t *testing.T) {
 	}
 }
 
+func TestExpire(t *testing.T) {
+	t.Run("ttl", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+
+		now := time.Now()
+		res, err := hash.ExpireWith("person", "name", "city").TTL(time.Minute).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldUpdated, rhash.FieldNotFound})
+
+		exp, _ := hash.Expiry("person", "name", "age")
+		testx.AssertEqual(t, exp[0].Exists, true)
+		got := *exp[0].ETime
+		if got < now.Add(time.Minute).UnixMilli() || got > time.Now().Add(time.Minute).UnixMilli() {
+			t.Errorf("unexpected etime %v", got)
+		}
+		testx.AssertEqual(t, exp[1], rhash.Expiry{Exists: true})
+
+		key, _ := db.Key().Get("person")
+		testx.AssertEqual(t, key.Version, 3)
+	})
+	t.Run("expired", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Millisecond).Run()
+
+		time.Sleep(2 * time.Millisecond)
+		exist, _ := hash.Exists("person", "name")
+		testx.AssertEqual(t, exist, false)
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+		fields, _ := hash.Fields("person")
+		testx.AssertEqual(t, fields, []string{"age"})
+		items, _ := hash.Items("person")
+		testx.AssertEqual(t, items, map[string]core.Value{"age": core.Value("25")})
+
+		created, _ := hash.Set("person", "name", "bob")
+		testx.AssertEqual(t, created, true)
+		exp, _ := hash.Expiry("person", "name")
+		testx.AssertEqual(t, exp[0], rhash.Expiry{Exists: true})
+		hlen, _ = hash.Len("person")
+		testx.AssertEqual(t, hlen, 2)
+	})
+	t.Run("all expired", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Millisecond).Run()
+
+		// The hash is treated as absent once the last field expires.
+		time.Sleep(2 * time.Millisecond)
+		exists, err := db.Key().Exists("person")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, exists, false)
+		_, err = db.Key().Get("person")
+		testx.AssertErr(t, err, core.ErrNotFound)
+		keys, _ := db.Key().Keys("*")
+		testx.AssertEqual(t, len(keys), 0)
+		n, _ := db.Key().Len()
+		testx.AssertEqual(t, n, 0)
+
+		// A write deletes the hash and starts it anew.
+		_, _ = hash.Set("person", "age", 25)
+		key, _ := db.Key().Get("person")
+		testx.AssertEqual(t, key.Version, 1)
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+	})
+	t.Run("past", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+
+		res, err := hash.ExpireWith("person", "name").At(time.Now().Add(-time.Second)).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldDeleted})
+
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+
+		res, err = hash.ExpireWith("person", "age").TTL(0).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldDeleted})
+
+		exists, _ := db.Key().Exists("person")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("conditions", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		res, _ := hash.ExpireWith("person", "name", "age").TTL(time.Hour).IfNoTTL().Run()
+		testx.AssertEqual(t, res, []int{rhash.FieldSkipped, rhash.FieldUpdated})
+		_, _ = hash.Persist("person", "age")
+
+		res, _ = hash.ExpireWith("person", "name", "age").TTL(time.Hour).IfHasTTL().Run()
+		testx.AssertEqual(t, res, []int{rhash.FieldUpdated, rhash.FieldSkipped})
+
+		res, _ = hash.ExpireWith("person", "name", "age").TTL(time.Minute).IfGreater().Run()
+		testx.AssertEqual(t, res, []int{rhash.FieldSkipped, rhash.FieldSkipped})
+
+		res, _ = hash.ExpireWith("person", "name", "age").TTL(time.Minute).IfLess().Run()
+		testx.AssertEqual(t, res, []int{rhash.FieldUpdated, rhash.FieldUpdated})
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		res, err := hash.ExpireWith("person", "name").TTL(time.Minute).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldNotFound})
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("person", "alice")
+
+		res, err := hash.ExpireWith("person", "name").TTL(time.Minute).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldNotFound})
+	})
+}
+
 func TestFields(t *testing.T) {
 	db, hash := getDB(t)
 	defer db.Close()
@@ -199,6 +384,33 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestGetDelete(t *testing.T) {
+	t.Run("some", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+
+		items, err := hash.GetDelete("person", "name", "city")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, items, map[string]core.Value{"name": core.Value("alice")})
+
+		exist, _ := hash.Exists("person", "name")
+		testx.AssertEqual(t, exist, false)
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		items, err := hash.GetDelete("person", "name")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, items, map[string]core.Value{})
+	})
+}
+
 func TestGetMany(t *testing.T) {
 	db, hash := getDB(t)
 	defer db.Close()
@@ -450,6 +662,98 @@ func TestLen(t *testing.T) {
 	}
 }
 
+func TestPersist(t *testing.T) {
+	t.Run("persist", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+		_, _ = hash.ExpireWith("person", "name").TTL(time.Minute).Run()
+
+		res, err := hash.Persist("person", "name", "age", "city")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldUpdated, rhash.FieldNoTTL, rhash.FieldNotFound})
+
+		exp, _ := hash.Expiry("person", "name")
+		testx.AssertEqual(t, exp[0], rhash.Expiry{Exists: true})
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		res, err := hash.Persist("person", "name")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res, []int{rhash.FieldNotFound})
+	})
+}
+
+func TestRandom(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+
+		item, err := hash.Random("person")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, item.Field == "name" || item.Field == "age", true)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, err := hash.Random("person")
+		testx.AssertErr(t, err, core.ErrNotFound)
+	})
+}
+
+func TestRandomMany(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+		_, _ = hash.Set("person", "age", 25)
+		_, _ = hash.Set("person", "city", "paris")
+
+		items, err := hash.RandomMany("person", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(items), 2)
+		testx.AssertEqual(t, items[0].Field != items[1].Field, true)
+
+		items, err = hash.RandomMany("person", 5)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(items), 3)
+	})
+	t.Run("negative", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+
+		items, err := hash.RandomMany("person", -3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(items), 3)
+		for _, it := range items {
+			testx.AssertEqual(t, it.Field, "name")
+			testx.AssertEqual(t, it.Value, core.Value("alice"))
+		}
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		items, err := hash.RandomMany("person", 3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(items), 0)
+		items, err = hash.RandomMany("person", -3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(items), 0)
+	})
+}
+
 func TestScan(t *testing.T) {
 	db, hash := getDB(t)
 	defer db.Close()
@@ -835,6 +1139,80 @@ func TestSetNotExists(t *testing.T) {
 	})
 }
 
+func TestSetWith(t *testing.T) {
+	t.Run("ttl", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		ok, err := hash.SetWith("person", map[string]any{"name": "alice"}).
+			TTL(time.Minute).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+
+		exp, _ := hash.Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime != nil, true)
+
+		_, _ = hash.Set("person", "name", "bob")
+		exp, _ = hash.Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime, (*int64)(nil))
+	})
+	t.Run("keep ttl", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.SetWith("person", map[string]any{"name": "alice"}).
+			TTL(time.Minute).Run()
+		_, err := hash.SetWith("person", map[string]any{"name": "bob"}).
+			KeepTTL().Run()
+		testx.AssertNoErr(t, err)
+
+		val, _ := hash.Get("person", "name")
+		testx.AssertEqual(t, val, core.Value("bob"))
+		exp, _ := hash.Expiry("person", "name")
+		testx.AssertEqual(t, exp[0].ETime != nil, true)
+	})
+	t.Run("if none exist", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+
+		ok, err := hash.SetWith("person", map[string]any{"name": "bob", "age": 25}).
+			IfNoneExist().Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, false)
+		hlen, _ := hash.Len("person")
+		testx.AssertEqual(t, hlen, 1)
+	})
+	t.Run("if all exist", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_, _ = hash.Set("person", "name", "alice")
+
+		ok, err := hash.SetWith("person", map[string]any{"name": "bob", "age": 25}).
+			IfAllExist().Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, false)
+
+		ok, err = hash.SetWith("person", map[string]any{"name": "bob"}).
+			IfAllExist().Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+		val, _ := hash.Get("person", "name")
+		testx.AssertEqual(t, val, core.Value("bob"))
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, hash := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("person", "alice")
+
+		_, err := hash.SetWith("person", map[string]any{"name": "alice"}).Run()
+		testx.AssertErr(t, err, core.ErrKeyType)
+	})
+}
+
 func TestValues(t *testing.T) {
 	db, hash := getDB(t)
 	defer db.Close()
@@ -0,0 +1,411 @@
+package rhash
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/flarco/redka/internal/sqlx"
+)
+
+const (
+	sqlExpiry = `
+	select kid, field, rhash.etime
+	from rhash join rkey on kid = rkey.id and type = 4
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?) and field in (:fields)`
+
+	sqlExpire = `
+	update rhash set etime = ?
+	where kid = ? and field in (:fields)`
+
+	sqlExpireDelete = `
+	delete from rhash
+	where kid = ? and field in (:fields)`
+
+	sqlExpireKey = `
+	update rkey set
+		version = version + 1,
+		mtime = ?,
+		len = len - ?
+	where id = ?`
+
+	sqlDeleteExpired1 = `
+	select kid, count(*) from rhash
+	where etime is not null and etime <= ?
+	group by kid`
+
+	sqlDeleteExpired2 = `
+	delete from rhash
+	where etime is not null and etime <= ?`
+
+	sqlDeleteExpired3 = `
+	update rkey set
+		version = version + 1,
+		mtime = ?,
+		len = len - ?
+	where id = ?`
+
+	sqlDeleteExpired4 = `
+	delete from rkey
+	where id = ? and len <= 0`
+
+	sqlPurge1 = `
+	select id from rkey
+	where db = :db and key = ? and type = 4 and (etime is null or etime > ?)`
+
+	sqlPurge2 = `
+	delete from rhash
+	where kid = ? and etime is not null and etime <= ?`
+)
+
+// Field expiration results, as returned by [ExpireCmd.Run] and [Tx.Persist].
+const (
+	FieldNotFound = -2 // the field does not exist
+	FieldNoTTL    = -1 // the field exists but has no expiration time
+	FieldSkipped  = 0  // the condition (NX, XX, GT, LT) was not met
+	FieldUpdated  = 1  // the expiration time was set or removed
+	FieldDeleted  = 2  // the field was deleted (expiration time in the past)
+)
+
+// Expiry is the expiration state of a hash field.
+type Expiry struct {
+	Exists bool   // whether the field exists
+	ETime  *int64 // expiration time in unix milliseconds (nil if none)
+}
+
+// ExpireCmd sets the expiration time of hash fields.
+type ExpireCmd struct {
+	db        *DB
+	tx        *Tx
+	key       string
+	fields    []string
+	ttl       time.Duration
+	at        time.Time
+	ifNoTTL   bool
+	ifHasTTL  bool
+	ifGreater bool
+	ifLess    bool
+}
+
+// TTL sets the time-to-live for the fields.
+func (c ExpireCmd) TTL(ttl time.Duration) ExpireCmd {
+	c.ttl = ttl
+	c.at = time.Time{}
+	return c
+}
+
+// At sets the expiration time for the fields.
+func (c ExpireCmd) At(at time.Time) ExpireCmd {
+	c.ttl = 0
+	c.at = at
+	return c
+}
+
+// IfNoTTL instructs to set the expiration time
+// only for fields that have no expiration time (NX).
+func (c ExpireCmd) IfNoTTL() ExpireCmd {
+	c.ifNoTTL = true
+	return c
+}
+
+// IfHasTTL instructs to set the expiration time
+// only for fields that already have one (XX).
+func (c ExpireCmd) IfHasTTL() ExpireCmd {
+	c.ifHasTTL = true
+	return c
+}
+
+// IfGreater instructs to set the expiration time only if it is
+// greater than the current one (GT). A field without an expiration
+// time is considered to never expire, so it is not updated.
+func (c ExpireCmd) IfGreater() ExpireCmd {
+	c.ifGreater = true
+	return c
+}
+
+// IfLess instructs to set the expiration time only if it is
+// less than the current one (LT). A field without an expiration
+// time is considered to never expire, so it is always updated.
+func (c ExpireCmd) IfLess() ExpireCmd {
+	c.ifLess = true
+	return c
+}
+
+// Run sets the expiration time of the fields according
+// to the configured options. Returns a result for each field,
+// in the order of fields (see [FieldNotFound] and friends).
+// If the expiration time is in the past, deletes the fields.
+// If the key does not exist or is not a hash,
+// returns FieldNotFound for every field.
+func (c ExpireCmd) Run() ([]int, error) {
+	if c.db != nil {
+		var res []int
+		err := c.db.Update(func(tx *Tx) error {
+			var err error
+			res, err = c.run(tx)
+			return err
+		})
+		return res, err
+	}
+	if c.tx != nil {
+		return c.run(c.tx)
+	}
+	return nil, nil
+}
+
+func (c ExpireCmd) run(tx *Tx) ([]int, error) {
+	now := time.Now()
+	if c.ttl != 0 || c.at.IsZero() {
+		c.at = now.Add(c.ttl)
+	}
+	etime := c.at.UnixMilli()
+
+	if err := tx.purge(c.key); err != nil {
+		return nil, err
+	}
+	kid, expiry, err := tx.expiry(c.key, c.fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decide what to do with each field.
+	res := make([]int, len(c.fields))
+	var toSet, toDelete []string
+	for i, field := range c.fields {
+		exp, ok := expiry[field]
+		switch {
+		case !ok:
+			res[i] = FieldNotFound
+		case c.ifNoTTL && exp != nil,
+			c.ifHasTTL && exp == nil,
+			c.ifGreater && (exp == nil || etime <= *exp),
+			c.ifLess && exp != nil && etime >= *exp:
+			res[i] = FieldSkipped
+		case etime <= now.UnixMilli():
+			res[i] = FieldDeleted
+			toDelete = append(toDelete, field)
+			delete(expiry, field)
+		default:
+			res[i] = FieldUpdated
+			toSet = append(toSet, field)
+			expiry[field] = &etime
+		}
+	}
+	if len(toSet) == 0 && len(toDelete) == 0 {
+		return res, nil
+	}
+
+	// Update the fields and the key.
+	if len(toSet) > 0 {
+		query, fieldArgs := sqlx.ExpandIn(sqlExpire, ":fields", toSet)
+		query = sqlx.ConvertPlaceholders(query)
+		args := append([]any{etime, kid}, fieldArgs...)
+		if _, err := tx.tx.Exec(query, args...); err != nil {
+			return nil, err
+		}
+	}
+	if len(toDelete) > 0 {
+		query, fieldArgs := sqlx.ExpandIn(sqlExpireDelete, ":fields", toDelete)
+		query = sqlx.ConvertPlaceholders(query)
+		args := append([]any{kid}, fieldArgs...)
+		if _, err := tx.tx.Exec(query, args...); err != nil {
+			return nil, err
+		}
+	}
+	query := sqlx.ConvertPlaceholders(sqlExpireKey)
+	_, err = tx.tx.Exec(query, now.UnixMilli(), len(toDelete), kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete the hash if no fields are left.
+	if len(toDelete) > 0 {
+		query = sqlx.ConvertPlaceholders(sqlDeleteExpired4)
+		if _, err := tx.tx.Exec(query, kid); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ExpireWith sets the expiration time of hash fields.
+// Use the returned command to specify the time and conditions.
+func (tx *Tx) ExpireWith(key string, fields ...string) ExpireCmd {
+	return ExpireCmd{tx: tx, key: key, fields: fields}
+}
+
+// Expiry returns the expiration state of hash fields,
+// in the order of fields.
+// If the key does not exist or is not a hash,
+// reports every field as non-existing.
+func (tx *Tx) Expiry(key string, fields ...string) ([]Expiry, error) {
+	_, expiry, err := tx.expiry(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Expiry, len(fields))
+	for i, field := range fields {
+		exp, ok := expiry[field]
+		res[i] = Expiry{Exists: ok, ETime: exp}
+	}
+	return res, nil
+}
+
+// Persist removes the expiration time of hash fields.
+// Returns a result for each field, in the order of fields:
+// FieldUpdated if the expiration time was removed,
+// FieldNoTTL if the field had no expiration time,
+// or FieldNotFound if the field does not exist.
+// If the key does not exist or is not a hash,
+// returns FieldNotFound for every field.
+func (tx *Tx) Persist(key string, fields ...string) ([]int, error) {
+	if err := tx.purge(key); err != nil {
+		return nil, err
+	}
+	kid, expiry, err := tx.expiry(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]int, len(fields))
+	var toPersist []string
+	for i, field := range fields {
+		exp, ok := expiry[field]
+		switch {
+		case !ok:
+			res[i] = FieldNotFound
+		case exp == nil:
+			res[i] = FieldNoTTL
+		default:
+			res[i] = FieldUpdated
+			toPersist = append(toPersist, field)
+			expiry[field] = nil
+		}
+	}
+	if len(toPersist) == 0 {
+		return res, nil
+	}
+
+	query, fieldArgs := sqlx.ExpandIn(sqlExpire, ":fields", toPersist)
+	query = sqlx.ConvertPlaceholders(query)
+	args := append([]any{nil, kid}, fieldArgs...)
+	if _, err := tx.tx.Exec(query, args...); err != nil {
+		return nil, err
+	}
+	query = sqlx.ConvertPlaceholders(sqlExpireKey)
+	_, err = tx.tx.Exec(query, time.Now().UnixMilli(), 0, kid)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteExpired deletes expired fields from all hashes
+// and updates the length of the affected keys.
+// Deletes hashes that have no fields left.
+// Returns the number of fields deleted.
+func (tx *Tx) DeleteExpired() (int, error) {
+	now := time.Now().UnixMilli()
+
+	// Count the expired fields in each hash.
+	type hashCount struct{ kid, n int }
+	query := sqlx.ConvertPlaceholders(sqlDeleteExpired1)
+	counts, err := sqlx.Select(tx.tx, query, []any{now},
+		func(rows *sql.Rows) (hashCount, error) {
+			var c hashCount
+			err := rows.Scan(&c.kid, &c.n)
+			return c, err
+		})
+	if err != nil {
+		return 0, err
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	// Delete the fields, fix the lengths
+	// and remove the hashes left empty.
+	query = sqlx.ConvertPlaceholders(sqlDeleteExpired2)
+	if _, err := tx.tx.Exec(query, now); err != nil {
+		return 0, err
+	}
+	total := 0
+	updateQuery := sqlx.ConvertPlaceholders(sqlDeleteExpired3)
+	deleteQuery := sqlx.ConvertPlaceholders(sqlDeleteExpired4)
+	for _, c := range counts {
+		if _, err := tx.tx.Exec(updateQuery, now, c.n, c.kid); err != nil {
+			return 0, err
+		}
+		if _, err := tx.tx.Exec(deleteQuery, c.kid); err != nil {
+			return 0, err
+		}
+		total += c.n
+	}
+	return total, nil
+}
+
+// purge deletes the expired fields of a hash
+// and updates the length of the key.
+// Deletes the hash if it has no fields left.
+func (tx *Tx) purge(key string) error {
+	now := time.Now().UnixMilli()
+	var kid int
+	query := sqlx.ConvertPlaceholders(sqlPurge1)
+	err := tx.tx.QueryRow(query, key, now).Scan(&kid)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return sqlx.TypedError(err)
+	}
+
+	query = sqlx.ConvertPlaceholders(sqlPurge2)
+	res, err := tx.tx.Exec(query, kid, now)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return nil
+	}
+	query = sqlx.ConvertPlaceholders(sqlDeleteExpired3)
+	if _, err := tx.tx.Exec(query, now, n, kid); err != nil {
+		return err
+	}
+	query = sqlx.ConvertPlaceholders(sqlDeleteExpired4)
+	_, err = tx.tx.Exec(query, kid)
+	return err
+}
+
+// expiry returns the key ID and the expiration times
+// of the given hash fields that exist (nil if no TTL).
+func (tx *Tx) expiry(key string, fields ...string) (int, map[string]*int64, error) {
+	expiry := map[string]*int64{}
+	if len(fields) == 0 {
+		return 0, expiry, nil
+	}
+
+	now := time.Now().UnixMilli()
+	query, fieldArgs := sqlx.ExpandIn(sqlExpiry, ":fields", fields)
+	query = sqlx.ConvertPlaceholders(query)
+	args := append([]any{key, now, now}, fieldArgs...)
+	rows, err := tx.tx.Query(query, args...)
+	if err != nil {
+		return 0, nil, sqlx.TypedError(err)
+	}
+	defer rows.Close()
+
+	var kid int
+	for rows.Next() {
+		var field string
+		var etime *int64
+		if err := rows.Scan(&kid, &field, &etime); err != nil {
+			return 0, nil, err
+		}
+		expiry[field] = etime
+	}
+	if rows.Err() != nil {
+		return 0, nil, rows.Err()
+	}
+	return kid, expiry, nil
+}
@@ -0,0 +1,136 @@
+package rhash
+
+import (
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// SetCmd sets the values of hash fields.
+type SetCmd struct {
+	db          *DB
+	tx          *Tx
+	key         string
+	items       map[string]any
+	ttl         time.Duration
+	at          time.Time
+	keepTTL     bool
+	ifAllExist  bool
+	ifNoneExist bool
+}
+
+// IfAllExist instructs to set the values
+// only if all the fields already exist (FXX).
+func (c SetCmd) IfAllExist() SetCmd {
+	c.ifAllExist = true
+	c.ifNoneExist = false
+	return c
+}
+
+// IfNoneExist instructs to set the values
+// only if none of the fields exist (FNX).
+func (c SetCmd) IfNoneExist() SetCmd {
+	c.ifAllExist = false
+	c.ifNoneExist = true
+	return c
+}
+
+// TTL sets the time-to-live for the fields.
+func (c SetCmd) TTL(ttl time.Duration) SetCmd {
+	c.ttl = ttl
+	c.at = time.Time{}
+	c.keepTTL = false
+	return c
+}
+
+// At sets the expiration time for the fields.
+func (c SetCmd) At(at time.Time) SetCmd {
+	c.ttl = 0
+	c.at = at
+	c.keepTTL = false
+	return c
+}
+
+// KeepTTL instructs to keep the expiration time
+// already set for the existing fields.
+func (c SetCmd) KeepTTL() SetCmd {
+	c.ttl = 0
+	c.at = time.Time{}
+	c.keepTTL = true
+	return c
+}
+
+// Run sets the values of the fields according to the configured options.
+// Returns true if the fields were set, false if the existence
+// check failed and nothing was changed.
+//
+// Expiration time handling:
+//   - If called with TTL() > 0 or At(), sets the expiration time.
+//   - If called with KeepTTL(), keeps the expiration time of existing fields.
+//   - If called without TTL(), At() or KeepTTL(), sets fields that will not expire.
+//
+// If the key does not exist, creates it.
+// If the key exists but is not a hash, returns ErrKeyType.
+func (c SetCmd) Run() (bool, error) {
+	if c.db != nil {
+		var ok bool
+		err := c.db.Update(func(tx *Tx) error {
+			var err error
+			ok, err = c.run(tx)
+			return err
+		})
+		return ok, err
+	}
+	if c.tx != nil {
+		return c.run(c.tx)
+	}
+	return false, nil
+}
+
+func (c SetCmd) run(tx *Tx) (bool, error) {
+	for _, val := range c.items {
+		if !core.IsValueType(val) {
+			return false, core.ErrValueType
+		}
+	}
+	if len(c.items) == 0 {
+		return false, nil
+	}
+
+	// Check if the fields exist.
+	if c.ifAllExist || c.ifNoneExist {
+		fields := make([]string, 0, len(c.items))
+		for field := range c.items {
+			fields = append(fields, field)
+		}
+		existCount, err := tx.count(c.key, fields...)
+		if err != nil {
+			return false, err
+		}
+		if c.ifAllExist && existCount != len(fields) {
+			return false, nil
+		}
+		if c.ifNoneExist && existCount != 0 {
+			return false, nil
+		}
+	}
+
+	// Set the expiration time.
+	var etime *int64
+	if c.ttl > 0 {
+		c.at = time.Now().Add(c.ttl)
+	}
+	if !c.at.IsZero() {
+		ms := c.at.UnixMilli()
+		etime = &ms
+	}
+
+	// Set the values.
+	for field, val := range c.items {
+		err := tx.set(c.key, field, val, etime, c.keepTTL)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -2,6 +2,7 @@ package rhash
 
 import (
 	"database/sql"
+	"math/rand/v2"
 	"time"
 
 	"github.com/flarco/redka/internal/core"
@@ -12,13 +13,14 @@ const (
 	sqlCount = `
 	select count(field)
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?) and field in (:fields)`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?) and field in (:fields)`
 
 	sqlDelete1 = `
 	delete from rhash
 	where kid = (
 			select id from rkey
-			where key = ? and type = 4 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 4 and (etime is null or etime > ?)
 		) and field in (:fields)`
 
 	sqlDelete2 = `
@@ -26,60 +28,84 @@ const (
 		version = version + 1,
 		mtime = ?,
 		len = len - ?
-	where key = ? and type = 4 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 4 and (etime is null or etime > ?)`
 
 	sqlFields = `
 	select field
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?)`
 
 	sqlGet = `
 	select value
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?) and field = ?`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?) and field = ?`
 
 	sqlGetMany = `
 	select field, value
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?) and field in (:fields)`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?) and field in (:fields)`
 
 	sqlItems = `
 	select field, value
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?)`
 
 	sqlLen = `
-	select len from rkey
-	where key = ? and type = 4 and (etime is null or etime > ?)`
+	select len - (
+		select count(*) from rhash
+		where kid = rkey.id and rhash.etime is not null and rhash.etime <= ?
+	)
+	from rkey
+	where db = :db and key = ? and type = 4 and (etime is null or etime > ?)`
+
+	sqlRandom = `
+	select field, value
+	from rhash join rkey on kid = rkey.id and type = 4
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?)
+	order by random() limit ?`
 
 	sqlScan = `
 	select rhash.rowid, field, value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where
-		key = ? and (etime is null or etime > ?)
+		db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?)
 		and rhash.rowid > ? and field glob ?
 	limit ?`
 
 	sqlSet1 = `
 	insert into
-	rkey   (key, type, version, mtime, len)
-	values (  ?,    4,       1,     ?,   0)
-	on conflict (key) do update set
+	rkey   (db, key, type, version, mtime, len)
+	values (:db,   ?,    4,       1,     ?,   0)
+	on conflict (db, key) do update set
 		type = case when type = excluded.type then type else null end,
 		version = version+1,
 		mtime = excluded.mtime
 	returning id`
 
 	sqlSet2 = `
+	insert into rhash (kid, field, value, etime)
+	values (?, ?, ?, ?)
+	on conflict (kid, field) do update
+	set value = excluded.value, etime = excluded.etime`
+
+	sqlSet2KeepTTL = `
 	insert into rhash (kid, field, value)
 	values (?, ?, ?)
 	on conflict (kid, field) do update
-	set value = excluded.value`
+	set value = excluded.value,
+		etime = case when rhash.etime > ? then rhash.etime else null end`
 
 	sqlValues = `
 	select value
 	from rhash join rkey on kid = rkey.id and type = 4
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (rkey.etime is null or rkey.etime > ?)
+		and (rhash.etime is null or rhash.etime > ?)`
 )
 
 const scanPageSize = 10
@@ -104,6 +130,16 @@ func (tx *Tx) Delete(key string, fields ...string) (int, error) {
 		return 0, nil
 	}
 
+	// Remove the expired fields first, as only
+	// the live ones are reported as deleted.
+	if err := tx.purge(key); err != nil {
+		return 0, err
+	}
+	count, err := tx.count(key, fields...)
+	if err != nil {
+		return 0, err
+	}
+
 	// Delete fields from a hash.
 	now := time.Now().UnixMilli()
 	query, fieldArgs := sqlx.ExpandIn(sqlDelete1, ":fields", fields)
@@ -118,11 +154,9 @@ func (tx *Tx) Delete(key string, fields ...string) (int, error) {
 		return 0, nil
 	}
 
-	// If we've deleted any fields,
-	// update the len of the key.
-	count := int(n)
+	// If we've deleted any fields, update the len of the key.
 	query = sqlx.ConvertPlaceholders(sqlDelete2)
-	_, err = tx.tx.Exec(query, now, count, key, now)
+	_, err = tx.tx.Exec(query, now, n, key, now)
 	if err != nil {
 		return 0, sqlx.TypedError(err)
 	}
@@ -133,7 +167,7 @@ func (tx *Tx) Delete(key string, fields ...string) (int, error) {
 // If the key does not exist or is not a hash, returns false.
 func (tx *Tx) Exists(key, field string) (bool, error) {
 	now := time.Now().UnixMilli()
-	args := []any{key, now, field}
+	args := []any{key, now, now, field}
 	query := sqlx.ConvertPlaceholders(sqlGet)
 	rows, err := tx.tx.Query(query, args...)
 	if err != nil {
@@ -152,7 +186,7 @@ func (tx *Tx) Exists(key, field string) (bool, error) {
 // If the key does not exist or is not a hash, returns an empty slice.
 func (tx *Tx) Fields(key string) ([]string, error) {
 	now := time.Now().UnixMilli()
-	args := []any{key, now}
+	args := []any{key, now, now}
 	query := sqlx.ConvertPlaceholders(sqlFields)
 	rows, err := tx.tx.Query(query, args...)
 	if err != nil {
@@ -180,7 +214,7 @@ func (tx *Tx) Fields(key string) ([]string, error) {
 // If the key does not exist or is not a hash, returns ErrNotFound.
 func (tx *Tx) Get(key, field string) (core.Value, error) {
 	now := time.Now().UnixMilli()
-	args := []any{key, now, field}
+	args := []any{key, now, now, field}
 	query := sqlx.ConvertPlaceholders(sqlGet)
 	var val []byte
 	err := tx.tx.QueryRow(query, args...).Scan(&val)
@@ -193,6 +227,21 @@ func (tx *Tx) Get(key, field string) (core.Value, error) {
 	return core.Value(val), nil
 }
 
+// GetDelete returns the values of given fields and deletes the fields.
+// Ignores fields that do not exist and do not return them in the map.
+// If the key does not exist or is not a hash, returns an empty map.
+func (tx *Tx) GetDelete(key string, fields ...string) (map[string]core.Value, error) {
+	items, err := tx.GetMany(key, fields...)
+	if err != nil || len(items) == 0 {
+		return items, err
+	}
+	_, err = tx.Delete(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 // GetMany returns a map of values for given fields.
 // Ignores fields that do not exist and do not return them in the map.
 // If the key does not exist or is not a hash, returns an empty map.
@@ -202,23 +251,11 @@ func (tx *Tx) GetMany(key string, fields ...string) (map[string]core.Value, erro
 	}
 
 	now := time.Now().UnixMilli()
-	args := []any{key, now}
-	var rows *sql.Rows
-	var err error
-
-	if len(fields) == 1 {
-		// Use a simple query for a single field.
-		args = append(args, fields[0])
-		query := sqlx.ConvertPlaceholders(sqlGet)
-		rows, err = tx.tx.Query(query, args...)
-	} else {
-		// Use an expanded query for multiple fields.
-		query, fieldArgs := sqlx.ExpandIn(sqlGetMany, ":fields", fields)
-		query = sqlx.ConvertPlaceholders(query)
-		args = append(args, fieldArgs...)
-		rows, err = tx.tx.Query(query, args...)
-	}
-
+	args := []any{key, now, now}
+	query, fieldArgs := sqlx.ExpandIn(sqlGetMany, ":fields", fields)
+	query = sqlx.ConvertPlaceholders(query)
+	args = append(args, fieldArgs...)
+	rows, err := tx.tx.Query(query, args...)
 	if err != nil {
 		return nil, sqlx.TypedError(err)
 	}
@@ -261,7 +298,7 @@ func (tx *Tx) Incr(key, field string, delta int) (int, error) {
 
 	// increment the value
 	newVal := valInt + delta
-	err = tx.set(key, field, newVal)
+	err = tx.set(key, field, newVal, nil, true)
 	if err != nil {
 		return 0, err
 	}
@@ -290,7 +327,7 @@ func (tx *Tx) IncrFloat(key, field string, delta float64) (float64, error) {
 
 	// increment the value
 	newVal := valFloat + delta
-	err = tx.set(key, field, newVal)
+	err = tx.set(key, field, newVal, nil, true)
 	if err != nil {
 		return 0, err
 	}
@@ -303,7 +340,8 @@ func (tx *Tx) IncrFloat(key, field string, delta float64) (float64, error) {
 func (tx *Tx) Items(key string) (map[string]core.Value, error) {
 	// Select hash rows.
 	var rows *sql.Rows
-	args := []any{key, time.Now().UnixMilli()}
+	now := time.Now().UnixMilli()
+	args := []any{key, now, now}
 	rows, err := tx.tx.Query(sqlItems, args...)
 	if err != nil {
 		return nil, err
@@ -330,7 +368,8 @@ func (tx *Tx) Items(key string) (map[string]core.Value, error) {
 // If the key does not exist or is not a hash, returns 0.
 func (tx *Tx) Len(key string) (int, error) {
 	var n int
-	args := []any{key, time.Now().UnixMilli()}
+	now := time.Now().UnixMilli()
+	args := []any{now, key, now}
 	err := tx.tx.QueryRow(sqlLen, args...).Scan(&n)
 	if err == sql.ErrNoRows {
 		return 0, nil
@@ -338,6 +377,69 @@ func (tx *Tx) Len(key string) (int, error) {
 	return n, err
 }
 
+// Random returns a random field-value pair from a hash.
+// If the key does not exist or is not a hash, returns ErrNotFound.
+func (tx *Tx) Random(key string) (HashItem, error) {
+	items, err := tx.random(key, 1)
+	if err != nil {
+		return HashItem{}, err
+	}
+	if len(items) == 0 {
+		return HashItem{}, core.ErrNotFound
+	}
+	return items[0], nil
+}
+
+// RandomMany returns random field-value pairs from a hash.
+// If count is positive, returns up to count distinct items.
+// If count is negative, returns exactly -count items,
+// possibly repeating the same item multiple times.
+// If the key does not exist or is not a hash, returns an empty slice.
+func (tx *Tx) RandomMany(key string, count int) ([]HashItem, error) {
+	if count == 0 {
+		return []HashItem{}, nil
+	}
+	if count > 0 {
+		return tx.random(key, count)
+	}
+
+	// Sampling with repetitions. Draw the positions first,
+	// then fetch as many distinct random items as there are
+	// distinct positions, and map positions to items.
+	count = -count
+	n, err := tx.Len(key)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return []HashItem{}, nil
+	}
+	slots := make([]int, count)
+	seen := map[int]int{}
+	for i := range slots {
+		pos := rand.IntN(n)
+		slot, ok := seen[pos]
+		if !ok {
+			slot = len(seen)
+			seen[pos] = slot
+		}
+		slots[i] = slot
+	}
+	distinct, err := tx.random(key, len(seen))
+	if err != nil {
+		return nil, err
+	}
+	if len(distinct) < len(seen) {
+		// The hash has shrunk since we've checked its length.
+		return distinct, nil
+	}
+	items := make([]HashItem, count)
+	for i, slot := range slots {
+		items[i] = distinct[slot]
+	}
+	return items, nil
+}
+
 // Scan iterates over hash items with fields matching pattern.
 // Returns a slice of field-value pairs (see [HashItem]) of size count
 // based on the current state of the cursor. Returns an empty HashItem
@@ -349,8 +451,9 @@ func (tx *Tx) Scan(key string, cursor int, pattern string, count int) (ScanResul
 		count = scanPageSize
 	}
 
+	now := time.Now().UnixMilli()
 	args := []any{
-		key, time.Now().UnixMilli(),
+		key, now, now,
 		cursor, pattern, count,
 	}
 
@@ -399,7 +502,7 @@ func (tx *Tx) Set(key string, field string, value any) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	err = tx.set(key, field, value)
+	err = tx.set(key, field, value, nil, false)
 	if err != nil {
 		return false, err
 	}
@@ -429,7 +532,7 @@ func (tx *Tx) SetMany(key string, items map[string]any) (int, error) {
 
 	// Set the values.
 	for field, val := range items {
-		err := tx.set(key, field, val)
+		err := tx.set(key, field, val, nil, false)
 		if err != nil {
 			return 0, err
 		}
@@ -453,19 +556,25 @@ func (tx *Tx) SetNotExists(key, field string, value any) (bool, error) {
 	if exist {
 		return false, nil
 	}
-	err = tx.set(key, field, value)
+	err = tx.set(key, field, value, nil, false)
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
+// SetWith sets the values of hash fields with additional options.
+func (tx *Tx) SetWith(key string, items map[string]any) SetCmd {
+	return SetCmd{tx: tx, key: key, items: items}
+}
+
 // Values returns all values in a hash.
 // If the key does not exist or is not a hash, returns an empty slice.
 func (tx *Tx) Values(key string) ([]core.Value, error) {
 	// Select hash values.
 	var rows *sql.Rows
-	args := []any{key, time.Now().UnixMilli()}
+	now := time.Now().UnixMilli()
+	args := []any{key, now, now}
 	rows, err := tx.tx.Query(sqlValues, args...)
 	if err != nil {
 		return nil, err
@@ -492,7 +601,7 @@ func (tx *Tx) Values(key string) ([]core.Value, error) {
 // count returns the number of items deleted.
 func (tx *Tx) count(key string, fields ...string) (int, error) {
 	now := time.Now().UnixMilli()
-	args := []any{key, now}
+	args := []any{key, now, now}
 	query, fieldArgs := sqlx.ExpandIn(sqlCount, ":fields", fields)
 	query = sqlx.ConvertPlaceholders(query)
 	args = append(args, fieldArgs...)
@@ -505,12 +614,21 @@ func (tx *Tx) count(key string, fields ...string) (int, error) {
 }
 
 // set creates or updates a field in a hash.
-func (tx *Tx) set(key string, field string, value any) error {
+// Sets the field's expiration time to etime (nil means no expiration),
+// unless keepTTL is true, in which case an existing field keeps
+// its expiration time.
+func (tx *Tx) set(key string, field string, value any, etime *int64, keepTTL bool) error {
 	val, err := core.ToBytes(value)
 	if err != nil {
 		return err
 	}
 
+	// Remove the expired fields, so that a hash
+	// with all fields expired starts anew.
+	if err := tx.purge(key); err != nil {
+		return err
+	}
+
 	now := time.Now().UnixMilli()
 
 	// Insert the key if it doesn't exist.
@@ -524,7 +642,7 @@ func (tx *Tx) set(key string, field string, value any) error {
 
 	// If we got an error getting the key ID, try to fetch it directly
 	if err == sql.ErrNoRows {
-		query = "SELECT id FROM rkey WHERE key = $1 AND type = 4"
+		query = "SELECT id FROM rkey WHERE db = :db AND key = $1 AND type = 4"
 		err = tx.tx.QueryRow(query, key).Scan(&keyId)
 		if err != nil {
 			return sqlx.TypedError(err)
@@ -532,14 +650,40 @@ func (tx *Tx) set(key string, field string, value any) error {
 	}
 
 	// Insert the field.
-	query = sqlx.ConvertPlaceholders(sqlSet2)
-	_, err = tx.tx.Exec(query, keyId, field, val)
+	if keepTTL {
+		query = sqlx.ConvertPlaceholders(sqlSet2KeepTTL)
+		_, err = tx.tx.Exec(query, keyId, field, val, now)
+	} else {
+		query = sqlx.ConvertPlaceholders(sqlSet2)
+		_, err = tx.tx.Exec(query, keyId, field, val, etime)
+	}
 	if err != nil {
 		return sqlx.TypedError(err)
 	}
 	return nil
 }
 
+// random returns up to count distinct random items from a hash.
+func (tx *Tx) random(key string, count int) ([]HashItem, error) {
+	now := time.Now().UnixMilli()
+	args := []any{key, now, now, count}
+	query := sqlx.ConvertPlaceholders(sqlRandom)
+	items, err := sqlx.Select(tx.tx, query, args, func(rows *sql.Rows) (HashItem, error) {
+		var it HashItem
+		var val []byte
+		err := rows.Scan(&it.Field, &val)
+		it.Value = core.Value(val)
+		return it, err
+	})
+	if err != nil {
+		return nil, sqlx.TypedError(err)
+	}
+	if items == nil {
+		items = []HashItem{}
+	}
+	return items, nil
+}
+
 // scanValue scans a hash field value the current row.
 func scanValue(rows *sql.Rows) (field string, val core.Value, err error) {
 	var value []byte
@@ -0,0 +1,111 @@
+package rkey
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/flarco/redka/internal/sqlx"
+)
+
+const (
+	sqlChangeLogEnabled = `
+	select count(*) from sqlite_master
+	where type = 'trigger' and name like 'rchange\_%' escape '\'`
+
+	sqlChangeBounds = `
+	select coalesce(min(id), 0), coalesce(max(id), 0) from rchange`
+
+	sqlChanges = `
+	select id, db, key from rchange
+	where id > ?
+	order by id
+	limit ?`
+
+	sqlLastChange = `
+	select coalesce(max(id), 0) from rchange`
+)
+
+var (
+	// ErrChangeLogDisabled is returned when reading changes
+	// from a database without the change log.
+	ErrChangeLogDisabled = errors.New("change log is disabled")
+	// ErrChangeLogTruncated is returned when reading changes
+	// from a position that is no longer in the change log.
+	ErrChangeLogTruncated = errors.New("change log truncated")
+)
+
+// ChangeResult represents a result of the Changes call.
+type ChangeResult struct {
+	Pos  int64        // position of the last returned change
+	Keys []ChangedKey // changed keys
+}
+
+// ChangedKey is a key changed in a logical database.
+type ChangedKey struct {
+	DB  int    // logical database index
+	Key string // key name
+}
+
+// Changes returns the keys (with their logical databases) changed
+// after the given change log position, reading no more than count
+// changes. Each key is returned once, even if it changed multiple times.
+// The keys may no longer exist (if they were deleted).
+//
+// If there are no changes after the position, returns an empty
+// result with the same position. If the change log is disabled,
+// returns ErrChangeLogDisabled. If the position is no longer
+// in the change log (it was pruned, or the database was replaced),
+// returns ErrChangeLogTruncated.
+func (tx *Tx) Changes(after int64, count int) (ChangeResult, error) {
+	var nTriggers int
+	err := tx.tx.QueryRow(sqlChangeLogEnabled).Scan(&nTriggers)
+	if err != nil {
+		return ChangeResult{}, err
+	}
+	if nTriggers == 0 {
+		return ChangeResult{}, ErrChangeLogDisabled
+	}
+
+	var first, last int64
+	err = tx.tx.QueryRow(sqlChangeBounds).Scan(&first, &last)
+	if err != nil {
+		return ChangeResult{}, err
+	}
+	if after > last || (first > 0 && after < first-1) {
+		return ChangeResult{}, ErrChangeLogTruncated
+	}
+
+	type change struct {
+		id  int64
+		key ChangedKey
+	}
+	scan := func(rows *sql.Rows) (change, error) {
+		var c change
+		err := rows.Scan(&c.id, &c.key.DB, &c.key.Key)
+		return c, err
+	}
+	changes, err := sqlx.Select(tx.tx, sqlChanges, []any{after, count}, scan)
+	if err != nil {
+		return ChangeResult{}, err
+	}
+
+	res := ChangeResult{Pos: after, Keys: []ChangedKey{}}
+	seen := make(map[ChangedKey]bool, len(changes))
+	for _, c := range changes {
+		res.Pos = c.id
+		if seen[c.key] {
+			continue
+		}
+		seen[c.key] = true
+		res.Keys = append(res.Keys, c.key)
+	}
+	return res, nil
+}
+
+// LastChange returns the position of the latest change
+// in the change log, or 0 if the change log is empty.
+func (tx *Tx) LastChange() (int64, error) {
+	var pos int64
+	err := tx.tx.QueryRow(sqlLastChange).Scan(&pos)
+	return pos, err
+}
@@ -32,59 +32,129 @@ func NewWithDriver(rw *sql.DB, ro *sql.DB, driver string) *DB {
 	return &DB{d}
 }
 
+// Changes returns the keys changed after the given change log
+// position, reading no more than count changes.
+// See [Tx.Changes] for details.
+func (db *DB) Changes(after int64, count int) (ChangeResult, error) {
+	var res ChangeResult
+	err := db.View(func(tx *Tx) error {
+		var err error
+		res, err = tx.Changes(after, count)
+		return err
+	})
+	return res, err
+}
+
+// Copy copies the key and its value to a new key.
+// Returns true if the key was copied, false if the destination
+// key already exists and replace is false.
+// If replace is true, overwrites the destination key regardless of its type.
+// If the source key does not exist, returns ErrNotFound.
+// If the source and destination keys are the same, returns ErrNotAllowed.
+func (db *DB) Copy(src, dst string, replace bool) (bool, error) {
+	var ok bool
+	err := db.Update(func(tx *Tx) error {
+		var err error
+		ok, err = tx.Copy(src, dst, replace)
+		return err
+	})
+	return ok, err
+}
+
 // Count returns the number of existing keys among specified.
 func (db *DB) Count(keys ...string) (int, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Count(keys...)
 }
 
+// Counts returns the number of keys (total, with
+// an expiration time, and by type), excluding expired ones.
+func (db *DB) Counts() (KeyCounts, error) {
+	tx := NewTx(db.Reader())
+	return tx.Counts()
+}
+
 // Delete deletes keys and their values, regardless of the type.
 // Returns the number of deleted keys. Non-existing keys are ignored.
 func (db *DB) Delete(keys ...string) (int, error) {
-	tx := NewTx(db.RW)
+	tx := NewTx(db.Writer())
 	return tx.Delete(keys...)
 }
 
 // DeleteAll deletes all keys and their values, effectively resetting
 // the database. Should not be run inside a database transaction.
 func (db *DB) DeleteAll() error {
-	tx := NewTx(db.RW)
+	tx := NewTx(db.Writer())
 	return tx.DeleteAll()
 }
 
+// DeleteAllDatabases deletes all keys and their values
+// in all logical databases, not only the selected one.
+// Should not be run inside a database transaction.
+func (db *DB) DeleteAllDatabases() error {
+	tx := NewTx(db.Writer())
+	return tx.DeleteAllDatabases()
+}
+
+// DBCounts returns the number of keys (total and with
+// an expiration time) in each logical database that has keys,
+// excluding expired ones. Does not count the keys by type.
+func (db *DB) DBCounts() (map[int]KeyCounts, error) {
+	tx := NewTx(db.Reader())
+	return tx.DBCounts()
+}
+
 // DeleteExpired deletes keys with expired TTL, but no more than n keys.
 // If n = 0, deletes all expired keys.
 func (db *DB) DeleteExpired(n int) (count int, err error) {
-	tx := NewTx(db.RW)
+	tx := NewTx(db.Writer())
 	return tx.deleteExpired(n)
 }
 
+// Dump serializes the value stored at the key, along with
+// its expiration time, in a versioned, checksummed format.
+// Use [DB.Restore] to create a key from the serialized value.
+// If the key does not exist, returns ErrNotFound.
+func (db *DB) Dump(key string) ([]byte, error) {
+	var data []byte
+	err := db.View(func(tx *Tx) error {
+		var err error
+		data, err = tx.Dump(key)
+		return err
+	})
+	return data, err
+}
+
 // Exists reports whether the key exists.
 func (db *DB) Exists(key string) (bool, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Exists(key)
 }
 
 // Expire sets a time-to-live (ttl) for the key using a relative duration.
 // After the ttl passes, the key is expired and no longer exists.
+// Optional conditions (see [ExpireCond]) restrict when the ttl is set.
 // If the key does not exist, returns ErrNotFound.
-func (db *DB) Expire(key string, ttl time.Duration) error {
-	tx := NewTx(db.RW)
-	return tx.Expire(key, ttl)
+// If the conditions are not met, returns ErrNotAllowed.
+func (db *DB) Expire(key string, ttl time.Duration, conds ...ExpireCond) error {
+	tx := NewTx(db.Writer())
+	return tx.Expire(key, ttl, conds...)
 }
 
 // ExpireAt sets an expiration time for the key. After this time,
 // the key is expired and no longer exists.
+// Optional conditions (see [ExpireCond]) restrict when the time is set.
 // If the key does not exist, returns ErrNotFound.
-func (db *DB) ExpireAt(key string, at time.Time) error {
-	tx := NewTx(db.RW)
-	return tx.ExpireAt(key, at)
+// If the conditions are not met, returns ErrNotAllowed.
+func (db *DB) ExpireAt(key string, at time.Time, conds ...ExpireCond) error {
+	tx := NewTx(db.Writer())
+	return tx.ExpireAt(key, at, conds...)
 }
 
 // Get returns a specific key with all associated details.
 // If the key does not exist, returns ErrNotFound.
 func (db *DB) Get(key string) (core.Key, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Get(key)
 }
 
@@ -96,27 +166,48 @@ func (db *DB) Get(key string) (core.Key, error) {
 // Use this method only if you are sure that the number of keys is
 // limited. Otherwise, use the [DB.Scan] or [DB.Scanner] methods.
 func (db *DB) Keys(pattern string) ([]core.Key, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Keys(pattern)
 }
 
+// LastChange returns the position of the latest change
+// in the change log, or 0 if the change log is empty.
+func (db *DB) LastChange() (int64, error) {
+	tx := NewTx(db.Reader())
+	return tx.LastChange()
+}
+
 // Len returns the total number of keys, including expired ones.
 func (db *DB) Len() (int, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Len()
 }
 
+// Move moves the key to another logical database.
+// Returns true if the key was moved, false if the key
+// already exists in the target database.
+// If the key does not exist, returns ErrNotFound.
+func (db *DB) Move(key string, index int) (bool, error) {
+	var ok bool
+	err := db.Update(func(tx *Tx) error {
+		var err error
+		ok, err = tx.Move(key, index)
+		return err
+	})
+	return ok, err
+}
+
 // Persist removes the expiration time for the key.
 // If the key does not exist, returns ErrNotFound.
 func (db *DB) Persist(key string) error {
-	tx := NewTx(db.RW)
+	tx := NewTx(db.Writer())
 	return tx.Persist(key)
 }
 
 // Random returns a random key.
 // If there are no keys, returns ErrNotFound.
 func (db *DB) Random() (core.Key, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Random()
 }
 
@@ -144,6 +235,21 @@ func (db *DB) RenameNotExists(key, newKey string) (bool, error) {
 	return ok, err
 }
 
+// Restore creates the key from a value serialized by [DB.Dump].
+// The key gets the expiration time stored in the dump.
+// If the key already exists, returns ErrNotAllowed.
+// If the serialized value is invalid, returns ErrValueType.
+func (db *DB) Restore(key string, data []byte) error {
+	return db.RestoreWith(key, data).Run()
+}
+
+// RestoreWith creates the key from a value serialized by [DB.Dump].
+// Use the returned command to override the expiration time
+// or to replace an existing key.
+func (db *DB) RestoreWith(key string, data []byte) RestoreCmd {
+	return RestoreCmd{db: db, key: key, data: data}
+}
+
 // Scan iterates over keys matching pattern.
 // Returns a slice of keys (see [core.Key]) of size count
 // based on the current state of the cursor.
@@ -154,7 +260,7 @@ func (db *DB) RenameNotExists(key, newKey string) (bool, error) {
 //   - ktype to filter keys by type (TypeAny = any type).
 //   - count to limit the number of keys returned (0 = default).
 func (db *DB) Scan(cursor int, pattern string, ktype core.TypeID, count int) (ScanResult, error) {
-	tx := NewTx(db.RO)
+	tx := NewTx(db.Reader())
 	return tx.Scan(cursor, pattern, ktype, count)
 }
 
@@ -168,5 +274,20 @@ func (db *DB) Scan(cursor int, pattern string, ktype core.TypeID, count int) (Sc
 //   - ktype to filter keys by type (TypeAny = any type).
 //   - pageSize to limit the number of keys fetched at once (0 = default).
 func (db *DB) Scanner(pattern string, ktype core.TypeID, pageSize int) *Scanner {
-	return newScanner(NewTx(db.RO), pattern, ktype, pageSize)
+	return newScanner(NewTx(db.Reader()), pattern, ktype, pageSize)
+}
+
+// SortWith sorts the elements of a list, set or sorted set.
+// Use the returned command to specify the patterns and options.
+func (db *DB) SortWith(key string) SortCmd {
+	return SortCmd{db: db, key: key, count: -1}
+}
+
+// SwapDB swaps the keys of two logical databases,
+// so that the clients working with one database
+// see the keys of the other one.
+func (db *DB) SwapDB(index1, index2 int) error {
+	return db.Update(func(tx *Tx) error {
+		return tx.SwapDB(index1, index2)
+	})
 }
@@ -10,6 +10,229 @@ import (
 	"github.com/flarco/redka/internal/testx"
 )
 
+func TestChanges(t *testing.T) {
+	t.Run("changes", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.List().PushBack("list", "one")
+		_ = db.Str().Set("name", "bob")
+		_, _ = db.Set().Add("set", "one")
+		_, _ = kkey.Delete("list")
+
+		res, err := kkey.Changes(0, 1000)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(0, "name", "list", "set"))
+		last, _ := kkey.LastChange()
+		testx.AssertEqual(t, res.Pos, last)
+	})
+	t.Run("after", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		pos, _ := kkey.LastChange()
+		_ = db.Str().Set("age", 25)
+
+		res, err := kkey.Changes(pos, 1000)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(0, "age"))
+	})
+	t.Run("rename", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		pos, _ := kkey.LastChange()
+		_ = kkey.Rename("name", "title")
+
+		res, err := kkey.Changes(pos, 1000)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(0, "name", "title"))
+	})
+	t.Run("databases", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(1).Str().Set("name", "bob")
+		pos, _ := kkey.LastChange()
+		_ = db.Select(2).Str().Set("age", 25)
+		_, _ = kkey.Move("name", 3)
+
+		res, err := kkey.Changes(pos, 1000)
+		testx.AssertNoErr(t, err)
+		want := append(changed(2, "age"), changed(0, "name")...)
+		want = append(want, changed(3, "name")...)
+		testx.AssertEqual(t, res.Keys, want)
+	})
+	t.Run("db parameter", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		// Only the :db query parameter is replaced
+		// with the database index, not the data.
+		_ = db.Select(2).Str().Set("host:db", "replica:db:1")
+		val, err := db.Select(2).Str().Get("host:db")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, val.String(), "replica:db:1")
+
+		res, err := kkey.Changes(0, 1000)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(2, "host:db"))
+	})
+	t.Run("count", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+
+		res, err := kkey.Changes(0, 1)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(0, "name"))
+		testx.AssertEqual(t, res.Pos, int64(1))
+	})
+	t.Run("no changes", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		pos, _ := kkey.LastChange()
+
+		res, err := kkey.Changes(pos, 1000)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, res.Keys, changed(0))
+		testx.AssertEqual(t, res.Pos, pos)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+		_ = db.Str().Set("city", "paris")
+		_, _ = db.PruneChangeLog(1)
+
+		_, err := kkey.Changes(0, 1000)
+		testx.AssertErr(t, err, rkey.ErrChangeLogTruncated)
+		_, err = kkey.Changes(100, 1000)
+		testx.AssertErr(t, err, rkey.ErrChangeLogTruncated)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_, err := kkey.Changes(0, 1000)
+		testx.AssertErr(t, err, rkey.ErrChangeLogDisabled)
+	})
+}
+
+func TestCopy(t *testing.T) {
+	t.Run("all types", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("str", "alice")
+		_, _ = db.List().PushBack("list", "alice")
+		_, _ = db.List().PushBack("list", "bob")
+		_, _ = db.Set().Add("set", "alice", "bob")
+		_, _ = db.Hash().SetMany("hash", map[string]any{"name": "alice", "age": 25})
+		_, _ = db.ZSet().AddMany("zset", map[any]float64{"alice": 11, "bob": 22})
+
+		for _, key := range []string{"str", "list", "set", "hash", "zset"} {
+			ok, err := kkey.Copy(key, key+"2", false)
+			testx.AssertNoErr(t, err)
+			testx.AssertEqual(t, ok, true)
+
+			src, _ := kkey.Get(key)
+			dst, _ := kkey.Get(key + "2")
+			testx.AssertEqual(t, dst.Type, src.Type)
+			testx.AssertEqual(t, dst.Version, 1)
+		}
+
+		str, _ := db.Str().Get("str2")
+		testx.AssertEqual(t, str.String(), "alice")
+		list, _ := db.List().Range("list2", 0, -1)
+		testx.AssertEqual(t, len(list), 2)
+		testx.AssertEqual(t, list[1].String(), "bob")
+		slen, _ := db.Set().Len("set2")
+		testx.AssertEqual(t, slen, 2)
+		hlen, _ := db.Hash().Len("hash2")
+		testx.AssertEqual(t, hlen, 2)
+		age, _ := db.Hash().Get("hash2", "age")
+		testx.AssertEqual(t, age.String(), "25")
+		score, _ := db.ZSet().GetScore("zset2", "bob")
+		testx.AssertEqual(t, score, 22.0)
+		zlen, _ := db.ZSet().Len("zset2")
+		testx.AssertEqual(t, zlen, 2)
+	})
+	t.Run("keeps ttl", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+
+		ok, err := kkey.Copy("name", "title", false)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+
+		src, _ := kkey.Get("name")
+		dst, _ := kkey.Get("title")
+		testx.AssertEqual(t, *dst.ETime, *src.ETime)
+	})
+	t.Run("dst exists", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("title", "bob")
+
+		ok, err := kkey.Copy("name", "title", false)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, false)
+
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "bob")
+	})
+	t.Run("replace", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Set().Add("title", "bob", "cindy")
+
+		ok, err := kkey.Copy("name", "title", true)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "alice")
+		key, _ := kkey.Get("title")
+		testx.AssertEqual(t, key.Type, core.TypeString)
+	})
+	t.Run("src not found", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		ok, err := kkey.Copy("name", "title", false)
+		testx.AssertErr(t, err, core.ErrNotFound)
+		testx.AssertEqual(t, ok, false)
+	})
+	t.Run("same key", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		ok, err := kkey.Copy("name", "name", true)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+		testx.AssertEqual(t, ok, false)
+	})
+}
+
 func TestCount(t *testing.T) {
 	db, kkey := getDB(t)
 	defer db.Close()
@@ -103,6 +326,28 @@ func TestDeleteAll(t *testing.T) {
 	testx.AssertEqual(t, count, 0)
 }
 
+func TestDeleteAllDatabases(t *testing.T) {
+	db, kkey := getDB(t)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Select(3).Str().Set("name", "bob")
+
+	// DeleteAll only deletes the keys in the selected database.
+	err := db.Select(3).Key().DeleteAll()
+	testx.AssertNoErr(t, err)
+	count, _ := kkey.Count("name")
+	testx.AssertEqual(t, count, 1)
+
+	_ = db.Select(3).Str().Set("name", "bob")
+	err = kkey.DeleteAllDatabases()
+	testx.AssertNoErr(t, err)
+	count, _ = kkey.Count("name")
+	testx.AssertEqual(t, count, 0)
+	count, _ = db.Select(3).Key().Count("name")
+	testx.AssertEqual(t, count, 0)
+}
+
 func TestDeleteExpired(t *testing.T) {
 	t.Run("delete all", func(t *testing.T) {
 		db, kkey := getDB(t)
@@ -133,6 +378,114 @@ func TestDeleteExpired(t *testing.T) {
 	})
 }
 
+func TestDumpRestore(t *testing.T) {
+	t.Run("all types", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("str", "alice")
+		_, _ = db.List().PushBack("list", "alice")
+		_, _ = db.List().PushBack("list", "bob")
+		_, _ = db.Set().Add("set", "alice", "bob")
+		_, _ = db.Hash().SetMany("hash", map[string]any{"name": "alice", "age": 25})
+		_, _ = db.ZSet().AddMany("zset", map[any]float64{"alice": 11, "bob": 22.5})
+
+		for _, key := range []string{"str", "list", "set", "hash", "zset"} {
+			data, err := kkey.Dump(key)
+			testx.AssertNoErr(t, err)
+			err = kkey.Restore(key+"2", data)
+			testx.AssertNoErr(t, err)
+
+			src, _ := kkey.Get(key)
+			dst, _ := kkey.Get(key + "2")
+			testx.AssertEqual(t, dst.Type, src.Type)
+		}
+
+		str, _ := db.Str().Get("str2")
+		testx.AssertEqual(t, str.String(), "alice")
+		list, _ := db.List().Range("list2", 0, -1)
+		testx.AssertEqual(t, list, []core.Value{core.Value("alice"), core.Value("bob")})
+		llen, _ := db.List().Len("list2")
+		testx.AssertEqual(t, llen, 2)
+		slen, _ := db.Set().Len("set2")
+		testx.AssertEqual(t, slen, 2)
+		hlen, _ := db.Hash().Len("hash2")
+		testx.AssertEqual(t, hlen, 2)
+		age, _ := db.Hash().Get("hash2", "age")
+		testx.AssertEqual(t, age.String(), "25")
+		score, _ := db.ZSet().GetScore("zset2", "bob")
+		testx.AssertEqual(t, score, 22.5)
+		zlen, _ := db.ZSet().Len("zset2")
+		testx.AssertEqual(t, zlen, 2)
+	})
+	t.Run("ttl", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().SetExpires("name", "alice", 60*time.Second)
+		src, _ := kkey.Get("name")
+
+		data, err := kkey.Dump("name")
+		testx.AssertNoErr(t, err)
+
+		err = kkey.Restore("title", data)
+		testx.AssertNoErr(t, err)
+		dst, _ := kkey.Get("title")
+		testx.AssertEqual(t, *dst.ETime, *src.ETime)
+
+		err = kkey.RestoreWith("other", data).TTL(10 * time.Second).Run()
+		testx.AssertNoErr(t, err)
+		other, _ := kkey.Get("other")
+		testx.AssertEqual(t, *other.ETime/1000, time.Now().Add(10*time.Second).UnixMilli()/1000)
+
+		err = kkey.RestoreWith("past", data).At(time.Now().Add(-time.Second)).Run()
+		testx.AssertNoErr(t, err)
+		exists, _ := kkey.Exists("past")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("key exists", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.List().PushBack("title", "bob")
+		data, _ := kkey.Dump("name")
+
+		err := kkey.Restore("title", data)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+
+		err = kkey.RestoreWith("title", data).Replace().Run()
+		testx.AssertNoErr(t, err)
+		title, _ := db.Str().Get("title")
+		testx.AssertEqual(t, title.String(), "alice")
+	})
+	t.Run("dump not found", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, err := kkey.Dump("name")
+		testx.AssertErr(t, err, core.ErrNotFound)
+	})
+	t.Run("invalid payload", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		data, _ := kkey.Dump("name")
+
+		corrupt := append([]byte{}, data...)
+		corrupt[3] ^= 0xff
+		err := kkey.Restore("title", corrupt)
+		testx.AssertErr(t, err, core.ErrValueType)
+
+		err = kkey.Restore("title", []byte("alice"))
+		testx.AssertErr(t, err, core.ErrValueType)
+
+		exists, _ := kkey.Exists("title")
+		testx.AssertEqual(t, exists, false)
+	})
+}
+
 func TestExists(t *testing.T) {
 	db, kkey := getDB(t)
 	defer db.Close()
@@ -208,6 +561,42 @@ func TestExpire(t *testing.T) {
 		testx.AssertEqual(t, key.Version, 3)
 		testx.AssertEqual(t, key.ETime, (*int64)(nil))
 	})
+	t.Run("conditions", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+
+		// XX: no ttl yet.
+		err := kkey.Expire("name", 60*time.Second, rkey.IfHasTTL)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+		// GT: no ttl means infinite, so never greater.
+		err = kkey.Expire("name", 60*time.Second, rkey.IfGreater)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+		// LT: always less than infinite.
+		err = kkey.Expire("name", 60*time.Second, rkey.IfLess)
+		testx.AssertNoErr(t, err)
+		// NX: already has ttl.
+		err = kkey.Expire("name", 30*time.Second, rkey.IfNoTTL)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+		// LT: 90s is not less than 60s.
+		err = kkey.Expire("name", 90*time.Second, rkey.IfLess)
+		testx.AssertErr(t, err, core.ErrNotAllowed)
+		// GT: 90s is greater than 60s.
+		err = kkey.Expire("name", 90*time.Second, rkey.IfGreater)
+		testx.AssertNoErr(t, err)
+		// XX: has ttl.
+		err = kkey.Expire("name", 30*time.Second, rkey.IfHasTTL)
+		testx.AssertNoErr(t, err)
+
+		key, _ := kkey.Get("name")
+		got := (*key.ETime) / 1000
+		want := time.Now().Add(30*time.Second).UnixMilli() / 1000
+		testx.AssertEqual(t, got, want)
+
+		err = kkey.Expire("age", 30*time.Second, rkey.IfLess)
+		testx.AssertErr(t, err, core.ErrNotFound)
+	})
 }
 
 func TestExpireAt(t *testing.T) {
@@ -298,6 +687,26 @@ func TestKeys(t *testing.T) {
 	}
 }
 
+func TestLastChange(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		pos, err := kkey.LastChange()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, pos, int64(0))
+	})
+	t.Run("changed", func(t *testing.T) {
+		db, kkey := getChangeDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		pos, err := kkey.LastChange()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, pos > 0, true)
+	})
+}
+
 func TestLen(t *testing.T) {
 	t.Run("len", func(t *testing.T) {
 		db, kkey := getDB(t)
@@ -320,6 +729,114 @@ func TestLen(t *testing.T) {
 	})
 }
 
+func TestCounts(t *testing.T) {
+	t.Run("counts", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpires("age", 25, time.Minute)
+		_ = db.Str().SetExpires("city", "paris", time.Millisecond)
+		_, _ = db.List().PushBack("list", "one")
+		_, _ = db.Hash().Set("person", "name", "alice")
+		time.Sleep(2 * time.Millisecond)
+
+		counts, err := kkey.Counts()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, counts.Keys, 4)
+		testx.AssertEqual(t, counts.Expires, 1)
+		testx.AssertEqual(t, counts.Types, map[core.TypeID]int{
+			core.TypeString: 2, core.TypeList: 1, core.TypeHash: 1,
+		})
+	})
+	t.Run("empty", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		counts, err := kkey.Counts()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, counts.Keys, 0)
+		testx.AssertEqual(t, counts.Types, map[core.TypeID]int{})
+	})
+}
+
+func TestDBCounts(t *testing.T) {
+	db, kkey := getDB(t)
+	defer db.Close()
+
+	counts, err := kkey.DBCounts()
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, counts, map[int]rkey.KeyCounts{})
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().SetExpires("age", 25, time.Minute)
+	_ = db.Select(2).Str().Set("name", "bob")
+	_ = db.Select(5).Str().SetExpires("temp", "x", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	counts, err = kkey.DBCounts()
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, counts, map[int]rkey.KeyCounts{
+		0: {Keys: 2, Expires: 1},
+		2: {Keys: 1, Expires: 0},
+	})
+}
+
+func TestMove(t *testing.T) {
+	t.Run("move", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		ok, err := kkey.Move("name", 3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+
+		exists, _ := kkey.Exists("name")
+		testx.AssertEqual(t, exists, false)
+		name, _ := db.Select(3).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("target exists", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(3).Str().Set("name", "bob")
+		ok, err := kkey.Move("name", 3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, false)
+
+		name, _ := db.Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+		name, _ = db.Select(3).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "bob")
+	})
+	t.Run("target expired", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Select(3).Str().SetExpires("name", "bob", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		ok, err := kkey.Move("name", 3)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, ok, true)
+
+		name, _ := db.Select(3).Str().Get("name")
+		testx.AssertEqual(t, name.String(), "alice")
+	})
+	t.Run("not found", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Select(3).Str().Set("name", "bob")
+		ok, err := kkey.Move("name", 3)
+		testx.AssertErr(t, err, core.ErrNotFound)
+		testx.AssertEqual(t, ok, false)
+	})
+}
+
 func TestPersist(t *testing.T) {
 	t.Run("persist", func(t *testing.T) {
 		db, kkey := getDB(t)
@@ -608,6 +1125,162 @@ func TestScanner(t *testing.T) {
 	testx.AssertEqual(t, keyNames, []string{"11", "12", "21", "22", "31"})
 }
 
+func TestSwapDB(t *testing.T) {
+	db, kkey := getDB(t)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("age", 25)
+	_ = db.Select(1).Str().Set("name", "bob")
+
+	err := kkey.SwapDB(0, 1)
+	testx.AssertNoErr(t, err)
+
+	name, _ := db.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+	exists, _ := kkey.Exists("age")
+	testx.AssertEqual(t, exists, false)
+	name, _ = db.Select(1).Str().Get("name")
+	testx.AssertEqual(t, name.String(), "alice")
+	age, _ := db.Select(1).Str().Get("age")
+	testx.AssertEqual(t, age.String(), "25")
+
+	// Swapping with an empty database moves the keys.
+	err = kkey.SwapDB(1, 7)
+	testx.AssertNoErr(t, err)
+	count, _ := db.Select(1).Key().Len()
+	testx.AssertEqual(t, count, 0)
+	count, _ = db.Select(7).Key().Len()
+	testx.AssertEqual(t, count, 2)
+}
+
+func TestSort(t *testing.T) {
+	t.Run("numeric", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.List().PushBack("ids", "3")
+		_, _ = db.List().PushBack("ids", "10")
+		_, _ = db.List().PushBack("ids", "1")
+
+		vals, err := kkey.SortWith("ids").Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{
+			core.Value("1"), core.Value("3"), core.Value("10"),
+		})
+
+		vals, err = kkey.SortWith("ids").Desc().Limit(1, 5).Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{core.Value("3"), core.Value("1")})
+	})
+	t.Run("alpha", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Set().Add("names", "bob", "alice", "cindy")
+
+		_, err := kkey.SortWith("names").Run()
+		testx.AssertErr(t, err, core.ErrValueType)
+
+		vals, err := kkey.SortWith("names").Alpha().Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{
+			core.Value("alice"), core.Value("bob"), core.Value("cindy"),
+		})
+	})
+	t.Run("by string", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.Set().Add("ids", "1", "2", "3")
+		_ = db.Str().Set("weight_1", 30)
+		_ = db.Str().Set("weight_2", 10)
+		_ = db.Str().Set("weight_3", 20)
+
+		vals, err := kkey.SortWith("ids").By("weight_*").Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{
+			core.Value("2"), core.Value("3"), core.Value("1"),
+		})
+	})
+	t.Run("by hash", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.ZSet().AddMany("ids", map[any]float64{"1": 1, "2": 2, "3": 3})
+		_, _ = db.Hash().Set("user_1", "name", "cindy")
+		_, _ = db.Hash().Set("user_2", "name", "alice")
+		_, _ = db.Hash().Set("user_3", "name", "bob")
+
+		vals, err := kkey.SortWith("ids").By("user_*->name").Alpha().
+			Get("#", "user_*->name").Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{
+			core.Value("2"), core.Value("alice"),
+			core.Value("3"), core.Value("bob"),
+			core.Value("1"), core.Value("cindy"),
+		})
+	})
+	t.Run("nosort", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.List().PushBack("ids", "3")
+		_, _ = db.List().PushBack("ids", "1")
+		_, _ = db.List().PushBack("ids", "2")
+		_ = db.Str().Set("name_1", "alice")
+
+		vals, err := kkey.SortWith("ids").By("nosort").Get("name_*").Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{nil, core.Value("alice"), nil})
+	})
+	t.Run("store", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_, _ = db.List().PushBack("ids", "3")
+		_, _ = db.List().PushBack("ids", "1")
+		_, _ = db.List().PushBack("ids", "2")
+		_ = db.Str().Set("name_1", "alice")
+		_ = db.Str().Set("sorted", "value")
+
+		n, err := kkey.SortWith("ids").Get("name_*").Store("sorted")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 3)
+
+		vals, _ := db.List().Range("sorted", 0, -1)
+		testx.AssertEqual(t, vals, []core.Value{
+			core.Value("alice"), core.Value(""), core.Value(""),
+		})
+		llen, _ := db.List().Len("sorted")
+		testx.AssertEqual(t, llen, 3)
+
+		// An empty result deletes the destination.
+		n, err = kkey.SortWith("missing").Store("sorted")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 0)
+		exists, _ := kkey.Exists("sorted")
+		testx.AssertEqual(t, exists, false)
+	})
+	t.Run("not found", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		vals, err := kkey.SortWith("ids").Run()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, vals, []core.Value{})
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, kkey := getDB(t)
+		defer db.Close()
+
+		_ = db.Str().Set("ids", "1")
+
+		_, err := kkey.SortWith("ids").Run()
+		testx.AssertErr(t, err, core.ErrKeyType)
+	})
+}
+
 func getDB(tb testing.TB) (*redka.DB, *rkey.DB) {
 	tb.Helper()
 	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
@@ -616,3 +1289,21 @@ func getDB(tb testing.TB) (*redka.DB, *rkey.DB) {
 	}
 	return db, db.Key()
 }
+
+func getChangeDB(tb testing.TB) (*redka.DB, *rkey.DB) {
+	tb.Helper()
+	db, err := redka.Open("file:/data.db?vfs=memdb", &redka.Options{ChangeLog: true})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db, db.Key()
+}
+
+// changed returns the changed keys in the logical database.
+func changed(db int, keys ...string) []rkey.ChangedKey {
+	res := make([]rkey.ChangedKey, len(keys))
+	for i, key := range keys {
+		res[i] = rkey.ChangedKey{DB: db, Key: key}
+	}
+	return res
+}
@@ -0,0 +1,418 @@
+package rkey
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc64"
+	"io"
+	"math"
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+const (
+	sqlDumpString = `
+	select value from rstring where kid = ?`
+
+	sqlDumpList = `
+	select elem from rlist where kid = ? order by pos`
+
+	sqlDumpSet = `
+	select elem from rset where kid = ? order by elem`
+
+	sqlDumpHash = `
+	select field, value, etime from rhash
+	where kid = ? and (etime is null or etime > ?)
+	order by field`
+
+	sqlDumpZSet = `
+	select elem, score from rzset where kid = ? order by elem`
+
+	sqlRestoreDelete = sqlCopyDelete
+	sqlRestoreKey1   = sqlCopyKey1
+	sqlRestoreKey2   = sqlCopyKey2
+
+	sqlRestoreString = `
+	insert into rstring (kid, value) values (?, ?)`
+
+	sqlRestoreList = `
+	insert into rlist (kid, pos, elem) values (?, ?, ?)`
+
+	sqlRestoreSet = `
+	insert into rset (kid, elem) values (?, ?)`
+
+	sqlRestoreHash = `
+	insert into rhash (kid, field, value, etime) values (?, ?, ?, ?)`
+
+	sqlRestoreZSet = `
+	insert into rzset (kid, elem, score) values (?, ?, ?)`
+)
+
+// dumpVersion is the version of the serialization format.
+// Dumps with a different version are rejected on restore.
+const dumpVersion = 1
+
+// dumpTable is the checksum table for dumps.
+var dumpTable = crc64.MakeTable(crc64.ECMA)
+
+// A dump is a serialized key value, laid out as follows:
+//
+//	version  1 byte
+//	type     1 byte (core.TypeID)
+//	etime    optional int64 (unix milliseconds)
+//	count    uvarint (number of entries)
+//	entries  count entries, depending on the type:
+//	         string  value bytes (count is always 1)
+//	         list    elem bytes (in list order)
+//	         set     elem bytes
+//	         hash    field bytes, value bytes, optional int64 etime
+//	         zset    elem bytes, score float64
+//	checksum 8 bytes (CRC-64/ECMA of the above, little-endian)
+//
+// Bytes are encoded as a uvarint length followed by the data.
+// Optional int64 values are encoded as a 0 byte (absent)
+// or as a 1 byte followed by a varint (present).
+// Floats are encoded as 8 bytes (IEEE 754, little-endian).
+
+// dumpEntry is a single entry of a dumped value.
+// Only the fields relevant to the key type are set.
+type dumpEntry struct {
+	elem  []byte
+	value []byte
+	score float64
+	etime *int64
+}
+
+// dumpValue is a decoded dump.
+type dumpValue struct {
+	ktype   core.TypeID
+	etime   *int64
+	entries []dumpEntry
+}
+
+// Dump serializes the value stored at the key, along with
+// its expiration time, in a versioned, checksummed format.
+// Use [Tx.Restore] to create a key from the serialized value.
+// If the key does not exist, returns ErrNotFound.
+func (tx *Tx) Dump(key string) ([]byte, error) {
+	k, err := tx.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := tx.dumpEntries(k)
+	if err != nil {
+		return nil, err
+	}
+	val := dumpValue{ktype: k.Type, etime: k.ETime, entries: entries}
+	return val.encode(), nil
+}
+
+// Restore creates the key from a value serialized by [Tx.Dump].
+// The key gets the expiration time stored in the dump.
+// If the key already exists, returns ErrNotAllowed.
+// If the serialized value is invalid, returns ErrValueType.
+func (tx *Tx) Restore(key string, data []byte) error {
+	return tx.RestoreWith(key, data).Run()
+}
+
+// RestoreWith creates the key from a value serialized by [Tx.Dump].
+// Use the returned command to override the expiration time
+// or to replace an existing key.
+func (tx *Tx) RestoreWith(key string, data []byte) RestoreCmd {
+	return RestoreCmd{tx: tx, key: key, data: data}
+}
+
+// dumpEntries reads the value rows of the key.
+func (tx *Tx) dumpEntries(k core.Key) ([]dumpEntry, error) {
+	var query string
+	args := []any{k.ID}
+	switch k.Type {
+	case core.TypeString:
+		query = sqlDumpString
+	case core.TypeList:
+		query = sqlDumpList
+	case core.TypeSet:
+		query = sqlDumpSet
+	case core.TypeHash:
+		query = sqlDumpHash
+		args = append(args, time.Now().UnixMilli())
+	case core.TypeZSet:
+		query = sqlDumpZSet
+	default:
+		return nil, core.ErrKeyType
+	}
+
+	rows, err := tx.tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []dumpEntry
+	for rows.Next() {
+		var e dumpEntry
+		switch k.Type {
+		case core.TypeHash:
+			var field string
+			err = rows.Scan(&field, &e.value, &e.etime)
+			e.elem = []byte(field)
+		case core.TypeZSet:
+			err = rows.Scan(&e.elem, &e.score)
+		default:
+			err = rows.Scan(&e.elem)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// RestoreCmd creates a key from a serialized value.
+type RestoreCmd struct {
+	db      *DB
+	tx      *Tx
+	key     string
+	data    []byte
+	ttl     time.Duration
+	at      time.Time
+	replace bool
+}
+
+// TTL sets the time-to-live for the key,
+// overriding the expiration time stored in the dump.
+func (c RestoreCmd) TTL(ttl time.Duration) RestoreCmd {
+	c.ttl = ttl
+	c.at = time.Time{}
+	return c
+}
+
+// At sets the expiration time for the key,
+// overriding the expiration time stored in the dump.
+func (c RestoreCmd) At(at time.Time) RestoreCmd {
+	c.ttl = 0
+	c.at = at
+	return c
+}
+
+// Replace instructs to overwrite the key
+// if it already exists, regardless of its type.
+func (c RestoreCmd) Replace() RestoreCmd {
+	c.replace = true
+	return c
+}
+
+// Run creates the key from the serialized value.
+// If the resulting expiration time is in the past,
+// does not create the key (but still removes the
+// existing one if replacing).
+// If the key already exists and replace is not set, returns ErrNotAllowed.
+// If the serialized value is invalid, returns ErrValueType.
+func (c RestoreCmd) Run() error {
+	if c.db != nil {
+		return c.db.Update(func(tx *Tx) error {
+			return c.run(tx)
+		})
+	}
+	if c.tx != nil {
+		return c.run(c.tx)
+	}
+	return nil
+}
+
+func (c RestoreCmd) run(tx *Tx) error {
+	val, err := decodeDump(c.data)
+	if err != nil {
+		return err
+	}
+
+	// Decide on the expiration time.
+	now := time.Now()
+	etime := val.etime
+	if c.ttl > 0 {
+		at := now.Add(c.ttl).UnixMilli()
+		etime = &at
+	} else if !c.at.IsZero() {
+		at := c.at.UnixMilli()
+		etime = &at
+	}
+
+	// Make sure the key does not exist,
+	// or remove it if replacing.
+	exists, err := tx.Exists(c.key)
+	if err != nil {
+		return err
+	}
+	if exists && !c.replace {
+		return core.ErrNotAllowed
+	}
+	if _, err := tx.tx.Exec(sqlRestoreDelete, c.key); err != nil {
+		return err
+	}
+	if etime != nil && *etime <= now.UnixMilli() {
+		return nil
+	}
+
+	// Create the key and its values,
+	// then set the length (the value triggers may have changed it).
+	var kid int
+	err = tx.tx.QueryRow(sqlRestoreKey1, c.key, val.ktype, etime, now.UnixMilli()).Scan(&kid)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for i, e := range val.entries {
+		switch val.ktype {
+		case core.TypeString:
+			_, err = tx.tx.Exec(sqlRestoreString, kid, e.elem)
+		case core.TypeList:
+			_, err = tx.tx.Exec(sqlRestoreList, kid, i, e.elem)
+		case core.TypeSet:
+			_, err = tx.tx.Exec(sqlRestoreSet, kid, e.elem)
+		case core.TypeHash:
+			if e.etime != nil && *e.etime <= now.UnixMilli() {
+				continue
+			}
+			_, err = tx.tx.Exec(sqlRestoreHash, kid, string(e.elem), e.value, e.etime)
+		case core.TypeZSet:
+			_, err = tx.tx.Exec(sqlRestoreZSet, kid, e.elem, e.score)
+		}
+		if err != nil {
+			return err
+		}
+		n++
+	}
+
+	var size any = n
+	if val.ktype == core.TypeString {
+		size = nil
+	}
+	_, err = tx.tx.Exec(sqlRestoreKey2, size, kid)
+	return err
+}
+
+// encode serializes the value (see the format description above).
+func (v dumpValue) encode() []byte {
+	var buf []byte
+	buf = append(buf, dumpVersion, byte(v.ktype))
+	buf = appendOptInt(buf, v.etime)
+	buf = binary.AppendUvarint(buf, uint64(len(v.entries)))
+	for _, e := range v.entries {
+		buf = appendBytes(buf, e.elem)
+		switch v.ktype {
+		case core.TypeHash:
+			buf = appendBytes(buf, e.value)
+			buf = appendOptInt(buf, e.etime)
+		case core.TypeZSet:
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(e.score))
+		}
+	}
+	return binary.LittleEndian.AppendUint64(buf, crc64.Checksum(buf, dumpTable))
+}
+
+// decodeDump deserializes and validates the value.
+// Returns ErrValueType if the data is not a valid dump.
+func decodeDump(data []byte) (dumpValue, error) {
+	// Check the version and the checksum.
+	if len(data) < 2+8 || data[0] != dumpVersion {
+		return dumpValue{}, core.ErrValueType
+	}
+	payload, sum := data[:len(data)-8], data[len(data)-8:]
+	if crc64.Checksum(payload, dumpTable) != binary.LittleEndian.Uint64(sum) {
+		return dumpValue{}, core.ErrValueType
+	}
+
+	// Decode the value.
+	v := dumpValue{ktype: core.TypeID(payload[1])}
+	if v.ktype < core.TypeString || v.ktype > core.TypeZSet {
+		return dumpValue{}, core.ErrValueType
+	}
+	r := bytes.NewReader(payload[2:])
+	var err error
+	if v.etime, err = readOptInt(r); err != nil {
+		return dumpValue{}, core.ErrValueType
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil || count > uint64(r.Len()) {
+		return dumpValue{}, core.ErrValueType
+	}
+	if v.ktype == core.TypeString && count != 1 {
+		return dumpValue{}, core.ErrValueType
+	}
+	v.entries = make([]dumpEntry, count)
+	for i := range v.entries {
+		e := &v.entries[i]
+		if e.elem, err = readBytes(r); err != nil {
+			return dumpValue{}, core.ErrValueType
+		}
+		switch v.ktype {
+		case core.TypeHash:
+			if e.value, err = readBytes(r); err != nil {
+				return dumpValue{}, core.ErrValueType
+			}
+			if e.etime, err = readOptInt(r); err != nil {
+				return dumpValue{}, core.ErrValueType
+			}
+		case core.TypeZSet:
+			var bits uint64
+			if err = binary.Read(r, binary.LittleEndian, &bits); err != nil {
+				return dumpValue{}, core.ErrValueType
+			}
+			e.score = math.Float64frombits(bits)
+		}
+	}
+	if r.Len() != 0 {
+		return dumpValue{}, core.ErrValueType
+	}
+	return v, nil
+}
+
+// appendBytes appends a length-prefixed byte slice.
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// appendOptInt appends an optional integer.
+func appendOptInt(buf []byte, n *int64) []byte {
+	if n == nil {
+		return append(buf, 0)
+	}
+	buf = append(buf, 1)
+	return binary.AppendVarint(buf, *n)
+}
+
+// readBytes reads a length-prefixed byte slice.
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, core.ErrValueType
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+// readOptInt reads an optional integer.
+func readOptInt(r *bytes.Reader) (*int64, error) {
+	flag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch flag {
+	case 0:
+		return nil, nil
+	case 1:
+		n, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		return &n, nil
+	}
+	return nil, core.ErrValueType
+}
@@ -0,0 +1,397 @@
+package rkey
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+const (
+	sqlSortList = `select elem, pos as ord from rlist where kid = ?`
+	sqlSortSet  = `select elem, elem as ord from rset where kid = ?`
+	sqlSortZSet = `select elem, score as ord from rzset where kid = ?`
+
+	sqlSortJoinString = `
+	left join rkey k%[1]d on k%[1]d.db = :db and k%[1]d.key = ? || src.elem || ?
+		and k%[1]d.type = 1 and (k%[1]d.etime is null or k%[1]d.etime > ?)
+	left join rstring v%[1]d on v%[1]d.kid = k%[1]d.id`
+
+	sqlSortJoinHash = `
+	left join rkey k%[1]d on k%[1]d.db = :db and k%[1]d.key = ? || src.elem || ?
+		and k%[1]d.type = 4 and (k%[1]d.etime is null or k%[1]d.etime > ?)
+	left join rhash v%[1]d on v%[1]d.kid = k%[1]d.id
+		and v%[1]d.field = ? and (v%[1]d.etime is null or v%[1]d.etime > ?)`
+
+	sqlSortStore1 = `
+	delete from rkey where db = :db and key = ?`
+
+	sqlSortStore2 = `
+	insert into rkey (db, key, type, version, mtime, len)
+	values (:db, ?, 2, 1, ?, ?)
+	returning id`
+
+	sqlSortStore3 = `
+	insert into rlist (kid, pos, elem)
+	values (?, ?, ?)`
+)
+
+// sortSources are the queries that select
+// the elements to sort for each key type.
+var sortSources = map[core.TypeID]string{
+	core.TypeList: sqlSortList,
+	core.TypeSet:  sqlSortSet,
+	core.TypeZSet: sqlSortZSet,
+}
+
+// SortCmd sorts the elements of a list, set or sorted set.
+// Optionally looks up the sort weights and the returned values
+// in other keys using patterns (see [SortCmd.By] and [SortCmd.Get]).
+type SortCmd struct {
+	db     *DB
+	tx     *Tx
+	key    string
+	by     string
+	get    []string
+	offset int
+	count  int
+	desc   bool
+	alpha  bool
+}
+
+// By sets the pattern used to look up the sort weights.
+// The first * in the pattern is replaced with the element,
+// so that "weight_*" sorts the element "1" by the value
+// of the "weight_1" string key. The "key->field" form looks up
+// the field of a hash key instead (as in "user_*->age").
+// If the pattern contains no *, the elements are not sorted.
+func (c SortCmd) By(pattern string) SortCmd {
+	c.by = pattern
+	return c
+}
+
+// Get adds patterns used to look up the returned values
+// instead of the elements themselves. Patterns use the same
+// syntax as [SortCmd.By]. The "#" pattern returns the element.
+// Values that do not exist are returned as nil.
+func (c SortCmd) Get(patterns ...string) SortCmd {
+	c.get = append(c.get, patterns...)
+	return c
+}
+
+// Limit sets the offset and the maximum number of
+// sorted elements to return. A negative count means no limit.
+func (c SortCmd) Limit(offset, count int) SortCmd {
+	c.offset = offset
+	c.count = count
+	return c
+}
+
+// Desc sets the sorting direction to descending.
+func (c SortCmd) Desc() SortCmd {
+	c.desc = true
+	return c
+}
+
+// Alpha instructs to sort lexicographically
+// instead of numerically.
+func (c SortCmd) Alpha() SortCmd {
+	c.alpha = true
+	return c
+}
+
+// Run returns the sorted elements (or the values looked up
+// with the [SortCmd.Get] patterns, one per pattern per element).
+// If the key does not exist, returns an empty slice.
+// If the key is not a list, set or sorted set, returns ErrKeyType.
+// If sorting numerically and some weight is not a number,
+// returns ErrValueType.
+func (c SortCmd) Run() ([]core.Value, error) {
+	if c.db != nil {
+		var vals []core.Value
+		err := c.db.View(func(tx *Tx) error {
+			var err error
+			vals, err = c.run(tx)
+			return err
+		})
+		return vals, err
+	}
+	if c.tx != nil {
+		return c.run(c.tx)
+	}
+	return nil, nil
+}
+
+// Store sorts the elements like [SortCmd.Run] does, and stores
+// the result as a list in the dest key, overwriting it regardless
+// of its type. Values that do not exist are stored as empty strings.
+// If the result is empty, deletes the dest key.
+// Returns the number of elements stored.
+func (c SortCmd) Store(dest string) (int, error) {
+	if c.db != nil {
+		var n int
+		err := c.db.Update(func(tx *Tx) error {
+			var err error
+			n, err = c.store(tx, dest)
+			return err
+		})
+		return n, err
+	}
+	if c.tx != nil {
+		return c.store(c.tx, dest)
+	}
+	return 0, nil
+}
+
+// store sorts the elements and stores them in the dest key.
+func (c SortCmd) store(tx *Tx, dest string) (int, error) {
+	vals, err := c.run(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.tx.Exec(sqlSortStore1, dest); err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, nil
+	}
+
+	var kid int
+	now := time.Now().UnixMilli()
+	err = tx.tx.QueryRow(sqlSortStore2, dest, now, len(vals)).Scan(&kid)
+	if err != nil {
+		return 0, err
+	}
+	for i, val := range vals {
+		if val == nil {
+			val = core.Value{}
+		}
+		if _, err := tx.tx.Exec(sqlSortStore3, kid, i, []byte(val)); err != nil {
+			return 0, err
+		}
+	}
+	return len(vals), nil
+}
+
+// sortRow is a sorted element along with
+// its weight and the looked up values.
+type sortRow struct {
+	elem   core.Value
+	weight core.Value
+	score  float64
+	vals   []core.Value
+}
+
+// run selects, sorts and limits the elements.
+func (c SortCmd) run(tx *Tx) ([]core.Value, error) {
+	key, err := tx.Get(c.key)
+	if err == core.ErrNotFound {
+		return []core.Value{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	source, ok := sortSources[key.Type]
+	if !ok {
+		return nil, core.ErrKeyType
+	}
+
+	// Select the elements along with the weights and the values.
+	dosort := c.by == "" || strings.Contains(c.by, "*")
+	var patterns []pattern
+	if dosort && c.by != "" {
+		patterns = append(patterns, parsePattern(c.by))
+	}
+	for _, get := range c.get {
+		patterns = append(patterns, parsePattern(get))
+	}
+	rows, err := c.selectRows(tx, key.ID, source, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort the elements.
+	if dosort {
+		if err := c.sort(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply the limit.
+	start, end := c.offset, len(rows)
+	if start < 0 {
+		start = 0
+	}
+	if start > len(rows) {
+		start = len(rows)
+	}
+	if c.count >= 0 && start+c.count < end {
+		end = start + c.count
+	}
+	rows = rows[start:end]
+
+	// Collect the results.
+	if len(c.get) == 0 {
+		vals := make([]core.Value, len(rows))
+		for i, row := range rows {
+			vals[i] = row.elem
+		}
+		return vals, nil
+	}
+	vals := make([]core.Value, 0, len(rows)*len(c.get))
+	for _, row := range rows {
+		vals = append(vals, row.vals...)
+	}
+	return vals, nil
+}
+
+// selectRows selects the elements of the key and looks up
+// the values for the patterns in a single query.
+// If sorting by pattern, the first pattern is the weight.
+func (c SortCmd) selectRows(tx *Tx, kid int, source string, patterns []pattern) ([]sortRow, error) {
+	now := time.Now().UnixMilli()
+	var cols, joins strings.Builder
+	args := []any{kid}
+	for i, p := range patterns {
+		switch {
+		case p.self:
+			cols.WriteString(", src.elem")
+		case p.none:
+			cols.WriteString(", null")
+		case p.hash:
+			fmt.Fprintf(&cols, ", v%d.value", i)
+			fmt.Fprintf(&joins, sqlSortJoinHash, i)
+			args = append(args, p.prefix, p.suffix, now, p.field, now)
+		default:
+			fmt.Fprintf(&cols, ", v%d.value", i)
+			fmt.Fprintf(&joins, sqlSortJoinString, i)
+			args = append(args, p.prefix, p.suffix, now)
+		}
+	}
+	query := "select src.elem" + cols.String() +
+		" from (" + source + ") as src" + joins.String() +
+		" order by src.ord, src.elem"
+
+	sqlRows, err := tx.tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlRows.Close()
+
+	withWeight := len(patterns) > len(c.get)
+	var rows []sortRow
+	for sqlRows.Next() {
+		dest := make([]any, len(patterns)+1)
+		for i := range dest {
+			dest[i] = new(sql.RawBytes)
+		}
+		if err := sqlRows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		vals := make([]core.Value, len(dest))
+		for i, d := range dest {
+			raw := *d.(*sql.RawBytes)
+			if raw != nil {
+				vals[i] = bytes.Clone(raw)
+			}
+		}
+		row := sortRow{elem: vals[0], weight: vals[0], vals: vals[1:]}
+		if withWeight {
+			row.weight = vals[1]
+			row.vals = vals[2:]
+		}
+		rows = append(rows, row)
+	}
+	if err := sqlRows.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// sort sorts the rows by weight, then by element.
+func (c SortCmd) sort(rows []sortRow) error {
+	if !c.alpha {
+		for i := range rows {
+			if rows[i].weight == nil {
+				continue
+			}
+			score, err := strconv.ParseFloat(string(rows[i].weight), 64)
+			if err != nil {
+				return core.ErrValueType
+			}
+			rows[i].score = score
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b sortRow) int {
+		var cmp int
+		if c.alpha {
+			cmp = compareValues(a.weight, b.weight)
+		} else if a.score < b.score {
+			cmp = -1
+		} else if a.score > b.score {
+			cmp = 1
+		}
+		if cmp == 0 {
+			cmp = bytes.Compare(a.elem, b.elem)
+		}
+		if c.desc {
+			cmp = -cmp
+		}
+		return cmp
+	})
+	return nil
+}
+
+// compareValues compares two values lexicographically.
+// Missing (nil) values are less than any other value.
+func compareValues(a, b core.Value) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
+
+// pattern is a compiled sort lookup pattern.
+type pattern struct {
+	self   bool   // "#", the element itself
+	none   bool   // no * in the pattern, nothing to look up
+	hash   bool   // a hash field lookup
+	prefix string // key name part before the *
+	suffix string // key name part after the *
+	field  string // hash field name
+}
+
+// parsePattern compiles a sort lookup pattern,
+// such as "weight_*" or "user_*->age".
+func parsePattern(s string) pattern {
+	if s == "#" {
+		return pattern{self: true}
+	}
+	prefix, rest, found := strings.Cut(s, "*")
+	if !found {
+		return pattern{none: true}
+	}
+	suffix, field, found := strings.Cut(rest, "->")
+	if !found || field == "" {
+		return pattern{prefix: prefix, suffix: rest}
+	}
+	return pattern{hash: true, prefix: prefix, suffix: suffix, field: field}
+}
+
+// SortWith sorts the elements of a list, set or sorted set.
+// Use the returned command to specify the patterns and options.
+func (tx *Tx) SortWith(key string) SortCmd {
+	return SortCmd{tx: tx, key: key, count: -1}
+}
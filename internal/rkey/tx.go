@@ -10,15 +10,50 @@ import (
 )
 
 const (
+	sqlCopyGet = `
+	select id, type, etime, len from rkey
+	where db = :db and key = ? and (etime is null or etime > ?)`
+
+	sqlCopyDelete = `
+	delete from rkey where db = :db and key = ?`
+
+	sqlCopyKey1 = `
+	insert into rkey (db, key, type, version, etime, mtime, len)
+	values (:db, ?, ?, 1, ?, ?, 0)
+	returning id`
+
+	sqlCopyKey2 = `
+	update rkey set len = ?
+	where id = ?`
+
 	sqlCount = `
 	select count(id) from rkey
-	where key in (:keys) and (etime is null or etime > ?)`
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))`
+
+	sqlCounts = `
+	select type, count(*), count(etime) from rkey
+	where db = :db and (etime is null or etime > ?)
+	group by type`
+
+	sqlDBCounts = `
+	select db, count(*), count(etime) from rkey
+	where etime is null or etime > ?
+	group by db`
 
 	sqlDelete = `
 	delete from rkey
-	where key in (:keys) and (etime is null or etime > ?)`
+	where db = :db and key in (:keys) and (etime is null or etime > ?)`
 
 	sqlDeleteAll = `
+	delete from rkey where db = :db;
+	vacuum;
+	pragma integrity_check;`
+
+	sqlDeleteAllDatabases = `
 	delete from rkey;
 	vacuum;
 	pragma integrity_check;`
@@ -39,29 +74,67 @@ const (
 	update rkey set
 		version = version + 1,
 		etime = ?
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (etime is null or etime > ?)`
+
+	sqlExpireNX = ` and etime is null`
+	sqlExpireXX = ` and etime is not null`
+	sqlExpireGT = ` and etime is not null and etime < ?`
+	sqlExpireLT = ` and (etime is null or etime > ?)`
 
 	sqlGet = `
 	select id, key, type, version, etime, mtime
 	from rkey
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (etime is null or etime > ?)
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))`
 
 	sqlKeys = `
 	select id, key, type, version, etime, mtime from rkey
-	where key glob ? and (etime is null or etime > ?)`
+	where db = :db and key glob ? and (etime is null or etime > ?)
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))
+	order by id`
 
 	sqlLen = `
-	select count(*) from rkey`
+	select count(*) from rkey
+	where db = :db
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))`
+
+	sqlMove1 = `
+	delete from rkey
+	where db = ? and key = ? and etime <= ?`
+
+	sqlMove2 = `
+	select count(*) from rkey
+	where db = ? and key = ?`
+
+	sqlMove3 = `
+	update rkey set
+		db = ?,
+		version = version + 1,
+		mtime = ?
+	where db = :db and key = ? and (etime is null or etime > ?)`
 
 	sqlPersist = `
 	update rkey set
 		version = version + 1,
 		etime = null
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (etime is null or etime > ?)`
 
 	sqlRandom = `
 	select id, key, type, version, etime, mtime from rkey
-	where etime is null or etime > ?
+	where db = :db and (etime is null or etime > ?)
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))
 	order by random() limit 1`
 
 	sqlRename = `
@@ -75,17 +148,54 @@ const (
 	from (
 		select id, key, type, version, etime, mtime
 		from rkey
-		where key = ? and (etime is null or etime > ?)
+		where db = :db and key = ? and (etime is null or etime > ?)
 	) as old
-	where rkey.key = ? and (rkey.etime is null or rkey.etime > ?)`
+	where rkey.db = :db and rkey.key = ? and (rkey.etime is null or rkey.etime > ?)`
 
 	sqlScan = `
 	select id, key, type, version, etime, mtime from rkey
 	where
-		id > ? and key glob ? and (type = ? or true)
+		id > ? and db = :db and key glob ? and (type = ? or true)
 		and (etime is null or etime > ?)
+		and (type <> 4 or len = 0 or exists (
+			select 1 from rhash
+			where kid = rkey.id and (rhash.etime is null or rhash.etime > ?)
+		))
 	order by id asc
 	limit ?`
+
+	sqlSwapDB = `
+	update rkey set db = ? where db = ?`
+)
+
+// copyValues are the queries that duplicate
+// the value rows of a key for each key type.
+var copyValues = map[core.TypeID]string{
+	core.TypeString: `
+	insert into rstring (kid, value)
+	select ?, value from rstring where kid = ?`,
+	core.TypeList: `
+	insert into rlist (kid, pos, elem)
+	select ?, pos, elem from rlist where kid = ?`,
+	core.TypeSet: `
+	insert into rset (kid, elem)
+	select ?, elem from rset where kid = ?`,
+	core.TypeHash: `
+	insert into rhash (kid, field, value, etime)
+	select ?, field, value, etime from rhash where kid = ?`,
+	core.TypeZSet: `
+	insert into rzset (kid, elem, score)
+	select ?, elem, score from rzset where kid = ?`,
+}
+
+// ExpireCond is a condition for setting the expiration time of a key.
+type ExpireCond int
+
+const (
+	IfNoTTL   ExpireCond = iota + 1 // only if the key has no expiration time (NX)
+	IfHasTTL                        // only if the key has an expiration time (XX)
+	IfGreater                       // only if the new time is greater than the current one (GT)
+	IfLess                          // only if the new time is less than the current one (LT)
 )
 
 const scanPageSize = 10
@@ -101,11 +211,68 @@ func NewTx(tx sqlx.Tx) *Tx {
 	return &Tx{tx}
 }
 
+// Copy copies the key and its value to a new key.
+// Returns true if the key was copied, false if the destination
+// key already exists and replace is false.
+// If replace is true, overwrites the destination key regardless of its type.
+// If the source key does not exist, returns ErrNotFound.
+// If the source and destination keys are the same, returns ErrNotAllowed.
+func (tx *Tx) Copy(src, dst string, replace bool) (bool, error) {
+	now := time.Now().UnixMilli()
+
+	// Make sure the source key exists.
+	var id int
+	var ktype core.TypeID
+	var etime *int64
+	var size *int // nil for strings
+	err := tx.tx.QueryRow(sqlCopyGet, src, now).Scan(&id, &ktype, &etime, &size)
+	if err == sql.ErrNoRows {
+		return false, core.ErrNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if src == dst {
+		return false, core.ErrNotAllowed
+	}
+
+	// Make sure the destination key does not exist,
+	// or remove it if replacing. Expired keys are removed as well,
+	// so that the new key name is free.
+	exists, err := tx.Exists(dst)
+	if err != nil {
+		return false, err
+	}
+	if exists && !replace {
+		return false, nil
+	}
+	if _, err := tx.tx.Exec(sqlCopyDelete, dst); err != nil {
+		return false, err
+	}
+
+	// Create the destination key, copy the values,
+	// and set the length (the value triggers may have changed it).
+	var newID int
+	err = tx.tx.QueryRow(sqlCopyKey1, dst, ktype, etime, now).Scan(&newID)
+	if err != nil {
+		return false, err
+	}
+	if query, ok := copyValues[ktype]; ok {
+		if _, err := tx.tx.Exec(query, newID, id); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.tx.Exec(sqlCopyKey2, size, newID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Count returns the number of existing keys among specified.
 func (tx *Tx) Count(keys ...string) (int, error) {
 	now := time.Now().UnixMilli()
 	query, keyArgs := sqlx.ExpandIn(sqlCount, ":keys", keys)
-	args := append(keyArgs, now)
+	args := append(keyArgs, now, now)
 	var count int
 	err := tx.tx.QueryRow(query, args...).Scan(&count)
 	return count, err
@@ -132,6 +299,14 @@ func (tx *Tx) DeleteAll() error {
 	return err
 }
 
+// DeleteAllDatabases deletes all keys and their values
+// in all logical databases, not only the selected one.
+// Should not be run inside a database transaction.
+func (tx *Tx) DeleteAllDatabases() error {
+	_, err := tx.tx.Exec(sqlDeleteAllDatabases)
+	return err
+}
+
 // Exists reports whether the key exists.
 func (tx *Tx) Exists(key string) (bool, error) {
 	count, err := tx.Count(key)
@@ -140,32 +315,66 @@ func (tx *Tx) Exists(key string) (bool, error) {
 
 // Expire sets a time-to-live (ttl) for the key using a relative duration.
 // After the ttl passes, the key is expired and no longer exists.
+// Optional conditions (see [ExpireCond]) restrict when the ttl is set.
 // If the key does not exist, returns ErrNotFound.
-func (tx *Tx) Expire(key string, ttl time.Duration) error {
+// If the conditions are not met, returns ErrNotAllowed.
+func (tx *Tx) Expire(key string, ttl time.Duration, conds ...ExpireCond) error {
 	at := time.Now().Add(ttl)
-	return tx.ExpireAt(key, at)
+	return tx.ExpireAt(key, at, conds...)
 }
 
 // ExpireAt sets an expiration time for the key. After this time,
 // the key is expired and no longer exists.
+// Optional conditions (see [ExpireCond]) restrict when the time is set.
 // If the key does not exist, returns ErrNotFound.
-func (tx *Tx) ExpireAt(key string, at time.Time) error {
-	args := []any{at.UnixMilli(), key, time.Now().UnixMilli()}
-	res, err := tx.tx.Exec(sqlExpire, args...)
+// If the conditions are not met, returns ErrNotAllowed.
+func (tx *Tx) ExpireAt(key string, at time.Time, conds ...ExpireCond) error {
+	etime := at.UnixMilli()
+	query := sqlExpire
+	args := []any{etime, key, time.Now().UnixMilli()}
+	for _, cond := range conds {
+		switch cond {
+		case IfNoTTL:
+			query += sqlExpireNX
+		case IfHasTTL:
+			query += sqlExpireXX
+		case IfGreater:
+			query += sqlExpireGT
+			args = append(args, etime)
+		case IfLess:
+			query += sqlExpireLT
+			args = append(args, etime)
+		}
+	}
+
+	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return err
 	}
 	count, _ := res.RowsAffected()
-	if count == 0 {
+	if count > 0 {
+		return nil
+	}
+	if len(conds) == 0 {
 		return core.ErrNotFound
 	}
-	return nil
+
+	// Tell a missing key from an unmet condition.
+	exists, err := tx.Exists(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return core.ErrNotFound
+	}
+	return core.ErrNotAllowed
 }
 
 // Get returns a specific key with all associated details.
 // If the key does not exist, returns ErrNotFound.
 func (tx *Tx) Get(key string) (core.Key, error) {
-	args := []any{key, time.Now().UnixMilli()}
+	now := time.Now().UnixMilli()
+	args := []any{key, now, now}
 	var k core.Key
 	err := tx.tx.QueryRow(sqlGet, args...).Scan(
 		&k.ID, &k.Key, &k.Type, &k.Version, &k.ETime, &k.MTime,
@@ -184,7 +393,8 @@ func (tx *Tx) Get(key string) (core.Key, error) {
 // Use this method only if you are sure that the number of keys is
 // limited. Otherwise, use the [Tx.Scan] or [Tx.Scanner] methods.
 func (tx *Tx) Keys(pattern string) ([]core.Key, error) {
-	args := []any{pattern, time.Now().UnixMilli()}
+	now := time.Now().UnixMilli()
+	args := []any{pattern, now, now}
 	scan := func(rows *sql.Rows) (core.Key, error) {
 		var k core.Key
 		err := rows.Scan(&k.ID, &k.Key, &k.Type, &k.Version, &k.ETime, &k.MTime)
@@ -195,16 +405,99 @@ func (tx *Tx) Keys(pattern string) ([]core.Key, error) {
 	return keys, err
 }
 
-// Len returns the total number of keys, including expired ones.
+// Len returns the total number of keys, including expired ones
+// (but not hashes whose fields have all expired).
 func (tx *Tx) Len() (int, error) {
 	var n int
-	err := tx.tx.QueryRow(sqlLen).Scan(&n)
+	err := tx.tx.QueryRow(sqlLen, time.Now().UnixMilli()).Scan(&n)
 	if err != nil {
 		return 0, err
 	}
 	return n, nil
 }
 
+// Counts returns the number of keys (total, with
+// an expiration time, and by type), excluding expired ones.
+func (tx *Tx) Counts() (KeyCounts, error) {
+	now := time.Now().UnixMilli()
+	rows, err := tx.tx.Query(sqlCounts, now)
+	if err != nil {
+		return KeyCounts{}, err
+	}
+	defer rows.Close()
+
+	counts := KeyCounts{Types: map[core.TypeID]int{}}
+	for rows.Next() {
+		var typ core.TypeID
+		var n, expires int
+		if err := rows.Scan(&typ, &n, &expires); err != nil {
+			return KeyCounts{}, err
+		}
+		counts.Keys += n
+		counts.Expires += expires
+		counts.Types[typ] = n
+	}
+	return counts, rows.Err()
+}
+
+// DBCounts returns the number of keys (total and with
+// an expiration time) in each logical database that has keys,
+// excluding expired ones. Does not count the keys by type.
+func (tx *Tx) DBCounts() (map[int]KeyCounts, error) {
+	now := time.Now().UnixMilli()
+	rows, err := tx.tx.Query(sqlDBCounts, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int]KeyCounts{}
+	for rows.Next() {
+		var db, n, expires int
+		if err := rows.Scan(&db, &n, &expires); err != nil {
+			return nil, err
+		}
+		counts[db] = KeyCounts{Keys: n, Expires: expires}
+	}
+	return counts, rows.Err()
+}
+
+// Move moves the key to another logical database.
+// Returns true if the key was moved, false if the key
+// already exists in the target database.
+// If the key does not exist, returns ErrNotFound.
+func (tx *Tx) Move(key string, index int) (bool, error) {
+	// Expired keys in the target database are removed,
+	// so that the key name is free.
+	now := time.Now().UnixMilli()
+	if _, err := tx.tx.Exec(sqlMove1, index, key, now); err != nil {
+		return false, err
+	}
+	var count int
+	err := tx.tx.QueryRow(sqlMove2, index, key).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := tx.Exists(key)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, core.ErrNotFound
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	res, err := tx.tx.Exec(sqlMove3, index, now, key, now)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
 // Persist removes the expiration time for the key.
 // If the key does not exist, returns ErrNotFound.
 func (tx *Tx) Persist(key string) error {
@@ -225,7 +518,7 @@ func (tx *Tx) Persist(key string) error {
 func (tx *Tx) Random() (core.Key, error) {
 	now := time.Now().UnixMilli()
 	var k core.Key
-	err := tx.tx.QueryRow(sqlRandom, now).Scan(
+	err := tx.tx.QueryRow(sqlRandom, now, now).Scan(
 		&k.ID, &k.Key, &k.Type, &k.Version, &k.ETime, &k.MTime,
 	)
 	if err == sql.ErrNoRows {
@@ -311,6 +604,25 @@ func (tx *Tx) RenameNotExists(key, newKey string) (bool, error) {
 	return err == nil, err
 }
 
+// SwapDB swaps the keys of two logical databases,
+// so that the clients working with one database
+// see the keys of the other one.
+func (tx *Tx) SwapDB(index1, index2 int) error {
+	if index1 == index2 {
+		return nil
+	}
+	// Swap through a temporary index, so that
+	// the keys of the databases don't conflict.
+	const tmp = -1
+	moves := [][2]int{{tmp, index1}, {index1, index2}, {index2, tmp}}
+	for _, m := range moves {
+		if _, err := tx.tx.Exec(sqlSwapDB, m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Scan iterates over keys matching pattern.
 // Returns a slice of keys (see [core.Key]) of size count
 // based on the current state of the cursor.
@@ -328,11 +640,13 @@ func (tx *Tx) Scan(cursor int, pattern string, ktype core.TypeID, count int) (Sc
 	if ktype != core.TypeAny {
 		query = strings.Replace(query, "(type = ? or true)", "(type = ?)", 1)
 	}
+	now := time.Now().UnixMilli()
 	args := []any{
 		cursor,
 		pattern,
 		int(ktype),
-		time.Now().UnixMilli(),
+		now,
+		now,
 		count,
 	}
 	scan := func(rows *sql.Rows) (core.Key, error) {
@@ -374,6 +688,13 @@ type ScanResult struct {
 	Keys   []core.Key
 }
 
+// KeyCounts represents a result of the Counts call.
+type KeyCounts struct {
+	Keys    int                 // number of keys
+	Expires int                 // number of keys with an expiration time
+	Types   map[core.TypeID]int // number of keys by type
+}
+
 // deleteExpired deletes keys with expired TTL, but no more than n keys.
 // If n = 0, deletes all expired keys.
 func (tx *Tx) deleteExpired(n int) (int, error) {
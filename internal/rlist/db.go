@@ -77,7 +77,7 @@ func (d *DB) DeleteFront(key string, elem any, count int) (int, error) {
 // If the index is out of bounds, returns ErrNotFound.
 // If the key does not exist or is not a list, returns ErrNotFound.
 func (d *DB) Get(key string, idx int) (core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Get(key, idx)
 }
 
@@ -112,7 +112,7 @@ func (d *DB) InsertBefore(key string, pivot, elem any) (int, error) {
 // Len returns the number of elements in a list.
 // If the key does not exist or is not a list, returns 0.
 func (d *DB) Len(key string) (int, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Len(key)
 }
 
@@ -187,7 +187,7 @@ func (d *DB) PushFront(key string, elem any) (int, error) {
 // (-1 is the last element, -2 is the second last, etc.)
 // If the key does not exist or is not a list, returns an empty slice.
 func (d *DB) Range(key string, start, stop int) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Range(key, start, stop)
 }
 
@@ -14,14 +14,14 @@ const (
 	delete from rlist
 	where kid = (
 			select id from rkey
-			where key = ? and type = 2 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 		) and elem = ?`
 
 	sqlDeleteBack = `
 	with ids as (
 		select rlist.rowid
 		from rlist join rkey on kid = rkey.id and type = 2
-		where key = ? and (etime is null or etime > ?) and elem = ?
+		where db = :db and key = ? and (etime is null or etime > ?) and elem = ?
 		order by pos desc
 		limit ?
 	)
@@ -32,7 +32,7 @@ const (
 	with ids as (
 		select rlist.rowid
 		from rlist join rkey on kid = rkey.id and type = 2
-		where key = ? and (etime is null or etime > ?) and elem = ?
+		where db = :db and key = ? and (etime is null or etime > ?) and elem = ?
 		order by pos
 		limit ?
 	)
@@ -43,7 +43,7 @@ const (
 	with elems as (
 		select elem, row_number() over (order by pos asc) as rownum
 		from rlist join rkey on kid = rkey.id and type = 2
-		where key = ? and (etime is null or etime > ?)
+		where db = :db and key = ? and (etime is null or etime > ?)
 	)
 	select elem
 	from elems
@@ -54,7 +54,7 @@ const (
 		version = version + 1,
 		mtime = ?,
 		len = len + 1
-	where key = ? and type = 2 and (etime is null or etime > ?)
+	where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	returning id, len`
 
 	sqlInsertAfter = `
@@ -105,12 +105,12 @@ const (
 
 	sqlLen = `
 	select len from rkey
-	where key = ? and type = 2 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 2 and (etime is null or etime > ?)`
 
 	sqlPopBack = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	)
 	delete from rlist
 	where
@@ -124,7 +124,7 @@ const (
 	sqlPopFront = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	)
 	delete from rlist
 	where
@@ -137,9 +137,9 @@ const (
 
 	sqlPush = `
 	insert into
-	rkey   (key, type, version, mtime, len)
-	values (  ?,    2,       1,     ?,   1)
-	on conflict (key) do update set
+	rkey   (db, key, type, version, mtime, len)
+	values (:db,   ?,    2,       1,     ?,   1)
+	on conflict (db, key) do update set
 		type = case when type = excluded.type then type else null end,
 		version = version + 1,
 		mtime = excluded.mtime,
@@ -161,7 +161,7 @@ const (
 	sqlRange = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	),
 	counts as (
 		select len from rkey
@@ -190,7 +190,7 @@ const (
 	sqlRangePostgres = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	),
 	counts as (
 		select len from rkey
@@ -217,7 +217,7 @@ const (
 	sqlSet = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
     ),
     elems as (
 		select pos, row_number() over (order by pos asc) as rownum
@@ -231,7 +231,7 @@ const (
 	sqlTrim = `
 	with curkey as (
 		select id from rkey
-		where key = ? and type = 2 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 	),
 	counts as (
 		select len from rkey
@@ -433,7 +433,7 @@ func (tx *Tx) Range(key string, start, stop int) ([]core.Value, error) {
 	query := `
 	with curkey as (
 		select id from rkey
-		where key = $1 and type = 2 and (etime is null or etime > $2)
+		where db = :db and key = $1 and type = 2 and (etime is null or etime > $2)
 	),
 	counts as (
 		select len from rkey
@@ -531,7 +531,7 @@ func (tx *Tx) Trim(key string, start, stop int) (int, error) {
 		query = `
 		with curkey as (
 			select id from rkey
-			where key = $1 and type = 2 and (etime is null or etime > $2)
+			where db = :db and key = $1 and type = 2 and (etime is null or etime > $2)
 		),
 		counts as (
 			select len from rkey
@@ -564,7 +564,7 @@ func (tx *Tx) Trim(key string, start, stop int) (int, error) {
 		query = `
 		with curkey as (
 			select id, len from rkey
-			where key = ? and type = 2 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 2 and (etime is null or etime > ?)
 		),
 		bounds as (
 			select
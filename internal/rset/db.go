@@ -65,7 +65,7 @@ func (d *DB) Delete(key string, elems ...any) (int, error) {
 // If the first key does not exist or is not a set, returns an empty slice.
 // If any of the remaining keys do not exist or are not sets, ignores them.
 func (d *DB) Diff(keys ...string) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Diff(keys...)
 }
 
@@ -91,19 +91,38 @@ func (d *DB) DiffStore(dest string, keys ...string) (int, error) {
 // Exists reports whether the element belongs to a set.
 // If the key does not exist or is not a set, returns false.
 func (d *DB) Exists(key, elem any) (bool, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Exists(key, elem)
 }
 
+// ExistsMany reports whether each of the elements belongs to a set.
+// Returns a slice of the same length as elems, where each item
+// tells if the corresponding element exists.
+// If the key does not exist or is not a set, returns all false.
+func (d *DB) ExistsMany(key string, elems ...any) ([]bool, error) {
+	tx := NewTx(d.Reader())
+	return tx.ExistsMany(key, elems...)
+}
+
 // Inter returns the intersection of multiple sets.
 // The intersection consists of elements that exist in all given sets.
 // If any of the source keys do not exist or are not sets,
 // returns an empty slice.
 func (d *DB) Inter(keys ...string) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Inter(keys...)
 }
 
+// InterLen returns the number of elements in the intersection
+// of multiple sets, without returning the elements themselves.
+// If limit > 0, stops counting when the limit is reached
+// (limit = 0 means no limit).
+// If any of the source keys do not exist or are not sets, returns 0.
+func (d *DB) InterLen(limit int, keys ...string) (int, error) {
+	tx := NewTx(d.Reader())
+	return tx.InterLen(limit, keys...)
+}
+
 // InterStore intersects multiple sets and stores the result in a destination set.
 // Returns the number of elements in the destination set.
 // If the destination key already exists, it is fully overwritten
@@ -124,14 +143,14 @@ func (d *DB) InterStore(dest string, keys ...string) (int, error) {
 // Items returns all elements in a set.
 // If the key does not exist or is not a set, returns an empty slice.
 func (d *DB) Items(key string) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Items(key)
 }
 
 // Len returns the number of elements in a set.
 // Returns 0 if the key does not exist or is not a set.
 func (d *DB) Len(key string) (int, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Len(key)
 }
 
@@ -161,20 +180,43 @@ func (d *DB) Pop(key string) (core.Value, error) {
 	return v, err
 }
 
+// PopMany removes and returns up to count random elements from a set.
+// The elements are removed in a single statement.
+// If the key does not exist or is not a set, returns an empty slice.
+func (d *DB) PopMany(key string, count int) ([]core.Value, error) {
+	var vals []core.Value
+	err := d.Update(func(tx *Tx) error {
+		var err error
+		vals, err = tx.PopMany(key, count)
+		return err
+	})
+	return vals, err
+}
+
 // Random returns a random element from a set.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (d *DB) Random(key string) (core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Random(key)
 }
 
+// RandomMany returns random elements from a set.
+// If count > 0, returns up to count distinct elements.
+// If count < 0, returns exactly -count elements,
+// which may contain the same element multiple times.
+// If the key does not exist or is not a set, returns an empty slice.
+func (d *DB) RandomMany(key string, count int) ([]core.Value, error) {
+	tx := NewTx(d.Reader())
+	return tx.RandomMany(key, count)
+}
+
 // Scan iterates over set elements matching pattern.
 // Returns a slice of elements of size count based on the current state
 // of the cursor. Returns an empty slice when there are no more items.
 // If the key does not exist or is not a set, returns an empty slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scan(key, cursor, pattern, count)
 }
 
@@ -184,7 +226,7 @@ func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult
 // or an error occurs. If the key does not exist or is not a set, stops immediately.
 // Supports glob-style patterns. Set pageSize = 0 for default page size.
 func (d *DB) Scanner(key, pattern string, pageSize int) *Scanner {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scanner(key, pattern, pageSize)
 }
 
@@ -193,7 +235,7 @@ func (d *DB) Scanner(key, pattern string, pageSize int) *Scanner {
 // Ignores the keys that do not exist or are not sets.
 // If no keys exist, returns an empty slice.
 func (d *DB) Union(keys ...string) ([]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Union(keys...)
 }
 
@@ -437,6 +437,30 @@ func TestExists(t *testing.T) {
 	testx.AssertEqual(t, str, false)
 }
 
+func TestExistsMany(t *testing.T) {
+	db, set := getDB(t)
+	defer db.Close()
+
+	_, _ = set.Add("key", "one", "two", "thr")
+	_ = db.Str().Set("str", "str")
+
+	exists, err := set.ExistsMany("key", "one", "other", "thr", "one")
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, exists, []bool{true, false, true, true})
+
+	exists, err = set.ExistsMany("key")
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, exists, []bool{})
+
+	exists, err = set.ExistsMany("other", "one", "two")
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, exists, []bool{false, false})
+
+	exists, err = set.ExistsMany("str", "one", "str")
+	testx.AssertNoErr(t, err)
+	testx.AssertEqual(t, exists, []bool{false, false})
+}
+
 func TestInter(t *testing.T) {
 	t.Run("non-empty", func(t *testing.T) {
 		db, set := getDB(t)
@@ -517,6 +541,80 @@ func TestInter(t *testing.T) {
 	})
 }
 
+func TestInterLen(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key1", "one", "two", "thr", "fou")
+		_, _ = set.Add("key2", "one", "two", "thr")
+		_, _ = set.Add("key3", "one", "two", "fiv")
+
+		n, err := set.InterLen(0, "key1", "key2", "key3")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 2)
+
+		n, err = set.InterLen(0, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 3)
+	})
+	t.Run("limit", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key1", "one", "two", "thr", "fou")
+		_, _ = set.Add("key2", "one", "two", "thr")
+
+		n, err := set.InterLen(2, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 2)
+
+		n, err = set.InterLen(10, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 3)
+	})
+	t.Run("single key", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two", "thr")
+
+		n, err := set.InterLen(0, "key")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 3)
+
+		n, err = set.InterLen(0, "key", "key")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 3)
+	})
+	t.Run("empty", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key1", "one", "two")
+		_, _ = set.Add("key2", "thr", "fou")
+
+		n, err := set.InterLen(0, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 0)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key1", "one", "two")
+
+		n, err := set.InterLen(0, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 0)
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key1", "one", "two")
+		_ = db.Str().Set("key2", "one")
+
+		n, err := set.InterLen(0, "key1", "key2")
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, n, 0)
+	})
+}
+
 func TestInterStore(t *testing.T) {
 	t.Run("store", func(t *testing.T) {
 		db, set := getDB(t)
@@ -904,6 +1002,77 @@ func TestPop(t *testing.T) {
 	})
 }
 
+func TestPopMany(t *testing.T) {
+	t.Run("pop", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two", "thr")
+
+		elems, err := set.PopMany("key", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(elems), 2)
+		for _, elem := range elems {
+			exists, _ := set.Exists("key", elem)
+			testx.AssertEqual(t, exists, false)
+		}
+
+		key, _ := db.Key().Get("key")
+		testx.AssertEqual(t, key.Version, 2)
+
+		slen, _ := set.Len("key")
+		testx.AssertEqual(t, slen, 1)
+	})
+	t.Run("pop all", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two", "thr")
+
+		elems, err := set.PopMany("key", 5)
+		testx.AssertNoErr(t, err)
+		sort.Slice(elems, func(i, j int) bool {
+			return elems[i].String() < elems[j].String()
+		})
+		testx.AssertEqual(t, elems, []core.Value{
+			core.Value("one"), core.Value("thr"), core.Value("two"),
+		})
+
+		slen, _ := set.Len("key")
+		testx.AssertEqual(t, slen, 0)
+	})
+	t.Run("zero count", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two", "thr")
+
+		elems, err := set.PopMany("key", 0)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+
+		slen, _ := set.Len("key")
+		testx.AssertEqual(t, slen, 3)
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+
+		elems, err := set.PopMany("key", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("key", "str")
+
+		elems, err := set.PopMany("key", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+
+		sval, _ := db.Str().Get("key")
+		testx.AssertEqual(t, sval.String(), "str")
+	})
+}
+
 func TestRandom(t *testing.T) {
 	t.Run("random", func(t *testing.T) {
 		db, set := getDB(t)
@@ -943,6 +1112,69 @@ func TestRandom(t *testing.T) {
 	})
 }
 
+func TestRandomMany(t *testing.T) {
+	t.Run("positive count", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two", "thr")
+
+		elems, err := set.RandomMany("key", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(elems), 2)
+		testx.AssertEqual(t, elems[0].String() != elems[1].String(), true)
+
+		elems, err = set.RandomMany("key", 5)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(elems), 3)
+
+		slen, _ := set.Len("key")
+		testx.AssertEqual(t, slen, 3)
+	})
+	t.Run("negative count", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two")
+
+		elems, err := set.RandomMany("key", -10)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, len(elems), 10)
+		for _, elem := range elems {
+			s := elem.String()
+			testx.AssertEqual(t, s == "one" || s == "two", true)
+		}
+	})
+	t.Run("zero count", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_, _ = set.Add("key", "one", "two")
+
+		elems, err := set.RandomMany("key", 0)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+	})
+	t.Run("key not found", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+
+		elems, err := set.RandomMany("key", 2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+
+		elems, err = set.RandomMany("key", -2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		db, set := getDB(t)
+		defer db.Close()
+		_ = db.Str().Set("key", "str")
+
+		elems, err := set.RandomMany("key", -2)
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, elems, []core.Value{})
+	})
+}
+
 func TestScan(t *testing.T) {
 	db, set := getDB(t)
 	defer db.Close()
@@ -2,6 +2,7 @@ package rset
 
 import (
 	"database/sql"
+	"math/rand/v2"
 	"slices"
 	"time"
 
@@ -12,9 +13,9 @@ import (
 const (
 	sqlAdd1 = `
 	insert into
-	rkey   (key, type, version, mtime, len)
-	values (  ?,    3,       1,     ?,   0)
-	on conflict (key) do update set
+	rkey   (db, key, type, version, mtime, len)
+	values (:db,   ?,    3,       1,     ?,   0)
+	on conflict (db, key) do update set
 		type = case when type = excluded.type then type else null end,
 		version = version+1,
 		mtime = excluded.mtime
@@ -30,7 +31,7 @@ const (
 	delete from rset
 	where kid = (
 			select id from rkey
-			where key = ? and type = 3 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 3 and (etime is null or etime > ?)
 		) and elem in (:elems)`
 
 	sqlDelete2 = `
@@ -38,13 +39,13 @@ const (
 		version = version + 1,
 		mtime = ?,
 		len = len - ?
-	where key = ? and type = 3 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 3 and (etime is null or etime > ?)`
 
 	sqlDeleteKey1 = `
 	delete from rset
 	where kid = (
 		select id from rkey
-		where key = ? and type = 3 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 3 and (etime is null or etime > ?)
 	)`
 
 	sqlDeleteKey2 = `
@@ -52,7 +53,7 @@ const (
 		version = 0,
 		mtime = 0,
 		len = 0
-	where key = ? and type = 3 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 3 and (etime is null or etime > ?)`
 
 	sqlDiff = `
 	with others as (
@@ -60,14 +61,14 @@ const (
 		from rset
 		where kid in (
 			select id from rkey
-			where key in (:keys) and type = 3 and (etime is null or etime > ?)
+			where db = :db and key in (:keys) and type = 3 and (etime is null or etime > ?)
 		)
 	)
 	select elem
 	from rset
 	where kid = (
 		select id from rkey
-		where key = ? and type = 3 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 3 and (etime is null or etime > ?)
 	)
 	and elem not in (select elem from others)`
 
@@ -77,7 +78,7 @@ const (
 		from rset
 		where kid in (
 			select id from rkey
-			where key in (:keys) and type = 3 and (etime is null or etime > ?)
+			where db = :db and key in (:keys) and type = 3 and (etime is null or etime > ?)
 		)
 	)
 	insert into rset (kid, elem)
@@ -85,44 +86,64 @@ const (
 	from rset
 	where kid = (
 		select id from rkey
-		where key = ? and type = 3 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 3 and (etime is null or etime > ?)
 	)
 	and elem not in (select elem from others)`
 
 	sqlExists = `
 	select count(*)
 	from rset join rkey on kid = rkey.id and type = 3
-	where key = ? and (etime is null or etime > ?) and elem = ?`
+	where db = :db and key = ? and (etime is null or etime > ?) and elem = ?`
+
+	sqlExistsMany = `
+	select elem
+	from rset join rkey on kid = rkey.id and type = 3
+	where db = :db and key = ? and (etime is null or etime > ?) and elem in (:elems)`
 
 	sqlInter = `
 	select elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	having count(distinct kid) = ?`
 
+	sqlInterKeys = `
+	select id, len from rkey
+	where db = :db and key in (:keys) and type = 3 and (etime is null or etime > ?)
+	order by len asc`
+
+	sqlInterLen = `
+	select count(*) from (
+		select elem from rset
+		where kid = ? and (
+			select count(*) from rset as other
+			where other.kid in (:kids) and other.elem = rset.elem
+		) = ?
+		limit ?
+	) as inter`
+
 	sqlInterStore = `
 	insert into rset (kid, elem)
 	select ?, elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	having count(distinct kid) = ?`
 
 	sqlItems = `
 	select elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (etime is null or etime > ?)`
 
 	sqlLen = `
 	select len from rkey
-	where key = ? and type = 3 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 3 and (etime is null or etime > ?)`
 
 	sqlPop1 = `
 	with chosen as (
 		select rset.rowid
 		from rset join rkey on kid = rkey.id and type = 3
-		where key = ? and (etime is null or etime > ?)
+		where db = :db and key = ? and (etime is null or etime > ?)
 		order by random() limit 1
 	)
 	delete from rset
@@ -131,31 +152,50 @@ const (
 
 	sqlPop2 = sqlDelete2
 
+	sqlPopMany1 = `
+	with chosen as (
+		select rset.rowid
+		from rset join rkey on kid = rkey.id and type = 3
+		where db = :db and key = ? and (etime is null or etime > ?)
+		order by random() limit ?
+	)
+	delete from rset
+	where rowid in (select rowid from chosen)
+	returning elem`
+
+	sqlPopMany2 = sqlDelete2
+
 	sqlRandom = `
 	select elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key = ? and (etime is null or etime > ?)
+	where db = :db and key = ? and (etime is null or etime > ?)
 	order by random() limit 1`
 
+	sqlRandomMany = `
+	select elem
+	from rset join rkey on kid = rkey.id and type = 3
+	where db = :db and key = ? and (etime is null or etime > ?)
+	order by random() limit ?`
+
 	sqlScan = `
 	select rset.rowid, elem
 	from rset join rkey on kid = rkey.id and type = 3
 	where
-		key = ? and (etime is null or etime > ?)
+		db = :db and key = ? and (etime is null or etime > ?)
 		and rset.rowid > ? and elem glob ?
 	limit ?`
 
 	sqlUnion = `
 	select elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem`
 
 	sqlUnionStore = `
 	insert into rset (kid, elem)
 	select ?, elem
 	from rset join rkey on kid = rkey.id and type = 3
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem`
 )
 
@@ -309,6 +349,39 @@ func (tx *Tx) Exists(key, elem any) (bool, error) {
 	return exists, nil
 }
 
+// ExistsMany reports whether each of the elements belongs to a set.
+// Returns a slice of the same length as elems, where each item
+// tells if the corresponding element exists.
+// If the key does not exist or is not a set, returns all false.
+func (tx *Tx) ExistsMany(key string, elems ...any) ([]bool, error) {
+	elembs, err := core.ToBytesMany(elems...)
+	if err != nil {
+		return nil, err
+	}
+	exists := make([]bool, len(elembs))
+	if len(elembs) == 0 {
+		return exists, nil
+	}
+
+	// Select the elements that belong to the set.
+	query, elemArgs := sqlx.ExpandIn(sqlExistsMany, ":elems", elembs)
+	args := append([]any{key, time.Now().UnixMilli()}, elemArgs...)
+	found, err := tx.selectElems(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match the found elements with the requested ones.
+	foundSet := make(map[string]struct{}, len(found))
+	for _, elem := range found {
+		foundSet[string(elem)] = struct{}{}
+	}
+	for i, elemb := range elembs {
+		_, exists[i] = foundSet[string(elemb)]
+	}
+	return exists, nil
+}
+
 // Inter returns the intersection of multiple sets.
 // The intersection consists of elements that exist in all given sets.
 // If any of the source keys do not exist or are not sets,
@@ -322,6 +395,56 @@ func (tx *Tx) Inter(keys ...string) ([]core.Value, error) {
 	return tx.selectElems(query, args)
 }
 
+// InterLen returns the number of elements in the intersection
+// of multiple sets, without returning the elements themselves.
+// If limit > 0, stops counting when the limit is reached
+// (limit = 0 means no limit).
+// If any of the source keys do not exist or are not sets, returns 0.
+func (tx *Tx) InterLen(limit int, keys ...string) (int, error) {
+	keys = uniqueKeys(keys)
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// Select the source sets, smallest first.
+	type setKey struct{ id, size int }
+	query, keyArgs := sqlx.ExpandIn(sqlInterKeys, ":keys", keys)
+	query = sqlx.ConvertPlaceholders(query)
+	args := append(keyArgs, time.Now().UnixMilli())
+	scan := func(rows *sql.Rows) (setKey, error) {
+		var k setKey
+		err := rows.Scan(&k.id, &k.size)
+		return k, err
+	}
+	sets, err := sqlx.Select(tx.tx, query, args, scan)
+	if err != nil {
+		return 0, err
+	}
+	if len(sets) != len(keys) || sets[0].size == 0 {
+		// Some of the keys do not exist or are not sets.
+		return 0, nil
+	}
+	if limit <= 0 || limit > sets[0].size {
+		limit = sets[0].size
+	}
+
+	// Iterate over the smallest set and check each element against
+	// the other sets, stopping as soon as the limit is reached.
+	others := make([]any, len(sets)-1)
+	for i, set := range sets[1:] {
+		others[i] = set.id
+	}
+	query, kidArgs := sqlx.ExpandIn(sqlInterLen, ":kids", others)
+	query = sqlx.ConvertPlaceholders(query)
+	args = slices.Concat([]any{sets[0].id}, kidArgs, []any{len(others), limit})
+	var n int
+	err = tx.tx.QueryRow(query, args...).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // InterStore intersects multiple sets and stores the result in a destination set.
 // Returns the number of elements in the destination set.
 // If the destination key already exists, it is fully overwritten
@@ -423,6 +546,35 @@ func (tx *Tx) Pop(key string) (core.Value, error) {
 	return core.Value(val), nil
 }
 
+// PopMany removes and returns up to count random elements from a set.
+// The elements are removed in a single statement.
+// If the key does not exist or is not a set, returns an empty slice.
+func (tx *Tx) PopMany(key string, count int) ([]core.Value, error) {
+	if count <= 0 {
+		return []core.Value{}, nil
+	}
+
+	// Pop the elements from the set.
+	now := time.Now().UnixMilli()
+	args := []any{key, now, count}
+	elems, err := tx.selectElems(sqlPopMany1, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return elems, nil
+	}
+
+	// Update the key.
+	args = []any{now, len(elems), key, now}
+	_, err = tx.tx.Exec(sqlPopMany2, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return elems, nil
+}
+
 // Random returns a random element from a set.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (tx *Tx) Random(key string) (core.Value, error) {
@@ -438,6 +590,58 @@ func (tx *Tx) Random(key string) (core.Value, error) {
 	return core.Value(val), nil
 }
 
+// RandomMany returns random elements from a set.
+// If count > 0, returns up to count distinct elements.
+// If count < 0, returns exactly -count elements,
+// which may contain the same element multiple times.
+// If the key does not exist or is not a set, returns an empty slice.
+func (tx *Tx) RandomMany(key string, count int) ([]core.Value, error) {
+	if count == 0 {
+		return []core.Value{}, nil
+	}
+	if count > 0 {
+		args := []any{key, time.Now().UnixMilli(), count}
+		return tx.selectElems(sqlRandomMany, args)
+	}
+
+	// Sampling with repetitions. Draw the positions first,
+	// then fetch as many distinct random elements as there are
+	// distinct positions, and map positions to elements.
+	count = -count
+	n, err := tx.Len(key)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return []core.Value{}, nil
+	}
+	slots := make([]int, count)
+	seen := map[int]int{}
+	for i := range slots {
+		pos := rand.IntN(n)
+		slot, ok := seen[pos]
+		if !ok {
+			slot = len(seen)
+			seen[pos] = slot
+		}
+		slots[i] = slot
+	}
+	args := []any{key, time.Now().UnixMilli(), len(seen)}
+	distinct, err := tx.selectElems(sqlRandomMany, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(distinct) < len(seen) {
+		// The set has shrunk since we've checked its length.
+		return distinct, nil
+	}
+	elems := make([]core.Value, count)
+	for i, slot := range slots {
+		elems[i] = distinct[slot]
+	}
+	return elems, nil
+}
+
 // Scan iterates over set elements matching pattern.
 // Returns a slice of elements of size count based on the current state
 // of the cursor. Returns an empty slice when there are no more items.
@@ -604,6 +808,17 @@ func (tx *Tx) selectElems(query string, args []any) ([]core.Value, error) {
 	return elems, nil
 }
 
+// uniqueKeys returns keys without duplicates, preserving the order.
+func uniqueKeys(keys []string) []string {
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !slices.Contains(unique, key) {
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
 // ScanResult is a result of the scan operation.
 type ScanResult struct {
 	Cursor int
@@ -34,7 +34,7 @@ func NewWithDriver(rw *sql.DB, ro *sql.DB, driver string) *DB {
 // Get returns the value of the key.
 // If the key does not exist or is not a string, returns ErrNotFound.
 func (d *DB) Get(key string) (core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Get(key)
 }
 
@@ -42,7 +42,7 @@ func (d *DB) Get(key string) (core.Value, error) {
 // Ignores keys that do not exist or not strings,
 // and does not return them in the map.
 func (d *DB) GetMany(keys ...string) (map[string]core.Value, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.GetMany(keys...)
 }
 
@@ -12,18 +12,18 @@ const (
 	sqlGet = `
 	select value
 	from rstring join rkey on kid = rkey.id and type = 1
-	where key = ? and (etime is null or etime > ?)`
+	where db = :db and key = ? and (etime is null or etime > ?)`
 
 	sqlGetMany = `
 	select key, value
 	from rstring
 	join rkey on kid = rkey.id and type = 1
-	where key in (:keys) and (etime is null or etime > ?)`
+	where db = :db and key in (:keys) and (etime is null or etime > ?)`
 
 	sqlSet1 = `
-	insert into rkey (key, type, version, etime, mtime)
-	values (?, 1, 1, ?, ?)
-	on conflict (key) do update set
+	insert into rkey (db, key, type, version, etime, mtime)
+	values (:db, ?, 1, 1, ?, ?)
+	on conflict (db, key) do update set
 		type = case when rkey.type = excluded.type then rkey.type else null end,
 		version = rkey.version+1,
 		etime = excluded.etime,
@@ -31,14 +31,14 @@ const (
 
 	sqlSet2 = `
 	insert into rstring (kid, value)
-	values ((select id from rkey where key = ?), ?)
+	values ((select id from rkey where db = :db and key = ?), ?)
 	on conflict (kid) do update
 	set value = excluded.value`
 
 	sqlUpdate1 = `
-	insert into rkey (key, type, version, etime, mtime)
-	values (?, 1, 1, null, ?)
-	on conflict (key) do update set
+	insert into rkey (db, key, type, version, etime, mtime)
+	values (:db, ?, 1, 1, null, ?)
+	on conflict (db, key) do update set
 		type = case when rkey.type = excluded.type then excluded.type else null end,
 		version = rkey.version+1,
 		mtime = excluded.mtime`
@@ -68,7 +68,7 @@ func (d *DB) AddMany(key string, items map[any]float64) (int, error) {
 // min and max (inclusive). Exclusive ranges are not supported.
 // Returns 0 if the key does not exist or is not a set.
 func (d *DB) Count(key string, min, max float64) (int, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Count(key, min, max)
 }
 
@@ -97,7 +97,7 @@ func (d *DB) DeleteWith(key string) DeleteCmd {
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (d *DB) GetRank(key string, elem any) (rank int, score float64, err error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.GetRank(key, elem)
 }
 
@@ -107,7 +107,7 @@ func (d *DB) GetRank(key string, elem any) (rank int, score float64, err error)
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (d *DB) GetRankRev(key string, elem any) (rank int, score float64, err error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.GetRankRev(key, elem)
 }
 
@@ -115,7 +115,7 @@ func (d *DB) GetRankRev(key string, elem any) (rank int, score float64, err erro
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (d *DB) GetScore(key string, elem any) (float64, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.GetScore(key, elem)
 }
 
@@ -151,7 +151,7 @@ func (d *DB) InterWith(keys ...string) InterCmd {
 // Len returns the number of elements in a set.
 // Returns 0 if the key does not exist or is not a set.
 func (d *DB) Len(key string) (int, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Len(key)
 }
 
@@ -161,13 +161,13 @@ func (d *DB) Len(key string) (int, error) {
 // Start and stop are 0-based, inclusive. Negative values are not supported.
 // If the key does not exist or is not a set, returns a nil slice.
 func (d *DB) Range(key string, start, stop int) ([]SetItem, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Range(key, start, stop)
 }
 
 // RangeWith ranges elements from a set with additional options.
 func (d *DB) RangeWith(key string) RangeCmd {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.RangeWith(key)
 }
 
@@ -178,7 +178,7 @@ func (d *DB) RangeWith(key string) RangeCmd {
 // If the key does not exist or is not a set, returns a nil slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scan(key, cursor, pattern, count)
 }
 
@@ -188,7 +188,7 @@ func (d *DB) Scan(key string, cursor int, pattern string, count int) (ScanResult
 // or an error occurs. If the key does not exist or is not a set, stops immediately.
 // Supports glob-style patterns. Set pageSize = 0 for default page size.
 func (d *DB) Scanner(key, pattern string, pageSize int) *Scanner {
-	tx := NewTx(d.RO)
+	tx := NewTx(d.Reader())
 	return tx.Scanner(key, pattern, pageSize)
 }
 
@@ -1,6 +1,7 @@
 package rzset_test
 
 import (
+	"fmt"
 	"math"
 	"testing"
 
@@ -1582,6 +1583,81 @@ func TestUnionStore(t *testing.T) {
 	})
 }
 
+func BenchmarkGetRank(b *testing.B) {
+	for _, size := range []int{1_000, 10_000, 100_000} {
+		db, zset := getDB(b)
+		fillSet(b, db, "key", size)
+
+		// Elements have scores 0..size-1, so the element
+		// is also its rank in the ascending order.
+		tests := []struct {
+			name string
+			elem int
+			rev  bool
+			rank int
+		}{
+			{"asc/middle", size / 2, false, size / 2},
+			{"asc/last", size - 1, false, size - 1},
+			{"desc/middle", size / 2, true, size - 1 - size/2},
+			{"desc/last", 0, true, size - 1},
+		}
+		for _, test := range tests {
+			name := fmt.Sprintf("size=%d/%s", size, test.name)
+			b.Run(name, func(b *testing.B) {
+				getRank := zset.GetRank
+				if test.rev {
+					getRank = zset.GetRankRev
+				}
+				for i := 0; i < b.N; i++ {
+					rank, _, err := getRank("key", test.elem)
+					if err != nil || rank != test.rank {
+						b.Fatalf("want rank %d, got %d (%v)", test.rank, rank, err)
+					}
+				}
+			})
+		}
+		db.Close()
+	}
+}
+
+func BenchmarkRangeRank(b *testing.B) {
+	for _, size := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			db, zset := getDB(b)
+			defer db.Close()
+			fillSet(b, db, "key", size)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Top 10 elements, as in a leaderboard.
+				items, err := zset.RangeWith("key").ByRank(0, 9).Desc().Run()
+				if err != nil || len(items) != 10 {
+					b.Fatalf("want 10 items, got %d (%v)", len(items), err)
+				}
+			}
+		})
+	}
+}
+
+// fillSet adds n elements with scores 0..n-1 to a sorted set.
+func fillSet(tb testing.TB, db *redka.DB, key string, n int) {
+	tb.Helper()
+	const batchSize = 10_000
+	for start := 0; start < n; start += batchSize {
+		err := db.Update(func(tx *redka.Tx) error {
+			items := make(map[any]float64, batchSize)
+			for i := start; i < min(start+batchSize, n); i++ {
+				items[i] = float64(i)
+			}
+			_, err := tx.ZSet().AddMany(key, items)
+			return err
+		})
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
 func getDB(tb testing.TB) (*redka.DB, *rzset.DB) {
 	tb.Helper()
 	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
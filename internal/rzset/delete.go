@@ -13,7 +13,7 @@ const (
 		from rzset
 		where kid = (
 			select id from rkey
-			where key = ? and type = 5 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 5 and (etime is null or etime > ?)
 		)
 		order by score, elem
 		limit ?, ?
@@ -25,7 +25,7 @@ const (
 	delete from rzset
 	where kid = (
 			select id from rkey
-			where key = ? and type = 5 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 5 and (etime is null or etime > ?)
 		) and score between ? and ?`
 
 	sqlUpdateKey = sqlDelete2
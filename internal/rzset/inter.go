@@ -13,7 +13,7 @@ const (
 	sqlInter = `
 	select elem, sum(score) as score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	having count(distinct kid) = ?
 	order by sum(score), elem`
@@ -25,7 +25,7 @@ const (
 	insert into rzset (kid, elem, score)
 	select ?, elem, sum(score) as score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	having count(distinct kid) = ?
 	order by sum(score), elem`
@@ -70,7 +70,7 @@ func (c InterCmd) Max() InterCmd {
 // If any of the source keys do not exist or are not sets, returns an empty slice.
 func (c InterCmd) Run() ([]SetItem, error) {
 	if c.db != nil {
-		return c.run(c.db.RO)
+		return c.run(c.db.Reader())
 	}
 	if c.tx != nil {
 		return c.run(c.tx.tx)
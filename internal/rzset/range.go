@@ -9,21 +9,19 @@ import (
 
 const (
 	sqlRangeRank = `
-	with ranked as (
-		select elem, score, (row_number() over w - 1) as rank
-		from rzset join rkey on kid = rkey.id and type = 5
-		where key = ? and (etime is null or etime > ?)
-		window w as (partition by kid order by score asc, elem asc)
-	)
 	select elem, score
-	from ranked
-	where rank between ? and ?
-	order by rank, elem asc`
+	from rzset
+	where kid = (
+		select id from rkey
+		where db = :db and key = ? and type = 5 and (etime is null or etime > ?)
+	)
+	order by score asc, elem asc
+	limit ? offset ?`
 
 	sqlRangeScore = `
 	select elem, score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key = ? and (etime is null or etime > ?)
+	where db = :db and key = ? and (etime is null or etime > ?)
 	and score between ? and ?
 	order by score asc, elem asc`
 )
@@ -115,6 +113,9 @@ func (c RangeCmd) rangeRank() ([]SetItem, error) {
 	if c.byRank.start < 0 || c.byRank.stop < 0 {
 		return nil, nil
 	}
+	if c.byRank.start > c.byRank.stop {
+		return nil, nil
+	}
 
 	// Change sort direction if necessary.
 	query := sqlRangeRank
@@ -122,12 +123,15 @@ func (c RangeCmd) rangeRank() ([]SetItem, error) {
 		query = strings.Replace(query, sqlx.Asc, c.sortDir, -1)
 	}
 
-	// Prepare query arguments.
+	// Prepare query arguments. The rank range translates
+	// to limit/offset over the (kid, score, elem) index,
+	// so there is no need to number the whole set (skipping
+	// the offset still takes time proportional to it).
 	args := []any{
 		c.key,
 		time.Now().UnixMilli(),
+		c.byRank.stop - c.byRank.start + 1,
 		c.byRank.start,
-		c.byRank.stop,
 	}
 
 	// Apply PostgreSQL adaptations and placeholder conversion
@@ -174,13 +178,16 @@ func (c RangeCmd) rangeScore() ([]SetItem, error) {
 
 	// Add offset and count if necessary.
 	if c.offset > 0 && c.count > 0 {
-		query += " limit ?, ?"
-		args = append(args, c.offset, c.count)
+		query += " limit ? offset ?"
+		args = append(args, c.count, c.offset)
 	} else if c.count > 0 {
 		query += " limit ?"
 		args = append(args, c.count)
+	} else if c.offset > 0 && sqlx.IsPostgres() {
+		query += " offset ?"
+		args = append(args, c.offset)
 	} else if c.offset > 0 {
-		query += " limit ?, -1"
+		query += " limit -1 offset ?"
 		args = append(args, c.offset)
 	}
 
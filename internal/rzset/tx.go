@@ -12,9 +12,9 @@ import (
 const (
 	sqlAdd1 = `
 	insert into
-	rkey   (key, type, version, mtime, len)
-	values (  ?,    5,       1,     ?,   0)
-	on conflict (key) do update set
+	rkey   (db, key, type, version, mtime, len)
+	values (:db,   ?,    5,       1,     ?,   0)
+	on conflict (db, key) do update set
 		type = case when type = excluded.type then type else null end,
 		version = version+1,
 		mtime = excluded.mtime
@@ -29,18 +29,18 @@ const (
 	sqlCount = `
 	select count(elem)
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key = ? and (etime is null or etime > ?) and elem in (:elems)`
+	where db = :db and key = ? and (etime is null or etime > ?) and elem in (:elems)`
 
 	sqlCountScore = `
 	select count(elem)
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key = ? and (etime is null or etime > ?) and score between ? and ?`
+	where db = :db and key = ? and (etime is null or etime > ?) and score between ? and ?`
 
 	sqlDelete1 = `
 	delete from rzset
 	where kid = (
 			select id from rkey
-			where key = ? and type = 5 and (etime is null or etime > ?)
+			where db = :db and key = ? and type = 5 and (etime is null or etime > ?)
 		) and elem in (:elems)`
 
 	sqlDelete2 = `
@@ -48,13 +48,13 @@ const (
 		version = version + 1,
 		mtime = ?,
 		len = len - ?
-	where key = ? and type = 5 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 5 and (etime is null or etime > ?)`
 
 	sqlDeleteAll1 = `
 	delete from rzset
 	where kid = (
 		select id from rkey
-		where key = ? and type = 5 and (etime is null or etime > ?)
+		where db = :db and key = ? and type = 5 and (etime is null or etime > ?)
 	)`
 
 	sqlDeleteAll2 = `
@@ -62,23 +62,27 @@ const (
 		version = 0,
 		mtime = 0,
 		len = 0
-	where key = ? and type = 5 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 5 and (etime is null or etime > ?)`
 
 	sqlGetRank = `
-	with ranked as (
-		select elem, score, (row_number() over w - 1) as rank
+	with target as (
+		select kid, elem, score
 		from rzset join rkey on kid = rkey.id and type = 5
-		where key = ? and (etime is null or etime > ?)
-		window w as (partition by kid order by score asc, elem asc)
+		where db = :db and key = ? and (etime is null or etime > ?) and elem = ?
 	)
-	select rank, score
-	from ranked
-	where elem = ?`
+	select (
+		select count(*) from rzset
+		where kid = target.kid and score < target.score
+	) + (
+		select count(*) from rzset
+		where kid = target.kid and score = target.score and elem < target.elem
+	), score
+	from target`
 
 	sqlGetScore = `
 	select score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key = ? and (etime is null or etime > ?) and elem = ?`
+	where db = :db and key = ? and (etime is null or etime > ?) and elem = ?`
 
 	sqlIncr1 = sqlAdd1
 
@@ -91,13 +95,13 @@ const (
 
 	sqlLen = `
 	select len from rkey
-	where key = ? and type = 5 and (etime is null or etime > ?)`
+	where db = :db and key = ? and type = 5 and (etime is null or etime > ?)`
 
 	sqlScan = `
 	select rzset.rowid, elem, score
 	from rzset join rkey on kid = rkey.id and type = 5
 	where
-		key = ? and (etime is null or etime > ?)
+		db = :db and key = ? and (etime is null or etime > ?)
 		and rzset.rowid > ? and elem glob ?
 	limit ?`
 )
@@ -419,11 +423,17 @@ func (tx *Tx) getRank(key string, elem any, sortDir string) (rank int, score flo
 		return 0, 0, err
 	}
 
+	// The rank is the number of elements that go before the target one,
+	// so we count them using the (kid, score, elem) index instead of
+	// numbering and sorting the whole set. The count still walks
+	// the preceding index entries, so it takes time proportional
+	// to the rank (SQL has no order-statistic index).
 	args := []any{key, time.Now().UnixMilli(), elemb}
 	query := sqlGetRank
 	if sortDir != sqlx.Asc {
-		query = strings.Replace(query, sqlx.Asc, sortDir, 2)
+		query = strings.Replace(query, "<", ">", -1)
 	}
+	query = sqlx.AdaptPostgresQuery(sqlx.ConvertPlaceholders(query))
 
 	row := tx.tx.QueryRow(query, args...)
 	err = row.Scan(&rank, &score)
@@ -13,7 +13,7 @@ const (
 	sqlUnion = `
 	select elem, sum(score) as score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	order by sum(score), elem`
 
@@ -24,7 +24,7 @@ const (
 	insert into rzset (kid, elem, score)
 	select ?, elem, sum(score) as score
 	from rzset join rkey on kid = rkey.id and type = 5
-	where key in (:keys) and (etime is null or etime > ?)
+	where db = :db and key in (:keys) and (etime is null or etime > ?)
 	group by elem
 	order by sum(score), elem`
 )
@@ -69,7 +69,7 @@ func (c UnionCmd) Max() UnionCmd {
 // If no keys exist, returns a nil slice.
 func (c UnionCmd) Run() ([]SetItem, error) {
 	if c.db != nil {
-		return c.run(c.db.RO)
+		return c.run(c.db.Reader())
 	}
 	if c.tx != nil {
 		return c.run(c.tx.tx)
@@ -1,46 +1,304 @@
 package server
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/clients"
 	"github.com/flarco/redka/internal/command"
 	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/stats"
 	"github.com/tidwall/redcon"
 )
 
 // createHandlers returns the server command handlers.
-func createHandlers(db *redka.DB) redcon.HandlerFunc {
-	return logging(parse(multi(handle(db))))
+// The saver is optional (nil disables persistence commands),
+// and so are the replication manager (nil disables replication),
+// the command log (nil disables logging write commands)
+// the statistics collector (nil disables statistics),
+// the command monitor (nil disables MONITOR),
+// the client registry (nil disables CLIENT),
+// the ACL manager (nil disables AUTH and ACL)
+// and the invalidation message pusher (nil disables
+// delivering the CLIENT TRACKING messages).
+func createHandlers(db *redka.DB, saver redis.RSaver, repl redis.RRepl, log *aof.Logger, stats *stats.Collector, mon *monitor, reg *clients.Registry, acls *acl.Manager, push *pusher) redcon.HandlerFunc {
+	// Avoid passing a typed nil pointer as an interface.
+	var rstats redis.RStats
+	if stats != nil {
+		rstats = stats
+	}
+	var rclients redis.RClients
+	if reg != nil {
+		rclients = reg
+	}
+	var racl redis.RACL
+	if acls != nil {
+		racl = acls
+	}
+	env := handlerEnv{saver: saver, repl: repl, stats: rstats, clients: rclients, acl: racl}
+	return pushing(push, logging(stats, track(parse(repl, authorize(acls, monitoring(mon, pause(reg, multi(tracking(reg, handle(db, env, log))))))))))
+}
+
+// handlerEnv holds the server-level components
+// available to the commands.
+type handlerEnv struct {
+	saver   redis.RSaver
+	repl    redis.RRepl
+	stats   redis.RStats
+	clients redis.RClients
+	acl     redis.RACL
+}
+
+// redka returns a Redka instance with the server-level
+// components and the client running the command.
+func (e handlerEnv) redka(red redis.Redka, client *clients.Client) redis.Redka {
+	red = red.WithSaver(e.saver).WithRepl(e.repl).WithStats(e.stats)
+	return red.WithClients(e.clients).WithClient(client).WithACL(e.acl)
 }
 
-// logging logs the command processing time.
-func logging(next redcon.HandlerFunc) redcon.HandlerFunc {
+// logging logs the command processing time and records
+// it in the statistics and the slow log (if any).
+func logging(stats *stats.Collector, next redcon.HandlerFunc) redcon.HandlerFunc {
 	return func(conn redcon.Conn, cmd redcon.Command) {
 		start := time.Now()
-		next(conn, cmd)
+		if stats == nil {
+			next(conn, cmd)
+		} else {
+			sconn := &statsConn{Conn: conn}
+			next(sconn, cmd)
+			dur := time.Since(start)
+			stats.Command(statsName(cmd), dur, sconn.failed)
+			stats.SlowLog().Add(redactArgs(cmd.Args), conn.RemoteAddr(), clientName(conn), dur)
+		}
 		slog.Debug("process command", "client", conn.RemoteAddr(),
 			"name", string(cmd.Args[0]), "time", time.Since(start))
 	}
 }
 
+// clientName returns the name of the connection's client
+// (empty if clients are not tracked).
+func clientName(conn redcon.Conn) string {
+	client := getState(conn).client
+	if client == nil {
+		return ""
+	}
+	return client.Name()
+}
+
+// redactArgs hides the passwords in the AUTH, HELLO
+// and ACL SETUSER command arguments before they are logged.
+func redactArgs(args [][]byte) [][]byte {
+	name := strings.ToLower(string(args[0]))
+	if name == "acl" && len(args) > 1 && strings.EqualFold(string(args[1]), "setuser") {
+		name = "acl setuser"
+	}
+	if name != "auth" && name != "hello" && name != "acl setuser" {
+		return args
+	}
+	redacted := make([][]byte, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(args); i++ {
+		switch {
+		case name == "auth":
+			redacted[i] = []byte("(redacted)")
+		case name == "hello":
+			if strings.EqualFold(string(args[i]), "auth") && i+2 < len(args) {
+				// HELLO protover AUTH username password
+				redacted[i+1] = []byte("(redacted)")
+				redacted[i+2] = []byte("(redacted)")
+				i += 2
+			}
+		case i > 2 && len(args[i]) > 0:
+			// ACL SETUSER username >pass <pass #hash !hash
+			switch args[i][0] {
+			case '>', '<', '#', '!':
+				redacted[i] = []byte("(redacted)")
+			}
+		}
+	}
+	return redacted
+}
+
+// statsConn is a connection that tracks whether
+// the command has returned an error.
+type statsConn struct {
+	redcon.Conn
+	failed bool
+}
+
+func (c *statsConn) WriteError(msg string) {
+	c.failed = true
+	c.Conn.WriteError(msg)
+}
+
+// statsName returns the command name for the statistics.
+// Unknown commands share a single name, so that clients
+// can't create an unlimited number of metrics.
+func statsName(cmd redcon.Command) string {
+	name := normName(cmd)
+	switch name {
+	case "multi", "exec", "discard":
+		return name
+	}
+	if _, ok := redis.LookupCmd(name); !ok {
+		return "unknown"
+	}
+	return name
+}
+
+// track records the client's last command and transaction
+// state, and disconnects the client if it has killed itself.
+func track(next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.client == nil {
+			next(conn, cmd)
+			return
+		}
+		state.client.Touch(normName(cmd))
+		next(conn, cmd)
+		if state.inMulti {
+			state.client.SetMulti(len(state.cmds))
+		} else {
+			state.client.SetMulti(-1)
+		}
+		if state.client.Killed() {
+			conn.Close()
+		}
+	}
+}
+
 // parse parses the command arguments.
-func parse(next redcon.HandlerFunc) redcon.HandlerFunc {
+// Rejects write commands if the database is a replica,
+// and most commands if a RESP2 client has subscribed
+// to a channel.
+func parse(repl redis.RRepl, next redcon.HandlerFunc) redcon.HandlerFunc {
 	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.proto < redis.RESP3 && state.client != nil && state.client.Subscribed() {
+			switch name := normName(cmd); name {
+			case "subscribe", "unsubscribe", "ping", "quit", "reset":
+			default:
+				conn.WriteError(fmt.Sprintf("ERR Can't execute '%s': only (P|S)SUBSCRIBE / "+
+					"(P|S)UNSUBSCRIBE / PING / QUIT / RESET are allowed in this context", name))
+				return
+			}
+		}
 		pcmd, err := command.Parse(cmd.Args)
 		if err != nil {
+			state.aborted = state.inMulti
 			conn.WriteError(pcmd.Error(err))
 			return
 		}
-		state := getState(conn)
+		if repl != nil && repl.ReadOnly() && pcmd.IsWrite() {
+			state.aborted = state.inMulti
+			conn.WriteError(pcmd.Error(redis.ErrReadOnly))
+			return
+		}
 		state.push(pcmd)
 		next(conn, cmd)
 	}
 }
 
+// authorize rejects the command if the client has not
+// authenticated, or if the client's user is not allowed
+// to run the command or to access its keys.
+// Clients authenticate as the default user implicitly
+// if it does not have a password.
+func authorize(acls *acl.Manager, next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if acls == nil || state.client == nil {
+			next(conn, cmd)
+			return
+		}
+		name := normName(cmd)
+		if !state.client.Authenticated() {
+			if acls.NoAuth() {
+				state.client.SetUser(acl.DefaultUser)
+			} else if name != "auth" && name != "hello" {
+				state.pop()
+				conn.WriteError(redis.ErrNoAuth.Error())
+				return
+			}
+		}
+		if _, ok := redis.LookupCmd(name); !ok || name == "auth" || name == "hello" {
+			// Anyone can authenticate (AUTH or HELLO), and the
+			// transaction and unknown commands need no permissions.
+			next(conn, cmd)
+			return
+		}
+		if msg := checkAccess(acls, state.client.User(), state.cmds[len(state.cmds)-1]); msg != "" {
+			state.pop()
+			state.aborted = state.inMulti
+			conn.WriteError(msg)
+			return
+		}
+		next(conn, cmd)
+	}
+}
+
+// checkAccess checks the user's permissions to run the command.
+// Returns the error message, or an empty string if allowed.
+func checkAccess(acls *acl.Manager, user string, pcmd redis.Cmd) string {
+	acmd := acl.Cmd{
+		Name:       pcmd.Name(),
+		Categories: pcmd.Info().Categories(),
+		Keys:       pcmd.Keys(),
+	}
+	if c, ok := pcmd.(interface{ AllKeys() bool }); ok {
+		// Commands like SORT may access any key by pattern.
+		acmd.AllKeys = c.AllKeys()
+	}
+	if args := pcmd.Args(); len(args) > 0 {
+		acmd.Sub = strings.ToLower(string(args[0]))
+	}
+	err := acls.Check(user, acmd)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, acl.ErrCommand):
+		return fmt.Sprintf("NOPERM User %s has no permissions to run the '%s' command", user, pcmd.Name())
+	case errors.Is(err, acl.ErrKey):
+		return redis.ErrNoPermKey.Error()
+	default:
+		return redis.ErrNoAuth.Error()
+	}
+}
+
+// pause holds the command while the clients are paused
+// (CLIENT PAUSE). Write commands (and transactions with
+// writes) are held in any pause mode, the rest only in
+// the ALL mode. CLIENT commands are never held, so that
+// a paused server can be unpaused.
+func pause(reg *clients.Registry, next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		if reg == nil {
+			next(conn, cmd)
+			return
+		}
+		name := normName(cmd)
+		if name != "client" {
+			state := getState(conn)
+			// Queued commands are held on EXEC.
+			write := state.hasWrites() && (!state.inMulti || name == "exec")
+			reg.Wait(write)
+		}
+		next(conn, cmd)
+	}
+}
+
 // multi handles the MULTI, EXEC, and DISCARD commands and delegates
 // the rest to the next handler either in multi or single mode.
+// EXEC fails if any command was rejected while queueing.
 func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
 	return func(conn redcon.Conn, cmd redcon.Command) {
 		name := normName(cmd)
@@ -51,6 +309,14 @@ func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
 				state.pop()
 				conn.WriteError(redis.ErrNestedMulti.Error())
 			case "exec":
+				if state.aborted {
+					// Some of the commands were rejected
+					// while queueing, so discard them all.
+					state.clear()
+					conn.WriteError(redis.ErrExecAbort.Error())
+					state.inMulti, state.aborted = false, false
+					return
+				}
 				state.pop()
 				conn.WriteArray(len(state.cmds))
 				next(conn, cmd)
@@ -58,7 +324,7 @@ func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
 			case "discard":
 				state.clear()
 				conn.WriteString("OK")
-				state.inMulti = false
+				state.inMulti, state.aborted = false, false
 			default:
 				conn.WriteString("QUEUED")
 			}
@@ -82,43 +348,130 @@ func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
 }
 
 // handle processes the command in either multi or single mode.
-func handle(db *redka.DB) redcon.HandlerFunc {
+// Appends successful write commands to the command log (if any).
+func handle(db *redka.DB, env handlerEnv, log *aof.Logger) redcon.HandlerFunc {
+	// Serialize the write commands when logging,
+	// so that they are logged in the order they are applied.
+	var logMu sync.Mutex
 	return func(conn redcon.Conn, cmd redcon.Command) {
 		state := getState(conn)
+		if log != nil && state.hasWrites() {
+			logMu.Lock()
+			defer logMu.Unlock()
+		}
+		// HELLO may switch the protocol version,
+		// and SELECT the database.
+		w := redis.NewConn(conn, state.proto)
+		w.SetDB(state.db)
 		if state.inMulti {
-			handleMulti(conn, state, db)
+			handleMulti(w, state, db.Select(state.db), env, log)
 		} else {
-			handleSingle(conn, state, db)
+			handleSingle(w, state, db.Select(state.db), env, log)
 		}
 		state.clear()
+		if w.Proto() != state.proto {
+			state.proto = w.Proto()
+			if state.client != nil {
+				state.client.SetProto(w.Proto())
+			}
+		}
+		if w.DB() != state.db {
+			state.db = w.DB()
+			if state.client != nil {
+				state.client.SetDB(w.DB())
+			}
+		}
 	}
 }
 
 // handleMulti processes a batch of commands in a transaction.
-func handleMulti(conn redcon.Conn, state *connState, db *redka.DB) {
-	err := db.Update(func(tx *redka.Tx) error {
+// Read-only batches run in a read-only transaction.
+// Commands after SELECT run in the newly selected database.
+func handleMulti(conn *redis.Conn, state *connState, db *redka.DB, env handlerEnv, log *aof.Logger) {
+	execTx := db.Update
+	if state.isReadOnly() {
+		execTx = db.View
+	}
+	var writes [][][]byte
+	err := execTx(func(tx *redka.Tx) error {
+		writes = writes[:0]
+		conn.SetDB(db.Index())
+		index, logDB := db.Index(), db.Index()
+		red := env.redka(redis.RedkaTx(tx), state.client)
 		for _, pcmd := range state.cmds {
-			_, err := pcmd.Run(conn, redis.RedkaTx(tx))
+			if conn.DB() != index {
+				index = conn.DB()
+				red = env.redka(redis.RedkaTx(tx.Select(index)), state.client)
+			}
+			if log != nil && pcmd.IsWrite() && conn.DB() != logDB {
+				logDB = conn.DB()
+				writes = append(writes, [][]byte{[]byte("select"), []byte(strconv.Itoa(logDB))})
+			}
+			res, err := pcmd.Run(conn, red)
 			if err != nil {
 				slog.Warn("run multi command", "client", conn.RemoteAddr(),
 					"name", pcmd.Name(), "err", err)
 				return err
 			}
+			if log != nil && pcmd.IsWrite() {
+				if args := logArgs(pcmd, res); args != nil {
+					writes = append(writes, args)
+				}
+			}
 		}
 		return nil
 	})
 	if err != nil {
 		slog.Warn("run multi", "client", conn.RemoteAddr(), "err", err)
+		return
 	}
+	appendLog(log, db.Index(), writes...)
 }
 
 // handleSingle processes a single command.
-func handleSingle(conn redcon.Conn, state *connState, db *redka.DB) {
+// Read-only commands run in a read-only transaction.
+func handleSingle(conn *redis.Conn, state *connState, db *redka.DB, env handlerEnv, log *aof.Logger) {
 	pcmd := state.pop()
-	_, err := pcmd.Run(conn, redis.RedkaDB(db))
+	var res any
+	var err error
+	if pcmd.IsReadOnly() {
+		err = db.View(func(tx *redka.Tx) error {
+			res, err = pcmd.Run(conn, env.redka(redis.RedkaTx(tx), state.client))
+			return err
+		})
+	} else {
+		res, err = pcmd.Run(conn, env.redka(redis.RedkaDB(db), state.client))
+	}
 	if err != nil {
 		slog.Warn("run single command", "client", conn.RemoteAddr(),
 			"name", pcmd.Name(), "err", err)
 		return
 	}
+	if log != nil && pcmd.IsWrite() {
+		if args := logArgs(pcmd, res); args != nil {
+			appendLog(log, db.Index(), args)
+		}
+	}
+}
+
+// logArgs returns the command arguments (starting with the name)
+// to append to the command log, given the command result.
+// Returns nil if there is nothing to log.
+func logArgs(pcmd redis.Cmd, res any) [][]byte {
+	args := make([][]byte, 0, len(pcmd.Args())+1)
+	args = append(args, []byte(pcmd.Name()))
+	args = append(args, pcmd.Args()...)
+	return aof.Rewrite(args, res, time.Now())
+}
+
+// appendLog appends the commands run in the given database
+// to the command log. The commands have already been applied,
+// so a failed write is logged rather than reported to the client.
+func appendLog(log *aof.Logger, index int, cmds ...[][]byte) {
+	if log == nil || len(cmds) == 0 {
+		return
+	}
+	if err := log.Append(index, cmds...); err != nil {
+		slog.Error("append to command log", "path", log.Path(), "error", err)
+	}
 }
@@ -1,12 +1,19 @@
 package server
 
 import (
+	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/repl"
+	"github.com/flarco/redka/internal/stats"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/tidwall/redcon"
 )
@@ -17,7 +24,7 @@ func TestHandlers(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	mux := createHandlers(db)
+	mux := createHandlers(db, nil, nil, nil, nil, nil, nil, nil, nil)
 	tests := []struct {
 		cmd  redcon.Command
 		want string
@@ -39,6 +46,249 @@ func TestHandlers(t *testing.T) {
 	}
 }
 
+func TestHandlersReadOnly(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_ = db.Str().Set("name", "alice")
+
+	manager := repl.NewManager(db, nil)
+	defer manager.Close()
+	_ = manager.ReplicaOf("localhost:1")
+
+	mux := createHandlers(db, nil, manager, nil, nil, nil, nil, nil, nil)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"GET", "name"},
+			want: "alice",
+		},
+		{
+			args: []string{"SET", "name", "bob"},
+			want: redis.ErrReadOnly.Error() + " (set)",
+		},
+	}
+	for _, test := range tests {
+		cmd := redcon.Command{Args: make([][]byte, len(test.args))}
+		for i, arg := range test.args {
+			cmd.Args[i] = []byte(arg)
+		}
+		conn := new(fakeConn)
+		mux.ServeRESP(conn, cmd)
+		if conn.out() != test.want {
+			t.Fatalf("want '%s', got '%s'", test.want, conn.out())
+		}
+	}
+}
+
+func TestHandlersView(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Read-only commands and MULTI blocks run in read-only
+	// transactions, the rest in read-write ones.
+	mux := createHandlers(db, nil, nil, nil, nil, nil, nil, nil, nil)
+	conn := new(fakeConn)
+	for _, args := range [][]string{
+		{"SET", "name", "alice"},
+		{"GET", "name"},
+		{"MULTI"},
+		{"GET", "name"},
+		{"STRLEN", "name"},
+		{"EXEC"},
+		{"MULTI"},
+		{"GET", "name"},
+		{"SET", "name", "bob"},
+		{"EXEC"},
+		{"GET", "name"},
+	} {
+		cmd := redcon.Command{Args: make([][]byte, len(args))}
+		for i, arg := range args {
+			cmd.Args[i] = []byte(arg)
+		}
+		mux.ServeRESP(conn, cmd)
+	}
+	want := "OK,alice," +
+		"OK,QUEUED,QUEUED,2,alice,5," +
+		"OK,QUEUED,QUEUED,2,alice,OK," +
+		"bob"
+	if conn.out() != want {
+		t.Fatalf("want '%s', got '%s'", want, conn.out())
+	}
+}
+
+func TestHandlersExecAbort(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A command rejected while queueing discards
+	// the whole transaction on EXEC.
+	mux := createHandlers(db, nil, nil, nil, nil, nil, nil, nil, nil)
+	conn := new(fakeConn)
+	for _, args := range [][]string{
+		{"MULTI"},
+		{"SET", "abort:name", "alice"},
+		{"GET"},
+		{"EXEC"},
+		{"GET", "abort:name"},
+		{"MULTI"},
+		{"SET", "abort:name", "bob"},
+		{"EXEC"},
+		{"GET", "abort:name"},
+	} {
+		cmd := redcon.Command{Args: make([][]byte, len(args))}
+		for i, arg := range args {
+			cmd.Args[i] = []byte(arg)
+		}
+		mux.ServeRESP(conn, cmd)
+	}
+	want := "OK,QUEUED,ERR wrong number of arguments ()," +
+		redis.ErrExecAbort.Error() + ",(nil)," +
+		"OK,QUEUED,1,OK," +
+		"bob"
+	if conn.out() != want {
+		t.Fatalf("want '%s', got '%s'", want, conn.out())
+	}
+}
+
+func TestHandlersStats(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	collector := stats.New(db, &stats.Options{Version: "1.0.0"})
+	mux := createHandlers(db, nil, nil, nil, collector, nil, nil, nil, nil)
+	for _, args := range [][]string{
+		{"SET", "name", "alice"},
+		{"GET", "name"},
+		{"INFO", "stats"},
+		{"FOO", "bar"},
+	} {
+		cmd := redcon.Command{Args: make([][]byte, len(args))}
+		for i, arg := range args {
+			cmd.Args[i] = []byte(arg)
+		}
+		conn := new(fakeConn)
+		mux.ServeRESP(conn, cmd)
+		if args[0] == "INFO" && !strings.Contains(conn.out(), "total_commands_processed:2\r\n") {
+			t.Fatalf("want 2 processed commands, got '%s'", conn.out())
+		}
+	}
+	if got := collector.Server().Commands; got != 4 {
+		t.Fatalf("want 4 processed commands, got %d", got)
+	}
+
+	// Unknown commands are counted together.
+	var b strings.Builder
+	if err := collector.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`redka_commands_total{cmd="get"} 1`,
+		`redka_command_errors_total{cmd="get"} 0`,
+		`redka_command_errors_total{cmd="unknown"} 1`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Fatalf("want '%s' in metrics", line)
+		}
+	}
+}
+
+func TestHandlersAOF(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	log, err := aof.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := createHandlers(db, nil, nil, log, nil, nil, nil, nil, nil)
+	conn := new(fakeConn)
+	for _, args := range [][]string{
+		{"SET", "name", "alice"},
+		{"GET", "name"},
+		{"INCR", "name"},
+		{"MULTI"},
+		{"SET", "name", "bob"},
+		{"RPUSH", "list", "one"},
+		{"EXEC"},
+		{"SADD", "set", "one"},
+		{"SPOP", "set"},
+		{"SELECT", "2"},
+		{"SET", "name", "cindy"},
+		{"MULTI"},
+		{"SET", "age", "25"},
+		{"SELECT", "3"},
+		{"INCR", "count"},
+		{"EXEC"},
+	} {
+		cmd := redcon.Command{Args: make([][]byte, len(args))}
+		for i, arg := range args {
+			cmd.Args[i] = []byte(arg)
+		}
+		mux.ServeRESP(conn, cmd)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only successful write commands are logged.
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var got []string
+	rd := aof.NewReader(file)
+	for {
+		e, err := rd.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = string(arg)
+		}
+		got = append(got, strings.Join(args, " "))
+	}
+	want := []string{
+		"set name alice",
+		"multi", "set name bob", "rpush list one", "exec",
+		"sadd set one",
+		"srem set one",
+		"select 2", "set name cindy",
+		"multi", "set age 25", "select 3", "incr count", "exec",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	// The commands after SELECT run in the selected database.
+	count, _ := db.Select(3).Str().Get("count")
+	if count.String() != "1" {
+		t.Fatalf("want count=1 in db 3, got %q", count)
+	}
+}
+
 type fakeConn struct {
 	parts []string
 	ctx   any
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flarco/redka/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+// monitorBufSize is the number of command lines buffered
+// per monitoring client. Clients that fall further behind
+// are disconnected (similar to the Redis output buffer limit).
+const monitorBufSize = 10000
+
+// monitor streams the processed commands to
+// the monitoring clients (the MONITOR command).
+// Safe for concurrent use.
+type monitor struct {
+	n      atomic.Int64 // number of attached clients
+	mu     sync.Mutex
+	conns  map[*monitorConn]struct{}
+	closed func(conn redcon.Conn) // called when a client disconnects
+}
+
+// newMonitor creates a new command monitor.
+// The closed function is optional.
+func newMonitor(closed func(conn redcon.Conn)) *monitor {
+	return &monitor{conns: map[*monitorConn]struct{}{}, closed: closed}
+}
+
+// attach detaches the connection from the server
+// and starts streaming commands to it.
+func (m *monitor) attach(conn redcon.Conn) {
+	// The server doesn't track detached connections,
+	// so the monitor reports when the client disconnects.
+	state := getState(conn)
+	state.monitor = true
+	if state.client != nil {
+		state.client.SetMonitor()
+	}
+
+	dconn := conn.Detach()
+	dconn.WriteString("OK")
+	if err := dconn.Flush(); err != nil {
+		dconn.Close()
+		m.disconnect(dconn)
+		return
+	}
+	mc := &monitorConn{
+		conn:  dconn,
+		addr:  conn.RemoteAddr(),
+		lines: make(chan string, monitorBufSize),
+		done:  make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.conns[mc] = struct{}{}
+	m.n.Add(1)
+	m.mu.Unlock()
+	slog.Debug("attach monitor", "client", mc.addr)
+
+	go func() {
+		mc.write()
+		m.disconnect(dconn)
+	}()
+	go func() {
+		quit := mc.read()
+		m.detach(mc, quit)
+	}()
+}
+
+// detach stops streaming commands to the monitoring client
+// and closes the connection. If quit is true, replies OK
+// to the client before closing.
+func (m *monitor) detach(mc *monitorConn, quit bool) {
+	m.mu.Lock()
+	if _, ok := m.conns[mc]; ok {
+		delete(m.conns, mc)
+		m.n.Add(-1)
+	}
+	m.mu.Unlock()
+	mc.stop(quit)
+	slog.Debug("detach monitor", "client", mc.addr)
+}
+
+// feed sends the command to the monitoring clients.
+// Skips unknown commands, as Redis does.
+// Does nothing if there are no clients.
+func (m *monitor) feed(conn redcon.Conn, cmd redcon.Command) {
+	if m == nil || m.n.Load() == 0 {
+		return
+	}
+	if statsName(cmd) == "unknown" {
+		return
+	}
+	line := formatMonitor(time.Now(), getState(conn).db, conn.RemoteAddr(), redactArgs(cmd.Args))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for mc := range m.conns {
+		select {
+		case mc.lines <- line:
+		default:
+			// The client can't keep up, so disconnect it
+			// instead of buffering an unlimited number of lines.
+			slog.Warn("disconnect slow monitor", "client", mc.addr)
+			delete(m.conns, mc)
+			m.n.Add(-1)
+			mc.stop(false)
+		}
+	}
+}
+
+// close disconnects all monitoring clients.
+func (m *monitor) close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for mc := range m.conns {
+		delete(m.conns, mc)
+		m.n.Add(-1)
+		mc.stop(false)
+	}
+}
+
+// disconnect reports that the client has disconnected.
+func (m *monitor) disconnect(conn redcon.Conn) {
+	if m.closed != nil {
+		m.closed(conn)
+	}
+}
+
+// monitorConn is a connection of a monitoring client.
+type monitorConn struct {
+	conn  redcon.DetachedConn
+	addr  string
+	lines chan string
+	done  chan struct{}
+	once  sync.Once
+	quit  bool
+}
+
+// read reads the client commands until the client
+// disconnects or sends QUIT. Ignores other commands.
+// Returns true if the client has sent QUIT.
+func (c *monitorConn) read() bool {
+	for {
+		cmd, err := c.conn.ReadCommand()
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(string(cmd.Args[0]), "quit") {
+			return true
+		}
+	}
+}
+
+// write sends the command lines to the client
+// until stopped, then closes the connection.
+func (c *monitorConn) write() {
+	defer c.conn.Close()
+	for {
+		select {
+		case line := <-c.lines:
+			c.conn.WriteString(line)
+			// Batch the lines that are already queued.
+			for n := len(c.lines); n > 0; n-- {
+				c.conn.WriteString(<-c.lines)
+			}
+			if err := c.conn.Flush(); err != nil {
+				return
+			}
+		case <-c.done:
+			if c.quit {
+				c.conn.WriteString("OK")
+			}
+			return
+		}
+	}
+}
+
+// stop signals the writer to close the connection.
+func (c *monitorConn) stop(quit bool) {
+	c.once.Do(func() {
+		c.quit = quit
+		close(c.done)
+	})
+}
+
+// monitoring handles the MONITOR command, sends the rest
+// to the monitoring clients (if any) and delegates them
+// to the next handler.
+func monitoring(mon *monitor, next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		if mon == nil || normName(cmd) != "monitor" {
+			mon.feed(conn, cmd)
+			next(conn, cmd)
+			return
+		}
+		state := getState(conn)
+		state.pop()
+		if state.inMulti {
+			conn.WriteError(redis.ErrNotAllowedInMulti.Error() + " (monitor)")
+			return
+		}
+		if len(cmd.Args) != 1 {
+			conn.WriteError(redis.ErrInvalidArgNum.Error() + " (monitor)")
+			return
+		}
+		mon.attach(conn)
+	}
+}
+
+// formatMonitor returns the command line in the Redis MONITOR
+// format (sent to the client as a simple string):
+//
+//	1339518083.107412 [0 127.0.0.1:60866] "keys" "*"
+func formatMonitor(now time.Time, db int, addr string, args [][]byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%06d [%d %s]", now.Unix(), now.Nanosecond()/1000, db, addr)
+	for _, arg := range args {
+		b.WriteByte(' ')
+		writeQuoted(&b, arg)
+	}
+	return b.String()
+}
+
+// writeQuoted writes the argument as a quoted string,
+// escaping special and non-printable characters
+// (same as the Redis sdscatrepr function).
+func writeQuoted(b *strings.Builder, arg []byte) {
+	b.WriteByte('"')
+	for _, c := range arg {
+		switch c {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		default:
+			if c >= 0x20 && c < 0x7f {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(b, `\x%02x`, c)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
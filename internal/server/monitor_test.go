@@ -0,0 +1,69 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+)
+
+func TestFormatMonitor(t *testing.T) {
+	now := time.Unix(1339518083, 107412000)
+	args := [][]byte{
+		[]byte("set"), []byte("name"), []byte("say \"hi\"\r\n\\\x00\xff"),
+	}
+	got := formatMonitor(now, 0, "127.0.0.1:60866", args)
+	want := `1339518083.107412 [0 127.0.0.1:60866] "set" "name" "say \"hi\"\r\n\\\x00\xff"`
+	if got != want {
+		t.Fatalf("want '%s', got '%s'", want, got)
+	}
+
+	got = formatMonitor(now, 3, "127.0.0.1:60866", args[:2])
+	want = `1339518083.107412 [3 127.0.0.1:60866] "set" "name"`
+	if got != want {
+		t.Fatalf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	srv := New("unix", addr, db, nil)
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	mon := dial(t, addr)
+	defer mon.Close()
+	cli := dial(t, addr)
+	defer cli.Close()
+
+	mon.send(t, "MONITOR")
+	mon.expect(t, `^\+OK$`)
+
+	cli.send(t, "SET", "name", "alice")
+	cli.expect(t, `^\+OK$`)
+	cli.send(t, "GET", "name")
+	cli.expect(t, `^\$5$`)
+	cli.expect(t, `^alice$`)
+	cli.send(t, "FOO")
+	cli.expect(t, `^-ERR unknown command`)
+
+	// Only accepted commands are sent to the monitor.
+	mon.expect(t, `^\+\d+\.\d{6} \[0 .*\] "SET" "name" "alice"$`)
+	mon.expect(t, `^\+\d+\.\d{6} \[0 .*\] "GET" "name"$`)
+
+	// Other commands are ignored in monitor mode.
+	mon.send(t, "GET", "name")
+	mon.send(t, "QUIT")
+	mon.expect(t, `^\+OK$`)
+
+	// Monitors are not allowed in transactions.
+	cli.send(t, "MULTI")
+	cli.expect(t, `^\+OK$`)
+	cli.send(t, "MONITOR")
+	cli.expect(t, `^-ERR Command not allowed inside a transaction`)
+}
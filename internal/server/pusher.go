@@ -0,0 +1,262 @@
+package server
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+// pushBufSize is the number of invalidation messages buffered
+// per client. Clients that fall further behind are disconnected
+// (similar to the Redis output buffer limit).
+const pushBufSize = 10000
+
+// pusher serves the clients that receive invalidation messages
+// (CLIENT TRACKING and SUBSCRIBE). Such connections are detached
+// from the server, so that the messages can be written between
+// the command replies. Safe for concurrent use.
+type pusher struct {
+	mu     sync.Mutex
+	conns  map[*pushConn]struct{}
+	closed func(conn redcon.Conn) // called when a client disconnects
+}
+
+// newPusher creates a new invalidation message pusher.
+// The closed function is optional.
+func newPusher(closed func(conn redcon.Conn)) *pusher {
+	return &pusher{conns: map[*pushConn]struct{}{}, closed: closed}
+}
+
+// attach detaches the connection from the server, and starts
+// serving its commands with the handler and writing
+// the invalidation messages in between.
+func (p *pusher) attach(conn redcon.Conn, handler redcon.HandlerFunc) {
+	// The server doesn't track detached connections,
+	// so the pusher reports when the client disconnects.
+	state := getState(conn)
+	state.pushing = true
+	pc := &pushConn{
+		conn:  conn.Detach(),
+		state: state,
+		msgs:  make(chan clients.Invalidation, pushBufSize),
+		done:  make(chan struct{}),
+	}
+	state.client.SetNotify(pc.notify)
+
+	p.mu.Lock()
+	p.conns[pc] = struct{}{}
+	p.mu.Unlock()
+	slog.Debug("attach pusher", "client", conn.RemoteAddr())
+
+	go pc.write()
+	go func() {
+		pc.serve(handler)
+		p.detach(pc)
+	}()
+}
+
+// detach stops writing the invalidation messages to the client.
+// Closes the connection unless the client has switched
+// to the monitoring mode (which takes over the connection).
+func (p *pusher) detach(pc *pushConn) {
+	p.mu.Lock()
+	delete(p.conns, pc)
+	p.mu.Unlock()
+	pc.state.client.SetNotify(nil)
+	pc.stop()
+	if pc.state.monitor {
+		return
+	}
+	pc.mu.Lock()
+	pc.conn.Close()
+	pc.mu.Unlock()
+	slog.Debug("detach pusher", "client", pc.conn.RemoteAddr())
+	if p.closed != nil {
+		p.closed(pc.conn)
+	}
+}
+
+// close disconnects all clients.
+func (p *pusher) close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pc := range p.conns {
+		// The serve loop fails to read from the closed
+		// connection and detaches the client.
+		_ = pc.conn.NetConn().Close()
+	}
+}
+
+// pushConn is a connection of a client
+// receiving invalidation messages.
+type pushConn struct {
+	conn  redcon.DetachedConn
+	state *connState
+	msgs  chan clients.Invalidation
+	done  chan struct{}
+	once  sync.Once
+	mu    sync.Mutex // serializes the replies and the messages
+}
+
+// serve runs the client commands until the client
+// disconnects or switches to the monitoring mode.
+func (c *pushConn) serve(handler redcon.HandlerFunc) {
+	// Send the reply to the command that has attached the client.
+	c.mu.Lock()
+	err := c.conn.Flush()
+	c.mu.Unlock()
+	if err != nil {
+		return
+	}
+	for {
+		cmd, err := c.conn.ReadCommand()
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		handler(c.conn, cmd)
+		if c.state.monitor {
+			// The monitor has taken over the connection.
+			c.mu.Unlock()
+			return
+		}
+		err = c.conn.Flush()
+		c.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// write sends the invalidation messages to the client until stopped.
+func (c *pushConn) write() {
+	for {
+		select {
+		case msg := <-c.msgs:
+			c.mu.Lock()
+			w := redis.NewConn(c.conn, c.state.proto)
+			writeInvalidation(w, msg)
+			// Batch the messages that are already queued.
+			for n := len(c.msgs); n > 0; n-- {
+				writeInvalidation(w, <-c.msgs)
+			}
+			err := c.conn.Flush()
+			c.mu.Unlock()
+			if err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// notify queues the invalidation message. Disconnects
+// the client if it can't keep up with the messages.
+func (c *pushConn) notify(msg clients.Invalidation) {
+	select {
+	case c.msgs <- msg:
+	default:
+		slog.Warn("disconnect slow tracking client", "client", c.conn.RemoteAddr())
+		_ = c.conn.NetConn().Close()
+	}
+}
+
+// stop signals the writer to stop.
+func (c *pushConn) stop() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+// writeInvalidation writes the invalidation message either
+// as an invalidate push (RESP3) or as a message published
+// to the __redis__:invalidate channel (when redirected).
+// A nil list of keys means that all keys are invalidated.
+func writeInvalidation(w *redis.Conn, msg clients.Invalidation) {
+	if msg.Channel {
+		w.WritePush(3)
+		w.WriteBulkString("message")
+		w.WriteBulkString(clients.InvalidateChannel)
+	} else {
+		w.WritePush(2)
+		w.WriteBulkString("invalidate")
+	}
+	if msg.Keys == nil {
+		w.WriteNull()
+		return
+	}
+	w.WriteArray(len(msg.Keys))
+	for _, key := range msg.Keys {
+		w.WriteBulkString(key)
+	}
+}
+
+// pushing attaches the clients that have enabled
+// the tracking or subscribed to the invalidation
+// channel to the pusher after running the command.
+func pushing(push *pusher, next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		next(conn, cmd)
+		if push == nil {
+			return
+		}
+		state := getState(conn)
+		if state.client == nil || state.pushing || state.monitor {
+			return
+		}
+		if state.client.Killed() || !state.client.ReceivesPushes() {
+			return
+		}
+		push.attach(conn, next)
+	}
+}
+
+// tracking records the keys read by the commands for the
+// tracking clients, and notifies them when the commands
+// modify the keys (CLIENT TRACKING).
+func tracking(reg *clients.Registry, next redcon.HandlerFunc) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if reg == nil || state.client == nil {
+			next(conn, cmd)
+			return
+		}
+		// The handler clears the state, so keep the commands.
+		// Track the keys before reading them, so that
+		// concurrent changes are not missed.
+		cmds := state.cmds
+		for _, pcmd := range cmds {
+			if pcmd.IsReadOnly() {
+				reg.Track(state.client, pcmd.Keys())
+			}
+		}
+		next(conn, cmd)
+		for _, pcmd := range cmds {
+			switch {
+			case pcmd.Name() == "flushdb" || pcmd.Name() == "flushall" ||
+				pcmd.Name() == "swapdb":
+				reg.InvalidateAll(state.client)
+			case pcmd.IsWrite():
+				reg.Invalidate(pcmd.Keys(), state.client)
+			}
+		}
+		// CLIENT CACHING applies to the next command only.
+		if !isClientCaching(cmd) {
+			state.client.SetCaching("")
+		}
+	}
+}
+
+// isClientCaching reports whether the command is CLIENT CACHING.
+func isClientCaching(cmd redcon.Command) bool {
+	return normName(cmd) == "client" && len(cmd.Args) > 1 &&
+		strings.EqualFold(string(cmd.Args[1]), "caching")
+}
@@ -6,64 +6,205 @@ import (
 	"sync"
 
 	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/aof"
+	"github.com/flarco/redka/internal/clients"
+	"github.com/flarco/redka/internal/persist"
+	"github.com/flarco/redka/internal/redis"
+	"github.com/flarco/redka/internal/repl"
+	"github.com/flarco/redka/internal/stats"
 	"github.com/tidwall/redcon"
 )
 
+// Options configure the server.
+type Options struct {
+	// Saver saves database snapshots on SAVE and BGSAVE.
+	// If nil, the persistence commands return an error.
+	Saver *persist.Saver
+	// Repl switches the database between the leader and replica
+	// roles on REPLICAOF. If nil, the replication commands
+	// return an error (except ROLE, which reports a leader).
+	Repl *repl.Manager
+	// AOF is the command log that records successful
+	// write commands. If nil, the commands are not logged.
+	AOF *aof.Logger
+	// Stats configure the server statistics (INFO, SLOWLOG,
+	// LATENCY and metrics). If nil, uses the defaults.
+	Stats *stats.Options
+	// ACL authenticates the clients and checks their
+	// permissions (AUTH and ACL). If nil, all clients
+	// use the default user with no password.
+	ACL *acl.Manager
+	// TLS configures an additional listener for TLS
+	// connections. If nil, the server accepts
+	// only plaintext connections.
+	TLS *TLSOptions
+}
+
 // Server represents a Redka server.
 type Server struct {
-	net  string
-	addr string
-	srv  *redcon.Server
-	db   *redka.DB
-	wg   *sync.WaitGroup
+	net     string
+	addr    string
+	srv     *redcon.Server    // nil if plaintext connections are disabled
+	tls     *redcon.TLSServer // nil if TLS connections are disabled
+	tlsAddr string
+	db      *redka.DB
+	saver   *persist.Saver
+	repl    *repl.Manager
+	aof     *aof.Logger
+	stats   *stats.Collector
+	mon     *monitor
+	push    *pusher
+	wg      *sync.WaitGroup
 }
 
 // New creates a new Redka server.
-func New(net string, addr string, db *redka.DB) *Server {
-	handler := createHandlers(db)
+// If addr is empty, the server accepts only TLS
+// connections (which requires opts.TLS).
+// The opts parameter is optional.
+func New(net string, addr string, db *redka.DB, opts *Options) *Server {
+	if opts == nil {
+		opts = &Options{}
+	}
+	// Avoid passing a typed nil pointer as an interface.
+	var saver redis.RSaver
+	if opts.Saver != nil {
+		saver = opts.Saver
+	}
+	var manager redis.RRepl
+	if opts.Repl != nil {
+		manager = opts.Repl
+	}
+	acls := opts.ACL
+	if acls == nil {
+		// Without a file, the manager never fails.
+		acls, _ = acl.NewManager(nil)
+	}
+	collector := stats.New(db, opts.Stats)
+	reg := clients.NewRegistry()
+	disconnect := func(conn redcon.Conn) {
+		reg.Remove(getState(conn).client)
+		collector.Disconnect()
+	}
+	mon := newMonitor(disconnect)
+	push := newPusher(disconnect)
+	handler := createHandlers(db, saver, manager, opts.AOF, collector, mon, reg, acls, push)
 	accept := func(conn redcon.Conn) bool {
 		slog.Info("accept connection", "client", conn.RemoteAddr())
+		netConn := conn.NetConn()
+		client := reg.Add(conn.RemoteAddr(), netConn.LocalAddr().String(), netConn.Close)
+		conn.SetContext(&connState{client: client})
+		collector.Connect()
 		return true
 	}
 	closed := func(conn redcon.Conn, err error) {
+		if state := getState(conn); state.monitor || state.pushing {
+			// The monitor (or the pusher) disconnects the client.
+			slog.Debug("detach connection", "client", conn.RemoteAddr())
+			return
+		}
+		disconnect(conn)
 		if err != nil {
 			slog.Debug("close connection", "client", conn.RemoteAddr(), "error", err)
 		} else {
 			slog.Debug("close connection", "client", conn.RemoteAddr())
 		}
 	}
-	return &Server{
-		net:  net,
-		addr: addr,
-		srv:  redcon.NewServerNetwork(net, addr, handler, accept, closed),
-		db:   db,
-		wg:   &sync.WaitGroup{},
+	s := &Server{
+		net:   net,
+		addr:  addr,
+		db:    db,
+		saver: opts.Saver,
+		repl:  opts.Repl,
+		aof:   opts.AOF,
+		stats: collector,
+		mon:   mon,
+		push:  push,
+		wg:    &sync.WaitGroup{},
 	}
+	if addr != "" {
+		s.srv = redcon.NewServerNetwork(net, addr, handler, accept, closed)
+	}
+	if opts.TLS != nil {
+		s.tls = redcon.NewServerTLS(opts.TLS.Addr, handler, accept, closed, opts.TLS.Config)
+		s.tlsAddr = opts.TLS.Addr
+	}
+	return s
+}
+
+// Stats returns the server statistics collector.
+func (s *Server) Stats() *stats.Collector {
+	return s.stats
 }
 
 // Start starts the server.
 func (s *Server) Start() {
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
-		slog.Info("serve connections", "addr", s.addr)
-		err := s.srv.ListenAndServe()
-		if err != nil {
-			slog.Error("serve connections", "error", err)
-		}
-	}()
+	s.stats.Start()
+	if s.srv != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			slog.Info("serve connections", "addr", s.addr)
+			err := s.srv.ListenAndServe()
+			if err != nil {
+				slog.Error("serve connections", "error", err)
+			}
+		}()
+	}
+	if s.tls != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			slog.Info("serve tls connections", "addr", s.tlsAddr)
+			err := s.tls.ListenAndServe()
+			if err != nil {
+				slog.Error("serve tls connections", "error", err)
+			}
+		}()
+	}
 }
 
 // Stop stops the server.
 func (s *Server) Stop() error {
-	err := s.srv.Close()
-	if err != nil {
-		return err
+	if s.srv != nil {
+		if err := s.srv.Close(); err != nil {
+			return err
+		}
+		slog.Debug("close redcon server", "addr", s.addr)
+	}
+	if s.tls != nil {
+		if err := s.tls.Close(); err != nil {
+			return err
+		}
+		slog.Debug("close redcon tls server", "addr", s.tlsAddr)
+	}
+
+	s.mon.close()
+	slog.Debug("close monitors")
+
+	s.push.close()
+	slog.Debug("close tracking clients")
+
+	s.stats.Stop()
+
+	if s.repl != nil {
+		s.repl.Close()
+		slog.Debug("stop replication")
+	}
+
+	if s.saver != nil {
+		s.saver.Wait()
+		slog.Debug("wait for background saves")
+	}
+
+	if s.aof != nil {
+		if err := s.aof.Close(); err != nil {
+			return err
+		}
+		slog.Debug("close command log")
 	}
-	slog.Debug("close redcon server", "addr", s.addr)
 
-	err = s.db.Close()
-	if err != nil {
+	if err := s.db.Close(); err != nil {
 		return err
 	}
 	slog.Debug("close database")
@@ -0,0 +1,488 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/acl"
+	"github.com/flarco/redka/internal/stats"
+	"github.com/tidwall/redcon"
+)
+
+func TestServerClients(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	srv := New("unix", addr, db, nil)
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	c1 := dial(t, addr)
+	defer c1.Close()
+	c2 := dial(t, addr)
+	defer c2.Close()
+
+	c1.send(t, "CLIENT", "SETNAME", "alice")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "CLIENT", "ID")
+	c1.expect(t, `^:1$`)
+	c2.send(t, "CLIENT", "ID")
+	c2.expect(t, `^:2$`)
+
+	c2.send(t, "MULTI")
+	c2.expect(t, `^\+OK$`)
+	c2.send(t, "CLIENT", "LIST")
+	c2.expect(t, `^\+QUEUED$`)
+	c2.send(t, "EXEC")
+	c2.expect(t, `^\*1$`)
+	c2.expect(t, `^\$\d+$`)
+	c2.expect(t, `^id=1 .* name=alice .* flags=N .* cmd=client `)
+	c2.expect(t, `^id=2 .* name= .* flags=x .* multi=1 .* cmd=exec `)
+	c2.expect(t, `^$`)
+
+	// Paused writes are held until the pause ends.
+	c2.send(t, "CLIENT", "PAUSE", "100", "WRITE")
+	c2.expect(t, `^\+OK$`)
+	start := time.Now()
+	c1.send(t, "PING")
+	c1.expect(t, `^\$4$`)
+	c1.expect(t, `^PONG$`)
+	if time.Since(start) >= 100*time.Millisecond {
+		t.Fatal("want reads to proceed during the pause")
+	}
+	c1.send(t, "SET", "city", "paris")
+	c1.expect(t, `^\+OK$`)
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatal("want writes to be held during the pause")
+	}
+
+	// Killed clients are disconnected.
+	c2.send(t, "CLIENT", "KILL", "ID", "1")
+	c2.expect(t, `^:1$`)
+	_ = c1.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c1.rd.ReadByte(); err != io.EOF {
+		t.Fatalf("want EOF, got %v", err)
+	}
+
+	// A client can kill itself.
+	c2.send(t, "CLIENT", "KILL", "ID", "2", "SKIPME", "NO")
+	c2.expect(t, `^:1$`)
+	if _, err := c2.rd.ReadByte(); err != io.EOF {
+		t.Fatalf("want EOF, got %v", err)
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acls, err := acl.NewManager(&acl.Options{RequirePass: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	opts := &Options{
+		ACL:   acls,
+		Stats: &stats.Options{SlowLogThreshold: 0, SlowLogMaxLen: 10},
+	}
+	srv := New("unix", addr, db, opts)
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	mc := dial(t, addr)
+	defer mc.Close()
+	mc.send(t, "AUTH", "secret")
+	mc.expect(t, `^\+OK$`)
+	mc.send(t, "MONITOR")
+	mc.expect(t, `^\+OK$`)
+
+	c := dial(t, addr)
+	defer c.Close()
+
+	// Commands require authentication.
+	c.send(t, "PING")
+	c.expect(t, `^-NOAUTH `)
+	c.send(t, "AUTH", "wrong")
+	c.expect(t, `^-WRONGPASS `)
+	mc.expect(t, `"AUTH" "\(redacted\)"$`)
+	c.send(t, "AUTH", "secret")
+	c.expect(t, `^\+OK$`)
+	mc.expect(t, `"AUTH" "\(redacted\)"$`)
+
+	// Users are limited to the allowed commands and keys.
+	c.send(t, "ACL", "SETUSER", "alice", "on", ">alicepass", "~user:*", "+@read", "+set", "+sort")
+	c.expect(t, `^\+OK$`)
+
+	// Passwords are hidden from the slow log.
+	c.send(t, "SLOWLOG", "GET", "1")
+	c.expect(t, `^\*1$`)
+	c.expect(t, `^\*6$`)
+	c.expect(t, `^:\d+$`)
+	c.expect(t, `^:\d+$`)
+	c.expect(t, `^:\d+$`)
+	c.expect(t, `^\*9$`)
+	for _, arg := range []string{"ACL", "SETUSER", "alice", "on", "(redacted)", "~user:*", "+@read", "+set", "+sort"} {
+		c.expect(t, `^\$\d+$`)
+		c.expect(t, "^"+regexp.QuoteMeta(arg)+"$")
+	}
+	c.expect(t, `^\$\d+$`)
+	c.expect(t, `^.*$`)
+	c.expect(t, `^\$0$`)
+	c.expect(t, `^$`)
+
+	c.send(t, "AUTH", "alice", "alicepass")
+	c.expect(t, `^\+OK$`)
+	c.send(t, "ACL", "WHOAMI")
+	c.expect(t, `^-NOPERM User alice has no permissions to run the 'acl' command$`)
+	c.send(t, "SET", "user:auth", "alice")
+	c.expect(t, `^\+OK$`)
+	c.send(t, "GET", "user:auth")
+	c.expect(t, `^\$5$`)
+	c.expect(t, `^alice$`)
+	c.send(t, "GET", "city:auth")
+	c.expect(t, `^-NOPERM No permissions to access a key$`)
+	c.send(t, "DEL", "user:auth")
+	c.expect(t, `^-NOPERM User alice has no permissions to run the 'del' command$`)
+
+	// SORT checks the STORE destination, and requires
+	// access to all keys for the BY and GET patterns.
+	c.send(t, "SORT", "user:list", "BY", "nosort", "GET", "secret:*")
+	c.expect(t, `^-NOPERM No permissions to access a key$`)
+	c.send(t, "SORT", "user:list", "BY", "secret:*")
+	c.expect(t, `^-NOPERM No permissions to access a key$`)
+	c.send(t, "SORT", "user:list", "STORE", "secret:x")
+	c.expect(t, `^-NOPERM No permissions to access a key$`)
+	c.send(t, "SORT", "user:list", "BY", "nosort", "GET", "#", "STORE", "user:sorted")
+	c.expect(t, `^:0$`)
+
+	// Rejected commands abort the transaction.
+	c.send(t, "MULTI")
+	c.expect(t, `^\+OK$`)
+	c.send(t, "SET", "user:auth", "bob")
+	c.expect(t, `^\+QUEUED$`)
+	c.send(t, "DEL", "user:auth")
+	c.expect(t, `^-NOPERM `)
+	c.send(t, "EXEC")
+	c.expect(t, `^-EXECABORT Transaction discarded because of previous errors\.$`)
+	c.send(t, "GET", "user:auth")
+	c.expect(t, `^\$5$`)
+	c.expect(t, `^alice$`)
+
+	mc.expect(t, `"ACL" "SETUSER" "alice" "on" "\(redacted\)" "~user:\*" "\+@read" "\+set" "\+sort"$`)
+	mc.expect(t, `"SLOWLOG" "GET" "1"$`)
+	mc.expect(t, `"AUTH" "\(redacted\)" "\(redacted\)"$`)
+	mc.expect(t, `"SET" "user:auth" "alice"$`)
+	mc.expect(t, `"GET" "user:auth"$`)
+	mc.expect(t, `"SORT" "user:list" "BY" "nosort" "GET" "#" "STORE" "user:sorted"$`)
+}
+
+func TestServerRESP3(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acls, err := acl.NewManager(&acl.Options{RequirePass: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	srv := New("unix", addr, db, &Options{ACL: acls})
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	c := dial(t, addr)
+	defer c.Close()
+
+	// HELLO requires authentication, unless it authenticates itself.
+	c.send(t, "HELLO", "3")
+	c.expect(t, `^-NOAUTH HELLO must be called`)
+	c.send(t, "HELLO", "3", "AUTH", "default", "secret", "SETNAME", "resp3")
+	c.expect(t, `^%7$`)
+	c.expect(t, `^\$6$`)
+	c.expect(t, `^server$`)
+	c.expect(t, `^\$5$`)
+	c.expect(t, `^redis$`)
+	for range 21 {
+		c.expect(t, `.`)
+	}
+
+	// Replies use the native RESP3 types.
+	c.send(t, "HSET", "resp3:hash", "name", "alice")
+	c.expect(t, `^:1$`)
+	c.send(t, "HGETALL", "resp3:hash")
+	c.expect(t, `^%1$`)
+	c.expect(t, `^\$4$`)
+	c.expect(t, `^name$`)
+	c.expect(t, `^\$5$`)
+	c.expect(t, `^alice$`)
+	c.send(t, "SADD", "resp3:set", "one")
+	c.expect(t, `^:1$`)
+	c.send(t, "SMEMBERS", "resp3:set")
+	c.expect(t, `^~1$`)
+	c.expect(t, `^\$3$`)
+	c.expect(t, `^one$`)
+	c.send(t, "ZADD", "resp3:zset", "1.5", "one")
+	c.expect(t, `^:1$`)
+	c.send(t, "ZSCORE", "resp3:zset", "one")
+	c.expect(t, `^,1\.5$`)
+	c.send(t, "GET", "resp3:none")
+	c.expect(t, `^_$`)
+	c.send(t, "CLIENT", "INFO")
+	c.expect(t, `^\$\d+$`)
+	c.expect(t, ` name=resp3 .* resp=3 `)
+	c.expect(t, `^$`)
+
+	// The client can switch back to RESP2.
+	c.send(t, "HELLO", "2")
+	c.expect(t, `^\*14$`)
+	for range 25 {
+		c.expect(t, `.`)
+	}
+	c.send(t, "GET", "resp3:none")
+	c.expect(t, `^\$-1$`)
+	c.send(t, "HELLO", "4")
+	c.expect(t, `^-NOPROTO `)
+}
+
+func TestServerTracking(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	srv := New("unix", addr, db, nil)
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	c1 := dial(t, addr)
+	defer c1.Close()
+	c2 := dial(t, addr)
+	defer c2.Close()
+	c3 := dial(t, addr)
+	defer c3.Close()
+	c4 := dial(t, addr)
+	defer c4.Close()
+
+	// RESP3 clients receive invalidate push messages.
+	c1.send(t, "HELLO", "3")
+	c1.expect(t, `^%7$`)
+	for range 25 {
+		c1.expect(t, `.`)
+	}
+	c1.send(t, "CLIENT", "TRACKING", "ON")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "GET", "track:name")
+	c1.expect(t, `^_$`)
+	c2.send(t, "SET", "track:name", "alice")
+	c2.expect(t, `^\+OK$`)
+	c1.expect(t, `^>2$`)
+	c1.expect(t, `^\$10$`)
+	c1.expect(t, `^invalidate$`)
+	c1.expect(t, `^\*1$`)
+	c1.expect(t, `^\$10$`)
+	c1.expect(t, `^track:name$`)
+	c1.send(t, "CLIENT", "TRACKINGINFO")
+	c1.expect(t, `^%3$`)
+	c1.expect(t, `^\$5$`)
+	c1.expect(t, `^flags$`)
+	c1.expect(t, `^\*1$`)
+	c1.expect(t, `^\$2$`)
+	c1.expect(t, `^on$`)
+	for range 6 {
+		c1.expect(t, `.`)
+	}
+
+	// SORT ... STORE invalidates the destination key.
+	c1.send(t, "GET", "track:sorted")
+	c1.expect(t, `^_$`)
+	c2.send(t, "RPUSH", "track:list", "2")
+	c2.expect(t, `^:1$`)
+	c2.send(t, "RPUSH", "track:list", "1")
+	c2.expect(t, `^:2$`)
+	c2.send(t, "SORT", "track:list", "STORE", "track:sorted")
+	c2.expect(t, `^:2$`)
+	c1.expect(t, `^>2$`)
+	c1.expect(t, `^\$10$`)
+	c1.expect(t, `^invalidate$`)
+	c1.expect(t, `^\*1$`)
+	c1.expect(t, `^\$12$`)
+	c1.expect(t, `^track:sorted$`)
+
+	// RESP2 clients receive the redirected messages
+	// on the __redis__:invalidate channel.
+	c3.send(t, "SUBSCRIBE", "__redis__:invalidate")
+	c3.expect(t, `^\*3$`)
+	c3.expect(t, `^\$9$`)
+	c3.expect(t, `^subscribe$`)
+	c3.expect(t, `^\$20$`)
+	c3.expect(t, `^__redis__:invalidate$`)
+	c3.expect(t, `^:1$`)
+	c3.send(t, "GET", "track:name")
+	c3.expect(t, `^-ERR Can't execute 'get': only \(P\|S\)SUBSCRIBE`)
+	c4.send(t, "CLIENT", "TRACKING", "ON", "REDIRECT", "3", "BCAST", "PREFIX", "bc:")
+	c4.expect(t, `^\+OK$`)
+	c2.send(t, "MSET", "bc:1", "one", "other", "two")
+	c2.expect(t, `^\+OK$`)
+	c3.expect(t, `^\*3$`)
+	c3.expect(t, `^\$7$`)
+	c3.expect(t, `^message$`)
+	c3.expect(t, `^\$20$`)
+	c3.expect(t, `^__redis__:invalidate$`)
+	c3.expect(t, `^\*1$`)
+	c3.expect(t, `^\$4$`)
+	c3.expect(t, `^bc:1$`)
+
+	// Flushing the database invalidates all keys.
+	c2.send(t, "FLUSHALL")
+	c2.expect(t, `^\+OK$`)
+	c1.expect(t, `^>2$`)
+	c1.expect(t, `^\$10$`)
+	c1.expect(t, `^invalidate$`)
+	c1.expect(t, `^_$`)
+	c3.expect(t, `^\*3$`)
+	for range 4 {
+		c3.expect(t, `.`)
+	}
+	c3.expect(t, `^\$-1$`)
+}
+
+func TestServerDatabases(t *testing.T) {
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(t.TempDir(), "redka.sock")
+	srv := New("unix", addr, db, nil)
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	c1 := dial(t, addr)
+	defer c1.Close()
+	c2 := dial(t, addr)
+	defer c2.Close()
+
+	// The selected database is per connection.
+	c1.send(t, "SET", "name", "alice")
+	c1.expect(t, `^\+OK$`)
+	c2.send(t, "SELECT", "1")
+	c2.expect(t, `^\+OK$`)
+	c2.send(t, "GET", "name")
+	c2.expect(t, `^\$-1$`)
+	c2.send(t, "SET", "name", "bob")
+	c2.expect(t, `^\+OK$`)
+	c2.send(t, "SET", "age", "25")
+	c2.expect(t, `^\+OK$`)
+	c1.send(t, "GET", "name")
+	c1.expect(t, `^\$5$`)
+	c1.expect(t, `^alice$`)
+	c1.send(t, "DBSIZE")
+	c1.expect(t, `^:1$`)
+	c2.send(t, "DBSIZE")
+	c2.expect(t, `^:2$`)
+	c2.send(t, "CLIENT", "INFO")
+	c2.expect(t, `^\$\d+$`)
+	c2.expect(t, ` db=1 `)
+	c2.expect(t, `^$`)
+	c2.send(t, "SELECT", "16")
+	c2.expect(t, `^-ERR DB index is out of range`)
+
+	// SELECT in a transaction applies to the following commands.
+	c1.send(t, "MULTI")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "SELECT", "1")
+	c1.expect(t, `^\+QUEUED$`)
+	c1.send(t, "GET", "age")
+	c1.expect(t, `^\+QUEUED$`)
+	c1.send(t, "EXEC")
+	c1.expect(t, `^\*2$`)
+	c1.expect(t, `^\+OK$`)
+	c1.expect(t, `^\$2$`)
+	c1.expect(t, `^25$`)
+	c1.send(t, "SELECT", "0")
+	c1.expect(t, `^\+OK$`)
+
+	// MOVE and SWAPDB.
+	c1.send(t, "MOVE", "name", "1")
+	c1.expect(t, `^:0$`)
+	c1.send(t, "SET", "city", "paris")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "MOVE", "city", "1")
+	c1.expect(t, `^:1$`)
+	c1.send(t, "MOVE", "city", "0")
+	c1.expect(t, `^-ERR source and destination objects are the same`)
+	c1.send(t, "SWAPDB", "0", "1")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "DBSIZE")
+	c1.expect(t, `^:3$`)
+	c2.send(t, "GET", "name")
+	c2.expect(t, `^\$5$`)
+	c2.expect(t, `^alice$`)
+
+	// FLUSHDB only removes the keys of the selected database,
+	// FLUSHALL removes the keys of all databases.
+	c2.send(t, "FLUSHDB")
+	c2.expect(t, `^\+OK$`)
+	c1.send(t, "DBSIZE")
+	c1.expect(t, `^:3$`)
+	c1.send(t, "FLUSHALL")
+	c1.expect(t, `^\+OK$`)
+	c1.send(t, "DBSIZE")
+	c1.expect(t, `^:0$`)
+}
+
+// testConn is a client connection for server tests.
+type testConn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *testConn {
+	t.Helper()
+	var conn net.Conn
+	var err error
+	for range 50 {
+		conn, err = net.Dial("unix", addr)
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testConn{Conn: conn, rd: bufio.NewReader(conn)}
+}
+
+func (c *testConn) send(t *testing.T, args ...string) {
+	t.Helper()
+	var buf []byte
+	buf = redcon.AppendArray(buf, len(args))
+	for _, arg := range args {
+		buf = redcon.AppendBulkString(buf, arg)
+	}
+	if _, err := c.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *testConn) expect(t *testing.T, pattern string) {
+	t.Helper()
+	_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	line = line[:len(line)-2]
+	if !regexp.MustCompile(pattern).MatchString(line) {
+		t.Fatalf("want '%s', got '%s'", pattern, line)
+	}
+}
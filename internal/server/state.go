@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/flarco/redka/internal/clients"
 	"github.com/flarco/redka/internal/redis"
 	"github.com/tidwall/redcon"
 )
@@ -25,7 +26,13 @@ func getState(conn redcon.Conn) *connState {
 
 // connState represents the connection state.
 type connState struct {
+	client  *clients.Client // nil if clients are not tracked
+	monitor bool            // the client has run MONITOR
+	pushing bool            // the client receives invalidation messages
+	proto   int             // RESP protocol version (0 means RESP2)
+	db      int             // selected database index
 	inMulti bool
+	aborted bool // a command was rejected while queueing the transaction
 	cmds    []redis.Cmd
 }
 
@@ -44,6 +51,26 @@ func (s *connState) pop() redis.Cmd {
 	return last
 }
 
+// hasWrites reports whether any of the commands modify the database.
+func (s *connState) hasWrites() bool {
+	for _, cmd := range s.cmds {
+		if cmd.IsWrite() {
+			return true
+		}
+	}
+	return false
+}
+
+// isReadOnly reports whether all of the commands only read the database.
+func (s *connState) isReadOnly() bool {
+	for _, cmd := range s.cmds {
+		if !cmd.IsReadOnly() {
+			return false
+		}
+	}
+	return len(s.cmds) > 0
+}
+
 // clear removes all commands from the state.
 func (s *connState) clear() {
 	s.cmds = []redis.Cmd{}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS client authentication modes.
+const (
+	TLSAuthNo       = "no"       // don't request client certificates
+	TLSAuthOptional = "optional" // verify client certificates if given
+	TLSAuthYes      = "yes"      // require and verify client certificates
+)
+
+var (
+	ErrNoCACerts       = errors.New("no certificates found in the CA file")
+	ErrUnknownTLSAuth  = errors.New("unknown TLS client auth mode")
+	ErrTLSAuthRequires = errors.New("TLS client auth requires a CA file")
+)
+
+// TLSOptions configure the TLS listener.
+type TLSOptions struct {
+	// Addr is the TCP address (host:port) to accept
+	// TLS connections on.
+	Addr string
+	// Config holds the server certificate and
+	// the client certificate verification settings.
+	Config *tls.Config
+}
+
+// NewTLSConfig creates a TLS configuration from the PEM-encoded
+// server certificate and key files. If the CA file is set,
+// the client certificates are verified against the CA
+// certificates, and the auth mode defines whether
+// the clients must present a certificate (TLSAuthYes),
+// may present one (TLSAuthOptional), or are not asked
+// for it (TLSAuthNo).
+func NewTLSConfig(certFile, keyFile, caFile, auth string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch auth {
+	case TLSAuthNo:
+		return config, nil
+	case TLSAuthOptional:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case TLSAuthYes:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, ErrUnknownTLSAuth
+	}
+	if caFile == "" {
+		return nil, ErrTLSAuthRequires
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("load CA certificates: %w", err)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, ErrNoCACerts
+	}
+	return config, nil
+}
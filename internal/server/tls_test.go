@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	srv := newTestCert(t, "localhost", ca)
+	caFile := ca.write(t, dir)
+	certFile, keyFile := srv.write(t, dir), filepath.Join(dir, "localhost.key")
+
+	t.Run("no client auth", func(t *testing.T) {
+		config, err := NewTLSConfig(certFile, keyFile, "", TLSAuthNo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.ClientAuth != tls.NoClientCert {
+			t.Fatalf("want NoClientCert, got %v", config.ClientAuth)
+		}
+	})
+	t.Run("optional client auth", func(t *testing.T) {
+		config, err := NewTLSConfig(certFile, keyFile, caFile, TLSAuthOptional)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.ClientAuth != tls.VerifyClientCertIfGiven {
+			t.Fatalf("want VerifyClientCertIfGiven, got %v", config.ClientAuth)
+		}
+	})
+	t.Run("required client auth", func(t *testing.T) {
+		config, err := NewTLSConfig(certFile, keyFile, caFile, TLSAuthYes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.ClientAuth != tls.RequireAndVerifyClientCert {
+			t.Fatalf("want RequireAndVerifyClientCert, got %v", config.ClientAuth)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewTLSConfig(certFile, keyFile, "", TLSAuthYes)
+		if err != ErrTLSAuthRequires {
+			t.Fatalf("want ErrTLSAuthRequires, got %v", err)
+		}
+		_, err = NewTLSConfig(certFile, keyFile, caFile, "maybe")
+		if err != ErrUnknownTLSAuth {
+			t.Fatalf("want ErrUnknownTLSAuth, got %v", err)
+		}
+		_, err = NewTLSConfig(certFile, keyFile, keyFile, TLSAuthYes)
+		if err != ErrNoCACerts {
+			t.Fatalf("want ErrNoCACerts, got %v", err)
+		}
+		_, err = NewTLSConfig(filepath.Join(dir, "missing.crt"), keyFile, "", TLSAuthNo)
+		if err == nil {
+			t.Fatal("want error for missing certificate")
+		}
+	})
+}
+
+func TestServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	srvCert := newTestCert(t, "localhost", ca)
+	cliCert := newTestCert(t, "client", ca)
+	caFile := ca.write(t, dir)
+	certFile, keyFile := srvCert.write(t, dir), filepath.Join(dir, "localhost.key")
+
+	config, err := NewTLSConfig(certFile, keyFile, caFile, TLSAuthYes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sock := filepath.Join(dir, "redka.sock")
+	tlsAddr := freeAddr(t)
+	srv := New("unix", sock, db, &Options{TLS: &TLSOptions{Addr: tlsAddr, Config: config}})
+	srv.Start()
+	defer func() { _ = srv.Stop() }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	// Plaintext and TLS connections are served simultaneously.
+	c := dial(t, sock)
+	defer c.Close()
+	c.send(t, "ECHO", "plain")
+	c.expect(t, `^\$5$`)
+	c.expect(t, `^plain$`)
+
+	tc := dialTLS(t, tlsAddr, &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{cliCert.pair()},
+	})
+	defer tc.Close()
+	tc.send(t, "ECHO", "secure")
+	tc.expect(t, `^\$6$`)
+	tc.expect(t, `^secure$`)
+	tc.send(t, "CLIENT", "INFO")
+	tc.expect(t, `^\$\d+$`)
+	tc.expect(t, `^id=\d+ addr=127\.0\.0\.1:\d+ laddr=`+tlsAddr+` `)
+
+	// Clients without a certificate are rejected.
+	conn, err := tls.Dial("tcp", tlsAddr, &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	if err == nil {
+		defer conn.Close()
+		// With TLS 1.3, the server reports the missing
+		// certificate after the client handshake completes.
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+	}
+	if err == nil {
+		t.Fatal("want client without certificate to be rejected")
+	}
+}
+
+// testCert is a certificate with its private key.
+type testCert struct {
+	name string
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate for the name, signed by the parent
+// certificate. If the parent is nil, creates a self-signed CA certificate.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{name: name, cert: cert, der: der, key: key}
+}
+
+// write writes the certificate (name.crt) and the key (name.key)
+// to the directory, and returns the certificate file path.
+func (c *testCert) write(t *testing.T, dir string) string {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, c.name+".crt")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, c.name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile
+}
+
+// pair returns the certificate as a TLS certificate.
+func (c *testCert) pair() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// freeAddr returns a local TCP address that is not in use.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func dialTLS(t *testing.T, addr string, config *tls.Config) *testConn {
+	t.Helper()
+	var conn net.Conn
+	var err error
+	for range 50 {
+		conn, err = tls.Dial("tcp", addr, config)
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testConn{Conn: conn, rd: bufio.NewReader(conn)}
+}
@@ -0,0 +1,164 @@
+-- Change log triggers.
+-- Record the database and name of every key whose value changes
+-- (see the rchange table in schema.sql).
+
+create trigger if not exists
+rchange_rkey_insert
+after insert on rkey
+for each row
+begin
+    insert into rchange (db, key) values (new.db, new.key);
+end;
+
+create trigger if not exists
+rchange_rkey_update
+after update on rkey
+for each row
+begin
+    insert into rchange (db, key)
+    select old.db, old.key where old.db <> new.db or old.key <> new.key;
+    insert into rchange (db, key) values (new.db, new.key);
+end;
+
+create trigger if not exists
+rchange_rkey_delete
+after delete on rkey
+for each row
+begin
+    insert into rchange (db, key) values (old.db, old.key);
+end;
+
+create trigger if not exists
+rchange_rstring_insert
+after insert on rstring
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rstring_update
+after update on rstring
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rstring_delete
+after delete on rstring
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = old.kid;
+end;
+
+create trigger if not exists
+rchange_rlist_insert
+after insert on rlist
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rlist_update
+after update on rlist
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rlist_delete
+after delete on rlist
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = old.kid;
+end;
+
+create trigger if not exists
+rchange_rset_insert
+after insert on rset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rset_update
+after update on rset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rset_delete
+after delete on rset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = old.kid;
+end;
+
+create trigger if not exists
+rchange_rhash_insert
+after insert on rhash
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rhash_update
+after update on rhash
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rhash_delete
+after delete on rhash
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = old.kid;
+end;
+
+create trigger if not exists
+rchange_rzset_insert
+after insert on rzset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rzset_update
+after update on rzset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = new.kid;
+end;
+
+create trigger if not exists
+rchange_rzset_delete
+after delete on rzset
+for each row
+begin
+    insert into rchange (db, key)
+    select db, key from rkey where id = old.kid;
+end;
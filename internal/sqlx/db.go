@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"net/url"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -25,6 +28,17 @@ var sqlSchema string
 //go:embed schema_postgres.sql
 var postgresSchema string
 
+//go:embed changelog.sql
+var sqlChangeLog string
+
+const sqlChangeLogTriggers = `
+select name from sqlite_master
+where type = 'trigger' and name like 'rchange\_%' escape '\'`
+
+const sqlPruneChangeLog = `
+delete from rchange
+where id <= (select max(id) from rchange) - ?`
+
 // DefaultPragma is a set of default database settings.
 var DefaultPragma = map[string]string{
 	"journal_mode": "wal",
@@ -34,6 +48,15 @@ var DefaultPragma = map[string]string{
 	"foreign_keys": "on",
 }
 
+var (
+	// ErrChangeLogNotSupported is returned when enabling
+	// the change log for databases other than SQLite.
+	ErrChangeLogNotSupported = errors.New("change log is only supported for SQLite")
+	// ErrSnapshotNotSupported is returned when creating
+	// snapshots of databases other than SQLite.
+	ErrSnapshotNotSupported = errors.New("snapshots are only supported for SQLite")
+)
+
 // DB is a generic database-backed repository
 // with a domain-specific transaction of type T.
 // Has separate database handles for read-write
@@ -43,6 +66,7 @@ type DB[T any] struct {
 	RO     *sql.DB    // read-only handle
 	Driver string     // database driver type
 	newT   func(Tx) T // creates a new domain transaction
+	index  int        // logical database index
 	sync.Mutex
 }
 
@@ -67,6 +91,36 @@ func New[T any](rw *sql.DB, ro *sql.DB, newT func(Tx) T, driver string) *DB[T] {
 	}
 }
 
+// Scoped returns a repository that shares the database
+// handles, but works with the logical database
+// with the given index (see [Scope]).
+func (d *DB[T]) Scoped(index int) *DB[T] {
+	return &DB[T]{
+		RW:     d.RW,
+		RO:     d.RO,
+		Driver: d.Driver,
+		newT:   d.newT,
+		index:  index,
+	}
+}
+
+// Index returns the logical database index.
+func (d *DB[T]) Index() int {
+	return d.index
+}
+
+// Reader returns the read-only handle
+// scoped to the logical database.
+func (d *DB[T]) Reader() Tx {
+	return Scope(d.RO, d.index)
+}
+
+// Writer returns the read-write handle
+// scoped to the logical database.
+func (d *DB[T]) Writer() Tx {
+	return Scope(d.RW, d.index)
+}
+
 // Update executes a function within a writable transaction.
 func (d *DB[T]) Update(f func(tx T) error) error {
 	return d.UpdateContext(context.Background(), f)
@@ -87,6 +141,114 @@ func (d *DB[T]) ViewContext(ctx context.Context, f func(tx T) error) error {
 	return d.execTx(ctx, false, f)
 }
 
+// Snapshot writes a consistent copy of the database to path.
+// Uses VACUUM INTO on the read-only handle, which runs in a single
+// read transaction, so it does not block writers (in WAL mode).
+// Writes to a temporary file first and renames it when done,
+// so the file at path is either complete or unchanged.
+func (d *DB[T]) Snapshot(ctx context.Context, path string) error {
+	if d.Driver == DriverPostgres {
+		return ErrSnapshotNotSupported
+	}
+
+	// VACUUM INTO requires the target file
+	// to either not exist or be empty.
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// Use the OS file system explicitly. Otherwise SQLite writes
+	// the copy using the source database VFS (e.g. memdb).
+	absPath, err := filepath.Abs(tmpPath)
+	if err != nil {
+		return err
+	}
+	vfs := "unix"
+	if runtime.GOOS == "windows" {
+		vfs = "win32"
+	}
+	uriPath := filepath.ToSlash(absPath)
+	if !strings.HasPrefix(uriPath, "/") {
+		uriPath = "/" + uriPath
+	}
+	uri := url.URL{Scheme: "file", Path: uriPath, RawQuery: "vfs=" + vfs}
+	if _, err := d.RO.ExecContext(ctx, "vacuum into ?", uri.String()); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// EnableChangeLog starts recording the names of changed keys
+// in the rchange table. The change log is written by triggers
+// in the same transaction as the change itself.
+// The triggers are stored in the database file,
+// so the change log stays enabled once enabled.
+func (d *DB[T]) EnableChangeLog() error {
+	if d.Driver == DriverPostgres {
+		return ErrChangeLogNotSupported
+	}
+	_, err := d.RW.Exec(sqlChangeLog)
+	return err
+}
+
+// PruneChangeLog deletes old change log records,
+// keeping no more than n latest ones.
+// Returns the number of deleted records.
+func (d *DB[T]) PruneChangeLog(n int) (int, error) {
+	if d.Driver == DriverPostgres {
+		return 0, nil
+	}
+	res, err := d.RW.Exec(sqlPruneChangeLog, n)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := res.RowsAffected()
+	return int(count), nil
+}
+
+// Storage describes the database file (SQLite only).
+type Storage struct {
+	PageCount int64 // number of pages in the database file
+	PageSize  int64 // page size in bytes
+	WALSize   int64 // write-ahead log file size in bytes
+}
+
+// Storage returns the database file information.
+// Returns zero values for PostgreSQL.
+func (d *DB[T]) Storage(ctx context.Context) (Storage, error) {
+	var st Storage
+	if d.Driver == DriverPostgres {
+		return st, nil
+	}
+	if err := d.RO.QueryRowContext(ctx, "pragma page_count").Scan(&st.PageCount); err != nil {
+		return Storage{}, err
+	}
+	if err := d.RO.QueryRowContext(ctx, "pragma page_size").Scan(&st.PageSize); err != nil {
+		return Storage{}, err
+	}
+
+	// In-memory databases have no write-ahead log file,
+	// so a missing file means a zero size.
+	var path string
+	err := d.RO.QueryRowContext(ctx,
+		"select file from pragma_database_list where name = 'main'").Scan(&path)
+	if err != nil {
+		return Storage{}, err
+	}
+	if path != "" {
+		if fi, err := os.Stat(path + "-wal"); err == nil {
+			st.WALSize = fi.Size()
+		}
+	}
+	return st, nil
+}
+
 // Init sets the connection properties and creates the necessary tables.
 func (d *DB[T]) init(pragma map[string]string) error {
 	d.setNumConns()
@@ -178,10 +340,97 @@ func (d *DB[T]) createSchema() error {
 		return nil
 	}
 
+	if d.Driver != DriverPostgres {
+		if err := d.migrateSchema(); err != nil {
+			return err
+		}
+	}
+
 	_, err := d.RW.Exec(schema)
 	return err
 }
 
+// migrateSchema upgrades an existing SQLite database
+// created by an earlier version to the current schema.
+// Does nothing for new databases.
+func (d *DB[T]) migrateSchema() error {
+	// Hash field expiration: rhash.etime column.
+	// The vhash view is dropped because it refers to an unqualified
+	// etime, which becomes ambiguous once rhash gets its own.
+	// The schema recreates the view afterwards.
+	var nCols, nEtime int
+	err := d.RW.QueryRow(
+		"select count(*), count(*) filter (where name = 'etime') "+
+			"from pragma_table_info('rhash')",
+	).Scan(&nCols, &nEtime)
+	if err != nil {
+		return err
+	}
+	if nCols > 0 && nEtime == 0 {
+		_, err = d.RW.Exec(
+			"drop view if exists vhash; " +
+				"alter table rhash add column etime integer;",
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Logical databases: rkey.db column.
+	// The vkey view is dropped to add the column to it.
+	// The schema replaces the unique key index afterwards.
+	var nDB int
+	err = d.RW.QueryRow(
+		"select count(*), count(*) filter (where name = 'db') "+
+			"from pragma_table_info('rkey')",
+	).Scan(&nCols, &nDB)
+	if err != nil {
+		return err
+	}
+	if nCols > 0 && nDB == 0 {
+		_, err = d.RW.Exec(
+			"drop view if exists vkey; " +
+				"alter table rkey add column db integer not null default 0;",
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Logical databases: rchange.db column.
+	// The change log triggers (if enabled) are recreated
+	// to record the database along with the key name.
+	err = d.RW.QueryRow(
+		"select count(*), count(*) filter (where name = 'db') "+
+			"from pragma_table_info('rchange')",
+	).Scan(&nCols, &nDB)
+	if err != nil {
+		return err
+	}
+	if nCols == 0 || nDB > 0 {
+		return nil
+	}
+	_, err = d.RW.Exec("alter table rchange add column db integer not null default 0")
+	if err != nil {
+		return err
+	}
+	triggers, err := Select(d.RW, sqlChangeLogTriggers, nil, func(rows *sql.Rows) (string, error) {
+		var name string
+		err := rows.Scan(&name)
+		return name, err
+	})
+	if err != nil || len(triggers) == 0 {
+		return err
+	}
+	for _, name := range triggers {
+		if _, err := d.RW.Exec("drop trigger " + name); err != nil {
+			return err
+		}
+	}
+	_, err = d.RW.Exec(sqlChangeLog)
+	return err
+}
+
 // execTx executes a function within a transaction.
 func (d *DB[T]) execTx(ctx context.Context, writable bool, f func(tx T) error) error {
 	var dtx *sql.Tx
@@ -209,9 +458,9 @@ func (d *DB[T]) execTx(ctx context.Context, writable bool, f func(tx T) error) e
 	if d.Driver == DriverPostgres {
 		// Wrap the transaction with PostgreSQL query adaptation
 		ptx := &PostgresTx{tx: dtx}
-		tx = d.newT(ptx)
+		tx = d.newT(Scope(ptx, d.index))
 	} else {
-		tx = d.newT(dtx)
+		tx = d.newT(Scope(dtx, d.index))
 	}
 
 	err = f(tx)
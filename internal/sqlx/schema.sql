@@ -9,9 +9,12 @@ pragma user_version = 1;
 -- 3 - set
 -- 4 - hash
 -- 5 - zset (sorted set)
+-- Keys belong to logical databases (SELECT),
+-- identified by the db index.
 create table if not exists
 rkey (
     id       integer primary key,
+    db       integer not null default 0,
     key      text not null,
     type     integer not null,
     version  integer not null,
@@ -20,8 +23,12 @@ rkey (
     len      integer
 ) strict;
 
+-- Databases created before logical databases
+-- have a unique index on the key alone.
+drop index if exists rkey_key_idx;
+
 create unique index if not exists
-rkey_key_idx on rkey (key);
+rkey_db_key_idx on rkey (db, key);
 
 create index if not exists
 rkey_etime_idx on rkey (etime)
@@ -32,7 +39,8 @@ vkey as
 select
     id as kid, key, type, len,
     datetime(etime/1000, 'unixepoch') as etime,
-    datetime(mtime/1000, 'unixepoch') as mtime
+    datetime(mtime/1000, 'unixepoch') as mtime,
+    db
 from rkey
 where rkey.etime is null or rkey.etime > unixepoch('subsec');
 
@@ -152,6 +160,7 @@ rhash (
     kid   integer not null,
     field text not null,
     value blob not null,
+    etime integer,
 
     foreign key (kid) references rkey (id)
     on delete cascade
@@ -160,6 +169,10 @@ rhash (
 create unique index if not exists
 rhash_pk_idx on rhash (kid, field);
 
+create index if not exists
+rhash_etime_idx on rhash (etime)
+where etime is not null;
+
 create trigger if not exists
 rhash_on_insert
 before insert on rhash
@@ -178,10 +191,12 @@ create view if not exists
 vhash as
 select
     rkey.id as kid, rkey.key, rhash.field, rhash.value,
-    datetime(etime/1000, 'unixepoch') as etime,
-    datetime(mtime/1000, 'unixepoch') as mtime
+    datetime(rkey.etime/1000, 'unixepoch') as etime,
+    datetime(mtime/1000, 'unixepoch') as mtime,
+    datetime(rhash.etime/1000, 'unixepoch') as fetime
 from rhash join rkey on rhash.kid = rkey.id and rkey.type = 4
-where rkey.etime is null or rkey.etime > unixepoch('subsec');
+where (rkey.etime is null or rkey.etime > unixepoch('subsec'))
+    and (rhash.etime is null or rhash.etime > unixepoch('subsec') * 1000);
 
 -- ┌───────────────┐
 -- │ Sorted sets   │
@@ -224,3 +239,17 @@ select
     datetime(mtime/1000, 'unixepoch') as mtime
 from rzset join rkey on rzset.kid = rkey.id and rkey.type = 5
 where rkey.etime is null or rkey.etime > unixepoch('subsec');
+
+-- ┌───────────────┐
+-- │ Change log    │
+-- └───────────────┘
+-- Keys changed by write transactions (database and name),
+-- used by replicas to copy the changes.
+-- Populated by triggers (see changelog.sql)
+-- if the change log is enabled.
+create table if not exists
+rchange (
+    id   integer primary key autoincrement,
+    db   integer not null default 0,
+    key  text not null
+) strict;
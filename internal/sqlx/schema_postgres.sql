@@ -9,9 +9,12 @@
 -- 3 - set
 -- 4 - hash
 -- 5 - zset (sorted set)
+-- Keys belong to logical databases (SELECT),
+-- identified by the db index.
 CREATE TABLE IF NOT EXISTS
 rkey (
     id       SERIAL PRIMARY KEY,
+    db       INTEGER NOT NULL DEFAULT 0,
     key      TEXT NOT NULL,
     type     INTEGER NOT NULL,
     version  INTEGER NOT NULL,
@@ -20,8 +23,14 @@ rkey (
     len      INTEGER
 );
 
+-- Databases created before logical databases
+-- lack the db column and have a unique index
+-- on the key alone.
+ALTER TABLE rkey ADD COLUMN IF NOT EXISTS db INTEGER NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS rkey_key_idx;
+
 CREATE UNIQUE INDEX IF NOT EXISTS
-rkey_key_idx ON rkey (key);
+rkey_db_key_idx ON rkey (db, key);
 
 CREATE INDEX IF NOT EXISTS
 rkey_etime_idx ON rkey (etime)
@@ -32,7 +41,8 @@ vkey AS
 SELECT
     id AS kid, key, type, len,
     to_timestamp(etime::double precision/1000) AS etime,
-    to_timestamp(mtime::double precision/1000) AS mtime
+    to_timestamp(mtime::double precision/1000) AS mtime,
+    db
 FROM rkey
 WHERE rkey.etime IS NULL OR rkey.etime > (EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT;
 
@@ -166,14 +176,23 @@ rhash (
     kid   INTEGER NOT NULL,
     field TEXT NOT NULL,
     value BYTEA NOT NULL,
+    etime BIGINT,
 
     FOREIGN KEY (kid) REFERENCES rkey (id)
     ON DELETE CASCADE
 );
 
+-- Databases created before hash field expiration
+-- lack the etime column.
+ALTER TABLE rhash ADD COLUMN IF NOT EXISTS etime BIGINT;
+
 CREATE UNIQUE INDEX IF NOT EXISTS
 rhash_pk_idx ON rhash (kid, field);
 
+CREATE INDEX IF NOT EXISTS
+rhash_etime_idx ON rhash (etime)
+WHERE etime IS NOT NULL;
+
 -- Create trigger for hash inserts
 CREATE OR REPLACE FUNCTION update_rkey_on_rhash_insert() RETURNS TRIGGER AS $$
 BEGIN
@@ -198,10 +217,12 @@ CREATE OR REPLACE VIEW
 vhash AS
 SELECT
     rkey.id AS kid, rkey.key, rhash.field, rhash.value,
-    to_timestamp(etime::double precision/1000) AS etime,
-    to_timestamp(mtime::double precision/1000) AS mtime
+    to_timestamp(rkey.etime::double precision/1000) AS etime,
+    to_timestamp(mtime::double precision/1000) AS mtime,
+    to_timestamp(rhash.etime::double precision/1000) AS fetime
 FROM rhash JOIN rkey ON rhash.kid = rkey.id AND rkey.type = 4
-WHERE rkey.etime IS NULL OR rkey.etime > (EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT;
+WHERE (rkey.etime IS NULL OR rkey.etime > (EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT)
+    AND (rhash.etime IS NULL OR rhash.etime > (EXTRACT(EPOCH FROM NOW()) * 1000)::BIGINT);
 
 -- ┌───────────────┐
 -- │ Sorted sets   │
@@ -4,6 +4,7 @@ package sqlx
 
 import (
 	"database/sql"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -45,6 +46,73 @@ type Tx interface {
 	Exec(query string, args ...any) (sql.Result, error)
 }
 
+// DBParam is the query parameter replaced with the index
+// of the logical database the query runs against (see [Scope]).
+const DBParam = ":db"
+
+// Scope returns a transaction that runs the queries against
+// the logical database with the given index, replacing
+// the :db parameter in the queries with the index.
+func Scope(tx Tx, index int) Tx {
+	return scopedTx{tx: tx, db: strconv.Itoa(index)}
+}
+
+// scopedTx is a transaction scoped to a logical database.
+type scopedTx struct {
+	tx Tx
+	db string // database index
+}
+
+func (s scopedTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return s.tx.Query(replaceDBParam(query, s.db), args...)
+}
+
+func (s scopedTx) QueryRow(query string, args ...any) *sql.Row {
+	return s.tx.QueryRow(replaceDBParam(query, s.db), args...)
+}
+
+func (s scopedTx) Exec(query string, args ...any) (sql.Result, error) {
+	return s.tx.Exec(replaceDBParam(query, s.db), args...)
+}
+
+// replaceDBParam replaces the :db parameter in the query
+// with the database index. Leaves string literals and
+// longer names starting with :db (like :dbname) intact.
+func replaceDBParam(query string, db string) string {
+	if !strings.Contains(query, DBParam) {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query))
+	inLiteral := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// Escaped quotes ('') toggle the state twice.
+			inLiteral = !inLiteral
+		case !inLiteral && strings.HasPrefix(query[i:], DBParam) &&
+			!isNameChar(query, i+len(DBParam)):
+			b.WriteString(db)
+			i += len(DBParam) - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isNameChar reports whether the query has
+// an identifier character at position i.
+func isNameChar(query string, i int) bool {
+	if i >= len(query) {
+		return false
+	}
+	c := query[i]
+	return c == '_' || '0' <= c && c <= '9' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
 // rowScanner is an interface to scan rows.
 type RowScanner interface {
 	Scan(dest ...any) error
@@ -104,6 +172,10 @@ func ConstraintFailed(err error, constraint, column string) bool {
 	return strings.Contains(err.Error(), msg)
 }
 
+// unqualifiedEtime matches etime column references
+// that are not prefixed with a table name.
+var unqualifiedEtime = regexp.MustCompile(`([^.\w])etime (is null|>)`)
+
 // AdaptPostgresQuery adapts a SQLite query to work with PostgreSQL
 func AdaptPostgresQuery(query string) string {
 	// Replace SQLite-specific syntax with PostgreSQL syntax
@@ -320,11 +392,9 @@ func AdaptPostgresQuery(query string) string {
 		query = strings.Replace(query, "on kid = rkey.id and", "on kid = rkey.id AND", -1)
 
 		// Qualify etime and other ambiguous columns in WHERE clauses
-		if strings.Contains(query, "WHERE key =") && strings.Contains(query, "etime is null") {
-			query = strings.Replace(query, "etime is null", "rkey.etime is null", -1)
-		}
-		if strings.Contains(query, "WHERE key =") && strings.Contains(query, "etime >") {
-			query = strings.Replace(query, "etime >", "rkey.etime >", -1)
+		// Already qualified references (rkey.etime, rhash.etime) are left as is.
+		if strings.Contains(query, "WHERE key =") || strings.Contains(query, "WHERE db =") {
+			query = unqualifiedEtime.ReplaceAllString(query, "${1}rkey.etime ${2}")
 		}
 	}
 
@@ -0,0 +1,27 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestReplaceDBParam(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"select 1", "select 1"},
+		{"select id from rkey where db = :db", "select id from rkey where db = 3"},
+		{"insert into rkey (db, key) values (:db, ?)", "insert into rkey (db, key) values (3, ?)"},
+		{"select ':db' where db = :db", "select ':db' where db = 3"},
+		{"select 'it''s :db' where db = :db", "select 'it''s :db' where db = 3"},
+		{"select :dbname where db = :db", "select :dbname where db = 3"},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			got := replaceDBParam(test.query, "3")
+			testx.AssertEqual(t, got, test.want)
+		})
+	}
+}
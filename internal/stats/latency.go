@@ -0,0 +1,138 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latency events.
+const (
+	// EventCommand is a command that exceeds the threshold.
+	EventCommand = "command"
+	// EventExpireCycle is a background run that deletes expired keys.
+	EventExpireCycle = "expire-cycle"
+	// EventPoolWait is the time spent waiting for a free
+	// connection in the write connection pool (database/sql).
+	EventPoolWait = "pool-wait"
+)
+
+// latencyHistoryLen is the number of samples kept per event.
+const latencyHistoryLen = 160
+
+// LatencySample is a latency event sample.
+type LatencySample struct {
+	Time    time.Time     // when the event happened (with a second precision)
+	Latency time.Duration // event latency
+}
+
+// LatencyEvent describes the latest and maximum
+// latency samples of an event.
+type LatencyEvent struct {
+	Name   string
+	Latest LatencySample
+	Max    time.Duration
+}
+
+// LatencyMonitor records events that exceed the latency
+// threshold. Keeps the maximum latency per event per second,
+// for up to 160 latest seconds. Safe for concurrent use.
+type LatencyMonitor struct {
+	threshold time.Duration
+
+	mu     sync.Mutex
+	events map[string]*latencyHistory
+}
+
+// latencyHistory is the sample history of an event.
+type latencyHistory struct {
+	samples []LatencySample // oldest first
+	max     time.Duration
+}
+
+// NewLatencyMonitor creates a new monitor that records
+// events exceeding the threshold (zero disables the monitor).
+func NewLatencyMonitor(threshold time.Duration) *LatencyMonitor {
+	return &LatencyMonitor{threshold: threshold, events: map[string]*latencyHistory{}}
+}
+
+// Add records the event if it exceeds the threshold.
+func (m *LatencyMonitor) Add(event string, latency time.Duration) {
+	if m.threshold <= 0 || latency < m.threshold {
+		return
+	}
+	now := time.Now().Truncate(time.Second)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.events[event]
+	if !ok {
+		h = &latencyHistory{}
+		m.events[event] = h
+	}
+	h.max = max(h.max, latency)
+
+	// Keep a single (maximum) sample per second.
+	if n := len(h.samples); n > 0 && h.samples[n-1].Time.Equal(now) {
+		h.samples[n-1].Latency = max(h.samples[n-1].Latency, latency)
+		return
+	}
+	if len(h.samples) == latencyHistoryLen {
+		copy(h.samples, h.samples[1:])
+		h.samples = h.samples[:len(h.samples)-1]
+	}
+	h.samples = append(h.samples, LatencySample{Time: now, Latency: latency})
+}
+
+// Latest returns the latest and maximum latency
+// of each event, sorted by event name.
+func (m *LatencyMonitor) Latest() []LatencyEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := make([]LatencyEvent, 0, len(m.events))
+	for name, h := range m.events {
+		events = append(events, LatencyEvent{
+			Name:   name,
+			Latest: h.samples[len(h.samples)-1],
+			Max:    h.max,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Name < events[j].Name
+	})
+	return events
+}
+
+// History returns the samples of the event, oldest first.
+func (m *LatencyMonitor) History(event string) []LatencySample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.events[event]
+	if !ok {
+		return nil
+	}
+	samples := make([]LatencySample, len(h.samples))
+	copy(samples, h.samples)
+	return samples
+}
+
+// Reset removes the samples of the given events
+// (of all events if none are given).
+// Returns the number of reset events.
+func (m *LatencyMonitor) Reset(events ...string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(events) == 0 {
+		n := len(m.events)
+		m.events = map[string]*latencyHistory{}
+		return n
+	}
+	n := 0
+	for _, event := range events {
+		if _, ok := m.events[event]; ok {
+			delete(m.events, event)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestLatencyMonitor(t *testing.T) {
+	t.Run("threshold", func(t *testing.T) {
+		m := stats.NewLatencyMonitor(100 * time.Millisecond)
+		m.Add(stats.EventCommand, 10*time.Millisecond)
+		testx.AssertEqual(t, len(m.Latest()), 0)
+
+		m.Add(stats.EventCommand, 200*time.Millisecond)
+		m.Add(stats.EventCommand, 150*time.Millisecond)
+		m.Add(stats.EventExpireCycle, 300*time.Millisecond)
+		events := m.Latest()
+		testx.AssertEqual(t, len(events), 2)
+		testx.AssertEqual(t, events[0].Name, stats.EventCommand)
+		testx.AssertEqual(t, events[0].Max, 200*time.Millisecond)
+		testx.AssertEqual(t, events[1].Name, stats.EventExpireCycle)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		m := stats.NewLatencyMonitor(0)
+		m.Add(stats.EventCommand, time.Second)
+		testx.AssertEqual(t, len(m.Latest()), 0)
+	})
+	t.Run("history", func(t *testing.T) {
+		m := stats.NewLatencyMonitor(time.Millisecond)
+		// Samples within the same second are merged.
+		m.Add(stats.EventCommand, 5*time.Millisecond)
+		m.Add(stats.EventCommand, 3*time.Millisecond)
+		samples := m.History(stats.EventCommand)
+		if len(samples) == 2 {
+			// The second has changed between the calls.
+			samples = samples[1:]
+		}
+		testx.AssertEqual(t, len(samples), 1)
+		testx.AssertEqual(t, samples[0].Latency >= 3*time.Millisecond, true)
+		testx.AssertEqual(t, len(m.History("unknown")), 0)
+	})
+	t.Run("reset", func(t *testing.T) {
+		m := stats.NewLatencyMonitor(time.Millisecond)
+		m.Add(stats.EventCommand, time.Second)
+		m.Add(stats.EventExpireCycle, time.Second)
+		m.Add(stats.EventPoolWait, time.Second)
+		testx.AssertEqual(t, m.Reset(stats.EventCommand, "unknown"), 1)
+		testx.AssertEqual(t, len(m.Latest()), 2)
+		testx.AssertEqual(t, m.Reset(), 2)
+		testx.AssertEqual(t, len(m.Latest()), 0)
+	})
+}
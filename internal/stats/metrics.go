@@ -0,0 +1,211 @@
+package stats
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/flarco/redka/internal/core"
+)
+
+// durationBuckets are the upper bounds (in seconds)
+// of the command processing time histogram.
+var durationBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005,
+	0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5,
+}
+
+// keyTypes are the key types reported in metrics.
+var keyTypes = []core.TypeID{
+	core.TypeString, core.TypeList, core.TypeSet, core.TypeHash, core.TypeZSet,
+}
+
+// Handler returns an HTTP handler that serves
+// the metrics in the Prometheus text format.
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := c.WriteMetrics(w); err != nil {
+			slog.Error("write metrics", "error", err)
+		}
+	})
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format.
+func (c *Collector) WriteMetrics(w io.Writer) error {
+	srv := c.Server()
+	dbStats, err := c.db.Stats()
+	if err != nil {
+		return err
+	}
+	counts, err := c.db.Key().Counts()
+	if err != nil {
+		return err
+	}
+
+	m := metricWriter{w: bufio.NewWriter(w)}
+
+	// Server.
+	m.header("redka_uptime_seconds", "gauge", "Time since the server started.")
+	m.value("redka_uptime_seconds", "", srv.Uptime.Seconds())
+	m.header("redka_connected_clients", "gauge", "Number of connected clients.")
+	m.value("redka_connected_clients", "", float64(srv.Clients))
+	m.header("redka_connections_total", "counter", "Number of accepted connections.")
+	m.value("redka_connections_total", "", float64(srv.Connections))
+
+	// Commands.
+	c.writeCommands(&m)
+
+	// Keys.
+	m.header("redka_keys", "gauge", "Number of keys by type.")
+	for _, typ := range keyTypes {
+		labels := label("type", core.Key{Type: typ}.TypeName())
+		m.value("redka_keys", labels, float64(counts.Types[typ]))
+	}
+	m.header("redka_keys_with_expiry", "gauge", "Number of keys with an expiration time.")
+	m.value("redka_keys_with_expiry", "", float64(counts.Expires))
+	m.header("redka_expired_keys_total", "counter", "Number of expired keys deleted in the background.")
+	m.value("redka_expired_keys_total", "", float64(dbStats.ExpiredKeys))
+	m.header("redka_expired_fields_total", "counter", "Number of expired hash fields deleted in the background.")
+	m.value("redka_expired_fields_total", "", float64(dbStats.ExpiredFields))
+
+	// Database.
+	m.header("redka_db_size_bytes", "gauge", "Database file size (SQLite only).")
+	m.value("redka_db_size_bytes", "", float64(dbStats.PageCount*dbStats.PageSize))
+	m.header("redka_wal_size_bytes", "gauge", "Write-ahead log file size (SQLite only).")
+	m.value("redka_wal_size_bytes", "", float64(dbStats.WALSize))
+	writePools(&m, map[string]sql.DBStats{
+		"rw": c.db.RW.Stats(),
+		"ro": c.db.RO.Stats(),
+	})
+
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+// writeCommands writes the per-command metrics.
+func (c *Collector) writeCommands(m *metricWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.cmds))
+	for name := range c.cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m.header("redka_commands_total", "counter", "Number of processed commands.")
+	for _, name := range names {
+		m.value("redka_commands_total", label("cmd", name), float64(c.cmds[name].calls))
+	}
+	m.header("redka_command_errors_total", "counter", "Number of commands that returned an error.")
+	for _, name := range names {
+		m.value("redka_command_errors_total", label("cmd", name), float64(c.cmds[name].errors))
+	}
+	m.header("redka_command_duration_seconds", "histogram", "Command processing time.")
+	for _, name := range names {
+		cs := c.cmds[name]
+		var count int64
+		for i, le := range durationBuckets {
+			count += cs.buckets[i]
+			labels := label("cmd", name) + "," + label("le", formatFloat(le))
+			m.value("redka_command_duration_seconds_bucket", labels, float64(count))
+		}
+		labels := label("cmd", name) + "," + label("le", "+Inf")
+		m.value("redka_command_duration_seconds_bucket", labels, float64(cs.calls))
+		m.value("redka_command_duration_seconds_sum", label("cmd", name), cs.seconds)
+		m.value("redka_command_duration_seconds_count", label("cmd", name), float64(cs.calls))
+	}
+}
+
+// writePools writes the connection pool metrics.
+func writePools(m *metricWriter, pools map[string]sql.DBStats) {
+	names := []string{"rw", "ro"}
+	metrics := []struct {
+		name, typ, help string
+		value           func(sql.DBStats) float64
+	}{
+		{
+			"redka_sql_max_open_connections", "gauge", "Maximum number of open connections.",
+			func(st sql.DBStats) float64 { return float64(st.MaxOpenConnections) },
+		},
+		{
+			"redka_sql_open_connections", "gauge", "Number of open connections.",
+			func(st sql.DBStats) float64 { return float64(st.OpenConnections) },
+		},
+		{
+			"redka_sql_in_use_connections", "gauge", "Number of connections in use.",
+			func(st sql.DBStats) float64 { return float64(st.InUse) },
+		},
+		{
+			"redka_sql_idle_connections", "gauge", "Number of idle connections.",
+			func(st sql.DBStats) float64 { return float64(st.Idle) },
+		},
+		{
+			"redka_sql_wait_count_total", "counter", "Number of waits for a connection.",
+			func(st sql.DBStats) float64 { return float64(st.WaitCount) },
+		},
+		{
+			"redka_sql_wait_duration_seconds_total", "counter", "Time spent waiting for a connection.",
+			func(st sql.DBStats) float64 { return st.WaitDuration.Seconds() },
+		},
+		{
+			"redka_sql_max_idle_closed_total", "counter", "Number of connections closed due to the idle limit.",
+			func(st sql.DBStats) float64 { return float64(st.MaxIdleClosed) },
+		},
+		{
+			"redka_sql_max_lifetime_closed_total", "counter", "Number of connections closed due to the lifetime limit.",
+			func(st sql.DBStats) float64 { return float64(st.MaxLifetimeClosed) },
+		},
+	}
+	for _, metric := range metrics {
+		m.header(metric.name, metric.typ, metric.help)
+		for _, pool := range names {
+			m.value(metric.name, label("pool", pool), metric.value(pools[pool]))
+		}
+	}
+}
+
+// metricWriter writes metrics in the Prometheus text format.
+// Keeps the first write error and skips the rest of the writes.
+type metricWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// header writes the metric help and type lines.
+func (m *metricWriter) header(name, typ, help string) {
+	m.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// value writes a metric sample with optional labels.
+func (m *metricWriter) value(name, labels string, val float64) {
+	if labels != "" {
+		m.printf("%s{%s} %s\n", name, labels, formatFloat(val))
+	} else {
+		m.printf("%s %s\n", name, formatFloat(val))
+	}
+}
+
+func (m *metricWriter) printf(format string, args ...any) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, format, args...)
+}
+
+// label returns a label pair with the value quoted.
+func label(name, value string) string {
+	return name + "=" + strconv.Quote(value)
+}
+
+// formatFloat formats a sample value.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,69 @@
+package stats_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+	_ = db.Str().Set("name", "alice")
+	_, _ = db.Hash().Set("person", "name", "alice")
+
+	c := stats.New(db, nil)
+	c.Connect()
+	c.Command("get", 200*time.Microsecond, false)
+	c.Command("get", 3*time.Millisecond, false)
+	c.Command("set", 10*time.Second, true)
+
+	var b strings.Builder
+	err := c.WriteMetrics(&b)
+	testx.AssertNoErr(t, err)
+	out := b.String()
+
+	for _, line := range []string{
+		"# TYPE redka_commands_total counter",
+		`redka_commands_total{cmd="get"} 2`,
+		`redka_commands_total{cmd="set"} 1`,
+		`redka_command_errors_total{cmd="get"} 0`,
+		`redka_command_errors_total{cmd="set"} 1`,
+		"# TYPE redka_command_duration_seconds histogram",
+		`redka_command_duration_seconds_bucket{cmd="get",le="0.0001"} 0`,
+		`redka_command_duration_seconds_bucket{cmd="get",le="0.00025"} 1`,
+		`redka_command_duration_seconds_bucket{cmd="get",le="0.005"} 2`,
+		`redka_command_duration_seconds_bucket{cmd="get",le="+Inf"} 2`,
+		`redka_command_duration_seconds_bucket{cmd="set",le="5"} 0`,
+		`redka_command_duration_seconds_bucket{cmd="set",le="+Inf"} 1`,
+		`redka_command_duration_seconds_sum{cmd="set"} 10`,
+		`redka_command_duration_seconds_count{cmd="get"} 2`,
+		"redka_connected_clients 1",
+		`redka_keys{type="string"} 1`,
+		`redka_keys{type="hash"} 1`,
+		`redka_keys{type="zset"} 0`,
+		"redka_expired_keys_total 0",
+		`redka_sql_max_open_connections{pool="rw"} 1`,
+		`redka_sql_open_connections{pool="ro"}`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("missing %q", line)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	c := stats.New(db, nil)
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	testx.AssertEqual(t, rec.Code, 200)
+	testx.AssertEqual(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+	testx.AssertEqual(t, strings.Contains(rec.Body.String(), "redka_uptime_seconds "), true)
+}
@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Slow log defaults (same as in Redis).
+const (
+	DefaultSlowLogThreshold = 10 * time.Millisecond
+	DefaultSlowLogMaxLen    = 128
+)
+
+// Limits on the command arguments stored in the slow log.
+const (
+	slowLogMaxArgs   = 32
+	slowLogMaxArgLen = 128
+)
+
+// SlowEntry is a slow log entry.
+type SlowEntry struct {
+	ID       int64         // unique entry id
+	Time     time.Time     // when the command was processed
+	Duration time.Duration // command processing time
+	Args     []string      // command name and arguments (truncated)
+	Addr     string        // client address
+	Name     string        // client name
+}
+
+// SlowLog records commands that exceed the processing time
+// threshold. Keeps up to maxLen latest entries.
+// Safe for concurrent use.
+type SlowLog struct {
+	threshold time.Duration
+	maxLen    int
+
+	mu      sync.Mutex
+	nextID  int64
+	entries []SlowEntry // oldest first
+}
+
+// NewSlowLog creates a new slow log that records commands
+// slower than the threshold (a negative threshold disables
+// the log, and zero records every command).
+func NewSlowLog(threshold time.Duration, maxLen int) *SlowLog {
+	return &SlowLog{threshold: threshold, maxLen: maxLen}
+}
+
+// Add records the command if it exceeds the threshold.
+func (l *SlowLog) Add(args [][]byte, addr, name string, dur time.Duration) {
+	if l.threshold < 0 || dur < l.threshold || l.maxLen <= 0 {
+		return
+	}
+	entry := SlowEntry{
+		Time:     time.Now(),
+		Duration: dur,
+		Args:     truncateArgs(args),
+		Addr:     addr,
+		Name:     name,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.ID = l.nextID
+	l.nextID++
+	if len(l.entries) == l.maxLen {
+		copy(l.entries, l.entries[1:])
+		l.entries = l.entries[:len(l.entries)-1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// Entries returns up to n latest entries, newest first
+// (all entries if n is negative).
+func (l *SlowLog) Entries(n int) []SlowEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n < 0 || n > len(l.entries) {
+		n = len(l.entries)
+	}
+	entries := make([]SlowEntry, n)
+	for i := range entries {
+		entries[i] = l.entries[len(l.entries)-1-i]
+	}
+	return entries
+}
+
+// Len returns the number of entries.
+func (l *SlowLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// Reset removes all entries.
+func (l *SlowLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
+
+// truncateArgs converts the command arguments to strings,
+// keeping no more than slowLogMaxArgs arguments
+// and slowLogMaxArgLen bytes per argument (as Redis does).
+func truncateArgs(args [][]byte) []string {
+	n := len(args)
+	if n > slowLogMaxArgs {
+		n = slowLogMaxArgs
+	}
+	out := make([]string, n)
+	for i := range out {
+		arg := args[i]
+		if len(arg) > slowLogMaxArgLen {
+			out[i] = fmt.Sprintf("%s... (%d more bytes)",
+				arg[:slowLogMaxArgLen], len(arg)-slowLogMaxArgLen)
+		} else {
+			out[i] = string(arg)
+		}
+	}
+	if len(args) > slowLogMaxArgs {
+		out[n-1] = fmt.Sprintf("... (%d more arguments)", len(args)-slowLogMaxArgs+1)
+	}
+	return out
+}
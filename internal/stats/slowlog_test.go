@@ -0,0 +1,69 @@
+package stats_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+)
+
+func TestSlowLog(t *testing.T) {
+	t.Run("threshold", func(t *testing.T) {
+		log := stats.NewSlowLog(10*time.Millisecond, 10)
+		log.Add(args("get", "name"), "127.0.0.1:5000", "", time.Millisecond)
+		log.Add(args("keys", "*"), "127.0.0.1:5000", "", 20*time.Millisecond)
+		testx.AssertEqual(t, log.Len(), 1)
+
+		entries := log.Entries(-1)
+		testx.AssertEqual(t, entries[0].ID, int64(0))
+		testx.AssertEqual(t, entries[0].Args, []string{"keys", "*"})
+		testx.AssertEqual(t, entries[0].Addr, "127.0.0.1:5000")
+		testx.AssertEqual(t, entries[0].Duration, 20*time.Millisecond)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		log := stats.NewSlowLog(-1, 10)
+		log.Add(args("keys", "*"), "", "", time.Second)
+		testx.AssertEqual(t, log.Len(), 0)
+	})
+	t.Run("max len", func(t *testing.T) {
+		log := stats.NewSlowLog(0, 2)
+		for _, key := range []string{"k1", "k2", "k3"} {
+			log.Add(args("get", key), "", "", time.Millisecond)
+		}
+		testx.AssertEqual(t, log.Len(), 2)
+		entries := log.Entries(10)
+		testx.AssertEqual(t, entries[0].ID, int64(2))
+		testx.AssertEqual(t, entries[0].Args, []string{"get", "k3"})
+		testx.AssertEqual(t, entries[1].ID, int64(1))
+		testx.AssertEqual(t, len(log.Entries(1)), 1)
+	})
+	t.Run("truncate", func(t *testing.T) {
+		log := stats.NewSlowLog(0, 10)
+		vals := []string{"rpush", "list", strings.Repeat("a", 130)}
+		for i := 0; i < 40; i++ {
+			vals = append(vals, "x")
+		}
+		log.Add(args(vals...), "", "", time.Millisecond)
+		got := log.Entries(1)[0].Args
+		testx.AssertEqual(t, len(got), 32)
+		testx.AssertEqual(t, got[2], strings.Repeat("a", 128)+"... (2 more bytes)")
+		testx.AssertEqual(t, got[31], "... (12 more arguments)")
+	})
+	t.Run("reset", func(t *testing.T) {
+		log := stats.NewSlowLog(0, 10)
+		log.Add(args("get", "name"), "", "", time.Millisecond)
+		log.Reset()
+		testx.AssertEqual(t, log.Len(), 0)
+		testx.AssertEqual(t, len(log.Entries(-1)), 0)
+	})
+}
+
+func args(vals ...string) [][]byte {
+	out := make([][]byte, len(vals))
+	for i, val := range vals {
+		out[i] = []byte(val)
+	}
+	return out
+}
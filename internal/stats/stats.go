@@ -0,0 +1,226 @@
+// Package stats collects server statistics
+// (reported by the INFO command and as Prometheus metrics).
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flarco/redka"
+)
+
+// Server describes the server state.
+type Server struct {
+	Version     string        // server version
+	Uptime      time.Duration // time since the server started
+	Clients     int64         // number of connected clients
+	Connections int64         // number of accepted connections
+	Commands    int64         // number of processed commands
+	OpsPerSec   int64         // number of commands processed in the last second
+}
+
+// Options configure the statistics collector.
+type Options struct {
+	// Version is the server version.
+	Version string
+	// SlowLogThreshold is the processing time that commands
+	// must exceed to be recorded in the slow log. A negative
+	// threshold disables the slow log, and zero records
+	// every command.
+	SlowLogThreshold time.Duration
+	// SlowLogMaxLen is the maximum number of slow log
+	// entries (zero disables the slow log).
+	SlowLogMaxLen int
+	// LatencyThreshold is the latency that events must
+	// exceed to be recorded by the latency monitor
+	// (zero disables the monitor).
+	LatencyThreshold time.Duration
+}
+
+var defaultOptions = Options{
+	SlowLogThreshold: DefaultSlowLogThreshold,
+	SlowLogMaxLen:    DefaultSlowLogMaxLen,
+}
+
+// Collector counts connections and commands, and records
+// slow commands and latency events. Safe for concurrent use.
+type Collector struct {
+	db      *redka.DB
+	version string
+	start   time.Time
+	slowLog *SlowLog
+	latency *LatencyMonitor
+	stop    chan struct{}
+
+	clients     atomic.Int64
+	connections atomic.Int64
+	commands    atomic.Int64
+
+	mu      sync.Mutex
+	sec     int64                // current second (Unix time)
+	curOps  int64                // commands processed in the current second
+	lastOps int64                // commands processed in the previous second
+	cmds    map[string]*cmdStats // per-command statistics
+
+	// Latest sampled values of the background events.
+	expireCycles int64
+	rwWait       time.Duration
+}
+
+// cmdStats are the statistics of a single command.
+type cmdStats struct {
+	calls   int64
+	errors  int64
+	seconds float64 // total processing time
+	buckets []int64 // processing time histogram (see durationBuckets)
+}
+
+// New creates a new statistics collector for the database.
+// The opts parameter is optional (nil means the default slow
+// log settings and a disabled latency monitor).
+func New(db *redka.DB, opts *Options) *Collector {
+	if opts == nil {
+		opts = &defaultOptions
+	}
+	return &Collector{
+		db:      db,
+		version: opts.Version,
+		start:   time.Now(),
+		slowLog: NewSlowLog(opts.SlowLogThreshold, opts.SlowLogMaxLen),
+		latency: NewLatencyMonitor(opts.LatencyThreshold),
+		cmds:    map[string]*cmdStats{},
+	}
+}
+
+// Start starts sampling the background latency events
+// (expire cycles and write connection pool waits)
+// every second. Does nothing if the latency monitor
+// is disabled.
+func (c *Collector) Start() {
+	if c.latency.threshold <= 0 {
+		return
+	}
+	if st, err := c.db.Stats(); err == nil {
+		c.expireCycles = st.ExpireCycles
+	}
+	c.rwWait = c.db.RW.Stats().WaitDuration
+	stop := make(chan struct{})
+	c.stop = stop
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sample()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sampling the background latency events.
+// Must only be called once after Start.
+func (c *Collector) Stop() {
+	if c.stop != nil {
+		close(c.stop)
+	}
+}
+
+// SlowLog returns the slow log.
+func (c *Collector) SlowLog() *SlowLog {
+	return c.slowLog
+}
+
+// Latency returns the latency monitor.
+func (c *Collector) Latency() *LatencyMonitor {
+	return c.latency
+}
+
+// Connect records an accepted client connection.
+func (c *Collector) Connect() {
+	c.clients.Add(1)
+	c.connections.Add(1)
+}
+
+// Disconnect records a closed client connection.
+func (c *Collector) Disconnect() {
+	c.clients.Add(-1)
+}
+
+// Command records a processed command with the given name,
+// processing time, and whether it failed.
+func (c *Collector) Command(name string, dur time.Duration, failed bool) {
+	c.commands.Add(1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tick(time.Now().Unix())
+	c.curOps++
+
+	cs, ok := c.cmds[name]
+	if !ok {
+		cs = &cmdStats{buckets: make([]int64, len(durationBuckets))}
+		c.cmds[name] = cs
+	}
+	c.latency.Add(EventCommand, dur)
+
+	cs.calls++
+	if failed {
+		cs.errors++
+	}
+	cs.seconds += dur.Seconds()
+	for i, le := range durationBuckets {
+		if dur.Seconds() <= le {
+			cs.buckets[i]++
+			break
+		}
+	}
+}
+
+// Server returns the server state.
+func (c *Collector) Server() Server {
+	c.mu.Lock()
+	c.tick(time.Now().Unix())
+	ops := c.lastOps
+	c.mu.Unlock()
+	return Server{
+		Version:     c.version,
+		Uptime:      time.Since(c.start),
+		Clients:     c.clients.Load(),
+		Connections: c.connections.Load(),
+		Commands:    c.commands.Load(),
+		OpsPerSec:   ops,
+	}
+}
+
+// DB returns the database statistics.
+func (c *Collector) DB() (redka.Stats, error) {
+	return c.db.Stats()
+}
+
+// sample records the background latency events
+// since the previous sample.
+func (c *Collector) sample() {
+	if st, err := c.db.Stats(); err == nil && st.ExpireCycles != c.expireCycles {
+		c.expireCycles = st.ExpireCycles
+		c.latency.Add(EventExpireCycle, st.LastExpireCycle)
+	}
+	wait := c.db.RW.Stats().WaitDuration
+	c.latency.Add(EventPoolWait, wait-c.rwWait)
+	c.rwWait = wait
+}
+
+// tick moves the ops counters to the given second.
+func (c *Collector) tick(sec int64) {
+	switch sec {
+	case c.sec:
+		return
+	case c.sec + 1:
+		c.lastOps, c.curOps = c.curOps, 0
+	default:
+		c.lastOps, c.curOps = 0, 0
+	}
+	c.sec = sec
+}
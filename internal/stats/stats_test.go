@@ -0,0 +1,83 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarco/redka"
+	"github.com/flarco/redka/internal/stats"
+	"github.com/flarco/redka/internal/testx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCollector(t *testing.T) {
+	t.Run("server", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		c := stats.New(db, &stats.Options{Version: "1.0.0"})
+		c.Connect()
+		c.Connect()
+		c.Disconnect()
+		c.Command("get", time.Millisecond, false)
+		c.Command("get", time.Millisecond, false)
+
+		srv := c.Server()
+		testx.AssertEqual(t, srv.Version, "1.0.0")
+		testx.AssertEqual(t, srv.Uptime > 0, true)
+		testx.AssertEqual(t, srv.Clients, int64(1))
+		testx.AssertEqual(t, srv.Connections, int64(2))
+		testx.AssertEqual(t, srv.Commands, int64(2))
+	})
+	t.Run("ops per sec", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		c := stats.New(db, nil)
+		// Wait for the start of a second, so that
+		// all commands fall into the same second.
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+		for range 3 {
+			c.Command("get", time.Millisecond, false)
+		}
+		testx.AssertEqual(t, c.Server().OpsPerSec, int64(0))
+
+		time.Sleep(time.Second)
+		testx.AssertEqual(t, c.Server().OpsPerSec, int64(3))
+
+		time.Sleep(time.Second)
+		testx.AssertEqual(t, c.Server().OpsPerSec, int64(0))
+	})
+	t.Run("latency", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		c := stats.New(db, &stats.Options{LatencyThreshold: time.Millisecond})
+		c.Start()
+		defer c.Stop()
+		c.Command("get", 100*time.Microsecond, false)
+		c.Command("keys", 5*time.Millisecond, false)
+		events := c.Latency().Latest()
+		testx.AssertEqual(t, len(events), 1)
+		testx.AssertEqual(t, events[0].Name, stats.EventCommand)
+		testx.AssertEqual(t, events[0].Max, 5*time.Millisecond)
+	})
+	t.Run("db", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		c := stats.New(db, nil)
+		st, err := c.DB()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, st.PageSize > 0, true)
+	})
+}
+
+func getDB(tb testing.TB) *redka.DB {
+	tb.Helper()
+	db, err := redka.Open("file:/data.db?vfs=memdb", nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
@@ -13,6 +13,8 @@ import (
 	"database/sql"
 	"io"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/flarco/redka/internal/core"
@@ -77,6 +79,12 @@ type Options struct {
 	// Logger for the database. If nil, uses a silent logger.
 	Logger *slog.Logger
 
+	// If true, enables the change log, which records the names
+	// of changed keys so that replicas can follow the database.
+	// The change log is stored in the database file, so it stays
+	// enabled for all later connections. SQLite only.
+	ChangeLog bool
+
 	// If true, opens the database in read-only mode.
 	readonly bool
 }
@@ -92,6 +100,10 @@ var defaultOptions = Options{
 //
 // DB is safe for concurrent use by multiple goroutines as long as you use
 // a single instance of DB throughout your program.
+//
+// The data is split into logical databases (as with the Redis SELECT
+// command). DB works with the default database (index 0), use
+// [DB.Select] to work with the others.
 type DB struct {
 	*sqlx.DB[*Tx]
 	hashDB   *rhash.DB
@@ -102,6 +114,32 @@ type DB struct {
 	zsetDB   *rzset.DB
 	bg       *time.Ticker
 	log      *slog.Logger
+	changes  bool // change log enabled
+
+	main    *DB         // the database the handle was selected from
+	viewsMu sync.Mutex  // protects views
+	views   map[int]*DB // handles of the selected logical databases
+
+	expiredKeys   atomic.Int64 // keys deleted by the bg manager
+	expiredFields atomic.Int64 // hash fields deleted by the bg manager
+	expireCycles  atomic.Int64 // bg manager runs
+	expireTime    atomic.Int64 // last bg manager run duration (ns)
+}
+
+// Stats describes the database state (see [DB.Stats]).
+type Stats struct {
+	// Keys and hash fields deleted by the background
+	// manager after they expired (since the database was opened).
+	ExpiredKeys   int64
+	ExpiredFields int64
+	// Number of background runs that delete expired
+	// keys, and the duration of the last run.
+	ExpireCycles    int64
+	LastExpireCycle time.Duration
+	// Database file information (SQLite only).
+	PageCount int64 // number of pages
+	PageSize  int64 // page size in bytes
+	WALSize   int64 // write-ahead log size in bytes
 }
 
 // Open opens a new or existing database at the given path.
@@ -234,12 +272,59 @@ func new(sdb *sqlx.DB[*Tx], opts *Options) (*DB, error) {
 		zsetDB:   zsetDB,
 		log:      opts.Logger,
 	}
+	if opts.ChangeLog && !opts.readonly {
+		if err := sdb.EnableChangeLog(); err != nil {
+			return nil, err
+		}
+		rdb.changes = true
+	}
 	if !opts.readonly {
 		rdb.bg = rdb.startBgManager()
 	}
 	return rdb, nil
 }
 
+// Select returns the handle of the logical database with the given
+// index. Logical databases are numbered from 0 (the default one)
+// and share the underlying connections, so closing any of the
+// handles closes the database.
+func (db *DB) Select(index int) *DB {
+	if db.main != nil {
+		return db.main.Select(index)
+	}
+	if index == db.Index() {
+		return db
+	}
+
+	db.viewsMu.Lock()
+	defer db.viewsMu.Unlock()
+	if view, ok := db.views[index]; ok {
+		return view
+	}
+	view := &DB{
+		DB:       db.DB.Scoped(index),
+		hashDB:   &rhash.DB{DB: db.hashDB.DB.Scoped(index)},
+		keyDB:    &rkey.DB{DB: db.keyDB.DB.Scoped(index)},
+		listDB:   &rlist.DB{DB: db.listDB.DB.Scoped(index)},
+		setDB:    &rset.DB{DB: db.setDB.DB.Scoped(index)},
+		stringDB: &rstring.DB{DB: db.stringDB.DB.Scoped(index)},
+		zsetDB:   &rzset.DB{DB: db.zsetDB.DB.Scoped(index)},
+		log:      db.log,
+		changes:  db.changes,
+		main:     db,
+	}
+	if db.views == nil {
+		db.views = map[int]*DB{}
+	}
+	db.views[index] = view
+	return view
+}
+
+// Index returns the index of the logical database.
+func (db *DB) Index() int {
+	return db.DB.Index()
+}
+
 // Hash returns the hash repository.
 // A hash (hashmap) is a field-value map associated with a key.
 // Use the hash repository to work with individual hashmaps
@@ -319,9 +404,45 @@ func (db *DB) ViewContext(ctx context.Context, f func(tx *Tx) error) error {
 	return db.DB.ViewContext(ctx, f)
 }
 
+// Snapshot writes a consistent copy of the database to a file
+// at path (SQLite only). Does not block concurrent writes.
+// The file at path is replaced atomically when the copy is complete.
+func (db *DB) Snapshot(path string) error {
+	return db.DB.Snapshot(context.Background(), path)
+}
+
+// SnapshotContext writes a consistent copy of the database
+// to a file at path (SQLite only). See [DB.Snapshot] for details.
+func (db *DB) SnapshotContext(ctx context.Context, path string) error {
+	return db.DB.Snapshot(ctx, path)
+}
+
+// Stats returns the database statistics.
+func (db *DB) Stats() (Stats, error) {
+	if db.main != nil {
+		return db.main.Stats()
+	}
+	st, err := db.DB.Storage(context.Background())
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		ExpiredKeys:     db.expiredKeys.Load(),
+		ExpiredFields:   db.expiredFields.Load(),
+		ExpireCycles:    db.expireCycles.Load(),
+		LastExpireCycle: time.Duration(db.expireTime.Load()),
+		PageCount:       st.PageCount,
+		PageSize:        st.PageSize,
+		WALSize:         st.WALSize,
+	}, nil
+}
+
 // Close closes the database.
 // It's safe for concurrent use by multiple goroutines.
 func (db *DB) Close() error {
+	if db.main != nil {
+		return db.main.Close()
+	}
 	if db.bg != nil {
 		db.bg.Stop()
 	}
@@ -336,7 +457,7 @@ func (db *DB) Close() error {
 }
 
 // startBgManager starts the goroutine than runs
-// in the background and deletes expired keys.
+// in the background and deletes expired keys and hash fields.
 // Triggers every 60 seconds, deletes up all expired keys.
 func (db *DB) startBgManager() *time.Ticker {
 	// TODO: needs further investigation. Deleting all keys may be expensive
@@ -347,16 +468,38 @@ func (db *DB) startBgManager() *time.Ticker {
 	// so we have to use DELETE IN (SELECT ...), which is more expensive.
 	const interval = 60 * time.Second
 	const nKeys = 0
+	const nChanges = 100_000
 
 	ticker := time.NewTicker(interval)
 	go func() {
 		for range ticker.C {
+			start := time.Now()
 			count, err := db.keyDB.DeleteExpired(nKeys)
 			if err != nil {
 				db.log.Error("bg: delete expired keys", "error", err)
 			} else {
+				db.expiredKeys.Add(int64(count))
 				db.log.Info("bg: delete expired keys", "count", count)
 			}
+
+			count, err = db.hashDB.DeleteExpired()
+			if err != nil {
+				db.log.Error("bg: delete expired hash fields", "error", err)
+			} else {
+				db.expiredFields.Add(int64(count))
+				db.log.Info("bg: delete expired hash fields", "count", count)
+			}
+			db.expireTime.Store(int64(time.Since(start)))
+			db.expireCycles.Add(1)
+
+			if db.changes {
+				count, err = db.PruneChangeLog(nChanges)
+				if err != nil {
+					db.log.Error("bg: prune change log", "error", err)
+				} else {
+					db.log.Info("bg: prune change log", "count", count)
+				}
+			}
 		}
 	}()
 	return ticker
@@ -366,6 +509,8 @@ func (db *DB) startBgManager() *time.Ticker {
 // Same as [DB], Tx provides access to data structures like keys,
 // strings, and hashes. The difference is that you call Tx methods
 // within a transaction managed by [DB.Update] or [DB.View].
+// The transaction works with the logical database of the [DB]
+// handle that started it (see [DB.Select]).
 //
 // See the [tx] example for details.
 //
@@ -392,6 +537,13 @@ func newTx(tx sqlx.Tx) *Tx {
 	}
 }
 
+// Select returns a transaction that works with the logical
+// database with the given index, as part of the same
+// underlying database transaction (see [DB.Select]).
+func (tx *Tx) Select(index int) *Tx {
+	return newTx(sqlx.Scope(tx.tx, index))
+}
+
 // Hash returns the hash transaction.
 func (tx *Tx) Hash() *rhash.Tx {
 	return tx.hashTx
@@ -437,5 +589,6 @@ func applyOptions(opts Options, custom *Options) *Options {
 	if custom.Logger != nil {
 		opts.Logger = custom.Logger
 	}
+	opts.ChangeLog = custom.ChangeLog
 	return &opts
 }
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -21,8 +23,15 @@ func ExampleOpen() {
 }
 
 func ExampleOpenRead() {
+	dir, err := os.MkdirTemp("", "redka")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data.db")
+
 	// open a writable database
-	db, err := redka.Open("data.db", nil)
+	db, err := redka.Open(path, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -30,7 +39,7 @@ func ExampleOpenRead() {
 	db.Close()
 
 	// open a read-only database
-	db, err = redka.OpenRead("data.db", nil)
+	db, err = redka.OpenRead(path, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -112,6 +121,27 @@ func ExampleDB_Key() {
 	// keys: name city
 }
 
+func ExampleDB_Select() {
+	// Error handling is omitted for brevity.
+	// In real code, always check for errors.
+
+	db, _ := redka.Open("file:/data.db?vfs=memdb", nil)
+	defer db.Close()
+
+	_ = db.Str().Set("name", "alice")
+	db1 := db.Select(1)
+	_ = db1.Str().Set("name", "bob")
+
+	name, _ := db.Str().Get("name")
+	fmt.Printf("db%d name=%v\n", db.Index(), name)
+	name, _ = db1.Str().Get("name")
+	fmt.Printf("db%d name=%v\n", db1.Index(), name)
+
+	// Output:
+	// db0 name=alice
+	// db1 name=bob
+}
+
 func ExampleDB_Str() {
 	// Error handling is omitted for brevity.
 	// In real code, always check for errors.
@@ -317,6 +347,112 @@ func TestDBUpdateRollback(t *testing.T) {
 	testx.AssertEqual(t, age.MustInt(), 25)
 }
 
+func TestDBSelect(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	db3 := db.Select(3)
+	testx.AssertEqual(t, db3.Index(), 3)
+	testx.AssertEqual(t, db3.Select(3) == db3, true)
+	testx.AssertEqual(t, db3.Select(0) == db, true)
+	testx.AssertEqual(t, db.Select(3) == db3, true)
+
+	_ = db.Str().Set("name", "alice")
+	_ = db3.Str().Set("name", "bob")
+	_ = db3.Str().Set("age", 25)
+
+	name, _ := db.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "alice")
+	name, _ = db3.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+	count, _ := db.Key().Len()
+	testx.AssertEqual(t, count, 1)
+	count, _ = db3.Key().Len()
+	testx.AssertEqual(t, count, 2)
+
+	err := db3.Update(func(tx *redka.Tx) error {
+		_, err := tx.Key().Delete("name")
+		return err
+	})
+	testx.AssertNoErr(t, err)
+	name, _ = db.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "alice")
+	exists, _ := db3.Key().Exists("name")
+	testx.AssertEqual(t, exists, false)
+}
+
+func TestTxSelect(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	err := db.Update(func(tx *redka.Tx) error {
+		if err := tx.Str().Set("name", "alice"); err != nil {
+			return err
+		}
+		tx2 := tx.Select(2)
+		if err := tx2.Str().Set("name", "bob"); err != nil {
+			return err
+		}
+		// Selecting again switches to another database.
+		return tx2.Select(0).Str().Set("age", 25)
+	})
+	testx.AssertNoErr(t, err)
+
+	name, _ := db.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "alice")
+	age, _ := db.Str().Get("age")
+	testx.AssertEqual(t, age.String(), "25")
+	name, _ = db.Select(2).Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+}
+
+func TestDBSnapshot(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+	_ = db.Str().Set("name", "alice")
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	err := db.Snapshot(path)
+	testx.AssertNoErr(t, err)
+
+	// Overwrites the existing snapshot.
+	_ = db.Str().Set("name", "bob")
+	err = db.Snapshot(path)
+	testx.AssertNoErr(t, err)
+
+	snap, err := redka.OpenRead(path, nil)
+	testx.AssertNoErr(t, err)
+	defer snap.Close()
+	name, _ := snap.Str().Get("name")
+	testx.AssertEqual(t, name.String(), "bob")
+}
+
+func TestDBStats(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data.db")
+		db, err := redka.Open(path, nil)
+		testx.AssertNoErr(t, err)
+		defer db.Close()
+		_ = db.Str().Set("name", "alice")
+
+		stats, err := db.Stats()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, stats.PageCount > 0, true)
+		testx.AssertEqual(t, stats.PageSize > 0, true)
+		testx.AssertEqual(t, stats.WALSize > 0, true)
+		testx.AssertEqual(t, stats.ExpiredKeys, int64(0))
+	})
+	t.Run("memory", func(t *testing.T) {
+		db := getDB(t)
+		defer db.Close()
+
+		stats, err := db.Stats()
+		testx.AssertNoErr(t, err)
+		testx.AssertEqual(t, stats.PageSize > 0, true)
+		testx.AssertEqual(t, stats.WALSize, int64(0))
+	})
+}
+
 func getDB(tb testing.TB) *redka.DB {
 	tb.Helper()
 	db, err := redka.Open("file:/data.db?vfs=memdb", nil)